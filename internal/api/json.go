@@ -10,7 +10,7 @@ import (
 const (
 	Success string = "success" //The request ended successfully
 	Error   string = "error"   //The request ended with error - check the message field
-	//Running      string = "running"      //The request was accepted but the job which for which is called is in running state
+	Running string = "running" //The request was accepted but the job which for which is called is in running state
 	//Pending      string = "pending"      //The request was accepted but is in a pending state
 	//Unauthorized string = "unauthorized" //The request ended because you are not allowed to access that resource
 )
@@ -35,6 +35,16 @@ func NewErrorResponse(message string) gin.H {
 	}
 }
 
+// NewCodedErrorResponse is like NewErrorResponse, but it also carries a machine-readable code (e.g. "token_expired")
+func NewCodedErrorResponse(code string, message string) gin.H {
+	return gin.H{
+		"status":  Error,
+		"code":    code,
+		"message": message,
+		"data":    gin.H{},
+	}
+}
+
 func NewErrorResponsef(format string, a ...interface{}) gin.H {
 	return gin.H{
 		"status":  Error,
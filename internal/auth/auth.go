@@ -5,7 +5,6 @@ import (
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/models"
 	"errors"
-	"golang.org/x/crypto/bcrypt"
 )
 
 const usernamePasswordFalse = "username or password false"
@@ -23,9 +22,10 @@ func (c *AuthService) DoLogin(user *models.User) error {
 		return errors.New(usernamePasswordFalse)
 	}
 	err = models.VerifyPassword(foundUser.Password, user.Password)
-	if err != nil && errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+	if err != nil {
 		return errors.New(usernamePasswordFalse)
 	}
 	user.ID = foundUser.ID
+	user.Roles = foundUser.Roles
 	return nil
 }
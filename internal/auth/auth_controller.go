@@ -3,11 +3,14 @@ package auth
 import (
 	"context"
 	"dice-sorensen-similarity-search/internal/api"
+	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/middlewares"
 	"dice-sorensen-similarity-search/internal/models"
+	"errors"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
+	"github.com/samborkent/uuidv7"
 	"io"
 	"net/http"
 	"strings"
@@ -30,6 +33,15 @@ type Api interface {
 
 	// GetAuthToken validates credentials and issues a token without session context.
 	GetAuthToken(c *gin.Context)
+
+	// CreateUser provisions a new user with a hashed password
+	CreateUser(c *gin.Context)
+
+	// Logout revokes the token the request was authorized with
+	Logout(c *gin.Context)
+
+	// Me returns the username, roles, and expiry of the token the request was authorized with
+	Me(c *gin.Context)
 }
 
 // Controller wires environment dependencies with authentication service methods.
@@ -37,6 +49,17 @@ type Api interface {
 type Controller struct {
 	*environment.Env
 	*AuthService
+
+	// TokenKeys are the keys the JWTs are signed and validated with
+	TokenKeys *middlewares.TokenKeys
+	// TokenTTL is the lifetime of an issued token (default: middlewares.DefaultTokenTTL)
+	TokenTTL time.Duration
+	// RefreshTTL is the lifetime of a refreshed token (default: middlewares.DefaultRefreshTTL)
+	RefreshTTL time.Duration
+	// Denylist keeps track of revoked tokens; if nil, tokens cannot be revoked
+	Denylist middlewares.TokenDenylist
+	// BcryptCost is the cost of the bcrypt hashes of passwords (default: bcrypt.DefaultCost)
+	BcryptCost int
 }
 
 // ensure Controller implements Api
@@ -45,7 +68,7 @@ var _ Api = &Controller{}
 func (ac *Controller) Login(c *gin.Context) {
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		ac.LogErrorf(nil, "Error reading login info: %v", err)
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error reading login info: %v", err)
 		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("Error reading login info"))
 		return
 	}
@@ -53,7 +76,7 @@ func (ac *Controller) Login(c *gin.Context) {
 	request := api.GenericRequest{}
 	err = request.Load(body)
 	if err != nil {
-		ac.LogErrorf(nil, "Error loading request data: %v", err)
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error loading request data: %v", err)
 		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("Error reading login info"))
 		return
 	}
@@ -61,14 +84,14 @@ func (ac *Controller) Login(c *gin.Context) {
 	user := models.User{}
 	err = request.DecodeDataTo(&user)
 	if err != nil {
-		ac.LogErrorf(nil, "Error loading user data: %v", err)
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error loading user data: %v", err)
 		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("Error reading user info"))
 		return
 	}
 	user.Prepare()
 	err = user.Validate()
 	if err != nil {
-		ac.LogErrorf(nil, "Error validating user: %v", err)
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error validating user: %v", err)
 		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponsef("Error validating User: %v", err))
 		return
 	}
@@ -79,8 +102,12 @@ func (ac *Controller) Login(c *gin.Context) {
 		return
 	}
 
-	//issue token
-	token, _, err := middlewares.GenerateToken(context.Background(), []byte(middlewares.SigningKey), 0, user.Username, []string{"admin"})
+	// the token carries the stored user's roles; users stored before roles were introduced are readers
+	roles := []string(user.Roles)
+	if len(roles) == 0 {
+		roles = []string{models.RoleReader}
+	}
+	token, _, err := middlewares.GenerateToken(context.Background(), ac.TokenKeys, ac.TokenTTL, 0, user.Username, roles)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadGateway, api.NewErrorResponse("Error creating JWT"))
 		return
@@ -99,19 +126,25 @@ func (ac *Controller) RefreshToken(c *gin.Context) {
 		return
 	}
 
-	token, err := middlewares.ValidateToken(t[1], middlewares.SigningKey)
+	token, err := middlewares.ValidateToken(t[1], ac.TokenKeys, ac.Denylist)
 	if err != nil {
-		c.JSON(http.StatusUnprocessableEntity, err.Error())
+		c.AbortWithStatusJSON(http.StatusUnauthorized, middlewares.TokenErrorResponse(err))
 		return
 	}
 
 	claims := token.Claims.(*middlewares.CimClaims)
-	claims.ExpiresAt = time.Now().Add(43200 * time.Second).Unix()
+	// the refreshed token must be revocable independently of the old one
+	claims.Id = uuidv7.New().String()
+	refreshTTL := ac.RefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = middlewares.DefaultRefreshTTL
+	}
+
+	claims.ExpiresAt = time.Now().Add(refreshTTL).Unix()
 	claims.IssuedAt = time.Now().Unix()
 
 	// Create the token
-	newToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := newToken.SignedString([]byte(middlewares.SigningKey))
+	tokenString, err := ac.TokenKeys.Sign(claims)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadGateway, api.NewErrorResponse("Error refreshing JWT"))
 		return
@@ -120,9 +153,16 @@ func (ac *Controller) RefreshToken(c *gin.Context) {
 
 }
 
+// CreatePasswordHash hashes the password given by the path parameter pw with the configured BcryptCost;
+// an empty password is rejected with 400 instead of being hashed
 func (ac *Controller) CreatePasswordHash(c *gin.Context) {
 	password := c.Param("pw")
-	hashPw, hashErr := models.Hash(password)
+	if len(password) == 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse("no password provided"))
+		return
+	}
+
+	hashPw, hashErr := models.Hash(password, ac.BcryptCost)
 	if hashErr != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("an error occurred"))
 		return
@@ -141,28 +181,158 @@ func (ac *Controller) CreatePasswordHash(c *gin.Context) {
 // @Success		200	{object}	api.RestJsonResponse{data=string}
 // @Failure 401 {object} api.RestJsonResponse{data=string}
 func (ac *Controller) GetAuthToken(c *gin.Context) {
-	ac.LogInfo(nil, "getting auth token")
+	ac.LogInfo(logging.GetLogTypeWithContext(c.Request.Context()), "getting auth token")
 
 	genericToken := c.GetHeader("Authorization")
 	if genericToken == "" {
-		ac.LogErrorf(nil, "missing Authorization header")
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "missing Authorization header")
 		c.AbortWithStatusJSON(http.StatusForbidden, api.NewErrorResponsef("no token provided"))
 		return
 	}
 
 	if genericToken != "generic" {
-		ac.LogErrorf(nil, "the initial token does not match \\'generic\\'; invalid token provided")
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "the initial token does not match \\'generic\\'; invalid token provided")
 		c.AbortWithStatusJSON(http.StatusForbidden, api.NewErrorResponsef("invalid token"))
 		return
 	}
 
-	token, expiresAt, err := middlewares.GenerateToken(context.Background(), []byte(middlewares.SigningKey), 0, genericToken, []string{"admin"})
+	// the bootstrap token only grants read access; admin endpoints require the token of a user with RoleAdmin (see Login)
+	token, expiresAt, err := middlewares.GenerateToken(context.Background(), ac.TokenKeys, ac.TokenTTL, 0, genericToken, []string{models.RoleReader})
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadGateway, api.NewErrorResponse("Error creating JWT"))
 		return
 	}
 
-	ac.LogInfof(nil, "successfully generated token: %s", token)
-	ac.LogInfof(nil, "successfully generated token; it expires in: %ds", expiresAt.Second())
+	ac.LogInfof(logging.GetLogTypeWithContext(c.Request.Context()), "successfully generated token: %s", token)
+	ac.LogInfof(logging.GetLogTypeWithContext(c.Request.Context()), "successfully generated token; it expires in: %ds", expiresAt.Second())
 	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "", token))
 }
+
+// CreateUser provisions a new user; the password is stored as a bcrypt hash.
+//
+// @ID createUser
+// @Summary Create a user
+// @Tags auth
+// @Router /auth/users [post]
+// @Param request body api.GenericRequest true "The user's username, email (optional), and password in the data property"
+// @Success		201	{object}	api.RestJsonResponse{data=models.User}
+// @Failure 409 {object} api.RestJsonResponse{data=string}
+// @Failure 422 {object} api.RestJsonResponse{data=string}
+func (ac *Controller) CreateUser(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error reading user info: %v", err)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("Error reading user info"))
+		return
+	}
+
+	request := api.GenericRequest{}
+	err = request.Load(body)
+	if err != nil {
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error loading request data: %v", err)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("Error reading user info"))
+		return
+	}
+
+	user := models.User{}
+	err = request.DecodeDataTo(&user)
+	if err != nil {
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error loading user data: %v", err)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("Error reading user info"))
+		return
+	}
+	user.Prepare()
+	err = user.Validate()
+	if err != nil {
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error validating user: %v", err)
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponsef("Error validating User: %v", err))
+		return
+	}
+
+	hashPw, err := models.Hash(user.Password, ac.BcryptCost)
+	if err != nil {
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error hashing password: %v", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse("Error creating user"))
+		return
+	}
+	user.Password = string(hashPw)
+
+	err = ac.Repository.CreateUser(c.Request.Context(), &user)
+	if errors.Is(err, database.ErrDuplicateUsername) {
+		c.AbortWithStatusJSON(http.StatusConflict, api.NewErrorResponsef("a user named %s already exists", user.Username))
+		return
+	}
+	if err != nil {
+		ac.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context()), "Error creating user: %v", err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse("Error creating user"))
+		return
+	}
+
+	ac.LogInfof(logging.GetLogTypeWithContext(c.Request.Context()), "created user %s", user.Username)
+	c.JSON(http.StatusCreated, api.NewGenericResponse(api.Success, "", user))
+}
+
+// Logout revokes the token the request was authorized with; it is rejected from then on, even before it expires.
+//
+// @ID logout
+// @Summary Revoke the authentication token
+// @Tags auth
+// @Router /auth/logout [post]
+// @Param Authorization header string true "The token to revoke, prefixed with 'Bearer '"
+// @Success		200	{object}	api.RestJsonResponse{data=string}
+// @Failure 401 {object} api.RestJsonResponse{data=string}
+// @Failure 422 {object} api.RestJsonResponse{data=string}
+func (ac *Controller) Logout(c *gin.Context) {
+	t := strings.Split(c.Request.Header.Get("Authorization"), "Bearer ")
+	if len(t) < 2 {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, api.NewErrorResponse("An authorization token was not supplied"))
+		return
+	}
+
+	token, err := middlewares.ValidateToken(t[1], ac.TokenKeys, ac.Denylist)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, middlewares.TokenErrorResponse(err))
+		return
+	}
+
+	claims := token.Claims.(*middlewares.CimClaims)
+	if ac.Denylist == nil || len(claims.Id) == 0 {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, api.NewErrorResponse("The token cannot be revoked"))
+		return
+	}
+
+	ac.Denylist.Revoke(claims.Id, time.Unix(claims.ExpiresAt, 0))
+	ac.LogInfof(logging.GetLogTypeWithContext(c.Request.Context()), "revoked token of user %s", claims.Username)
+	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "logged out", ""))
+}
+
+// CurrentUser describes the user of the token a request was authorized with
+type CurrentUser struct {
+	Username  string    `json:"username"`
+	Roles     []string  `json:"roles"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Me returns the claims of the token the request was authorized with; it requires AuthHandler to run first.
+//
+// @ID me
+// @Summary Get the current user
+// @Tags auth
+// @Router /auth/me [get]
+// @Param Authorization header string true "The token of the current user, prefixed with 'Bearer '"
+// @Success		200	{object}	api.RestJsonResponse{data=CurrentUser}
+// @Failure 401 {object} api.RestJsonResponse{data=string}
+func (ac *Controller) Me(c *gin.Context) {
+	claims, ok := middlewares.ClaimsFromContext(c)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, api.NewErrorResponse("The request was not authorized with a token"))
+		return
+	}
+
+	currentUser := CurrentUser{
+		Username:  claims.Username,
+		Roles:     claims.Roles,
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0).UTC(),
+	}
+	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "", currentUser))
+}
@@ -0,0 +1,543 @@
+package auth_test
+
+import (
+	"bytes"
+	"context"
+	"dice-sorensen-similarity-search/internal/api"
+	"dice-sorensen-similarity-search/internal/auth"
+	"dice-sorensen-similarity-search/internal/database"
+	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"dice-sorensen-similarity-search/internal/models"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// mockRepository keeps the created users in memory; all other methods are no-ops
+type mockRepository struct {
+	database.NullRepository
+	users map[string]models.User
+}
+
+func newMockRepository() *mockRepository {
+	return &mockRepository{users: map[string]models.User{}}
+}
+
+func (m *mockRepository) CreateUser(_ context.Context, user *models.User) error {
+	if _, ok := m.users[user.Username]; ok {
+		return database.ErrDuplicateUsername
+	}
+
+	user.ID = uint(len(m.users) + 1)
+	m.users[user.Username] = *user
+	return nil
+}
+
+func (m *mockRepository) FindUserLoginCredentials(_ context.Context, username string, user *models.User) error {
+	found, ok := m.users[username]
+	if !ok {
+		return errors.New("record not found")
+	}
+
+	*user = found
+	return nil
+}
+
+func newMockController(repo database.Repository) *auth.Controller {
+	env := environment.Environment(repo, nil)
+	return &auth.Controller{Env: env, AuthService: &auth.AuthService{Env: env}}
+}
+
+// performCreateUserRequest sends the given user data to the CreateUser handler of the given controller
+func performCreateUserRequest(t *testing.T, ctrl *auth.Controller, data map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(api.GenericRequest{Data: data})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/auth/users", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.CreateUser(c)
+
+	return w
+}
+
+func TestCreateUser_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	ctrl := newMockController(repo)
+
+	w := performCreateUserRequest(t, ctrl, map[string]interface{}{
+		"username": " jane ",
+		"email":    "jane@example.com",
+		"password": "secret",
+	})
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want status 201, got %d: %s", w.Code, w.Body.String())
+	}
+
+	created, ok := repo.users["jane"]
+	if !ok {
+		t.Fatalf("want user jane to be persisted, got %v", repo.users)
+	}
+
+	if created.Email != "jane@example.com" {
+		t.Errorf("want email jane@example.com, got %s", created.Email)
+		return
+	}
+
+	if err := models.VerifyPassword(created.Password, "secret"); err != nil {
+		t.Errorf("want the persisted password to be a hash of the given one, got %v", err)
+		return
+	}
+
+	if bytes.Contains(w.Body.Bytes(), []byte(created.Password)) {
+		t.Error("want the password hash to be omitted from the response")
+		return
+	}
+}
+
+func performCreatePasswordHashRequest(ctrl *auth.Controller, password string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/hash/"+password, nil)
+	c.Params = gin.Params{{Key: "pw", Value: password}}
+
+	ctrl.CreatePasswordHash(c)
+
+	return w
+}
+
+func TestCreatePasswordHash_BcryptCost(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		cost     int
+		wantCost int
+	}{
+		{name: "default", wantCost: bcrypt.DefaultCost},
+		{name: "configured", cost: bcrypt.MinCost + 1, wantCost: bcrypt.MinCost + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(newMockRepository())
+			ctrl.BcryptCost = tt.cost
+
+			w := performCreatePasswordHashRequest(ctrl, "secret")
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var response struct {
+				Data string `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if err := models.VerifyPassword(response.Data, "secret"); err != nil {
+				t.Fatalf("want a hash of the given password, got %v", err)
+			}
+
+			gotCost, err := bcrypt.Cost([]byte(response.Data))
+			if err != nil || gotCost != tt.wantCost {
+				t.Errorf("want cost %d, got %d (%v)", tt.wantCost, gotCost, err)
+				return
+			}
+		})
+	}
+}
+
+func TestCreatePasswordHash_EmptyPassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := performCreatePasswordHashRequest(newMockController(newMockRepository()), "")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want status 400, got %d", w.Code)
+		return
+	}
+}
+
+func TestCreateUser_ValidationFailure(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		data map[string]interface{}
+	}{
+		{name: "missingUsername", data: map[string]interface{}{"username": "  ", "password": "secret"}},
+		{name: "missingPassword", data: map[string]interface{}{"username": "jane"}},
+		{name: "invalidEmail", data: map[string]interface{}{"username": "jane", "email": "no-email", "password": "secret"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMockRepository()
+			w := performCreateUserRequest(t, newMockController(repo), tt.data)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("want status 422, got %d", w.Code)
+				return
+			}
+
+			if len(repo.users) > 0 {
+				t.Errorf("want no user to be persisted, got %v", repo.users)
+				return
+			}
+		})
+	}
+}
+
+func TestCreateUser_DuplicateUsername(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	data := map[string]interface{}{"username": "jane", "password": "secret"}
+
+	if w := performCreateUserRequest(t, ctrl, data); w.Code != http.StatusCreated {
+		t.Fatalf("want status 201, got %d", w.Code)
+	}
+
+	w := performCreateUserRequest(t, ctrl, data)
+	if w.Code != http.StatusConflict {
+		t.Errorf("want status 409, got %d", w.Code)
+		return
+	}
+}
+
+// performLogoutRequest sends a logout request authorized with the given token to the given controller
+func performLogoutRequest(t *testing.T, ctrl *auth.Controller, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, "/auth/logout", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.Logout(c)
+
+	return w
+}
+
+func TestLogout_RevokesToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	ctrl.TokenKeys = middlewares.NewHMACTokenKeys("key")
+	ctrl.Denylist = middlewares.NewMemoryDenylist()
+
+	token, _, err := middlewares.GenerateToken(context.Background(), ctrl.TokenKeys, time.Hour, 1, "jane", []string{"reader"})
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+
+	if w := performLogoutRequest(t, ctrl, token); w.Code != http.StatusOK {
+		t.Fatalf("want status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	if _, err := middlewares.ValidateToken(token, ctrl.TokenKeys, ctrl.Denylist); !errors.Is(err, middlewares.ErrTokenRevoked) {
+		t.Errorf("want ErrTokenRevoked after logout, got %v", err)
+	}
+
+	if w := performLogoutRequest(t, ctrl, token); w.Code != http.StatusUnauthorized {
+		t.Errorf("want status 401 for a revoked token, got %d", w.Code)
+		return
+	}
+}
+
+func performRefreshTokenRequest(t *testing.T, ctrl *auth.Controller, token string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, "/auth/refresh", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.RefreshToken(c)
+
+	return w
+}
+
+func TestRefreshToken_RejectedTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middlewares.NewHMACTokenKeys("key")
+
+	expired, err := keys.Sign(&middlewares.CimClaims{
+		Username:       "jane",
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	valid, _, err := middlewares.GenerateToken(context.Background(), keys, time.Hour, 0, "jane", []string{"reader"})
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+
+	// an expired token signed with another key must not be reported as merely expired
+	expiredAndForeign, err := middlewares.NewHMACTokenKeys("another-key").Sign(&middlewares.CimClaims{
+		Username:       "jane",
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode string
+	}{
+		{name: "expired", token: expired, wantCode: middlewares.TokenExpiredCode},
+		{name: "tampered", token: valid[:len(valid)-2] + "xx", wantCode: middlewares.TokenInvalidCode},
+		{name: "expiredAndSignedWithAnotherKey", token: expiredAndForeign, wantCode: middlewares.TokenInvalidCode},
+		{name: "malformed", token: "not-a-jwt", wantCode: middlewares.TokenInvalidCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(newMockRepository())
+			ctrl.TokenKeys = keys
+
+			w := performRefreshTokenRequest(t, ctrl, tt.token)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("want status 401, got %d", w.Code)
+			}
+
+			var body struct {
+				Status string `json:"status"`
+				Code   string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if body.Status != api.Error || body.Code != tt.wantCode {
+				t.Errorf("want status %q and code %q, got %q and %q", api.Error, tt.wantCode, body.Status, body.Code)
+				return
+			}
+		})
+	}
+}
+
+// tokenRoles returns the roles carried by the token in the data of the given response
+func tokenRoles(t *testing.T, ctrl *auth.Controller, w *httptest.ResponseRecorder) []string {
+	t.Helper()
+
+	var body struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	token, err := middlewares.ValidateToken(body.Data, ctrl.TokenKeys, nil)
+	if err != nil {
+		t.Fatalf("ValidateToken error: %v", err)
+	}
+
+	return token.Claims.(*middlewares.CimClaims).Roles
+}
+
+func TestLogin_RolesOfStoredUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	hash, err := models.Hash("secret", bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Hash error: %v", err)
+	}
+
+	repo := newMockRepository()
+	repo.users["jane"] = models.User{Username: "jane", Password: string(hash), Roles: models.Roles{models.RoleAdmin}}
+	repo.users["john"] = models.User{Username: "john", Password: string(hash)}
+
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		wantRoles []string
+	}{
+		{name: "admin", data: map[string]interface{}{"username": "jane", "password": "secret"}, wantRoles: []string{models.RoleAdmin}},
+		{name: "withoutRoles", data: map[string]interface{}{"username": "john", "password": "secret"}, wantRoles: []string{models.RoleReader}},
+		// the roles of the request are ignored
+		{name: "requestedRoles", data: map[string]interface{}{"username": "john", "password": "secret", "roles": []string{models.RoleAdmin}}, wantRoles: []string{models.RoleReader}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(repo)
+			ctrl.TokenKeys = middlewares.NewHMACTokenKeys("key")
+
+			body, err := json.Marshal(api.GenericRequest{Data: tt.data})
+			if err != nil {
+				t.Fatalf("failed to marshal payload: %v", err)
+			}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+
+			ctrl.Login(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("want status 200, got %d: %s", w.Code, w.Body.String())
+			}
+
+			if got := tokenRoles(t, ctrl, w); !cmp.Equal(tt.wantRoles, got) {
+				t.Error(cmp.Diff(tt.wantRoles, got))
+				return
+			}
+		})
+	}
+}
+
+func TestLogin_RejectsUnverifiablePassword(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the stored password is not a bcrypt hash, so it can never be verified
+	repo := newMockRepository()
+	repo.users["jane"] = models.User{Username: "jane", Password: "secret", Roles: models.Roles{models.RoleAdmin}}
+
+	ctrl := newMockController(repo)
+	ctrl.TokenKeys = middlewares.NewHMACTokenKeys("key")
+
+	body, err := json.Marshal(api.GenericRequest{Data: map[string]interface{}{"username": "jane", "password": "secret"}})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/auth/login", bytes.NewBuffer(body))
+
+	ctrl.Login(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("want status 401, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Status string                 `json:"status"`
+		Data   map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if response.Status != api.Error || len(response.Data) != 0 {
+		t.Errorf("want status %q without a token, got %q and %v", api.Error, response.Status, response.Data)
+		return
+	}
+}
+
+func TestGetAuthToken_ReaderOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	ctrl.TokenKeys = middlewares.NewHMACTokenKeys("key")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/token", nil)
+	c.Request.Header.Set("Authorization", "generic")
+
+	ctrl.GetAuthToken(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	want := []string{models.RoleReader}
+	if got := tokenRoles(t, ctrl, w); !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestMe_ReturnsClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	ctrl.TokenKeys = middlewares.NewHMACTokenKeys("key")
+
+	token, expiresAt, err := middlewares.GenerateToken(context.Background(), ctrl.TokenKeys, time.Hour, 0, "jane", []string{"reader", "admin"})
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/auth/me", middlewares.AuthHandler(ctrl.TokenKeys, nil, "reader"), ctrl.Me)
+
+	req := httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200, got %d", w.Code)
+	}
+
+	var body struct {
+		Data auth.CurrentUser `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	want := auth.CurrentUser{Username: "jane", Roles: []string{"reader", "admin"}, ExpiresAt: time.Unix(expiresAt.Unix(), 0).UTC()}
+	if !cmp.Equal(want, body.Data) {
+		t.Error(cmp.Diff(want, body.Data))
+		return
+	}
+}
+
+func TestMe_WithoutClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/auth/me", nil)
+
+	newMockController(newMockRepository()).Me(c)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("want status 401, got %d", w.Code)
+		return
+	}
+}
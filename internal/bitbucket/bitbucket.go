@@ -3,10 +3,13 @@ package bitbucket
 import (
 	"context"
 	"dice-sorensen-similarity-search/internal/config"
+	"dice-sorensen-similarity-search/internal/constants"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/logging"
 	"fmt"
 	"github.com/gfleury/go-bitbucket-v1"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 )
@@ -22,12 +25,13 @@ type Result struct {
 
 // BitbucketApiServiceAdapter wraps an abstraction layer around the Bitbucket APIs
 // that are used within the application for retrieving repository content and file streams.
+// The calls are not retried (see RetryingAdapter) once the given context is done.
 //
 // @Summary Interface for Bitbucket API data access
 type BitbucketApiServiceAdapter interface {
-	GetContent(projectKey string, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error)
-	GetRawContent(projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error)
-	StreamFiles(projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error)
+	GetContent(ctx context.Context, projectKey string, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error)
+	GetRawContent(ctx context.Context, projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error)
+	StreamFiles(ctx context.Context, projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error)
 }
 
 // BitbucketApiClient is a concrete implementation of BitbucketApiServiceAdapter.
@@ -36,53 +40,56 @@ type BitbucketApiServiceAdapter interface {
 // domain logic to the underlying API implementation.
 //
 // Based on the Dependency Inversion Principle [DIP].
+// The requests are bound to the context the APIClient was created with; the client does not support a context per request.
 //
 // [DIP]: https://en.wikipedia.org/wiki/Dependency_inversion_principle
 type BitbucketApiClient struct {
 	*bitbucketv1.APIClient
 }
 
-func (a *BitbucketApiClient) GetContent(projectKey string, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+func (a *BitbucketApiClient) GetContent(_ context.Context, projectKey string, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
 	return a.DefaultApi.GetContent(projectKey, repositorySlug, localVarOptionals)
 }
 
-func (a *BitbucketApiClient) GetRawContent(projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+func (a *BitbucketApiClient) GetRawContent(_ context.Context, projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
 	return a.DefaultApi.GetRawContent(projectKey, repositorySlug, path, localVarOptionals)
 }
 
-func (a *BitbucketApiClient) StreamFiles(projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+func (a *BitbucketApiClient) StreamFiles(_ context.Context, projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
 	return a.DefaultApi.StreamFiles(projectKey, repositorySlug, localVarOptionals)
 }
 
 // BitbucketReader defines methods for extracting content and structure
 // from a Bitbucket repository relevant to Markdown documentation.
+// The reads honor the cancellation and deadline of the given context and return its error once it is done.
 //
 // @Summary Interface for reading Markdown content and structure from Bitbucket
 type BitbucketReader interface {
 
 	// ReadMarkdownFileStructureRecursively reads the full file structure of a repository,
-	// returning only the Markdown (.md) files under the "markdowns/" root-level folder.
+	// returning only the Markdown files (e.g. .md) under the docs root folder (e.g., "markdowns/").
 	//
 	// Param projectName path string true "Bitbucket project key"
 	// Param repoName path string true "Bitbucket repository name"
+	// Param revision query string false "Git reference or commit hash (e.g. 'refs/tags/v1.0.0' or 'a1b2c3d'); empty for the default branch"
 	// Param start query int false "Pagination start offset"
 	// Param limit query int false "Pagination limit"
-	ReadMarkdownFileStructureRecursively(projectName, repoName string, start, limit int) ([]string, error)
+	ReadMarkdownFileStructureRecursively(ctx context.Context, projectName, repoName, revision string, start, limit int) ([]string, error)
 
 	// ReadRepoRootFolderContent returns names of all direct children (files and folders)
 	// in the root folder of the specified Bitbucket repository.
 	//
 	// Param projectName path string true "Bitbucket project key"
 	// Param repoName path string true "Bitbucket repository name"
-	ReadRepoRootFolderContent(projectName, repoName string) ([]string, error)
+	ReadRepoRootFolderContent(ctx context.Context, projectName, repoName string) ([]string, error)
 
 	// ReadFileContentAtRevision reads the raw content of a single file at the specified revision.
 	//
 	// Param projectName path string true "Bitbucket project key"
 	// Param repoName path string true "Bitbucket repository name"
 	// Param filePath path string true "Path to file in repository"
-	// Param revision query string true "Git reference or tag (e.g. 'main' or 'v1.0.0')"
-	ReadFileContentAtRevision(projectName, repoName string, filePath string, revision string) (string, error)
+	// Param revision query string false "Git reference or commit hash (e.g. 'refs/tags/v1.0.0' or 'a1b2c3d'); empty for the default branch"
+	ReadFileContentAtRevision(ctx context.Context, projectName, repoName string, filePath string, revision string) (string, error)
 }
 
 // O11yBitbucketReader provides a concrete implementation of BitbucketReader that
@@ -92,17 +99,45 @@ type BitbucketReader interface {
 type O11yBitbucketReader struct {
 	*environment.Env
 	Adapter BitbucketApiServiceAdapter
+
+	// DocsRoot is the root-level folder containing the Markdown files (default: markdowns)
+	DocsRoot string
+	// Extensions are the file extensions of Markdown files (default: .md)
+	Extensions []string
+}
+
+func (obbr *O11yBitbucketReader) docsRoot() string {
+	if len(obbr.DocsRoot) == 0 {
+		return constants.DefaultDocsRoot
+	}
+
+	return obbr.DocsRoot
+}
+
+// HasMarkdownExtension reports whether the file's extension is one of the given extensions (e.g. ".md", ".mdx");
+// if no extensions are given, only constants.DefaultMarkdownExtension is accepted.
+func HasMarkdownExtension(filePath string, extensions []string) bool {
+	extension := filepath.Ext(filePath)
+	if len(extensions) == 0 {
+		return extension == constants.DefaultMarkdownExtension
+	}
+
+	return slices.Contains(extensions, extension)
 }
 
 // ReadRepoRootFolderContent fetches the content of the given remote Bitbucket repository's root folder
 //
 // Returns a slice of top-level file/folder names or an error if extraction fails.
-func (obbr *O11yBitbucketReader) ReadRepoRootFolderContent(projectName, repoName string) ([]string, error) {
+func (obbr *O11yBitbucketReader) ReadRepoRootFolderContent(ctx context.Context, projectName, repoName string) ([]string, error) {
 	if obbr.Adapter == nil {
 		return nil, fmt.Errorf("bitbucket API not initialized")
 	}
 
-	bitbucketResponse, err := obbr.Adapter.GetContent(projectName, repoName, nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	bitbucketResponse, err := obbr.Adapter.GetContent(ctx, projectName, repoName, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error reading file structure from Bitbucket: %w", err)
 	}
@@ -159,17 +194,23 @@ func (obbr *O11yBitbucketReader) ReadRepoRootFolderContent(projectName, repoName
 
 // ReadFileContentAtRevision retrieves the raw contents of the specified file at a given revision.
 //
-// Note: the revision string may be a tag or commit hash.
-func (obbr *O11yBitbucketReader) ReadFileContentAtRevision(projectName, repoName, filePath, revision string) (string, error) {
+// Note: the revision string may be a fully qualified ref (e.g. refs/tags/v1.0.0 or refs/heads/main) or a commit hash.
+// It is passed to Bitbucket as is; if it is empty, the file is read from the repository's default branch.
+func (obbr *O11yBitbucketReader) ReadFileContentAtRevision(ctx context.Context, projectName, repoName, filePath, revision string) (string, error) {
 	if obbr.Adapter == nil {
 		return "", fmt.Errorf("bitbucket API not initialized")
 	}
 
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
 	params := make(map[string]any)
-	// todo use the revision
-	//params["at"] = fmt.Sprintf("refs/tags/%v", revision)
+	if len(revision) > 0 {
+		params["at"] = revision
+	}
 
-	bitbucketResponse, err := obbr.Adapter.GetRawContent(projectName, repoName, filePath, params)
+	bitbucketResponse, err := obbr.Adapter.GetRawContent(ctx, projectName, repoName, filePath, params)
 	if err != nil {
 		return "", err
 	}
@@ -182,64 +223,90 @@ func (obbr *O11yBitbucketReader) ReadFileContentAtRevision(projectName, repoName
 }
 
 // ReadMarkdownFileStructureRecursively recursively traverses the Bitbucket repository,
-// collecting absolute paths of all .md files located under the root-level `markdowns/` directory.
+// collecting absolute paths of all Markdown files located under the root-level docs root directory (e.g., `markdowns/`).
 //
-// Only Markdown (.md) files are included. Returns a list of file paths or an error.
-func (obbr *O11yBitbucketReader) ReadMarkdownFileStructureRecursively(projectName, repoName string, start, limit int) ([]string, error) {
+// The repository is traversed at the given revision (see ReadFileContentAtRevision), so that the listed files exist there.
+// Only files with one of the Extensions (default: .md) are included; other files are skipped and logged at debug level.
+// The context is checked before each page; once it is done, the pagination stops and the context's error is returned.
+// Returns a list of file paths or an error.
+func (obbr *O11yBitbucketReader) ReadMarkdownFileStructureRecursively(ctx context.Context, projectName, repoName, revision string, start, limit int) ([]string, error) {
 	if obbr.Adapter == nil {
 		return nil, fmt.Errorf("bitbucket API not initialized")
 	}
 
+	// stops the pagination if the consumer returns early, e.g., on an invalid page
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	m := make(map[string]any)
 	m["start"] = start
 	m["limit"] = limit
+	if len(revision) > 0 {
+		m["at"] = revision
+	}
 
 	read := func() <-chan Result {
 		outStream := make(chan Result)
 
+		// send reports whether the result was sent before the context was done
+		send := func(result Result) bool {
+			select {
+			case outStream <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
 		go func() {
 			defer close(outStream)
 
 			var reachedLastPage bool
 
 			for !reachedLastPage {
+				if ctx.Err() != nil {
+					return
+				}
+
 				s := time.Now()
 				obbr.LogInfo(nil, "start fetching file structure")
-				bitbucketResponse, err := obbr.Adapter.StreamFiles(projectName, repoName, m)
+				bitbucketResponse, err := obbr.Adapter.StreamFiles(ctx, projectName, repoName, m)
 				e := time.Now()
 				obbr.LogInfo(nil, fmt.Sprintf("fetched file structure in %v", e.Sub(s)))
 
 				if err != nil {
-					outStream <- Result{AnyFilePaths: nil, Error: fmt.Errorf("error reading file structure from Bitbucket: %w", err)}
+					send(Result{AnyFilePaths: nil, Error: fmt.Errorf("error reading file structure from Bitbucket: %w", err)})
 					return
 				}
 
 				if bitbucketResponse == nil || bitbucketResponse.Values == nil {
-					outStream <- Result{AnyFilePaths: nil, Error: fmt.Errorf("bitbucket API response is nil or has no paged values")}
+					send(Result{AnyFilePaths: nil, Error: fmt.Errorf("bitbucket API response is nil or has no paged values")})
 					return
 				}
 
 				values, ok := bitbucketResponse.Values["values"]
 				if !ok {
-					outStream <- Result{AnyFilePaths: nil, Error: fmt.Errorf("bitbucket API response does not contain the property 'values'")}
+					send(Result{AnyFilePaths: nil, Error: fmt.Errorf("bitbucket API response does not contain the property 'values'")})
 					reachedLastPage = true
 					return
 				}
 
 				if values == nil {
-					outStream <- Result{AnyFilePaths: []any{}, Error: nil}
+					send(Result{AnyFilePaths: []any{}, Error: nil})
 					reachedLastPage = true
 					continue
 				}
 
 				anyFilePaths, ok := values.([]any)
 				if !ok {
-					outStream <- Result{AnyFilePaths: nil, Error: fmt.Errorf("type conversion to slice of type any failed; received type: %T", values)}
+					send(Result{AnyFilePaths: nil, Error: fmt.Errorf("type conversion to slice of type any failed; received type: %T", values)})
 					reachedLastPage = true
 					continue
 				}
 
-				outStream <- Result{AnyFilePaths: anyFilePaths, Error: nil}
+				if !send(Result{AnyFilePaths: anyFilePaths, Error: nil}) {
+					return
+				}
 
 				isLastPage, lastPageOk := bitbucketResponse.Values["isLastPage"].(bool)
 				if !lastPageOk {
@@ -265,6 +332,8 @@ func (obbr *O11yBitbucketReader) ReadMarkdownFileStructureRecursively(projectNam
 		return outStream
 	}
 
+	docsRootPrefix := obbr.docsRoot() + "/"
+
 	consume := func(results <-chan Result) ([]string, error) {
 		var filePaths []string
 
@@ -280,7 +349,12 @@ func (obbr *O11yBitbucketReader) ReadMarkdownFileStructureRecursively(projectNam
 					return nil, fmt.Errorf("type conversion to slice of type string failed; received type: %T", v)
 				}
 
-				if !strings.HasPrefix(fp, "markdowns/") {
+				if !strings.HasPrefix(fp, docsRootPrefix) {
+					continue
+				}
+
+				if !HasMarkdownExtension(fp, obbr.Extensions) {
+					obbr.LogDebugf(nil, "skipping %s: file extension is not markdown", fp)
 					continue
 				}
 
@@ -288,6 +362,11 @@ func (obbr *O11yBitbucketReader) ReadMarkdownFileStructureRecursively(projectNam
 			}
 		}
 
+		// the producer stops without a result once the context is done
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("reading the file structure was interrupted: %w", err)
+		}
+
 		return filePaths, nil
 	}
 
@@ -295,8 +374,9 @@ func (obbr *O11yBitbucketReader) ReadMarkdownFileStructureRecursively(projectNam
 	return consume(results)
 }
 
-// InitBitbucket initializes the Bitbucket API with the provided configuration and creates an instance of *O11yBitbucketReader
-func InitBitbucket(c *config.Configuration, env *environment.Env) (*O11yBitbucketReader, error) {
+// InitBitbucket initializes the Bitbucket API with the provided configuration and creates an instance of *O11yBitbucketReader;
+// the given context is the base of the API client's context, which carries the credentials
+func InitBitbucket(ctx context.Context, c *config.Configuration, env *environment.Env) (*O11yBitbucketReader, error) {
 	env.LogInfo(logging.GetLogTypeInitialization(), "initializing Bitbucket API (async)")
 
 	if c.BitBucket.Url == nil {
@@ -310,8 +390,6 @@ func InitBitbucket(c *config.Configuration, env *environment.Env) (*O11yBitbucke
 		UserAgent: "cim-api",
 	}
 
-	ctx := context.Background()
-
 	if len(c.BitBucket.AccessToken) > 0 {
 		ctx = context.WithValue(ctx, bitbucketv1.ContextAccessToken, c.BitBucket.AccessToken)
 
@@ -331,5 +409,10 @@ func InitBitbucket(c *config.Configuration, env *environment.Env) (*O11yBitbucke
 
 	env.LogDebug(logging.GetLogTypeInitialization(), "Bitbucket API initialized")
 
-	return &O11yBitbucketReader{env, &BitbucketApiClient{bitbucketApi}}, nil
+	adapter := &RetryingAdapter{Adapter: &BitbucketApiClient{bitbucketApi}, MaxAttempts: c.BitBucket.RetryMaxAttempts}
+	if c.BitBucket.RetryBaseDelay != nil {
+		adapter.BaseDelay = c.BitBucket.RetryBaseDelay.Duration
+	}
+
+	return &O11yBitbucketReader{Env: env, Adapter: adapter, DocsRoot: c.BitBucket.DocsRoot, Extensions: c.BitBucket.Extensions}, nil
 }
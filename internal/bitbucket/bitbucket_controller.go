@@ -2,14 +2,25 @@ package bitbucket
 
 import (
 	"context"
+	"crypto/sha256"
 	"dice-sorensen-similarity-search/internal/api"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/models"
+	"dice-sorensen-similarity-search/internal/tracing"
+	"dice-sorensen-similarity-search/internal/utils"
+	"encoding/hex"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
 	"net/http"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 )
 
 // Api defines the set of endpoints related to synchronizing markdown files from Bitbucket.
@@ -18,6 +29,10 @@ import (
 type Api interface {
 	// FetchMarkdownsFromBitbucket imports markdown files from a Bitbucket repository into the database.
 	FetchMarkdownsFromBitbucket(c *gin.Context)
+	// Reindex imports the markdown files like FetchMarkdownsFromBitbucket and reports the outcome.
+	Reindex(c *gin.Context)
+	// GetSyncStatus reports the outcome of the last sync.
+	GetSyncStatus(c *gin.Context)
 }
 
 // Controller handles the ingestion of markdown documents from Bitbucket repositories.
@@ -29,6 +44,116 @@ type Controller struct {
 
 	RepositoryName string
 	ProjectName    string
+	// Revision is the ref or commit hash the Markdown files are read at; empty for the default branch
+	Revision string
+	// FetchConcurrency is the maximum number of files read from Bitbucket in parallel (default: 8)
+	FetchConcurrency int
+	// StreamPageSize is the number of file paths per page of the file structure (default: DefaultStreamPageSize);
+	// it is clamped to MaxStreamPageSize
+	StreamPageSize int
+	// MaxFileBytes is the maximum size of a Markdown file to ingest (default: DefaultMaxFileBytes); larger files are skipped
+	MaxFileBytes int
+	// Extensions are the file extensions of Markdown files (default: .md)
+	Extensions []string
+	// ContentCache is invalidated after the Markdown contents were written into the database; it is optional
+	ContentCache ContentCache
+	// SyncMetrics records the duration and the number of ingested files of successful syncs; it is optional
+	SyncMetrics SyncMetrics
+	// SyncRetryDelay is the delay before the first retry of a failed sync on startup (default: DefaultSyncRetryDelay);
+	// it doubles with each retry up to MaxSyncRetryDelay
+	SyncRetryDelay time.Duration
+	// SyncMaxDeletionPercent is the largest drop of the number of files read versus the number of files in the database,
+	// in percent, for which the obsolete files are deleted (default: DefaultMaxDeletionPercent). If the drop is larger
+	// or no file was read at all (e.g., due to a transient API quirk), the deletion is skipped to keep the index
+	SyncMaxDeletionPercent int
+
+	// syncing is set while a sync is running; concurrent triggers are coalesced into the running sync
+	syncing atomic.Bool
+	// syncStatus is updated at the end of each sync (see GetSyncStatus)
+	syncStatus syncStatusHolder
+}
+
+// ContentCache is a cache derived from the Markdown contents stored in the database
+type ContentCache interface {
+	// Invalidate removes all entries from the cache
+	Invalidate()
+}
+
+// ContentCaches combines several caches into one ContentCache, e.g., to invalidate all of them after a sync
+type ContentCaches []ContentCache
+
+// Invalidate invalidates each of the caches
+func (cs ContentCaches) Invalidate() {
+	for _, cache := range cs {
+		cache.Invalidate()
+	}
+}
+
+// SyncMetrics records metrics of syncs
+type SyncMetrics interface {
+	// ObserveSync records the duration of a sync and the number of files it ingested
+	ObserveSync(duration time.Duration, filesIngested int)
+}
+
+// DefaultFetchConcurrency is the maximum number of files read from Bitbucket in parallel
+// if no (positive) FetchConcurrency is configured
+const DefaultFetchConcurrency = 8
+
+const (
+	// DefaultStreamPageSize is the number of file paths per page of the file structure if no (positive) StreamPageSize is configured
+	DefaultStreamPageSize = 150
+	// MaxStreamPageSize is the largest number of file paths per page Bitbucket returns; larger limits are capped by Bitbucket anyway
+	MaxStreamPageSize = 1000
+)
+
+const (
+	// DefaultSyncRetryDelay is the delay before the first retry of a failed sync on startup if no (positive) SyncRetryDelay is configured
+	DefaultSyncRetryDelay = 30 * time.Second
+	// MaxSyncRetryDelay is the longest delay between the retries of a failed sync on startup
+	MaxSyncRetryDelay = 10 * time.Minute
+)
+
+// DefaultMaxDeletionPercent is the largest drop of the number of files, in percent, for which a sync deletes the obsolete files
+// if no (positive) SyncMaxDeletionPercent is configured
+const DefaultMaxDeletionPercent = 50
+
+// DefaultMaxFileBytes is the maximum size of a Markdown file to ingest if no (positive) MaxFileBytes is configured
+const DefaultMaxFileBytes = 5 << 20
+
+// ReindexReport summarizes a sync of the Markdown files into the database
+type ReindexReport struct {
+	// FilesProcessed is the number of Markdown files read from the source
+	FilesProcessed int `json:"filesProcessed"`
+	// FilesChanged is the number of processed files whose content was new or changed and therefore written into the database
+	FilesChanged int `json:"filesChanged"`
+	// FilesSkipped is the number of Markdown files that could not be read or exceed MaxFileBytes; they are kept as is in the database
+	FilesSkipped int `json:"filesSkipped"`
+	// FilesDeleted is the number of Markdown files soft-deleted from the database because they no longer exist in the source
+	FilesDeleted int `json:"filesDeleted"`
+	// DeletionSkipped is set if the obsolete files were kept since too few files were read (see Controller.SyncMaxDeletionPercent)
+	DeletionSkipped bool  `json:"deletionSkipped"`
+	DurationMs      int64 `json:"durationMs"`
+}
+
+// SyncPlan summarizes the changes a sync would apply to the database; it is the outcome of a dry run.
+// The files are identified by their folder path and sanitized name (e.g., "markdowns/Gateway/1-Onboarding").
+type SyncPlan struct {
+	// FilesProcessed is the number of Markdown files read from the source
+	FilesProcessed int `json:"filesProcessed"`
+	// FilesToChange are the processed files whose content is new or changed and would therefore be written into the database
+	FilesToChange []string `json:"filesToChange"`
+	// FilesToSkip are the Markdown files that could not be read or exceed MaxFileBytes; they would be kept as is in the database
+	FilesToSkip []string `json:"filesToSkip"`
+	// FilesToDelete are the Markdown files that would be deleted from the database because they no longer exist in the source
+	FilesToDelete []string `json:"filesToDelete"`
+	// DeletionSkipped is set if the obsolete files would be kept since too few files were read (see Controller.SyncMaxDeletionPercent)
+	DeletionSkipped bool `json:"deletionSkipped"`
+}
+
+// fileContentResult holds the content of a file read from Bitbucket or the error that occurred while reading it
+type fileContentResult struct {
+	content string
+	err     error
 }
 
 // ensure Controller implements Api
@@ -43,45 +168,217 @@ const (
 
 // FetchMarkdownsFromBitbucket retrieves Markdown file paths and contents from a Bitbucket repository,
 // deduplicates and stores them into the database, and deletes obsolete entries.
-// Only `.md` files under the "markdowns/" folder are processed. Filenames containing spaces or dots
+// Only files with one of the Extensions (default: `.md`) under the docs root folder (e.g., "markdowns/") are processed. Filenames containing spaces or dots
 // are sanitized before insertion.
+// Only one sync runs at a time; a trigger arriving while a sync is running is answered with 202 and does not start another one.
+// If the query parameter dryRun is true, the files are read and compared with the database, but nothing is written into
+// or deleted from it; the response is a SyncPlan of the changes a sync would apply.
 //
 // @ID fetchMarkdownsFromBitbucket
 // @Summary Sync Markdown files from Bitbucket into the database
 // @Tags bitbucket
 // @Router /bitbucket/markdowns/ [get]
+// @Param dryRun query bool false "report the changes of the sync without applying them"
+// @Success 200 {object} api.RestJsonResponse{data=bitbucket.SyncPlan}
+// @Success 202
 // @Success 204
 // @Failure 400
 // @Failure 500
 func (bc *Controller) FetchMarkdownsFromBitbucket(c *gin.Context) {
-	var ctx context.Context
-	if c.Request == nil || c.Request.Context() == nil {
-		ctx = context.Background()
-	} else {
-		ctx = c.Request.Context()
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dryRun", "false"))
+	if err != nil {
+		msg := fmt.Sprintf("did not sync because of an invalid dryRun flag: %s", err)
+		bc.LogError(nil, msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	// a dry run only reads; therefore, it neither waits for nor blocks a sync
+	if dryRun {
+		plan, err := bc.planSync(requestContext(c))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "dry run", plan))
+		return
+	}
+
+	if !bc.syncing.CompareAndSwap(false, true) {
+		bc.LogInfo(nil, "a sync is already running; coalescing this trigger into it")
+		c.JSON(http.StatusAccepted, api.NewGenericResponse(api.Running, "a sync is already running", nil))
+		return
 	}
+	defer bc.syncing.Store(false)
 
-	filePaths, err := bc.ReadMarkdownFileStructureRecursively(bc.ProjectName, bc.RepositoryName, 0, 150)
+	_, err = bc.sync(requestContext(c))
 	if err != nil {
-		bc.LogError(nil, err.Error())
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("Error reading filePath: %s", err.Error()))
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(err.Error()))
 		return
 	}
 
-	var markdownMetasFromBitbucket []models.MarkdownMeta
-	var markdownContentsFromBitbucket []models.MarkdownContent
+	c.JSON(http.StatusNoContent, "")
+}
 
-	for _, filePath := range filePaths {
-		fileContent, err := bc.ReadFileContentAtRevision(bc.ProjectName, bc.RepositoryName, filePath, "0")
-		if err != nil {
-			bc.LogError(nil, err.Error())
+// Reindex syncs the Markdown files like FetchMarkdownsFromBitbucket, but answers with a ReindexReport summarizing the sync.
+// A request arriving while a sync is running is answered with 202 and does not start another one.
+//
+// @ID reindex
+// @Summary Sync Markdown files into the database and report the outcome
+// @Tags admin
+// @Router /admin/reindex [post]
+// @Success 200 {object} api.RestJsonResponse{data=bitbucket.ReindexReport}
+// @Success 202
+// @Failure 500
+func (bc *Controller) Reindex(c *gin.Context) {
+	if !bc.syncing.CompareAndSwap(false, true) {
+		bc.LogInfo(nil, "a sync is already running; not reindexing")
+		c.JSON(http.StatusAccepted, api.NewGenericResponse(api.Running, "a sync is already running", nil))
+		return
+	}
+	defer bc.syncing.Store(false)
+
+	report, err := bc.sync(requestContext(c))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "reindexed", report))
+}
+
+// GetSyncStatus answers with the SyncStatus, i.e., the time, the duration, and the outcome of the last sync,
+// regardless of whether it was triggered by FetchMarkdownsFromBitbucket, Reindex, or SyncOnStartup.
+//
+// @ID getSyncStatus
+// @Summary Report the outcome of the last sync
+// @Tags bitbucket
+// @Router /markdown-doc/sync-status [get]
+// @Success 200 {object} api.RestJsonResponse{data=bitbucket.SyncStatus}
+func (bc *Controller) GetSyncStatus(c *gin.Context) {
+	status := bc.syncStatus.get()
+	status.Running = bc.syncing.Load()
+
+	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "sync status", status))
+}
+
+// SyncOnStartup runs the initial sync and retries it until it succeeds or the context is done, e.g., while the source is
+// unreachable (see LazyReader); meanwhile, the endpoints serve the data already stored in the database.
+// An attempt is postponed while a sync triggered otherwise is running.
+func (bc *Controller) SyncOnStartup(ctx context.Context) error {
+	delay := bc.syncRetryDelay()
+	for {
+		if bc.syncing.CompareAndSwap(false, true) {
+			_, err := bc.sync(ctx)
+			bc.syncing.Store(false)
+			if err == nil {
+				return nil
+			}
+
+			bc.LogWarnf(nil, "the sync on startup failed; retrying in %s: %s", delay, err.Error())
 		}
 
-		extension := filepath.Ext(filePath)
-		if extension != ".md" {
-			bc.LogWarn(nil, fmt.Sprintf("file extension is not markdown: %s", filePath))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay = min(2*delay, MaxSyncRetryDelay)
+	}
+}
+
+func (bc *Controller) maxFileBytes() int {
+	if bc.MaxFileBytes <= 0 {
+		return DefaultMaxFileBytes
+	}
+
+	return bc.MaxFileBytes
+}
+
+func (bc *Controller) maxDeletionPercent() int {
+	if bc.SyncMaxDeletionPercent <= 0 {
+		return DefaultMaxDeletionPercent
+	}
+
+	return bc.SyncMaxDeletionPercent
+}
+
+// skipDeletion reports whether the obsolete files must be kept since the number of files read from the source
+// is zero or dropped by more than maxDeletionPercent versus the number of files in the database; it warns if so
+func (bc *Controller) skipDeletion(filesRead, filesInDb int) bool {
+	if filesInDb == 0 {
+		return false
+	}
+
+	drop := filesInDb - filesRead
+	if filesRead > 0 && drop*100 <= filesInDb*bc.maxDeletionPercent() {
+		return false
+	}
+
+	bc.LogWarnf(nil, "SKIPPING THE DELETION of obsolete markdown files: read %d file(s) from the source, but %d are in the database; "+
+		"the drop exceeds %d%%, which rather indicates a failed read than deleted files", filesRead, filesInDb, bc.maxDeletionPercent())
+	return true
+}
+
+func (bc *Controller) syncRetryDelay() time.Duration {
+	if bc.SyncRetryDelay <= 0 {
+		return DefaultSyncRetryDelay
+	}
+
+	return bc.SyncRetryDelay
+}
+
+// requestContext returns the context of the request or, if there is none (e.g., for the sync on startup), the background context
+func requestContext(c *gin.Context) context.Context {
+	if c.Request == nil || c.Request.Context() == nil {
+		return context.Background()
+	}
+
+	return c.Request.Context()
+}
+
+// sourceMarkdowns holds the Markdown files read from the source
+type sourceMarkdowns struct {
+	metas []models.MarkdownMeta
+	// contents are the contents of the metas at the same index
+	contents []models.MarkdownContent
+	// unreadableMetas are the metas of files that could not be read (or are no text or too large);
+	// they are neither upserted nor deleted as obsolete
+	unreadableMetas []models.MarkdownMeta
+}
+
+// existingMetas returns the metas of all Markdown files existing in the source, including the unreadable ones
+func (sm sourceMarkdowns) existingMetas() []models.MarkdownMeta {
+	return append(slices.Clip(sm.metas), sm.unreadableMetas...)
+}
+
+// readMarkdowns reads the Markdown files from the source; the returned errors are meant to be shown to the client
+func (bc *Controller) readMarkdowns(ctx context.Context) (sourceMarkdowns, error) {
+	_, structureSpan := tracing.Start(ctx, "ReadMarkdownFileStructureRecursively")
+	filePaths, err := bc.ReadMarkdownFileStructureRecursively(ctx, bc.ProjectName, bc.RepositoryName, bc.Revision, 0, bc.streamPageSize())
+	tracing.End(structureSpan, err)
+	if err != nil {
+		bc.LogError(nil, err.Error())
+		return sourceMarkdowns{}, fmt.Errorf("error reading filePath: %s", err.Error())
+	}
+
+	markdownFilePaths := make([]string, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		if !HasMarkdownExtension(filePath, bc.Extensions) {
+			bc.LogDebugf(nil, "skipping %s: file extension is not markdown", filePath)
 			continue
 		}
+		markdownFilePaths = append(markdownFilePaths, filePath)
+	}
+
+	fileContents := bc.readFileContents(ctx, markdownFilePaths)
+
+	var markdowns sourceMarkdowns
+
+	for i, filePath := range markdownFilePaths {
+		extension := filepath.Ext(filePath)
 		name := strings.TrimSuffix(filepath.Base(filePath), extension)
 		path := filepath.Dir(filePath)
 
@@ -89,50 +386,255 @@ func (bc *Controller) FetchMarkdownsFromBitbucket(c *gin.Context) {
 			name = strings.ReplaceAll(name, " ", "_")
 		}
 
-		var charCount uint
-		if len(fileContent) > 0 {
-			charCount = uint(len(fileContent))
+		if fileContents[i].err != nil {
+			bc.LogErrorf(nil, "skipping %s: %s", filePath, fileContents[i].err.Error())
+			markdowns.unreadableMetas = append(markdowns.unreadableMetas, models.MarkdownMeta{Name: name, Path: path})
+			continue
+		}
+		fileContent := fileContents[i].content
+
+		// e.g., a generated API dump; its trigrams would blow up the memory of the sync and the search
+		if len(fileContent) > bc.maxFileBytes() {
+			bc.LogErrorf(nil, "skipping %s: its size of %d bytes exceeds the maximum of %d bytes", filePath, len(fileContent), bc.maxFileBytes())
+			markdowns.unreadableMetas = append(markdowns.unreadableMetas, models.MarkdownMeta{Name: name, Path: path})
+			continue
 		}
 
-		markdownMetasFromBitbucket = append(markdownMetasFromBitbucket, models.MarkdownMeta{Name: name, Path: path, CharCount: charCount})
-		markdownContentsFromBitbucket = append(markdownContentsFromBitbucket, models.MarkdownContent{Content: fileContent})
+		// a mis-committed binary file would result in a garbage char count and garbage trigrams
+		if !utf8.ValidString(fileContent) {
+			bc.LogErrorf(nil, "skipping %s: its content is not valid UTF-8 (e.g., a binary file)", filePath)
+			markdowns.unreadableMetas = append(markdowns.unreadableMetas, models.MarkdownMeta{Name: name, Path: path})
+			continue
+		}
+
+		// counts characters (runes) instead of bytes; otherwise, multibyte characters are counted multiple times
+		charCount := uint(utf8.RuneCountInString(fileContent))
+
+		markdowns.metas = append(markdowns.metas, models.MarkdownMeta{Name: name, Path: path, CharCount: charCount})
+		markdowns.contents = append(markdowns.contents, models.MarkdownContent{Content: fileContent, Hash: contentHash(fileContent)})
+	}
+
+	return markdowns, nil
+}
+
+// sync reads the Markdown files from the source, stores the changed ones into the database, deletes the obsolete ones,
+// and reports the outcome, which is also recorded for GetSyncStatus; the returned errors are meant to be shown to the client
+func (bc *Controller) sync(ctx context.Context) (report ReindexReport, err error) {
+	start := time.Now()
+	defer func() {
+		bc.syncStatus.record(start, report, err)
+	}()
+
+	markdowns, err := bc.readMarkdowns(ctx)
+	if err != nil {
+		return ReindexReport{}, err
 	}
+	markdownMetasFromBitbucket := markdowns.metas
+	markdownContentsFromBitbucket := markdowns.contents
 
 	var markdownMetasFromDb []models.MarkdownMeta
 
 	err = bc.FindAllMarkdownMetas(ctx, &markdownMetasFromDb)
 	if err != nil {
 		bc.LogError(nil, err.Error())
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error fetching existing markdown meta data from the database: %s", err.Error()))
-		return
+		return ReindexReport{}, fmt.Errorf("error fetching existing markdown meta data from the database: %s", err.Error())
 	}
 
-	if len(markdownMetasFromDb) > 0 {
-		err := bc.DeleteObsoleteMarkdownsFromDatabase(ctx, markdownMetasFromBitbucket, markdownMetasFromDb)
+	var filesDeleted int
+	var changedContents []models.MarkdownContent
+
+	existingMarkdownMetas := markdowns.existingMetas()
+	deletionSkipped := bc.skipDeletion(len(existingMarkdownMetas), len(markdownMetasFromDb))
+
+	// the deletes and upserts are applied all together or not at all; e.g., no metas without content are left if a step fails
+	err = bc.WithTransaction(ctx, func(ctx context.Context) error {
+		if len(markdownMetasFromDb) > 0 && !deletionSkipped {
+			err := bc.DeleteObsoleteMarkdownsFromDatabase(ctx, existingMarkdownMetas, markdownMetasFromDb)
+			if err != nil {
+				return err
+			}
+			filesDeleted = len(bc.FindObsoleteMarkdownMetas(existingMarkdownMetas, markdownMetasFromDb))
+		}
+
+		err := bc.UpsertMarkdownMetas(ctx, markdownMetasFromBitbucket)
 		if err != nil {
-			c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(err.Error()))
-			return
+			bc.LogError(nil, err.Error())
+			return fmt.Errorf("error writing markdown meta data into the database: %s", err.Error())
+		}
+
+		// links meta and content
+		for i := 0; i < len(markdownMetasFromBitbucket); i++ {
+			markdownContentsFromBitbucket[i].Meta = markdownMetasFromBitbucket[i]
+		}
+
+		var contentHashesFromDb []models.MarkdownContentHash
+
+		err = bc.FindMarkdownContentHashes(ctx, &contentHashesFromDb)
+		if err != nil {
+			bc.LogError(nil, err.Error())
+			return fmt.Errorf("error fetching existing markdown content hashes from the database: %s", err.Error())
+		}
+
+		changedContents = changedMarkdownContents(markdownContentsFromBitbucket, contentHashesFromDb)
+		bc.LogInfof(nil, "%d of %d markdown file(s) changed", len(changedContents), len(markdownContentsFromBitbucket))
+
+		if len(changedContents) > 0 {
+			err = bc.UpsertMarkdownContents(ctx, changedContents)
+			if err != nil {
+				bc.LogError(nil, err.Error())
+				return fmt.Errorf("error writing markdown files into the database: %s", err.Error())
+			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		return ReindexReport{}, err
+	}
+
+	if bc.ContentCache != nil {
+		bc.ContentCache.Invalidate()
 	}
 
-	err = bc.UpsertMarkdownMetas(ctx, markdownMetasFromBitbucket)
+	duration := time.Since(start)
+	if bc.SyncMetrics != nil {
+		bc.SyncMetrics.ObserveSync(duration, len(markdownContentsFromBitbucket))
+	}
+
+	return ReindexReport{
+		FilesProcessed:  len(markdownContentsFromBitbucket),
+		FilesChanged:    len(changedContents),
+		FilesSkipped:    len(markdowns.unreadableMetas),
+		FilesDeleted:    filesDeleted,
+		DeletionSkipped: deletionSkipped,
+		DurationMs:      duration.Milliseconds(),
+	}, nil
+}
+
+// planSync reads the Markdown files from the source and compares them with the database like sync,
+// but only reports the changes instead of applying them; the returned errors are meant to be shown to the client
+func (bc *Controller) planSync(ctx context.Context) (SyncPlan, error) {
+	markdowns, err := bc.readMarkdowns(ctx)
 	if err != nil {
-		bc.LogError(nil, err.Error())
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error writing markdown meta data into the database: %s", err.Error()))
-		return
+		return SyncPlan{}, err
 	}
 
-	// links meta and content
-	for i := 0; i < len(markdownMetasFromBitbucket); i++ {
-		markdownContentsFromBitbucket[i].Meta = markdownMetasFromBitbucket[i]
+	var markdownMetasFromDb []models.MarkdownMeta
+
+	err = bc.FindAllMarkdownMetas(ctx, &markdownMetasFromDb)
+	if err != nil {
+		bc.LogError(nil, err.Error())
+		return SyncPlan{}, fmt.Errorf("error fetching existing markdown meta data from the database: %s", err.Error())
 	}
 
-	err = bc.UpsertMarkdownContents(ctx, markdownContentsFromBitbucket)
+	var contentHashesFromDb []models.MarkdownContentHash
+
+	err = bc.FindMarkdownContentHashes(ctx, &contentHashesFromDb)
 	if err != nil {
 		bc.LogError(nil, err.Error())
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error writing markdown files into the database: %s", err.Error()))
-		return
+		return SyncPlan{}, fmt.Errorf("error fetching existing markdown content hashes from the database: %s", err.Error())
 	}
 
-	c.JSON(http.StatusNoContent, "")
+	// links meta and content
+	for i := 0; i < len(markdowns.metas); i++ {
+		markdowns.contents[i].Meta = markdowns.metas[i]
+	}
+
+	plan := SyncPlan{
+		FilesProcessed: len(markdowns.contents),
+		FilesToChange:  make([]string, 0),
+		FilesToSkip:    make([]string, 0, len(markdowns.unreadableMetas)),
+		FilesToDelete:  make([]string, 0),
+	}
+
+	for _, v := range changedMarkdownContents(markdowns.contents, contentHashesFromDb) {
+		plan.FilesToChange = append(plan.FilesToChange, markdownKey(v.Meta.Path, v.Meta.Name))
+	}
+
+	for _, v := range markdowns.unreadableMetas {
+		plan.FilesToSkip = append(plan.FilesToSkip, markdownKey(v.Path, v.Name))
+	}
+
+	existingMarkdownMetas := markdowns.existingMetas()
+	plan.DeletionSkipped = bc.skipDeletion(len(existingMarkdownMetas), len(markdownMetasFromDb))
+	if !plan.DeletionSkipped {
+		for _, v := range bc.FindObsoleteMarkdownMetas(existingMarkdownMetas, markdownMetasFromDb) {
+			plan.FilesToDelete = append(plan.FilesToDelete, markdownKey(v.Path, v.Name))
+		}
+	}
+
+	bc.LogInfof(nil, "dry run: %d of %d markdown file(s) changed, %d skipped, %d obsolete",
+		len(plan.FilesToChange), plan.FilesProcessed, len(plan.FilesToSkip), len(plan.FilesToDelete))
+
+	return plan, nil
+}
+
+// contentHash returns the hex-encoded SHA-256 of the given content (see models.MarkdownContent)
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// changedMarkdownContents returns the contents (linked to their metas) whose hash differs from the one stored in the database;
+// contents that are not stored yet have changed, too
+func changedMarkdownContents(markdownContents []models.MarkdownContent, contentHashesFromDb []models.MarkdownContentHash) []models.MarkdownContent {
+	contentHashesFromDbByKey := utils.SliceToMap(contentHashesFromDb, func(h models.MarkdownContentHash) string { return markdownKey(h.Path, h.Name) })
+
+	changed := make([]models.MarkdownContent, 0, len(markdownContents))
+	for _, v := range markdownContents {
+		if stored, ok := contentHashesFromDbByKey[markdownKey(v.Meta.Path, v.Meta.Name)]; ok && stored.Hash == v.Hash {
+			continue
+		}
+		changed = append(changed, v)
+	}
+
+	return changed
+}
+
+// streamPageSize returns the StreamPageSize clamped to [1, MaxStreamPageSize]; it defaults to DefaultStreamPageSize
+func (bc *Controller) streamPageSize() int {
+	if bc.StreamPageSize <= 0 {
+		return DefaultStreamPageSize
+	}
+
+	return min(bc.StreamPageSize, MaxStreamPageSize)
+}
+
+// readFileContents reads the contents of the given files from Bitbucket using a bounded pool of workers.
+//
+// The returned slice is index-aligned with filePaths, i.e. the i-th result belongs to the i-th file path.
+// An error reading a single file is stored in its result and does not affect the other files.
+func (bc *Controller) readFileContents(ctx context.Context, filePaths []string) []fileContentResult {
+	results := make([]fileContentResult, len(filePaths))
+
+	concurrency := bc.FetchConcurrency
+	if concurrency <= 0 {
+		concurrency = DefaultFetchConcurrency
+	}
+
+	indexes := make(chan int)
+
+	var wg sync.WaitGroup
+	for range min(concurrency, len(filePaths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range indexes {
+				_, span := tracing.Start(ctx, "ReadFileContentAtRevision", attribute.String("file.path", filePaths[i]))
+				content, err := bc.ReadFileContentAtRevision(ctx, bc.ProjectName, bc.RepositoryName, filePaths[i], bc.Revision)
+				tracing.End(span, err)
+				results[i] = fileContentResult{content: content, err: err}
+			}
+		}()
+	}
+
+	for i := range filePaths {
+		indexes <- i
+	}
+	close(indexes)
+
+	wg.Wait()
+
+	return results
 }
@@ -2,10 +2,13 @@ package bitbucket_test
 
 import (
 	"context"
+	"crypto/sha256"
 	"dice-sorensen-similarity-search/internal/bitbucket"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/models"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
@@ -14,7 +17,9 @@ import (
 	"go.uber.org/zap/zapcore"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 // ####################### valid cases
@@ -39,6 +44,8 @@ func TestFetchMarkdownsFromBitbucket_Success(t *testing.T) {
 		sanitizedName:   "guide-asd-asd",
 		charCountByName: gotCharCountByName,
 	}
+	cache := &mockContentCache{}
+	syncMetrics := &mockSyncMetrics{}
 	mockCtrl := &bitbucket.Controller{
 		Env: &environment.Env{
 			Repository: mockedRepo,
@@ -57,6 +64,9 @@ func TestFetchMarkdownsFromBitbucket_Success(t *testing.T) {
 		MarkdownHousekeeper: &mockHousekeeper{},
 		ProjectName:         "CIM",
 		RepositoryName:      "o11y-self-service-content",
+		Revision:            "refs/tags/v1.0.0",
+		ContentCache:        cache,
+		SyncMetrics:         syncMetrics,
 	}
 
 	mockCtrl.FetchMarkdownsFromBitbucket(c)
@@ -82,6 +92,810 @@ func TestFetchMarkdownsFromBitbucket_Success(t *testing.T) {
 		t.Error(cmp.Diff(wantCharCountByName, gotCharCountByName))
 		return
 	}
+
+	reader := mockCtrl.BitbucketReader.(*mockBitbucketReader)
+	if reader.gotRevision != mockCtrl.Revision {
+		t.Errorf("want files to be read at revision %s, got %s", mockCtrl.Revision, reader.gotRevision)
+		return
+	}
+
+	if reader.gotListRevision != mockCtrl.Revision {
+		t.Errorf("want the file structure to be listed at revision %s, got %s", mockCtrl.Revision, reader.gotListRevision)
+		return
+	}
+
+	if !cache.invalidated {
+		t.Error("content cache was not invalidated")
+		return
+	}
+
+	if syncMetrics.syncs != 1 || syncMetrics.filesIngested != 2 {
+		t.Errorf("want 1 sync with 2 ingested files to be recorded, got %d sync(s) with %d file(s)", syncMetrics.syncs, syncMetrics.filesIngested)
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_SkipsUnreadableFiles(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var core zapcore.Core
+
+	mockedRepo := &mockRepository{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/a.md", "doc/b.md", "doc/c.md", "doc/d.md"},
+			readContent: map[string]string{
+				"doc/a.md": "content a",
+				"doc/b.md": "content b",
+				"doc/c.md": "content c",
+				"doc/d.md": "content d",
+			},
+			failReadFile: map[string]bool{"doc/b.md": true},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+		FetchConcurrency:    2,
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	want := http.StatusNoContent
+	got := w.Code
+	if got != want {
+		t.Errorf("status code mismatch: got %d, want %d", got, want)
+		return
+	}
+
+	wantContentByName := map[string]string{
+		"a": "content a",
+		"c": "content c",
+		"d": "content d",
+	}
+
+	gotContentByName := make(map[string]string, len(mockedRepo.upsertedContents))
+	for _, mc := range mockedRepo.upsertedContents {
+		gotContentByName[mc.Meta.Name] = mc.Content
+	}
+
+	if !cmp.Equal(wantContentByName, gotContentByName) {
+		t.Error(cmp.Diff(wantContentByName, gotContentByName))
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_SkipsInvalidUTF8(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var core zapcore.Core
+
+	wantCharCountByName := map[string]uint{
+		"greeting": 5, // "Grüße" has 5 characters, but 7 bytes
+		"text":     9,
+	}
+
+	gotCharCountByName := make(map[string]uint, len(wantCharCountByName))
+	for k := range wantCharCountByName {
+		gotCharCountByName[k] = 100_000_000
+	}
+
+	mockedRepo := &mockRepository{charCountByName: gotCharCountByName}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/greeting.md", "doc/binary.md", "doc/text.md"},
+			readContent: map[string]string{
+				"doc/greeting.md": "Grüße",
+				"doc/binary.md":   "\x89PNG\r\n\x1a\n\xff\xfe",
+				"doc/text.md":     "content t",
+			},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	want := http.StatusNoContent
+	got := w.Code
+	if got != want {
+		t.Errorf("status code mismatch: got %d, want %d", got, want)
+		return
+	}
+
+	gotNames := make([]string, 0, len(mockedRepo.upsertedContents))
+	for _, mc := range mockedRepo.upsertedContents {
+		gotNames = append(gotNames, mc.Meta.Name)
+	}
+
+	wantNames := []string{"greeting", "text"}
+	if !cmp.Equal(wantNames, gotNames) {
+		t.Error(cmp.Diff(wantNames, gotNames))
+		return
+	}
+
+	if !cmp.Equal(wantCharCountByName, gotCharCountByName) {
+		t.Error(cmp.Diff(wantCharCountByName, gotCharCountByName))
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_SkipsTooLargeFiles(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mockedRepo := &mockRepository{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/dump.md", "doc/exactlyTheMaximum.md", "doc/text.md"},
+			readContent: map[string]string{
+				"doc/dump.md":              "0123456789a",
+				"doc/exactlyTheMaximum.md": "0123456789",
+				"doc/text.md":              "content",
+			},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+		MaxFileBytes:        10,
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	want := http.StatusNoContent
+	got := w.Code
+	if got != want {
+		t.Errorf("status code mismatch: got %d, want %d", got, want)
+		return
+	}
+
+	gotNames := make([]string, 0, len(mockedRepo.upsertedContents))
+	for _, mc := range mockedRepo.upsertedContents {
+		gotNames = append(gotNames, mc.Meta.Name)
+	}
+
+	wantNames := []string{"exactlyTheMaximum", "text"}
+	if !cmp.Equal(wantNames, gotNames) {
+		t.Error(cmp.Diff(wantNames, gotNames))
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_CountsRunes(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var core zapcore.Core
+
+	wantCharCountByName := map[string]uint{
+		"japanese": 6, // "日本語の文書" has 6 characters, but 18 bytes
+		"accented": 4, // "café" has 4 characters, but 5 bytes
+	}
+
+	gotCharCountByName := make(map[string]uint, len(wantCharCountByName))
+	for k := range wantCharCountByName {
+		gotCharCountByName[k] = 100_000_000
+	}
+
+	mockedRepo := &mockRepository{charCountByName: gotCharCountByName}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/japanese.md", "doc/accented.md"},
+			readContent: map[string]string{
+				"doc/japanese.md": "日本語の文書",
+				"doc/accented.md": "café",
+			},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	want := http.StatusNoContent
+	got := w.Code
+	if got != want {
+		t.Errorf("status code mismatch: got %d, want %d", got, want)
+		return
+	}
+
+	if !cmp.Equal(wantCharCountByName, gotCharCountByName) {
+		t.Error(cmp.Diff(wantCharCountByName, gotCharCountByName))
+		return
+	}
+}
+
+func TestReindex_Success(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var core zapcore.Core
+
+	mockedRepo := &mockRepository{
+		metas: []models.MarkdownMeta{
+			{Model: models.Model{ID: 1}, Name: "a", Path: "doc"},
+			{Model: models.Model{ID: 2}, Name: "b", Path: "doc"},
+			{Model: models.Model{ID: 3}, Name: "obsolete", Path: "doc"},
+		},
+		contentHashes: []models.MarkdownContentHash{
+			{Name: "a", Path: "doc", Hash: sha256Hex("content a")},
+		},
+	}
+	housekeeper := &mockHousekeeper{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/a.md", "doc/b.md", "doc/c.md"},
+			readContent: map[string]string{
+				"doc/a.md": "content a",
+				"doc/c.md": "content c",
+			},
+			failReadFile: map[string]bool{"doc/b.md": true},
+		},
+		MarkdownHousekeeper: housekeeper,
+	}
+
+	mockCtrl.Reindex(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Status string                  `json:"status"`
+		Data   bitbucket.ReindexReport `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	// a is unchanged, b is unreadable (and therefore kept), c is new, and obsolete no longer exists
+	want := bitbucket.ReindexReport{FilesProcessed: 2, FilesChanged: 1, FilesSkipped: 1, FilesDeleted: 1}
+	got := response.Data
+	if got.DurationMs < 0 {
+		t.Errorf("want a non-negative duration, got %d", got.DurationMs)
+		return
+	}
+	got.DurationMs = 0
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+
+	if !housekeeper.called {
+		t.Error("want the obsolete markdowns to be deleted")
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_RollsBackIfContentUpsertFails(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mockedRepo := &mockRepository{upsertContentsErr: errors.New("connection reset")}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.NullLogger{},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files:       []string{"doc/a.md"},
+			readContent: map[string]string{"doc/a.md": "content a"},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status code mismatch: got %d, want %d", w.Code, http.StatusInternalServerError)
+		return
+	}
+
+	if !mockedRepo.upsertMetasCalled || mockedRepo.transactions != 1 || !mockedRepo.rolledBack {
+		t.Errorf("want the metas to be upserted in a single transaction that is rolled back; upserted: %t, transactions: %d, rolled back: %t",
+			mockedRepo.upsertMetasCalled, mockedRepo.transactions, mockedRepo.rolledBack)
+		return
+	}
+}
+
+func TestReindex_ReadStructureFails(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mockCtrl := &bitbucket.Controller{
+		Env:                 environment.Null(),
+		BitbucketReader:     &mockBitbucketReader{failList: true},
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	mockCtrl.Reindex(c)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status code mismatch: got %d, want %d", w.Code, http.StatusInternalServerError)
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_SameNameInDifferentFolders(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mockedRepo := &mockRepository{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"markdowns/Gateway/Guide.md", "markdowns/Alloy/Guide.md"},
+			readContent: map[string]string{
+				"markdowns/Gateway/Guide.md": "gateway guide",
+				"markdowns/Alloy/Guide.md":   "alloy guide",
+			},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status code mismatch: got %d, want %d", w.Code, http.StatusNoContent)
+		return
+	}
+
+	wantContentByPath := map[string]string{
+		"markdowns/Gateway/Guide": "gateway guide",
+		"markdowns/Alloy/Guide":   "alloy guide",
+	}
+
+	gotContentByPath := make(map[string]string, len(mockedRepo.upsertedContents))
+	for _, mc := range mockedRepo.upsertedContents {
+		gotContentByPath[mc.Meta.Path+"/"+mc.Meta.Name] = mc.Content
+	}
+
+	if !cmp.Equal(wantContentByPath, gotContentByPath) {
+		t.Error(cmp.Diff(wantContentByPath, gotContentByPath))
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_Extensions(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mockedRepo := &mockRepository{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/a.md", "doc/b.mdx", "doc/c.markdown", "doc/d.txt"},
+			readContent: map[string]string{
+				"doc/a.md":       "content a",
+				"doc/b.mdx":      "content b",
+				"doc/c.markdown": "content c",
+				"doc/d.txt":      "content d",
+			},
+		},
+		MarkdownHousekeeper: &mockHousekeeper{},
+		Extensions:          []string{".md", ".mdx", ".markdown"},
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	want := http.StatusNoContent
+	got := w.Code
+	if got != want {
+		t.Errorf("status code mismatch: got %d, want %d", got, want)
+		return
+	}
+
+	wantContentByName := map[string]string{
+		"a": "content a",
+		"b": "content b",
+		"c": "content c",
+	}
+
+	gotContentByName := make(map[string]string, len(mockedRepo.upsertedContents))
+	for _, mc := range mockedRepo.upsertedContents {
+		gotContentByName[mc.Meta.Name] = mc.Content
+	}
+
+	if !cmp.Equal(wantContentByName, gotContentByName) {
+		t.Error(cmp.Diff(wantContentByName, gotContentByName))
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_BoundsConcurrentReads(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	var core zapcore.Core
+
+	files := make([]string, 20)
+	readContent := make(map[string]string, len(files))
+	for i := range files {
+		files[i] = fmt.Sprintf("doc/file-%d.md", i)
+		readContent[files[i]] = fmt.Sprintf("content %d", i)
+	}
+
+	reader := &mockBitbucketReader{
+		files:       files,
+		readContent: readContent,
+		delay:       5 * time.Millisecond,
+	}
+	mockedRepo := &mockRepository{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader:     reader,
+		MarkdownHousekeeper: &mockHousekeeper{},
+		FetchConcurrency:    4,
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status code mismatch: got %d, want %d", w.Code, http.StatusNoContent)
+		return
+	}
+
+	if reader.maxInFlight > mockCtrl.FetchConcurrency {
+		t.Errorf("want at most %d concurrent reads, got %d", mockCtrl.FetchConcurrency, reader.maxInFlight)
+		return
+	}
+
+	if len(mockedRepo.upsertedContents) != len(files) {
+		t.Errorf("want %d upserted contents, got %d", len(files), len(mockedRepo.upsertedContents))
+		return
+	}
+
+	for _, mc := range mockedRepo.upsertedContents {
+		if want := readContent[mc.Meta.Path+"/"+mc.Meta.Name+".md"]; mc.Content != want {
+			t.Errorf("content of %s is misaligned: got %q, want %q", mc.Meta.Name, mc.Content, want)
+		}
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_DryRun(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/bitbucket/markdowns/?dryRun=true", nil)
+
+	var core zapcore.Core
+
+	mockedRepo := &mockRepository{
+		metas: []models.MarkdownMeta{
+			{Model: models.Model{ID: 1}, Name: "a", Path: "doc"},
+			{Model: models.Model{ID: 2}, Name: "b", Path: "doc"},
+			{Model: models.Model{ID: 3}, Name: "obsolete", Path: "doc"},
+		},
+		contentHashes: []models.MarkdownContentHash{
+			{Name: "a", Path: "doc", Hash: sha256Hex("content a")},
+		},
+		foundContentIds: []uint{3},
+	}
+	housekeeper := &mockHousekeeper{}
+	cache := &mockContentCache{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader: &mockBitbucketReader{
+			files: []string{"doc/a.md", "doc/b.md", "doc/c.md"},
+			readContent: map[string]string{
+				"doc/a.md": "content a",
+				"doc/c.md": "content c",
+			},
+			failReadFile: map[string]bool{"doc/b.md": true},
+		},
+		MarkdownHousekeeper: housekeeper,
+		ContentCache:        cache,
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Status string             `json:"status"`
+		Data   bitbucket.SyncPlan `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	// a is unchanged, b is unreadable (and therefore kept), c is new, and obsolete no longer exists
+	want := bitbucket.SyncPlan{
+		FilesProcessed: 2,
+		FilesToChange:  []string{"doc/c"},
+		FilesToSkip:    []string{"doc/b"},
+		FilesToDelete:  []string{"doc/obsolete"},
+	}
+	if !cmp.Equal(want, response.Data) {
+		t.Error(cmp.Diff(want, response.Data))
+		return
+	}
+
+	if mockedRepo.upsertMetasCalled || mockedRepo.upsertContentsCalled {
+		t.Errorf("want no upserts in a dry run, got upsert metas: %t, upsert contents: %t", mockedRepo.upsertMetasCalled, mockedRepo.upsertContentsCalled)
+		return
+	}
+
+	if housekeeper.called || mockedRepo.deletedMetas != nil || mockedRepo.deletedContent != nil {
+		t.Errorf("want no deletes in a dry run, got deleted metas %v and contents %v", mockedRepo.deletedMetas, mockedRepo.deletedContent)
+		return
+	}
+
+	if mockedRepo.transactions != 0 || cache.invalidated {
+		t.Errorf("want neither a transaction nor an invalidated cache in a dry run, got %d transaction(s), invalidated: %t", mockedRepo.transactions, cache.invalidated)
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_InvalidDryRunFlag(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/bitbucket/markdowns/?dryRun=maybe", nil)
+
+	var core zapcore.Core
+
+	reader := &mockBitbucketReader{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: &mockRepository{},
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader:     reader,
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	if reader.listCalls != 0 {
+		t.Errorf("want no sync, got %d read(s) of the file structure", reader.listCalls)
+		return
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_SkipsUnchangedFiles(t *testing.T) {
+	var core zapcore.Core
+
+	newController := func(repo *mockRepository) *bitbucket.Controller {
+		return &bitbucket.Controller{
+			Env: &environment.Env{
+				Repository: repo,
+				Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+			},
+			BitbucketReader: &mockBitbucketReader{
+				files: []string{"doc/unchanged.md", "doc/changed.md", "doc/new.md"},
+				readContent: map[string]string{
+					"doc/unchanged.md": "same content",
+					"doc/changed.md":   "new content",
+					"doc/new.md":       "brand-new content",
+				},
+			},
+			MarkdownHousekeeper: &mockHousekeeper{},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		contentHashes []models.MarkdownContentHash
+		wantUpserted  []string
+	}{
+		{
+			name: "someChanged",
+			contentHashes: []models.MarkdownContentHash{
+				{Name: "unchanged", Path: "doc", Hash: sha256Hex("same content")},
+				{Name: "changed", Path: "doc", Hash: sha256Hex("old content")},
+			},
+			wantUpserted: []string{"changed", "new"},
+		},
+		{
+			name: "noneChanged",
+			contentHashes: []models.MarkdownContentHash{
+				{Name: "unchanged", Path: "doc", Hash: sha256Hex("same content")},
+				{Name: "changed", Path: "doc", Hash: sha256Hex("new content")},
+				{Name: "new", Path: "doc", Hash: sha256Hex("brand-new content")},
+			},
+			wantUpserted: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			mockedRepo := &mockRepository{contentHashes: tt.contentHashes}
+			newController(mockedRepo).FetchMarkdownsFromBitbucket(c)
+
+			if w.Code != http.StatusNoContent {
+				t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusNoContent)
+			}
+
+			if !mockedRepo.upsertMetasCalled {
+				t.Error("upsert metas was not called")
+				return
+			}
+
+			if got := mockedRepo.upsertContentsCalled; got != (tt.wantUpserted != nil) {
+				t.Errorf("want upsert contents to be called: %t, got %t", tt.wantUpserted != nil, got)
+				return
+			}
+
+			var gotUpserted []string
+			for _, mc := range mockedRepo.upsertedContents {
+				gotUpserted = append(gotUpserted, mc.Meta.Name)
+
+				if want := sha256Hex(mc.Content); mc.Hash != want {
+					t.Errorf("want the hash of %s to be %s, got %s", mc.Meta.Name, want, mc.Hash)
+				}
+			}
+
+			if !cmp.Equal(tt.wantUpserted, gotUpserted) {
+				t.Error(cmp.Diff(tt.wantUpserted, gotUpserted))
+				return
+			}
+		})
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_CoalescesConcurrentTriggers(t *testing.T) {
+	var core zapcore.Core
+
+	reader := &mockBitbucketReader{
+		files:       []string{"doc/intro.md"},
+		readContent: map[string]string{"doc/intro.md": "hi"},
+		listStarted: make(chan struct{}, 2),
+		release:     make(chan struct{}),
+	}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: &mockRepository{},
+			Logger:     &logging.DefaultLogger{Logger: zap.New(core).Sugar()},
+		},
+		BitbucketReader:     reader,
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	trigger := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		mockCtrl.FetchMarkdownsFromBitbucket(c)
+		return w
+	}
+
+	running := make(chan *httptest.ResponseRecorder)
+	go func() { running <- trigger() }()
+	<-reader.listStarted
+
+	if w := trigger(); w.Code != http.StatusAccepted {
+		t.Errorf("want a trigger during a running sync to be answered with %d, got %d", http.StatusAccepted, w.Code)
+	}
+
+	close(reader.release)
+	if w := <-running; w.Code != http.StatusNoContent {
+		t.Fatalf("want the running sync to finish with %d, got %d", http.StatusNoContent, w.Code)
+	}
+
+	reader.mu.Lock()
+	listCalls := reader.listCalls
+	reader.mu.Unlock()
+	if listCalls != 1 {
+		t.Fatalf("want Bitbucket to be read once, got %d", listCalls)
+	}
+
+	// once the running sync finished, the next trigger starts a new one
+	if w := trigger(); w.Code != http.StatusNoContent {
+		t.Errorf("want a trigger after the sync finished to be answered with %d, got %d", http.StatusNoContent, w.Code)
+		return
+	}
+}
+
+func getSyncStatus(t *testing.T, ctrl *bitbucket.Controller) bitbucket.SyncStatus {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/sync-status", nil)
+
+	ctrl.GetSyncStatus(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var response struct {
+		Data bitbucket.SyncStatus `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	return response.Data
+}
+
+func TestGetSyncStatus(t *testing.T) {
+	reader := &mockBitbucketReader{
+		files:       []string{"doc/a.md", "doc/b.md"},
+		readContent: map[string]string{"doc/a.md": "content a", "doc/b.md": "content b"},
+	}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: &mockRepository{},
+			Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+		},
+		BitbucketReader:     reader,
+		MarkdownHousekeeper: &mockHousekeeper{},
+	}
+
+	if got := getSyncStatus(t, mockCtrl); got.LastSyncAt != nil || got.LastSuccessfulSyncAt != nil {
+		t.Fatalf("want no sync before the first one, got %+v", got)
+	}
+
+	syncStart := time.Now()
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	succeeded := getSyncStatus(t, mockCtrl)
+	if succeeded.LastSyncAt == nil || succeeded.LastSyncAt.Before(syncStart) || succeeded.FilesIngested != 2 || len(succeeded.LastError) > 0 {
+		t.Fatalf("want a successful sync of 2 files after %s, got %+v", syncStart, succeeded)
+	}
+	if succeeded.LastSuccessfulSyncAt == nil || !succeeded.LastSuccessfulSyncAt.Equal(*succeeded.LastSyncAt) {
+		t.Fatalf("want the last sync to be the last successful one, got %+v", succeeded)
+	}
+
+	reader.failList = true
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+
+	// the failed sync is reported, but the successful one is kept
+	failed := getSyncStatus(t, mockCtrl)
+	if failed.LastSyncAt.Before(*succeeded.LastSyncAt) || failed.FilesIngested != 0 || len(failed.LastError) == 0 || failed.Running {
+		t.Errorf("want a failed sync after the successful one, got %+v", failed)
+		return
+	}
+	if !failed.LastSuccessfulSyncAt.Equal(*succeeded.LastSuccessfulSyncAt) {
+		t.Errorf("want the last successful sync at %s, got %s", succeeded.LastSuccessfulSyncAt, failed.LastSuccessfulSyncAt)
+		return
+	}
 }
 
 // ####################### invalid cases
@@ -108,6 +922,42 @@ func TestFetchMarkdownsFromBitbucket_ReadStructureFails(t *testing.T) {
 	}
 }
 
+func TestFetchMarkdownsFromBitbucket_StreamPageSize(t *testing.T) {
+	tests := []struct {
+		name           string
+		streamPageSize int
+		wantLimit      int
+	}{
+		{name: "default", streamPageSize: 0, wantLimit: bitbucket.DefaultStreamPageSize},
+		{name: "configured", streamPageSize: 500, wantLimit: 500},
+		{name: "clampedToMax", streamPageSize: 5000, wantLimit: bitbucket.MaxStreamPageSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			var core zapcore.Core
+
+			// listing fails, so that the sync stops right after
+			reader := &mockBitbucketReader{failList: true}
+			mockCtrl := &bitbucket.Controller{
+				Env:             &environment.Env{Logger: &logging.DefaultLogger{Logger: zap.New(core).Sugar()}},
+				BitbucketReader: reader,
+				StreamPageSize:  tt.streamPageSize,
+			}
+
+			mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+			if reader.gotLimit != tt.wantLimit {
+				t.Errorf("want limit %d, got %d", tt.wantLimit, reader.gotLimit)
+				return
+			}
+		})
+	}
+}
+
 func TestFetchMarkdownsFromBitbucket_DBMetaFetchFails(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -139,7 +989,133 @@ func TestFetchMarkdownsFromBitbucket_DBMetaFetchFails(t *testing.T) {
 	}
 }
 
+func TestReindex_SkipsDeletionIfTooFewFilesAreRead(t *testing.T) {
+	metasFromDb := []models.MarkdownMeta{
+		{Model: models.Model{ID: 1}, Name: "a", Path: "doc"},
+		{Model: models.Model{ID: 2}, Name: "b", Path: "doc"},
+		{Model: models.Model{ID: 3}, Name: "c", Path: "doc"},
+		{Model: models.Model{ID: 4}, Name: "d", Path: "doc"},
+	}
+
+	tests := []struct {
+		name               string
+		files              []string
+		maxDeletionPercent int
+		wantDeleted        bool
+	}{
+		{name: "emptyRead", files: []string{}, maxDeletionPercent: 100, wantDeleted: false},
+		{name: "dropAboveDefault", files: []string{"doc/a.md"}, wantDeleted: false},
+		{name: "dropBelowDefault", files: []string{"doc/a.md", "doc/b.md", "doc/c.md"}, wantDeleted: true},
+		{name: "dropBelowConfigured", files: []string{"doc/a.md"}, maxDeletionPercent: 75, wantDeleted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			readContent := make(map[string]string, len(tt.files))
+			for _, file := range tt.files {
+				readContent[file] = "content of " + file
+			}
+
+			housekeeper := &mockHousekeeper{}
+			mockCtrl := &bitbucket.Controller{
+				Env: &environment.Env{
+					Repository: &mockRepository{metas: metasFromDb},
+					Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+				},
+				BitbucketReader:        &mockBitbucketReader{files: tt.files, readContent: readContent},
+				MarkdownHousekeeper:    housekeeper,
+				SyncMaxDeletionPercent: tt.maxDeletionPercent,
+			}
+
+			mockCtrl.Reindex(c)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusOK)
+			}
+
+			var response struct {
+				Data bitbucket.ReindexReport `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if housekeeper.called != tt.wantDeleted {
+				t.Errorf("want the obsolete markdowns deleted %t, got %t", tt.wantDeleted, housekeeper.called)
+				return
+			}
+
+			if response.Data.DeletionSkipped == tt.wantDeleted {
+				t.Errorf("want the deletion skipped %t, got %t", !tt.wantDeleted, response.Data.DeletionSkipped)
+				return
+			}
+
+			if !tt.wantDeleted && response.Data.FilesDeleted != 0 {
+				t.Errorf("want no deleted files, got %d", response.Data.FilesDeleted)
+				return
+			}
+		})
+	}
+}
+
+func TestFetchMarkdownsFromBitbucket_NullReader(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mockedRepo := &mockRepository{
+		metas: []models.MarkdownMeta{
+			{Model: models.Model{ID: 1}, Name: "a", Path: "doc"},
+			{Model: models.Model{ID: 2}, Name: "b", Path: "doc"},
+		},
+	}
+	housekeeper := &mockHousekeeper{}
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: mockedRepo,
+			Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+		},
+		BitbucketReader:     &bitbucket.NullBitbucketReader{},
+		MarkdownHousekeeper: housekeeper,
+	}
+
+	mockCtrl.FetchMarkdownsFromBitbucket(c)
+
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status code mismatch: got %d, want %d", w.Code, http.StatusNoContent)
+	}
+
+	// the stored markdowns are kept as is
+	if housekeeper.called {
+		t.Error("want no obsolete markdowns to be deleted")
+		return
+	}
+
+	if mockedRepo.upsertContentsCalled || len(mockedRepo.upsertedContents) > 0 {
+		t.Errorf("want no markdown contents to be written, got %v", mockedRepo.upsertedContents)
+		return
+	}
+}
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
 // ####################### creating mocks
+func TestContentCaches_Invalidate(t *testing.T) {
+	first, second := &mockContentCache{}, &mockContentCache{}
+
+	bitbucket.ContentCaches{first, second}.Invalidate()
+
+	if !first.invalidated || !second.invalidated {
+		t.Errorf("want every cache to be invalidated, got %t and %t", first.invalidated, second.invalidated)
+		return
+	}
+}
+
 type mockHousekeeper struct {
 	called      bool
 	inputBitMd  []models.MarkdownMeta
@@ -158,27 +1134,88 @@ func (m *mockHousekeeper) DeleteObsoleteMarkdownsFromDatabase(
 	return m.returnError
 }
 
+func (m *mockHousekeeper) FindObsoleteMarkdownMetas(markdownMetasFromBitbucket []models.MarkdownMeta, markdownMetasFromDb []models.MarkdownMeta) []models.MarkdownMeta {
+	return (&bitbucket.DefaultMarkdownHousekeeper{}).FindObsoleteMarkdownMetas(markdownMetasFromBitbucket, markdownMetasFromDb)
+}
+
+type mockContentCache struct {
+	invalidated bool
+}
+
+func (m *mockContentCache) Invalidate() {
+	m.invalidated = true
+}
+
+type mockSyncMetrics struct {
+	syncs         int
+	filesIngested int
+}
+
+func (m *mockSyncMetrics) ObserveSync(_ time.Duration, filesIngested int) {
+	m.syncs++
+	m.filesIngested += filesIngested
+}
+
 type mockBitbucketReader struct {
 	files       []string
 	readContent map[string]string
 
 	failList     bool
 	failReadFile map[string]bool
+
+	// delay is the time each file read takes
+	delay time.Duration
+
+	// if set, listing the files signals listStarted and then blocks until release is closed
+	listStarted chan struct{}
+	release     chan struct{}
+
+	mu          sync.Mutex
+	listCalls   int
+	gotLimit    int
+	gotRevision string
+	// the revision the file structure was listed at
+	gotListRevision string
+	inFlight        int
+	maxInFlight     int
 }
 
-func (m *mockBitbucketReader) ReadMarkdownFileStructureRecursively(projectName, repoName string, start, limit int) ([]string, error) {
+func (m *mockBitbucketReader) ReadMarkdownFileStructureRecursively(_ context.Context, projectName, repoName, revision string, start, limit int) ([]string, error) {
+	m.mu.Lock()
+	m.listCalls++
+	m.gotLimit = limit
+	m.gotListRevision = revision
+	m.mu.Unlock()
+
+	if m.listStarted != nil {
+		m.listStarted <- struct{}{}
+		<-m.release
+	}
+
 	if m.failList {
 		return nil, fmt.Errorf("failed to list markdown files")
 	}
 	return m.files, nil
 }
 
-func (m *mockBitbucketReader) ReadRepoRootFolderContent(projectName, repoName string) ([]string, error) {
+func (m *mockBitbucketReader) ReadRepoRootFolderContent(_ context.Context, projectName, repoName string) ([]string, error) {
 	// Not needed for current tests, implement if required later
 	return nil, nil
 }
 
-func (m *mockBitbucketReader) ReadFileContentAtRevision(projectName, repoName, filePath, revision string) (string, error) {
+func (m *mockBitbucketReader) ReadFileContentAtRevision(_ context.Context, projectName, repoName, filePath, revision string) (string, error) {
+	m.mu.Lock()
+	m.gotRevision = revision
+	m.inFlight++
+	m.maxInFlight = max(m.maxInFlight, m.inFlight)
+	m.mu.Unlock()
+
+	time.Sleep(m.delay)
+
+	m.mu.Lock()
+	m.inFlight--
+	m.mu.Unlock()
+
 	if m.failReadFile != nil && m.failReadFile[filePath] {
 		return "", fmt.Errorf("failed to read %s", filePath)
 	}
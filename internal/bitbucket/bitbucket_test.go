@@ -1,15 +1,18 @@
 package bitbucket_test
 
 import (
+	"context"
 	"dice-sorensen-similarity-search/internal/bitbucket"
 	"dice-sorensen-similarity-search/internal/config"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/logging"
 	"errors"
+	"fmt"
 	bitbucketv1 "github.com/gfleury/go-bitbucket-v1"
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 	"testing"
+	"time"
 )
 
 type MockBitbucketAdapter struct {
@@ -17,20 +20,51 @@ type MockBitbucketAdapter struct {
 	GetRawContentResponse *bitbucketv1.APIResponse
 	GetContentResponse    *bitbucketv1.APIResponse
 	Error                 error
+
+	// captures the options passed to GetRawContent
+	GetRawContentOptions map[string]any
+	// captures the limit option of the last StreamFiles call
+	StreamFilesLimit any
+	// captures the options of the last StreamFiles call
+	StreamFilesOptions map[string]any
 }
 
-func (m *MockBitbucketAdapter) GetContent(projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+func (m *MockBitbucketAdapter) GetContent(_ context.Context, projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
 	return m.GetContentResponse, m.Error
 }
 
-func (m *MockBitbucketAdapter) GetRawContent(projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+func (m *MockBitbucketAdapter) GetRawContent(_ context.Context, projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	m.GetRawContentOptions = localVarOptionals
 	return m.GetRawContentResponse, m.Error
 }
 
-func (m *MockBitbucketAdapter) StreamFiles(projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+func (m *MockBitbucketAdapter) StreamFiles(_ context.Context, projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	m.StreamFilesLimit = localVarOptionals["limit"]
+	m.StreamFilesOptions = localVarOptionals
 	return m.StreamFilesResponse, m.Error
 }
 
+// endlessBitbucketAdapter serves endless pages of the file structure and calls onPage after each page
+type endlessBitbucketAdapter struct {
+	MockBitbucketAdapter
+	onPage func(page int)
+
+	pages int
+}
+
+func (m *endlessBitbucketAdapter) StreamFiles(_ context.Context, projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	m.pages++
+	m.onPage(m.pages)
+
+	return &bitbucketv1.APIResponse{
+		Values: map[string]any{
+			"isLastPage":    false,
+			"nextPageStart": float64(m.pages),
+			"values":        []any{fmt.Sprintf("markdowns/Page-%d.md", m.pages)},
+		},
+	}, nil
+}
+
 func TestReadRepoRootFolderContent(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -190,7 +224,7 @@ func TestReadRepoRootFolderContent(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			reader := &bitbucket.O11yBitbucketReader{Adapter: tt.adapter}
 
-			gotRootFolderContent, err := reader.ReadRepoRootFolderContent("test_project", "test_repo")
+			gotRootFolderContent, err := reader.ReadRepoRootFolderContent(context.Background(), "test_project", "test_repo")
 
 			if tt.expectError {
 				if err == nil {
@@ -252,7 +286,7 @@ func TestReadFileContentAtRevision(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			reader := &bitbucket.O11yBitbucketReader{Adapter: tt.adapter}
 
-			gotFileContentAtRevision, err := reader.ReadFileContentAtRevision("test_project", "test_repo", "my-path", "1")
+			gotFileContentAtRevision, err := reader.ReadFileContentAtRevision(context.Background(), "test_project", "test_repo", "my-path", "1")
 
 			if tt.expectError {
 				if err == nil {
@@ -271,6 +305,46 @@ func TestReadFileContentAtRevision(t *testing.T) {
 	}
 }
 
+func TestReadFileContentAtRevision_passesRevision(t *testing.T) {
+	tests := []struct {
+		name        string
+		revision    string
+		wantOptions map[string]any
+	}{
+		{
+			name:        "defaultBranch",
+			revision:    "",
+			wantOptions: map[string]any{},
+		},
+		{
+			name:        "tag",
+			revision:    "refs/tags/v1.0.0",
+			wantOptions: map[string]any{"at": "refs/tags/v1.0.0"},
+		},
+		{
+			name:        "commitHash",
+			revision:    "4f1d2c9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e",
+			wantOptions: map[string]any{"at": "4f1d2c9e8b7a6f5e4d3c2b1a0f9e8d7c6b5a4f3e"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &MockBitbucketAdapter{GetRawContentResponse: &bitbucketv1.APIResponse{Payload: []byte("# Hello")}}
+			reader := &bitbucket.O11yBitbucketReader{Adapter: adapter}
+
+			_, err := reader.ReadFileContentAtRevision(context.Background(), "test_project", "test_repo", "my-path", tt.revision)
+			if err != nil {
+				t.Fatalf("want NO error, but got: %v", err)
+			}
+
+			if !cmp.Equal(adapter.GetRawContentOptions, tt.wantOptions) {
+				t.Error(cmp.Diff(tt.wantOptions, adapter.GetRawContentOptions))
+			}
+		})
+	}
+}
+
 func getDummyFileContent() string {
 	return "# Step 1: Gather Your Information\n\nFor the onboarding process there are several Organisational information that is needed which will be uploaded later in Service Now. \n\n| Information needed                                   | Example                                                                                                                              |\n| ---------------------------------------------------- | ------------------------------------------------------------------------------------------------------------------------------------ |\n| Solution Code                                        | e.g.: 2DA31                                                                                                                          |\n| Solution Name                                        | e.g.: CLOUDSTREAM                                                                                                                    |\n| **three contacts for your solution**                 | **e.g.: (Solution manager, TAM, ...)**                                                                                               |\n| Contact Name                                         | e.g.: Hans Zimmermann                                                                                                                |\n| Contact Name                                         | e.g.: ...                                                                                                                            |\n| Contact Name                                         | e.g.: ...                                                                                                                            |\n|                                                      |                                                                                                                                      |\n|                                                      |                                                                                                                                      |\n| **Hostlist of the Systems that you want to monitor** |                                                                                                                                      |\n|                                                      |                                                                                                                                      |\n| Source Environment                                   | e.g.:<br>Production Data<br><br>- PROD<br>- QSYS<br><br>Non-Production <br><br>- Data<br>- DEV<br>- FAT<br>- UAT<br>- ABNA<br>- MONA |\n| IP                                                   | e.g.:                                                                                                                                |\n| Fully qualified domain Name                          | e.g.:                                                                                                                                |\n| System                                               | e.g.:RHEL, Windows, F5, Haproxy, Lamp,etc...                                                                                         |\n|                                                      |                                                                                                                                      |\n\n## Step 2 - Upload gathered information\n\nOnce all required information has been gathered, the formal request can be uploaded in [Service Now](https://servus.service-now.com/sp?id=im_cat_item&sys_id=8b6a088c87c47994028f631c8bbb358a) \n\n\n![](https://stash.s-mxs.net/projects/CIM/repos/o11y-self-service-content/raw/images/gatewayImg1ObservabilityGrafanaRequest.png)\n\nUpon receival, the data will be processed and the required Firewall-Clearance will be set up from our side.\nWhile waiting for the acceptance you can complete the procedure with step 3\n\n## Step 3 - ISD Update\n\nIn this step, you will update your ISD from your side. Align your update information with the table below. \n\n>[!NOTE]\n>- You as a data owner will allow us to monitor you with this DF. \n>- Also, security will be aware what kind of data you are sending to us\n>- This has nothing to do with technical implementation - that is covered in the Monitoring ISD! -> You do not need to add anything else or change architecture diagrams or so.\n\n>[!NOTE] \n> Next Step\n> Once processed, you will be contacted with an update or if needed we will align with you for a kickoff or followup meeting.\n  \n\n| **Dataflow Description ID** | **Dataflow ID** | **Source Solution**        | **Source Name**       | **Source Tenant**       | **Source Environment**                                                                                                                                    | **Source Zone**       | **Destination Solution**          | **Destination Name**    | **Destination Tenant** | **Destination Environment**                                       | **Destination Zone** | **Protocol** | **Ports** | **Transport Protection**                          | **Payload Protection**                            | **Description**                                                                                                                                                                                                                                                                                                                                        | **Justification**                                                                                                                                                     | **Cross-Environment Connections** | **Obsolete** | **Last Updated** |\n| --------------------------- | --------------- | -------------------------- | --------------------- | ----------------------- | --------------------------------------------------------------------------------------------------------------------------------------------------------- | --------------------- | --------------------------------- | ----------------------- | ---------------------- | ----------------------------------------------------------------- | -------------------- | ------------ | --------- | ------------------------------------------------- | ------------------------------------------------- | ------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------------ | --------------------------------------------------------------------------------------------------------------------------------------------------------------------- | --------------------------------- | ------------ | ---------------- |\n| DF-XXXXXXXX                 |                 | ***Insert your Solution*** | ***Please fill out*** | ***ED or EG or EB AT*** | ***Production Data<br><br>- PROD<br><br>- QSYS<br><br>Non-Production <br><br>- Data<br><br>- DEV<br><br>- FAT<br><br>- UAT<br><br>- ABNA<br><br>- MONA*** | ***Please fill out*** | AR341 - Infrastructure Monitoring | Zabbix Monitoring Proxy | ED                     | Production Data<br><br>- PROD<br><br>Non-Production <br><br>- UAT | Application          |              |           | ***In case of business data -> please fill out*** | ***In case of business data -> please fill out*** | ***DF for Data-Owners defining Data that is sent. <br><br>  <br><br>Data that is sent to Monitoring: <br><br>- Infrastructure & Performance Data<br><br>- Business Data<br><br>  <br><br>Business Data:<br><br>Describe your Data that you are sending to the Monitoring here. <br><br>  <br><br>Technical Implementation is defined in ISD-4960755*** | CEC: Our UAT is for Testing for our Customers, meaning that they will add different Environments  to it – but not Prod. Our Prod env will receive Data from all ENV’s | Yes (please enter justification)  |              |                  |\n\n\n> [!TIP]\n> In case you experience a longer delay, please contact the department [here](fakeDepartmentEmail@ErsteGroup.Com)\n"
 }
@@ -383,7 +457,7 @@ func TestReadMarkdownFileStructureRecursively(t *testing.T) {
 				Adapter: tt.adapter,
 			}
 
-			gotFilePaths, err := reader.ReadMarkdownFileStructureRecursively("test_project", "test_repo", 0, 150)
+			gotFilePaths, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "test_project", "test_repo", "", 0, 150)
 
 			if tt.expectError {
 				if err == nil {
@@ -402,6 +476,215 @@ func TestReadMarkdownFileStructureRecursively(t *testing.T) {
 	}
 }
 
+func TestReadMarkdownFileStructureRecursively_customDocsRoot(t *testing.T) {
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+
+	adapter := &MockBitbucketAdapter{
+		StreamFilesResponse: &bitbucketv1.APIResponse{
+			Values: map[string]any{
+				"isLastPage": true,
+				"values": []any{
+					".gitignore",
+					"docs/Gateway/1-Onboarding.md",
+					"docs/Getting-Started.md",
+					"docsearch/README.md",
+					"markdowns/Gateway/2-Data-Preparation.md",
+				},
+			},
+		},
+	}
+
+	reader := &bitbucket.O11yBitbucketReader{
+		Env:      env,
+		Adapter:  adapter,
+		DocsRoot: "docs",
+	}
+
+	gotFilePaths, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "test_project", "test_repo", "", 0, 150)
+	if err != nil {
+		t.Fatalf("want NO error, but got: %v", err)
+	}
+
+	want := []string{
+		"docs/Gateway/1-Onboarding.md",
+		"docs/Getting-Started.md",
+	}
+	if !cmp.Equal(gotFilePaths, want) {
+		t.Error(cmp.Diff(want, gotFilePaths))
+	}
+}
+
+func TestReadMarkdownFileStructureRecursively_limit(t *testing.T) {
+	adapter := &MockBitbucketAdapter{
+		StreamFilesResponse: &bitbucketv1.APIResponse{
+			Values: map[string]any{"isLastPage": true, "values": []any{"markdowns/Getting-Started.md"}},
+		},
+	}
+
+	reader := &bitbucket.O11yBitbucketReader{Env: environment.Null(), Adapter: adapter}
+
+	if _, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "test_project", "test_repo", "", 0, 500); err != nil {
+		t.Fatalf("want NO error, but got: %v", err)
+	}
+
+	if adapter.StreamFilesLimit != 500 {
+		t.Errorf("want the limit 500 in the options, got %v", adapter.StreamFilesLimit)
+		return
+	}
+}
+
+func TestReadMarkdownFileStructureRecursively_revision(t *testing.T) {
+	tests := []struct {
+		name     string
+		revision string
+		wantAt   any
+	}{
+		{name: "defaultBranch", revision: "", wantAt: nil},
+		{name: "tag", revision: "refs/tags/v1.0.0", wantAt: "refs/tags/v1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adapter := &MockBitbucketAdapter{
+				StreamFilesResponse: &bitbucketv1.APIResponse{
+					Values: map[string]any{"isLastPage": true, "values": []any{"markdowns/Getting-Started.md"}},
+				},
+			}
+
+			reader := &bitbucket.O11yBitbucketReader{Env: environment.Null(), Adapter: adapter}
+
+			if _, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "test_project", "test_repo", tt.revision, 0, 150); err != nil {
+				t.Fatalf("want NO error, but got: %v", err)
+			}
+
+			if got := adapter.StreamFilesOptions["at"]; got != tt.wantAt {
+				t.Errorf("want the option at %v, got %v", tt.wantAt, got)
+				return
+			}
+		})
+	}
+}
+
+func TestReadMarkdownFileStructureRecursively_canceledMidPagination(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// e.g., a shutdown while a hung sync is still paginating
+	adapter := &endlessBitbucketAdapter{onPage: func(page int) {
+		if page == 2 {
+			cancel()
+		}
+	}}
+
+	reader := &bitbucket.O11yBitbucketReader{Env: environment.Null(), Adapter: adapter}
+
+	gotFilePaths, err := reader.ReadMarkdownFileStructureRecursively(ctx, "test_project", "test_repo", "", 0, 150)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("want %v, got %v", context.Canceled, err)
+	}
+
+	if gotFilePaths != nil {
+		t.Errorf("want no file paths, got %v", gotFilePaths)
+		return
+	}
+
+	// the next page is not fetched once the context is done
+	if adapter.pages != 2 {
+		t.Errorf("want 2 fetched pages, got %d", adapter.pages)
+		return
+	}
+}
+
+func TestReadMarkdownFileStructureRecursively_deadlineExceeded(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	adapter := &endlessBitbucketAdapter{onPage: func(int) { time.Sleep(time.Millisecond) }}
+
+	reader := &bitbucket.O11yBitbucketReader{Env: environment.Null(), Adapter: adapter}
+
+	if _, err := reader.ReadMarkdownFileStructureRecursively(ctx, "test_project", "test_repo", "", 0, 150); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+		return
+	}
+}
+
+func TestReadFileContentAtRevision_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	adapter := &MockBitbucketAdapter{GetRawContentResponse: &bitbucketv1.APIResponse{Payload: []byte("# Hello")}}
+	reader := &bitbucket.O11yBitbucketReader{Env: environment.Null(), Adapter: adapter}
+
+	if _, err := reader.ReadFileContentAtRevision(ctx, "test_project", "test_repo", "markdowns/Hello.md", ""); !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+		return
+	}
+
+	if adapter.GetRawContentOptions != nil {
+		t.Error("want no request once the context is done")
+		return
+	}
+}
+
+func TestReadMarkdownFileStructureRecursively_extensions(t *testing.T) {
+	adapter := &MockBitbucketAdapter{
+		StreamFilesResponse: &bitbucketv1.APIResponse{
+			Values: map[string]any{
+				"isLastPage": true,
+				"values": []any{
+					"markdowns/Gateway/1-Onboarding.md",
+					"markdowns/Gateway/2-Data-Preparation.mdx",
+					"markdowns/Gateway/3-Visualization.markdown",
+					"markdowns/Gateway/diagram.png",
+					"markdowns/README.txt",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		extensions []string
+		want       []string
+	}{
+		{
+			name:       "default",
+			extensions: nil,
+			want:       []string{"markdowns/Gateway/1-Onboarding.md"},
+		},
+		{
+			name:       "configured",
+			extensions: []string{".md", ".mdx", ".markdown"},
+			want: []string{
+				"markdowns/Gateway/1-Onboarding.md",
+				"markdowns/Gateway/2-Data-Preparation.mdx",
+				"markdowns/Gateway/3-Visualization.markdown",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := &bitbucket.O11yBitbucketReader{
+				Env:        environment.Null(),
+				Adapter:    adapter,
+				Extensions: tt.extensions,
+			}
+
+			gotFilePaths, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "test_project", "test_repo", "", 0, 150)
+			if err != nil {
+				t.Fatalf("want NO error, but got: %v", err)
+			}
+
+			if !cmp.Equal(gotFilePaths, tt.want) {
+				t.Error(cmp.Diff(tt.want, gotFilePaths))
+			}
+		})
+	}
+}
+
 func createMockBitbucketAdapter() *MockBitbucketAdapter {
 	return &MockBitbucketAdapter{
 		StreamFilesResponse: &bitbucketv1.APIResponse{
@@ -436,12 +719,22 @@ func createMockBitbucketAdapter() *MockBitbucketAdapter {
 func TestInitBitbucket(t *testing.T) {
 	c := &config.Configuration{
 		BitBucket: struct {
-			Url         *config.JsonUrl
-			User        string
-			Password    string
-			AccessToken string
-			ProjectName string
-			Repository  string
+			Url                    *config.JsonUrl
+			User                   string
+			Password               string
+			AccessToken            string
+			ProjectName            string
+			Repository             string
+			DocsRoot               string
+			Extensions             []string
+			Revision               string
+			FetchConcurrency       int
+			StreamPageSize         int
+			MaxFileBytes           int
+			SyncMaxDeletionPercent int
+			RetryMaxAttempts       int
+			RetryBaseDelay         *config.JsonDuration
+			WebhookSecret          string
 		}{
 			//Url:         &config.JsonUrl{URL: &url.URL{Host: "api.bitbucket.org", Scheme: "https"}},
 			User:        "your-username",
@@ -455,7 +748,7 @@ func TestInitBitbucket(t *testing.T) {
 	env := environment.Null()
 	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
 
-	_, err := bitbucket.InitBitbucket(c, env)
+	_, err := bitbucket.InitBitbucket(context.Background(), c, env)
 	if err == nil {
 		t.Fatal("want error, but got nil")
 	}
@@ -0,0 +1,80 @@
+package bitbucket
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// LazyReader is a BitbucketReader that initializes its underlying reader on first use and retries the initialization
+// on every use until it succeeds. Until then, the reader is degraded: each read fails with the initialization error,
+// while the endpoints serving the data already stored in the database keep working.
+// This way, a source that is unreachable on startup (e.g., a transient Bitbucket outage) does not prevent the API from starting.
+type LazyReader struct {
+	// Init creates the underlying reader, e.g., with InitBitbucket
+	Init func() (BitbucketReader, error)
+
+	mu     sync.Mutex
+	reader BitbucketReader
+}
+
+// NewLazyReader creates a LazyReader; if the reader is already initialized, it is used right away
+func NewLazyReader(reader BitbucketReader, init func() (BitbucketReader, error)) *LazyReader {
+	return &LazyReader{Init: init, reader: reader}
+}
+
+// ensure LazyReader implements BitbucketReader
+var _ BitbucketReader = &LazyReader{}
+
+// Degraded reports whether the underlying reader is not initialized yet
+func (lr *LazyReader) Degraded() bool {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	return lr.reader == nil
+}
+
+// get returns the underlying reader, initializing it if necessary
+func (lr *LazyReader) get() (BitbucketReader, error) {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+
+	if lr.reader != nil {
+		return lr.reader, nil
+	}
+
+	reader, err := lr.Init()
+	if err != nil {
+		return nil, fmt.Errorf("the source is not initialized: %w", err)
+	}
+
+	lr.reader = reader
+	return reader, nil
+}
+
+func (lr *LazyReader) ReadMarkdownFileStructureRecursively(ctx context.Context, projectName, repoName, revision string, start, limit int) ([]string, error) {
+	reader, err := lr.get()
+	if err != nil {
+		return nil, err
+	}
+
+	return reader.ReadMarkdownFileStructureRecursively(ctx, projectName, repoName, revision, start, limit)
+}
+
+func (lr *LazyReader) ReadRepoRootFolderContent(ctx context.Context, projectName, repoName string) ([]string, error) {
+	reader, err := lr.get()
+	if err != nil {
+		return nil, err
+	}
+
+	return reader.ReadRepoRootFolderContent(ctx, projectName, repoName)
+}
+
+func (lr *LazyReader) ReadFileContentAtRevision(ctx context.Context, projectName, repoName string, filePath string, revision string) (string, error) {
+	reader, err := lr.get()
+	if err != nil {
+		return "", err
+	}
+
+	return reader.ReadFileContentAtRevision(ctx, projectName, repoName, filePath, revision)
+}
@@ -0,0 +1,127 @@
+package bitbucket_test
+
+import (
+	"context"
+	"dice-sorensen-similarity-search/internal/bitbucket"
+	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/logging"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// flakyInit fails the first failures initializations and returns the reader afterward
+type flakyInit struct {
+	failures int
+	reader   bitbucket.BitbucketReader
+
+	calls int
+}
+
+func (f *flakyInit) init() (bitbucket.BitbucketReader, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, errors.New("bitbucket unavailable")
+	}
+
+	return f.reader, nil
+}
+
+func TestLazyReader(t *testing.T) {
+	reader := &mockBitbucketReader{files: []string{"markdowns/Guide.md"}}
+	init := &flakyInit{failures: 1, reader: reader}
+
+	lazyReader := bitbucket.NewLazyReader(nil, init.init)
+	if !lazyReader.Degraded() {
+		t.Fatal("want a degraded reader before the initialization succeeded")
+	}
+
+	if _, err := lazyReader.ReadMarkdownFileStructureRecursively(context.Background(), "project", "repo", "", 0, 10); err == nil {
+		t.Fatal("want the initialization error while degraded, got nil")
+	}
+
+	got, err := lazyReader.ReadMarkdownFileStructureRecursively(context.Background(), "project", "repo", "", 0, 10)
+	if err != nil {
+		t.Fatalf("ReadMarkdownFileStructureRecursively error: %v", err)
+	}
+
+	if !cmp.Equal(reader.files, got) {
+		t.Error(cmp.Diff(reader.files, got))
+		return
+	}
+
+	// the initialized reader is kept
+	if _, err := lazyReader.ReadFileContentAtRevision(context.Background(), "project", "repo", "markdowns/Guide.md", ""); err != nil {
+		t.Fatalf("ReadFileContentAtRevision error: %v", err)
+	}
+
+	if lazyReader.Degraded() || init.calls != 2 {
+		t.Errorf("want an initialized reader after 2 initializations, got degraded %t after %d", lazyReader.Degraded(), init.calls)
+		return
+	}
+}
+
+func TestLazyReader_alreadyInitialized(t *testing.T) {
+	init := &flakyInit{}
+
+	lazyReader := bitbucket.NewLazyReader(&mockBitbucketReader{}, init.init)
+	if _, err := lazyReader.ReadRepoRootFolderContent(context.Background(), "project", "repo"); err != nil {
+		t.Fatalf("ReadRepoRootFolderContent error: %v", err)
+	}
+
+	if lazyReader.Degraded() || init.calls != 0 {
+		t.Errorf("want the given reader without initializing another one, got degraded %t after %d initialization(s)", lazyReader.Degraded(), init.calls)
+		return
+	}
+}
+
+func TestSyncOnStartup_retriesUntilSucceeded(t *testing.T) {
+	reader := &mockBitbucketReader{
+		files:       []string{"markdowns/Guide.md"},
+		readContent: map[string]string{"markdowns/Guide.md": "guide"},
+	}
+	init := &flakyInit{failures: 2, reader: reader}
+
+	mockCtrl := &bitbucket.Controller{
+		Env: &environment.Env{
+			Repository: &mockRepository{},
+			Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+		},
+		BitbucketReader:     bitbucket.NewLazyReader(nil, init.init),
+		MarkdownHousekeeper: &mockHousekeeper{},
+		SyncRetryDelay:      time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := mockCtrl.SyncOnStartup(ctx); err != nil {
+		t.Fatalf("SyncOnStartup error: %v", err)
+	}
+
+	if init.calls != 3 || reader.listCalls != 1 {
+		t.Errorf("want a sync after 3 initializations, got %d initialization(s) and %d sync(s)", init.calls, reader.listCalls)
+		return
+	}
+}
+
+func TestSyncOnStartup_stopsWhenContextIsDone(t *testing.T) {
+	init := &flakyInit{failures: 1_000_000}
+
+	mockCtrl := &bitbucket.Controller{
+		Env:                 &environment.Env{Logger: &logging.DefaultLogger{Logger: zap.NewNop().Sugar()}},
+		BitbucketReader:     bitbucket.NewLazyReader(nil, init.init),
+		MarkdownHousekeeper: &mockHousekeeper{},
+		SyncRetryDelay:      time.Millisecond,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := mockCtrl.SyncOnStartup(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+		return
+	}
+}
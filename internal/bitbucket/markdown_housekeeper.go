@@ -12,23 +12,43 @@ import (
 
 // MarkdownHousekeeper defines methods for cleaning up obsolete markdown records
 type MarkdownHousekeeper interface {
-	// DeleteObsoleteMarkdownsFromDatabase removes MarkdownMeta and MarkdownContent records
-	// that no longer exist in the Bitbucket repository.
+	// DeleteObsoleteMarkdownsFromDatabase soft-deletes MarkdownMeta and MarkdownContent records
+	// that no longer exist in the Bitbucket repository; they are purged once the retention window elapsed.
 	//
 	// param ctx param context.Context true "the context used for request-scoped operations"
 	// param markdownMetasFromBitbucket the set of markdown meta records fetched from Bitbucket
 	// param markdownMetasFromDb the set of markdown meta records currently in the database
 	// return error if deletion or lookup operations fail
 	DeleteObsoleteMarkdownsFromDatabase(ctx context.Context, markdownMetasFromBitbucket []models.MarkdownMeta, markdownMetasFromDb []models.MarkdownMeta) error
+
+	// FindObsoleteMarkdownMetas returns the MarkdownMeta records that DeleteObsoleteMarkdownsFromDatabase would delete
+	// without touching the database, e.g., for a dry run of a sync.
+	//
+	// param markdownMetasFromBitbucket the set of markdown meta records fetched from Bitbucket
+	// param markdownMetasFromDb the set of markdown meta records currently in the database
+	// return the markdown meta records from the database that no longer exist in the Bitbucket repository
+	FindObsoleteMarkdownMetas(markdownMetasFromBitbucket []models.MarkdownMeta, markdownMetasFromDb []models.MarkdownMeta) []models.MarkdownMeta
 }
 
+const (
+	// DefaultSoftDeleteRetention is how long soft-deleted markdowns are kept (and can be recovered) before they are purged
+	DefaultSoftDeleteRetention = 7 * 24 * time.Hour
+	// DefaultPurgeInterval is the interval of the purge job
+	DefaultPurgeInterval = time.Hour
+)
+
 // DefaultMarkdownHousekeeper provides a default implementation of MarkdownHousekeeper.
 type DefaultMarkdownHousekeeper struct {
 	*environment.Env
+
+	// SoftDeleteRetention is how long soft-deleted markdowns are kept before they are purged (default: DefaultSoftDeleteRetention)
+	SoftDeleteRetention time.Duration
+	// PurgeInterval is the interval of RunPurgeJob (default: DefaultPurgeInterval)
+	PurgeInterval time.Duration
 }
 
 // DeleteObsoleteMarkdownsFromDatabase compares markdown metadata from Bitbucket
-// with entries in the database and deletes entries from the database that are no longer present.
+// with entries in the database and soft-deletes entries from the database that are no longer present.
 //
 // param ctx the context for database operations
 // param markdownMetasFromBitbucket the current markdown metadata from Bitbucket
@@ -37,13 +57,10 @@ type DefaultMarkdownHousekeeper struct {
 func (hk *DefaultMarkdownHousekeeper) DeleteObsoleteMarkdownsFromDatabase(ctx context.Context, markdownMetasFromBitbucket []models.MarkdownMeta, markdownMetasFromDb []models.MarkdownMeta) error {
 	hk.LogInfo(nil, "start markdown meta data clean up")
 
-	markdownMetasFromBitbucketByName := utils.SliceToMap(markdownMetasFromBitbucket, func(meta models.MarkdownMeta) string { return meta.Name })
-
-	toBeDeletedMarkdownMetaIds := make([]uint, 0, len(markdownMetasFromDb)/2)
-	for _, v := range markdownMetasFromDb {
-		if _, ok := markdownMetasFromBitbucketByName[v.Name]; !ok {
-			toBeDeletedMarkdownMetaIds = append(toBeDeletedMarkdownMetaIds, v.ID)
-		}
+	toBeDeletedMarkdownMetas := hk.FindObsoleteMarkdownMetas(markdownMetasFromBitbucket, markdownMetasFromDb)
+	toBeDeletedMarkdownMetaIds := make([]uint, 0, len(toBeDeletedMarkdownMetas))
+	for _, v := range toBeDeletedMarkdownMetas {
+		toBeDeletedMarkdownMetaIds = append(toBeDeletedMarkdownMetaIds, v.ID)
 	}
 
 	if len(toBeDeletedMarkdownMetaIds) == 0 {
@@ -77,6 +94,25 @@ func (hk *DefaultMarkdownHousekeeper) DeleteObsoleteMarkdownsFromDatabase(ctx co
 	return nil
 }
 
+// FindObsoleteMarkdownMetas compares markdown metadata from Bitbucket with entries in the database
+// and returns the entries from the database that are no longer present.
+//
+// param markdownMetasFromBitbucket the current markdown metadata from Bitbucket
+// param markdownMetasFromDb the existing markdown metadata in the database
+// return the obsolete markdown metadata in the database
+func (hk *DefaultMarkdownHousekeeper) FindObsoleteMarkdownMetas(markdownMetasFromBitbucket []models.MarkdownMeta, markdownMetasFromDb []models.MarkdownMeta) []models.MarkdownMeta {
+	markdownMetasFromBitbucketByKey := utils.SliceToMap(markdownMetasFromBitbucket, func(meta models.MarkdownMeta) string { return markdownKey(meta.Path, meta.Name) })
+
+	obsolete := make([]models.MarkdownMeta, 0, len(markdownMetasFromDb)/2)
+	for _, v := range markdownMetasFromDb {
+		if _, ok := markdownMetasFromBitbucketByKey[markdownKey(v.Path, v.Name)]; !ok {
+			obsolete = append(obsolete, v)
+		}
+	}
+
+	return obsolete
+}
+
 // deleteObsoleteTuples removes markdown records (either meta or content) from the database,
 // logs the outcome and tracks the deletion duration.
 //
@@ -86,7 +122,7 @@ func (hk *DefaultMarkdownHousekeeper) DeleteObsoleteMarkdownsFromDatabase(ctx co
 // return error if deletion fails or if modelType is invalid
 func (hk *DefaultMarkdownHousekeeper) deleteObsoleteTuples(ctx context.Context, toBeDeletedMarkdownTupleIds []uint, modelType ModelType) error {
 	start := time.Now()
-	msg := fmt.Sprintf("deleting %d obsolete %s tuple(s)", len(toBeDeletedMarkdownTupleIds), modelType)
+	msg := fmt.Sprintf("soft-deleting %d obsolete %s tuple(s)", len(toBeDeletedMarkdownTupleIds), modelType)
 
 	hk.LogInfo(nil, "start "+msg)
 
@@ -113,6 +149,56 @@ func (hk *DefaultMarkdownHousekeeper) deleteObsoleteTuples(ctx context.Context,
 	return nil
 }
 
+// PurgeSoftDeletedMarkdowns permanently removes the markdowns that were soft-deleted longer than the retention window ago.
+//
+// param ctx the context for database operations
+// return error if the purge fails
+func (hk *DefaultMarkdownHousekeeper) PurgeSoftDeletedMarkdowns(ctx context.Context) error {
+	deletedBefore := time.Now().Add(-hk.softDeleteRetention())
+
+	var purgedMetas int64
+	if err := hk.Repository.PurgeSoftDeletedMarkdowns(ctx, deletedBefore, &purgedMetas); err != nil {
+		hk.LogError(nil, err.Error())
+		return fmt.Errorf("error purging soft-deleted markdowns from the database: %s", err.Error())
+	}
+
+	hk.LogInfo(nil, fmt.Sprintf("purged %d markdown file(s) soft-deleted before %s", purgedMetas, deletedBefore.Format(time.RFC3339)))
+	return nil
+}
+
+// RunPurgeJob purges the soft-deleted markdowns every PurgeInterval until the context is done.
+// A failed purge is logged and retried on the next run.
+//
+// param ctx the context stopping the job
+// return the context's error
+func (hk *DefaultMarkdownHousekeeper) RunPurgeJob(ctx context.Context) error {
+	ticker := time.NewTicker(hk.purgeInterval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			_ = hk.PurgeSoftDeletedMarkdowns(ctx)
+		}
+	}
+}
+
+func (hk *DefaultMarkdownHousekeeper) softDeleteRetention() time.Duration {
+	if hk.SoftDeleteRetention <= 0 {
+		return DefaultSoftDeleteRetention
+	}
+	return hk.SoftDeleteRetention
+}
+
+func (hk *DefaultMarkdownHousekeeper) purgeInterval() time.Duration {
+	if hk.PurgeInterval <= 0 {
+		return DefaultPurgeInterval
+	}
+	return hk.PurgeInterval
+}
+
 // createWarningMsgForMetasWithoutAReferenceToAContent generates a warning message for
 // markdown meta entries that no longer have related content records.
 //
@@ -134,3 +220,8 @@ func (hk *DefaultMarkdownHousekeeper) createWarningMsgForMetasWithoutAReferenceT
 
 	return msg
 }
+
+// markdownKey identifies a Markdown file by its folder path and name, which are unique together (see models.MarkdownMeta)
+func markdownKey(path, name string) string {
+	return path + "/" + name
+}
@@ -3,6 +3,7 @@ package bitbucket_test
 import (
 	"context"
 	"dice-sorensen-similarity-search/internal/bitbucket"
+	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/models"
@@ -14,6 +15,7 @@ import (
 	"log/slog"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ####################### tests
@@ -54,6 +56,65 @@ func TestDeleteObsoleteMarkdownsFromDatabase_CleansUpCorrectly(t *testing.T) {
 	}
 }
 
+func TestDeleteObsoleteMarkdownsFromDatabase_SameNameInDifferentFolders(t *testing.T) {
+	mockRepo := &mockRepository{
+		foundContentIds: []uint{102},
+	}
+
+	env := environment.Null()
+	env.Repository = mockRepo
+
+	hk := &bitbucket.DefaultMarkdownHousekeeper{Env: env}
+
+	dbMetas := []models.MarkdownMeta{
+		{Model: models.Model{ID: 1}, Name: "Guide", Path: "markdowns/Gateway"},
+		{Model: models.Model{ID: 2}, Name: "Guide", Path: "markdowns/Alloy"},
+	}
+	bitbucketMetas := []models.MarkdownMeta{
+		{Name: "Guide", Path: "markdowns/Gateway"},
+	}
+
+	err := hk.DeleteObsoleteMarkdownsFromDatabase(context.Background(), bitbucketMetas, dbMetas)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []uint{2}
+	got := mockRepo.deletedMetas
+	if !cmp.Equal(got, want) {
+		t.Errorf("deletedMetas mismatch:\n got:  %v\n want: %v", got, want)
+	}
+}
+
+func TestFindObsoleteMarkdownMetas_DoesNotTouchTheDatabase(t *testing.T) {
+	mockRepo := &mockRepository{
+		foundContentIds: []uint{102},
+	}
+
+	env := environment.Null()
+	env.Repository = mockRepo
+
+	hk := &bitbucket.DefaultMarkdownHousekeeper{Env: env}
+
+	dbMetas := []models.MarkdownMeta{
+		{Model: models.Model{ID: 1}, Name: "Guide", Path: "markdowns/Gateway"},
+		{Model: models.Model{ID: 2}, Name: "Guide", Path: "markdowns/Alloy"},
+	}
+	bitbucketMetas := []models.MarkdownMeta{
+		{Name: "Guide", Path: "markdowns/Gateway"},
+	}
+
+	want := []models.MarkdownMeta{dbMetas[1]}
+	got := hk.FindObsoleteMarkdownMetas(bitbucketMetas, dbMetas)
+	if !cmp.Equal(got, want) {
+		t.Error(cmp.Diff(want, got))
+	}
+
+	if mockRepo.deletedMetas != nil || mockRepo.deletedContent != nil {
+		t.Errorf("want no deletes, got deleted metas %v and contents %v", mockRepo.deletedMetas, mockRepo.deletedContent)
+	}
+}
+
 func TestDeleteObsoleteMarkdowns_EarlyReturn(t *testing.T) {
 	mockRepo := &mockRepository{}
 	var core zapcore.Core
@@ -190,6 +251,77 @@ func TestDeleteObsoleteMarkdowns_WarnWhenNoContentToDelete(t *testing.T) {
 	}
 }
 
+func TestPurgeSoftDeletedMarkdowns_RetentionWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		retention time.Duration
+		want      time.Duration
+	}{
+		{name: "default", want: bitbucket.DefaultSoftDeleteRetention},
+		{name: "configured", retention: time.Hour, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := &mockRepository{}
+			env := &environment.Env{
+				Repository: mockRepo,
+				Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+			}
+
+			hk := &bitbucket.DefaultMarkdownHousekeeper{Env: env, SoftDeleteRetention: tt.retention}
+
+			before := time.Now()
+			if err := hk.PurgeSoftDeletedMarkdowns(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			// only the markdowns soft-deleted before the retention window are purged
+			if got := before.Sub(mockRepo.purgedBefore); got > tt.want || got < tt.want-time.Minute {
+				t.Errorf("want a cutoff %v ago, got %v ago", tt.want, got)
+				return
+			}
+		})
+	}
+}
+
+func TestPurgeSoftDeletedMarkdowns_Error(t *testing.T) {
+	mockRepo := &mockRepository{purgeErr: errors.New("can't purge")}
+	env := &environment.Env{
+		Repository: mockRepo,
+		Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+	}
+
+	hk := &bitbucket.DefaultMarkdownHousekeeper{Env: env}
+
+	if err := hk.PurgeSoftDeletedMarkdowns(context.Background()); err == nil || !strings.Contains(err.Error(), "can't purge") {
+		t.Errorf("expected purge error, got = %v", err)
+	}
+}
+
+func TestRunPurgeJob_stopsWhenContextIsDone(t *testing.T) {
+	mockRepo := &mockRepository{}
+	env := &environment.Env{
+		Repository: mockRepo,
+		Logger:     &logging.DefaultLogger{Logger: zap.NewNop().Sugar()},
+	}
+
+	hk := &bitbucket.DefaultMarkdownHousekeeper{Env: env, PurgeInterval: time.Millisecond}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := hk.RunPurgeJob(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want %v, got %v", context.DeadlineExceeded, err)
+		return
+	}
+
+	if mockRepo.purgedBefore.IsZero() {
+		t.Error("want at least one purge before the context was done")
+		return
+	}
+}
+
 // ####################### creating mocks
 type mockRepository struct {
 	deletedMetas   []uint
@@ -207,13 +339,60 @@ type mockRepository struct {
 	nameWasSanitizedCorrectly bool
 
 	charCountByName map[string]uint
+
+	upsertedContents []models.MarkdownContent
+	contentHashes    []models.MarkdownContentHash
+	metas            []models.MarkdownMeta
+
+	upsertContentsErr error
+	transactions      int
+	rolledBack        bool
+
+	purgedBefore time.Time
+	purgeErr     error
+}
+
+func (m *mockRepository) FindVisibleMarkdowns(ctx context.Context, markdowns *[]models.MarkdownContent) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.MarkdownContent) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTermWithOptions(ctx context.Context, searchTerm string, options database.SearchOptions, markdowns *[]models.MarkdownContent) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTerms(ctx context.Context, searchTerms []string, matchAll bool, pathPrefix string, markdowns *[]models.MarkdownContent) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error {
+	panic("implement me")
 }
 
-func (m *mockRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm string, markdowns *[]models.MarkdownContent) error {
+func (m *mockRepository) FindMarkdownsByFullText(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error {
 	panic("implement me")
 }
 
-func (m *mockRepository) CountMarkdownsMatchesBySearchTermSimple(ctx context.Context, searchTerm string, matchCount *int) error {
+func (m *mockRepository) FindMarkdownsBySearchTermPaged(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, pageNumber, pageSize int, markdowns *[]models.ScoredMarkdownContent) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) CountMarkdownsMatchesBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, matchCount *int) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) FindMarkdownDocumentsPaged(ctx context.Context, options database.DocumentListOptions, pageNumber, pageSize int, documents *[]models.MarkdownDocument) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) CountMarkdownDocuments(ctx context.Context, includeHidden bool, documentCount *int) error {
+	panic("implement me")
+}
+
+func (m *mockRepository) CountAllMarkdowns(ctx context.Context, stats *models.MarkdownStats) error {
 	panic("implement me")
 }
 
@@ -233,14 +412,44 @@ func (m *mockRepository) DeleteMarkdownContentsByIds(_ context.Context, ids []ui
 	return nil
 }
 
+func (m *mockRepository) PurgeSoftDeletedMarkdowns(_ context.Context, deletedBefore time.Time, purgedMetas *int64) error {
+	if m.purgeErr != nil {
+		return m.purgeErr
+	}
+	m.purgedBefore = deletedBefore
+	*purgedMetas = 1
+	return nil
+}
+
+func (m *mockRepository) Ping(_ context.Context) error {
+	return nil
+}
+
+func (m *mockRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.transactions++
+	err := fn(ctx)
+	m.rolledBack = err != nil
+	return err
+}
+
 func (m *mockRepository) FindUserLoginCredentials(_ context.Context, _ string, _ *models.User) error {
 	return nil
 }
 
-func (m *mockRepository) FindAllMarkdownMetas(_ context.Context, _ *[]models.MarkdownMeta) error {
+func (m *mockRepository) CreateUser(_ context.Context, _ *models.User) error {
+	return nil
+}
+
+func (m *mockRepository) FindAllMarkdownMetas(_ context.Context, metas *[]models.MarkdownMeta) error {
 	if m.failMetaQuery {
 		return m.findErr
 	}
+	*metas = append(*metas, m.metas...)
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownContentHashes(_ context.Context, contentHashes *[]models.MarkdownContentHash) error {
+	*contentHashes = append(*contentHashes, m.contentHashes...)
 	return nil
 }
 
@@ -252,6 +461,10 @@ func (m *mockRepository) FindMarkdownContentByName(_ context.Context, _ string,
 	return nil
 }
 
+func (m *mockRepository) FindMarkdownContentByPath(_ context.Context, _, _ string, _ *models.MarkdownContent) error {
+	return nil
+}
+
 func (m *mockRepository) FindMarkdownContentIdsByMetaIds(_ context.Context, _ []uint, out *[]uint) error {
 	if m.findErr != nil {
 		return m.findErr
@@ -284,7 +497,11 @@ func (m *mockRepository) UpsertMarkdownMetas(_ context.Context, metas []models.M
 	return nil
 }
 
-func (m *mockRepository) UpsertMarkdownContents(_ context.Context, _ []models.MarkdownContent) error {
+func (m *mockRepository) UpsertMarkdownContents(_ context.Context, contents []models.MarkdownContent) error {
 	m.upsertContentsCalled = true
+	if m.upsertContentsErr != nil {
+		return m.upsertContentsErr
+	}
+	m.upsertedContents = contents
 	return nil
 }
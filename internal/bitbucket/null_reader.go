@@ -0,0 +1,23 @@
+package bitbucket
+
+import "context"
+
+// NullBitbucketReader is a no-op implementation of the BitbucketReader interface; the source has no Markdown files.
+// Useful for local development or testing when the API serves the Markdown files already stored in the database
+// without connecting to a source (see constants.SourceProviderNone).
+type NullBitbucketReader struct{}
+
+// ensure NullBitbucketReader implements BitbucketReader
+var _ BitbucketReader = &NullBitbucketReader{}
+
+func (n *NullBitbucketReader) ReadMarkdownFileStructureRecursively(_ context.Context, projectName, repoName, revision string, start, limit int) ([]string, error) {
+	return []string{}, nil
+}
+
+func (n *NullBitbucketReader) ReadRepoRootFolderContent(_ context.Context, projectName, repoName string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (n *NullBitbucketReader) ReadFileContentAtRevision(_ context.Context, projectName, repoName string, filePath string, revision string) (string, error) {
+	return "", nil
+}
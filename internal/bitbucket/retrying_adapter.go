@@ -0,0 +1,105 @@
+package bitbucket
+
+import (
+	"context"
+	"github.com/gfleury/go-bitbucket-v1"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultRetryMaxAttempts is the number of attempts made per Bitbucket API call if no (positive) MaxAttempts is configured
+	DefaultRetryMaxAttempts = 3
+	// DefaultRetryBaseDelay is the delay before the first retry if no (positive) BaseDelay is configured
+	DefaultRetryBaseDelay = 200 * time.Millisecond
+)
+
+// RetryingAdapter decorates a BitbucketApiServiceAdapter and retries failed (idempotent) GET requests
+// using exponential backoff with jitter.
+//
+// Only network errors and server errors (5xx) are retried; client errors (4xx) are returned immediately.
+// Once the context of a call is done, it is neither retried nor waited for.
+type RetryingAdapter struct {
+	Adapter BitbucketApiServiceAdapter
+
+	// MaxAttempts is the total number of attempts per call, including the first one (default: 3)
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry; it doubles with every further retry (default: 200ms)
+	BaseDelay time.Duration
+}
+
+// ensure RetryingAdapter implements BitbucketApiServiceAdapter
+var _ BitbucketApiServiceAdapter = &RetryingAdapter{}
+
+func (ra *RetryingAdapter) GetContent(ctx context.Context, projectKey string, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	return ra.retry(ctx, func() (*bitbucketv1.APIResponse, error) {
+		return ra.Adapter.GetContent(ctx, projectKey, repositorySlug, localVarOptionals)
+	})
+}
+
+func (ra *RetryingAdapter) GetRawContent(ctx context.Context, projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	return ra.retry(ctx, func() (*bitbucketv1.APIResponse, error) {
+		return ra.Adapter.GetRawContent(ctx, projectKey, repositorySlug, path, localVarOptionals)
+	})
+}
+
+func (ra *RetryingAdapter) StreamFiles(ctx context.Context, projectKey, repositorySlug string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	return ra.retry(ctx, func() (*bitbucketv1.APIResponse, error) {
+		return ra.Adapter.StreamFiles(ctx, projectKey, repositorySlug, localVarOptionals)
+	})
+}
+
+// retry calls the given function until it succeeds, fails with a non-retryable error, the attempts are exhausted, or the context is done.
+// The response and error of the last attempt are returned; the context's error is returned if it is done while backing off.
+func (ra *RetryingAdapter) retry(ctx context.Context, call func() (*bitbucketv1.APIResponse, error)) (*bitbucketv1.APIResponse, error) {
+	maxAttempts := ra.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultRetryMaxAttempts
+	}
+
+	baseDelay := ra.BaseDelay
+	if baseDelay <= 0 {
+		baseDelay = DefaultRetryBaseDelay
+	}
+
+	var response *bitbucketv1.APIResponse
+	var err error
+
+	for attempt := range maxAttempts {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff(baseDelay, attempt)):
+			}
+		}
+
+		response, err = call()
+		// a failure caused by the done context (e.g., a canceled sync) is not worth retrying
+		if err == nil || ctx.Err() != nil || !isRetryable(response) {
+			return response, err
+		}
+	}
+
+	return response, err
+}
+
+// backoff returns the delay before the given retry (1-based);
+// it grows exponentially and is jittered to a random value within [delay/2, delay]
+func backoff(baseDelay time.Duration, retry int) time.Duration {
+	delay := baseDelay << (retry - 1)
+	half := delay / 2
+
+	return half + rand.N(half+1)
+}
+
+// isRetryable reports whether a failed request is worth retrying, i.e. it failed with a network error
+// (there is no HTTP response) or with a server error (5xx)
+func isRetryable(response *bitbucketv1.APIResponse) bool {
+	if response == nil || response.Response == nil {
+		return true
+	}
+
+	return response.StatusCode >= http.StatusInternalServerError
+}
@@ -0,0 +1,177 @@
+package bitbucket_test
+
+import (
+	"context"
+	"dice-sorensen-similarity-search/internal/bitbucket"
+	"errors"
+	bitbucketv1 "github.com/gfleury/go-bitbucket-v1"
+	"github.com/google/go-cmp/cmp"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// flakyBitbucketAdapter fails the first failures calls with the given failure response and delegates afterward
+type flakyBitbucketAdapter struct {
+	*MockBitbucketAdapter
+
+	failures        int
+	failureResponse *bitbucketv1.APIResponse
+	// onCall is called on every call, if set
+	onCall func()
+
+	calls int
+}
+
+func (f *flakyBitbucketAdapter) GetRawContent(ctx context.Context, projectKey, repositorySlug, path string, localVarOptionals map[string]any) (*bitbucketv1.APIResponse, error) {
+	f.calls++
+	if f.onCall != nil {
+		f.onCall()
+	}
+	if f.calls <= f.failures {
+		return f.failureResponse, errors.New("bitbucket unavailable")
+	}
+
+	return f.MockBitbucketAdapter.GetRawContent(ctx, projectKey, repositorySlug, path, localVarOptionals)
+}
+
+func responseWithStatus(statusCode int) *bitbucketv1.APIResponse {
+	return &bitbucketv1.APIResponse{Response: &http.Response{StatusCode: statusCode}}
+}
+
+func TestRetryingAdapter_GetRawContent(t *testing.T) {
+	tests := []struct {
+		name            string
+		failures        int
+		failureResponse *bitbucketv1.APIResponse
+		maxAttempts     int
+		wantCalls       int
+		wantPayload     string
+		wantErr         bool
+	}{
+		{
+			name:            "succeedsAfterTwoServerErrors",
+			failures:        2,
+			failureResponse: responseWithStatus(http.StatusServiceUnavailable),
+			maxAttempts:     3,
+			wantCalls:       3,
+			wantPayload:     "# Hello",
+		},
+		{
+			name:        "succeedsAfterTwoNetworkErrors",
+			failures:    2,
+			maxAttempts: 3,
+			wantCalls:   3,
+			wantPayload: "# Hello",
+		},
+		{
+			name:            "givesUpAfterMaxAttempts",
+			failures:        5,
+			failureResponse: responseWithStatus(http.StatusBadGateway),
+			maxAttempts:     3,
+			wantCalls:       3,
+			wantErr:         true,
+		},
+		{
+			name:            "doesNotRetryClientErrors",
+			failures:        2,
+			failureResponse: responseWithStatus(http.StatusNotFound),
+			maxAttempts:     3,
+			wantCalls:       1,
+			wantErr:         true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyBitbucketAdapter{
+				MockBitbucketAdapter: &MockBitbucketAdapter{
+					GetRawContentResponse: &bitbucketv1.APIResponse{Payload: []byte("# Hello")},
+				},
+				failures:        tt.failures,
+				failureResponse: tt.failureResponse,
+			}
+
+			adapter := &bitbucket.RetryingAdapter{
+				Adapter:     flaky,
+				MaxAttempts: tt.maxAttempts,
+				BaseDelay:   time.Millisecond,
+			}
+
+			response, err := adapter.GetRawContent(context.Background(), "CIM", "o11y-self-service-content", "markdowns/intro.md", nil)
+
+			if flaky.calls != tt.wantCalls {
+				t.Errorf("want %d calls, got %d", tt.wantCalls, flaky.calls)
+				return
+			}
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("want error, but got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Errorf("want no error, got %v", err)
+				return
+			}
+
+			if !cmp.Equal(tt.wantPayload, string(response.Payload)) {
+				t.Error(cmp.Diff(tt.wantPayload, string(response.Payload)))
+				return
+			}
+		})
+	}
+}
+
+func TestRetryingAdapter_GetRawContent_canceledDuringBackoff(t *testing.T) {
+	flaky := &flakyBitbucketAdapter{
+		MockBitbucketAdapter: &MockBitbucketAdapter{},
+		failures:             5,
+		failureResponse:      responseWithStatus(http.StatusServiceUnavailable),
+	}
+
+	// the backoff would outlast the test if it was not canceled
+	adapter := &bitbucket.RetryingAdapter{Adapter: flaky, MaxAttempts: 3, BaseDelay: time.Hour}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	_, err := adapter.GetRawContent(ctx, "CIM", "o11y-self-service-content", "markdowns/intro.md", nil)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want %v, got %v", context.Canceled, err)
+		return
+	}
+
+	if flaky.calls != 1 {
+		t.Errorf("want 1 call, got %d", flaky.calls)
+		return
+	}
+}
+
+func TestRetryingAdapter_GetRawContent_doesNotRetryOnceContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// the call fails like a request aborted by the canceled context, i.e., with a network error
+	flaky := &flakyBitbucketAdapter{
+		MockBitbucketAdapter: &MockBitbucketAdapter{},
+		failures:             5,
+		onCall:               cancel,
+	}
+
+	adapter := &bitbucket.RetryingAdapter{Adapter: flaky, MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	if _, err := adapter.GetRawContent(ctx, "CIM", "o11y-self-service-content", "markdowns/intro.md", nil); err == nil {
+		t.Error("want error, but got nil")
+		return
+	}
+
+	if flaky.calls != 1 {
+		t.Errorf("want 1 call, got %d", flaky.calls)
+		return
+	}
+}
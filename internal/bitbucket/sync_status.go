@@ -0,0 +1,53 @@
+package bitbucket
+
+import (
+	"sync"
+	"time"
+)
+
+// SyncStatus reports the outcome of the last sync, so that operators can tell whether the index is stale
+type SyncStatus struct {
+	// Running is set while a sync is running
+	Running bool `json:"running"`
+	// LastSyncAt is the time the last sync finished, successfully or not; it is nil if no sync has finished yet
+	LastSyncAt *time.Time `json:"lastSyncAt"`
+	// LastSuccessfulSyncAt is the time the last successful sync finished; it is nil if no sync has succeeded yet
+	LastSuccessfulSyncAt *time.Time `json:"lastSuccessfulSyncAt"`
+	LastSyncDurationMs   int64      `json:"lastSyncDurationMs"`
+	// FilesIngested is the number of Markdown files the last sync read from the source; 0 if it failed
+	FilesIngested int `json:"filesIngested"`
+	// LastError is the error of the last sync; empty if it succeeded
+	LastError string `json:"lastError"`
+}
+
+// syncStatusHolder holds the SyncStatus of a Controller; it is safe for concurrent use and its zero value is ready to use
+type syncStatusHolder struct {
+	mu     sync.RWMutex
+	status SyncStatus
+}
+
+// record updates the status with the outcome of a sync that started at start and finished now
+func (h *syncStatusHolder) record(start time.Time, report ReindexReport, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	finishedAt := time.Now()
+	h.status.LastSyncAt = &finishedAt
+	h.status.LastSyncDurationMs = finishedAt.Sub(start).Milliseconds()
+	h.status.FilesIngested = report.FilesProcessed
+
+	if err != nil {
+		h.status.LastError = err.Error()
+		return
+	}
+
+	h.status.LastError = ""
+	h.status.LastSuccessfulSyncAt = &finishedAt
+}
+
+func (h *syncStatusHolder) get() SyncStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.status
+}
@@ -2,12 +2,20 @@ package main
 
 import (
 	"context"
+	"dice-sorensen-similarity-search/internal/config"
 	"dice-sorensen-similarity-search/internal/middlewares"
 	"log/slog"
+	"os"
 )
 
 func main() {
-	token, _, err := middlewares.GenerateToken(context.Background(), []byte(middlewares.SigningKey), 0, "s79bb", []string{"admin"})
+	signingKey := os.Getenv(config.SigningKeyEnvVar)
+	if err := config.ValidateSigningKey(signingKey); err != nil {
+		slog.Error(err.Error())
+		return
+	}
+
+	token, _, err := middlewares.GenerateToken(context.Background(), middlewares.NewHMACTokenKeys(signingKey), middlewares.DefaultTokenTTL, 0, "s79bb", []string{"admin"})
 	if err != nil {
 		slog.Error(err.Error())
 	}
@@ -1,12 +1,19 @@
 package config
 
 import (
+	"dice-sorensen-similarity-search/internal/constants"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/bcrypt"
 	"net/url"
 	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
@@ -50,15 +57,19 @@ func (j *JsonDuration) UnmarshalJSON(b []byte) error {
 
 type Configuration struct {
 	Logging struct {
-		MaxSize         int
-		MaxBackups      int
-		MaxAge          int
+		MaxSize    int
+		MaxBackups int
+		MaxAge     int
+		// Level, ConsoleLogLevel, and SubTypeLevels are reloaded on SIGHUP; all other fields require a restart
 		Level           zapcore.Level
 		ConsoleLogLevel zapcore.Level
-		File            string
-		HttpAccessFile  string
-		DbLogFile       string
-		LogAlerts       bool
+		// SubTypeLevels override Level and ConsoleLogLevel for the log lines of a subtype (see logging.GetLogType),
+		// e.g. {"search": "debug"}
+		SubTypeLevels  map[string]zapcore.Level
+		File           string
+		HttpAccessFile string
+		DbLogFile      string
+		LogAlerts      bool
 	}
 	ListeningPort    string
 	ListeningAddress string
@@ -71,6 +82,17 @@ type Configuration struct {
 		MaxIdleConns    int
 		MaxOpenConns    int
 		ConnMaxLifetime *JsonDuration
+		// UpsertBatchSize is the maximum number of rows per INSERT when storing the Markdown files (default: 500)
+		UpsertBatchSize int
+		// SoftDeleteRetention is how long the Markdown files deleted from the source are kept soft-deleted,
+		// and can be recovered, before they are purged (default: 168h)
+		SoftDeleteRetention *JsonDuration
+		// PurgeInterval is the interval of the job purging the soft-deleted Markdown files (default: 1h)
+		PurgeInterval *JsonDuration
+	}
+	Source struct {
+		// Provider is the source of the Markdown files: "bitbucket" (default), "github", or "none" to serve the database only
+		Provider string
 	}
 	BitBucket struct {
 		Url         *JsonUrl
@@ -79,10 +101,170 @@ type Configuration struct {
 		AccessToken string
 		ProjectName string
 		Repository  string
+		// DocsRoot is the root-level folder containing the Markdown files (default: markdowns); it applies to any Source.Provider
+		DocsRoot string
+		// Extensions are the file extensions of the Markdown files to ingest, e.g. [".md", ".mdx", ".markdown"] (default: [".md"]);
+		// they apply to any Source.Provider
+		Extensions []string
+		// Revision is the ref (e.g. refs/tags/v1.0.0) or commit hash to ingest; empty for the default branch
+		Revision string
+		// FetchConcurrency is the maximum number of files read in parallel (default: 8)
+		FetchConcurrency int
+		// StreamPageSize is the number of file paths per page when listing the repository's files (default: 150);
+		// it is clamped to Bitbucket's maximum of 1000
+		StreamPageSize int
+		// MaxFileBytes is the maximum size of a Markdown file to ingest (default: 5 MiB); larger files (e.g., generated API dumps)
+		// are skipped, so that they neither blow up the memory nor the search. It applies to any Source.Provider
+		MaxFileBytes int
+		// SyncMaxDeletionPercent is the largest drop of the number of files read versus the number of files in the database,
+		// in percent, for which a sync deletes the obsolete files (default: 50). If the drop is larger or no file was read,
+		// the deletion is skipped with a warning, so that a failed read does not wipe the index. It applies to any Source.Provider
+		SyncMaxDeletionPercent int
+		// RetryMaxAttempts is the number of attempts per Bitbucket API call, including the first one (default: 3)
+		RetryMaxAttempts int
+		// RetryBaseDelay is the delay before the first retry of a failed Bitbucket API call (default: 200ms)
+		RetryBaseDelay *JsonDuration
+		// WebhookSecret is the secret the payloads of webhooks (POST /hook) are signed with;
+		// it can be overridden by the environment variable BITBUCKET_WEBHOOK_SECRET. If empty, every webhook is rejected
+		WebhookSecret string
+	}
+	GitHub struct {
+		// Url is the base URL of the GitHub REST API (default: https://api.github.com)
+		Url *JsonUrl
+		// AccessToken is optional for public repositories
+		AccessToken string
+		// Owner is the user or organization owning the repository
+		Owner      string
+		Repository string
+		// Revision is the branch, tag, or commit hash to ingest; empty for the default branch
+		Revision string
+	}
+	Auth struct {
+		// Algorithm is the JWT signing algorithm: HS256 (default) or RS256
+		Algorithm string
+		// SigningKey is the HS256 secret JWTs are signed with; it can be overridden by the environment variable DSSS_JWT_KEY (or AUTH_SIGNING_KEY)
+		SigningKey string
+		// PrivateKeyFile is the path to the PEM encoded RSA private key used for RS256
+		PrivateKeyFile string
+		// PublicKeyFile is the path to the PEM encoded RSA public key used for RS256; if empty, it is derived from the private key
+		PublicKeyFile string
+		// TokenTTL is the lifetime of an issued token (default: 12h)
+		TokenTTL *JsonDuration
+		// RefreshTTL is the lifetime of a refreshed token (default: 12h)
+		RefreshTTL *JsonDuration
+		// LoginAttemptsPerMinute is the number of login attempts per client IP and per username; exceeding it results in 429 (default: 10)
+		LoginAttemptsPerMinute int
+		// BcryptCost is the cost of the bcrypt hashes of passwords within [4,31]; raise it as hardware improves (default: 10)
+		BcryptCost int
+	}
+	Cors struct {
+		// AllowedOrigins are the origins allowed to make cross-origin requests (default: "*", i.e. any origin without credentials);
+		// entries are exact origins or wildcard-subdomain patterns like "https://*.example.com"
+		AllowedOrigins []string
+	}
+	Navigation struct {
+		// CollapseSingleChild merges folders containing only a single folder into one navigation item (default: false)
+		CollapseSingleChild bool
+		// MaxDepth is the maximum depth of the navigation; Markdown files beyond it are dropped (default: 0, i.e. unlimited)
+		MaxDepth int
+		// LandingPageNames are the names of the top-level Markdown files excluded from the navigation, e.g. "Home" or "index"
+		// (default: ["Landing-Page"])
+		LandingPageNames []string
+	}
+	Search struct {
+		// SnippetWindow is the number of characters shown before and after a search match (default: 80)
+		SnippetWindow int
+		// MaxSnippetLength is the maximum number of characters of a search match's snippet; longer snippets are truncated
+		// with an ellipsis (default: 0, i.e. unlimited)
+		MaxSnippetLength int
+		// Engine is either "simple" (default) or "pg_trgm" which requires the Postgres extension pg_trgm
+		Engine string
+		// Backend is either "like" (default) or "fulltext" which matches word stems via Postgres' full-text search and ranks
+		// the matches by ts_rank instead of the Metric; it requires the "simple" Engine
+		Backend string
+		// Metric is the similarity metric the "simple" engine ranks by: "sorensen_dice" (default), "jaccard", or "cosine"
+		Metric string
+		// Scorer is the name of a registered scorer (e.g. "trigram-dice") the "simple" engine ranks by instead of the Metric;
+		// empty for the Metric. An unknown name fails the startup
+		Scorer string
+		// FoldAccents strips accents before extracting trigrams, so "café" ranks like "cafe" (default: false)
+		FoldAccents bool
+		// StopWords are dropped from search terms and contents before extracting trigrams (default: none)
+		StopWords []string
+		// MinCharCount excludes Markdown files with fewer characters (runes, not bytes) from search results and match counts,
+		// e.g. stubs (default: 0)
+		MinCharCount uint
+		// Analytics logs each search with its term, result count, highest similarity, and page size (default: false)
+		Analytics bool
+		// DefaultPageSize is the page size of search results if a request has none (default: 5)
+		DefaultPageSize int
+		// MaxPageSize is the largest page size of search results; larger requested page sizes are clamped (default: 100)
+		MaxPageSize int
+		// CacheTTL is the time the page of a search is cached; the cache is invalidated after every sync (default: 1m)
+		CacheTTL *JsonDuration
+		// CacheSize is the maximum number of cached search pages; a negative size disables the cache (default: 256)
+		CacheSize int
+		// TitleWeight within [0,1] boosts the "simple" engine's matches whose title (name) is similar to the search term:
+		// the score is TitleWeight*title similarity + (1-TitleWeight)*content similarity (default: 0, i.e. no boost)
+		TitleWeight float64
+		// MaxRelatedDocuments is the maximum number of related documents returned for a Markdown file (default: 5)
+		MaxRelatedDocuments int
 	}
+	Compression struct {
+		// MinSize is the minimum size in bytes of a response body to be compressed with gzip (default: 1024)
+		MinSize int
+	}
+	Tracing struct {
+		// Endpoint is the URL of the OTLP/HTTP trace collector, e.g. http://localhost:4318; if empty, no traces are exported
+		Endpoint string
+		// SamplingRatio is the share of traces sampled within (0,1] (default: 1, i.e. every trace)
+		SamplingRatio float64
+	}
+	// MaxRequestBodyBytes is the maximum size of the body of a POST request; larger ones are rejected with 413 (default: 1 MiB)
+	MaxRequestBodyBytes int64
+	// TrustedProxies are the IPs or CIDRs of the reverse proxies whose X-Forwarded-For header is trusted for the client IP,
+	// e.g., to rate limit logins per client (default: none, i.e. the client IP is the remote address)
+	TrustedProxies []string
 }
 
-var config *Configuration
+// current is the configuration in use; it is swapped atomically on a Reload
+var current atomic.Pointer[Configuration]
+
+// filePath is the path of the config file read by InitConfig
+var filePath string
+
+// SigningKeyEnvVar is the environment variable that overrides the configured JWT signing key
+const SigningKeyEnvVar = "AUTH_SIGNING_KEY"
+
+// WebhookSecretEnvVar is the environment variable that overrides the configured webhook secret
+const WebhookSecretEnvVar = "BITBUCKET_WEBHOOK_SECRET"
+
+// environment variables overriding the configured secrets, so that they need not be stored in the config file
+const (
+	DbPasswordEnvVar        = "DSSS_DB_PASSWORD"
+	BitbucketTokenEnvVar    = "DSSS_BITBUCKET_TOKEN"
+	BitbucketPasswordEnvVar = "DSSS_BITBUCKET_PASSWORD"
+	GitHubTokenEnvVar       = "DSSS_GITHUB_TOKEN"
+	JwtKeyEnvVar            = "DSSS_JWT_KEY"
+)
+
+// envOverrides maps environment variables onto the configuration values they override;
+// if several variables override the same value, the last one that is set wins
+var envOverrides = []struct {
+	name  string
+	value func(c *Configuration) *string
+}{
+	{name: DbPasswordEnvVar, value: func(c *Configuration) *string { return &c.Database.Password }},
+	{name: BitbucketTokenEnvVar, value: func(c *Configuration) *string { return &c.BitBucket.AccessToken }},
+	{name: BitbucketPasswordEnvVar, value: func(c *Configuration) *string { return &c.BitBucket.Password }},
+	{name: WebhookSecretEnvVar, value: func(c *Configuration) *string { return &c.BitBucket.WebhookSecret }},
+	{name: GitHubTokenEnvVar, value: func(c *Configuration) *string { return &c.GitHub.AccessToken }},
+	{name: SigningKeyEnvVar, value: func(c *Configuration) *string { return &c.Auth.SigningKey }},
+	{name: JwtKeyEnvVar, value: func(c *Configuration) *string { return &c.Auth.SigningKey }},
+}
+
+// knownSigningKeys were published with this repository and must therefore never be used
+var knownSigningKeys = []string{"79tesfUO0vy!U1wl7c8&EavOzmO2#W"}
 
 func InitConfig() *Configuration {
 	configFile := flag.String("config", "config.json", "Path to config file (json)")
@@ -93,13 +275,78 @@ func InitConfig() *Configuration {
 		_, _ = fmt.Fprint(os.Stderr, "\n")
 	}
 
-	file, _ := os.Open(*configFile)
+	config, err := load(*configFile)
+	if err != nil {
+		flag.Usage()
+		panic(err.Error())
+	}
+
+	filePath = *configFile
+	current.Store(config)
+
+	return config
+}
+
+// Reload re-reads the config file read by InitConfig and swaps the current configuration atomically.
+// Only the reloadable fields (the log levels and the search thresholds Search.TitleWeight, Search.MaxPageSize,
+// and Search.MinCharCount) take effect; all other fields keep their current values.
+// The names of the sections whose changes require a restart are returned.
+//
+// An invalid config file is reported as error; the current configuration is kept then.
+func Reload() (reloaded *Configuration, restartRequired []string, err error) {
+	defer func() {
+		// the defaults and validations of load panic, e.g., on an unknown search engine
+		if r := recover(); r != nil {
+			reloaded, restartRequired, err = nil, nil, fmt.Errorf("%v", r)
+		}
+	}()
+
+	config, err := load(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	previous := current.Load()
+	next := *previous
+	next.Logging.Level = config.Logging.Level
+	next.Logging.ConsoleLogLevel = config.Logging.ConsoleLogLevel
+	next.Logging.SubTypeLevels = config.Logging.SubTypeLevels
+	next.Search.TitleWeight = config.Search.TitleWeight
+	next.Search.MaxPageSize = config.Search.MaxPageSize
+	next.Search.MinCharCount = config.Search.MinCharCount
+
+	// compares the sections of the config file with the ones in use, ignoring the reloadable fields
+	got, want := reflect.ValueOf(*config), reflect.ValueOf(next)
+	for i := 0; i < got.NumField(); i++ {
+		if !reflect.DeepEqual(got.Field(i).Interface(), want.Field(i).Interface()) {
+			restartRequired = append(restartRequired, got.Type().Field(i).Name)
+		}
+	}
+
+	current.Store(&next)
+
+	return &next, restartRequired, nil
+}
+
+// load reads the config file at the given path, overrides it with the environment variables,
+// validates it, and applies the defaults
+func load(path string) (*Configuration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening config file: %w", err)
+	}
 	defer file.Close()
 
+	config := &Configuration{}
 	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&config); err != nil {
-		flag.Usage()
-		panic("Error parsing config file: " + err.Error())
+	if err := decoder.Decode(config); err != nil {
+		return nil, fmt.Errorf("error parsing config file: %w", err)
+	}
+
+	applyEnvOverrides(config)
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%w", err)
 	}
 
 	//defaults
@@ -112,34 +359,215 @@ func InitConfig() *Configuration {
 	if config.Logging.MaxAge <= 0 {
 		config.Logging.MaxAge = 28
 	}
+	if len(config.Source.Provider) == 0 {
+		config.Source.Provider = constants.SourceProviderBitbucket
+	}
+	if config.Source.Provider != constants.SourceProviderBitbucket && config.Source.Provider != constants.SourceProviderGitHub &&
+		config.Source.Provider != constants.SourceProviderNone {
+		panic(fmt.Sprintf("Unknown source provider %q; must be %q, %q, or %q",
+			config.Source.Provider, constants.SourceProviderBitbucket, constants.SourceProviderGitHub, constants.SourceProviderNone))
+	}
+	if len(config.BitBucket.DocsRoot) == 0 {
+		config.BitBucket.DocsRoot = constants.DefaultDocsRoot
+	}
+	if len(config.BitBucket.Extensions) == 0 {
+		config.BitBucket.Extensions = []string{constants.DefaultMarkdownExtension}
+	}
+	for i, extension := range config.BitBucket.Extensions {
+		extension = strings.TrimSpace(extension)
+		if len(extension) == 0 {
+			panic("Invalid markdown file extension: must not be empty")
+		}
+		if !strings.HasPrefix(extension, ".") {
+			extension = "." + extension
+		}
+		config.BitBucket.Extensions[i] = extension
+	}
+	if len(config.Auth.Algorithm) == 0 {
+		config.Auth.Algorithm = "HS256"
+	}
+	switch config.Auth.Algorithm {
+	case "HS256":
+		if err := ValidateSigningKey(config.Auth.SigningKey); err != nil {
+			panic("Invalid JWT signing key: " + err.Error())
+		}
+	case "RS256":
+		if len(config.Auth.PrivateKeyFile) == 0 {
+			panic("Invalid JWT configuration: RS256 requires Auth.PrivateKeyFile")
+		}
+	default:
+		panic(fmt.Sprintf("Unknown JWT signing algorithm %q; must be \"HS256\" or \"RS256\"", config.Auth.Algorithm))
+	}
+	if config.Auth.LoginAttemptsPerMinute == 0 {
+		config.Auth.LoginAttemptsPerMinute = 10
+	}
+	if config.Auth.BcryptCost == 0 {
+		config.Auth.BcryptCost = bcrypt.DefaultCost
+	}
+	if config.Auth.LoginAttemptsPerMinute < 0 {
+		panic(fmt.Sprintf("Invalid login attempts per minute %d; must not be negative", config.Auth.LoginAttemptsPerMinute))
+	}
+	if config.Tracing.SamplingRatio == 0 {
+		config.Tracing.SamplingRatio = 1
+	}
+	if config.Tracing.SamplingRatio < 0 || config.Tracing.SamplingRatio > 1 {
+		panic(fmt.Sprintf("Invalid tracing sampling ratio %v; must be within (0,1]", config.Tracing.SamplingRatio))
+	}
+	if config.MaxRequestBodyBytes == 0 {
+		config.MaxRequestBodyBytes = 1 << 20
+	}
+	if config.MaxRequestBodyBytes < 0 {
+		panic(fmt.Sprintf("Invalid maximum request body size %d; must not be negative", config.MaxRequestBodyBytes))
+	}
+	if config.Compression.MinSize == 0 {
+		config.Compression.MinSize = 1024
+	}
+	if config.Compression.MinSize < 0 {
+		panic(fmt.Sprintf("Invalid compression minimum size %d; must not be negative", config.Compression.MinSize))
+	}
+	if config.Database.UpsertBatchSize == 0 {
+		config.Database.UpsertBatchSize = 500
+	}
+	if config.Database.UpsertBatchSize < 0 {
+		panic(fmt.Sprintf("Invalid upsert batch size %d; must not be negative", config.Database.UpsertBatchSize))
+	}
+	if config.BitBucket.StreamPageSize == 0 {
+		config.BitBucket.StreamPageSize = 150
+	}
+	if config.BitBucket.StreamPageSize < 0 {
+		panic(fmt.Sprintf("Invalid Bitbucket stream page size %d; must not be negative", config.BitBucket.StreamPageSize))
+	}
+	if config.BitBucket.StreamPageSize > 1000 {
+		config.BitBucket.StreamPageSize = 1000
+	}
+	if config.BitBucket.MaxFileBytes == 0 {
+		config.BitBucket.MaxFileBytes = 5 << 20
+	}
+	if config.BitBucket.MaxFileBytes < 0 {
+		panic(fmt.Sprintf("Invalid maximum file size %d; must not be negative", config.BitBucket.MaxFileBytes))
+	}
+	if config.BitBucket.SyncMaxDeletionPercent == 0 {
+		config.BitBucket.SyncMaxDeletionPercent = 50
+	}
+	if config.BitBucket.SyncMaxDeletionPercent < 0 || config.BitBucket.SyncMaxDeletionPercent > 100 {
+		panic(fmt.Sprintf("Invalid maximum deletion percentage %d; must be between 1 and 100", config.BitBucket.SyncMaxDeletionPercent))
+	}
+	if len(config.Cors.AllowedOrigins) == 0 {
+		config.Cors.AllowedOrigins = []string{"*"}
+	}
+	if len(config.Search.Engine) == 0 {
+		config.Search.Engine = constants.SearchEngineSimple
+	}
+	if config.Search.Engine != constants.SearchEngineSimple && config.Search.Engine != constants.SearchEnginePgTrgm {
+		panic(fmt.Sprintf("Unknown search engine %q; must be %q or %q", config.Search.Engine, constants.SearchEngineSimple, constants.SearchEnginePgTrgm))
+	}
+	if len(config.Search.Backend) == 0 {
+		config.Search.Backend = constants.SearchBackendLike
+	}
+	if config.Search.Backend != constants.SearchBackendLike && config.Search.Backend != constants.SearchBackendFullText {
+		panic(fmt.Sprintf("Unknown search backend %q; must be %q or %q", config.Search.Backend, constants.SearchBackendLike, constants.SearchBackendFullText))
+	}
+	if config.Search.Backend == constants.SearchBackendFullText && config.Search.Engine != constants.SearchEngineSimple {
+		panic(fmt.Sprintf("The search backend %q requires the search engine %q", constants.SearchBackendFullText, constants.SearchEngineSimple))
+	}
+	if config.Search.TitleWeight < 0 || config.Search.TitleWeight > 1 {
+		panic(fmt.Sprintf("Invalid search title weight %v; must be within [0,1]", config.Search.TitleWeight))
+	}
+	if len(config.Search.Metric) == 0 {
+		config.Search.Metric = constants.SimilarityMetricSorensenDice
+	}
+	switch config.Search.Metric {
+	case constants.SimilarityMetricSorensenDice, constants.SimilarityMetricJaccard, constants.SimilarityMetricCosine:
+	default:
+		panic(fmt.Sprintf("Unknown similarity metric %q; must be %q, %q, or %q", config.Search.Metric, constants.SimilarityMetricSorensenDice, constants.SimilarityMetricJaccard, constants.SimilarityMetricCosine))
+	}
 
-	return config
+	return config, nil
+}
+
+// Validate checks that the required fields are set and returns an error listing every missing one;
+// the fields of the Bitbucket or GitHub source are only required if it is the configured Source.Provider
+func (c *Configuration) Validate() error {
+	var errs []error
+
+	if len(c.ListeningPort) == 0 {
+		errs = append(errs, errors.New("ListeningPort is required"))
+	}
+	if len(c.Database.Host) == 0 {
+		errs = append(errs, errors.New("Database.Host is required"))
+	}
+	if len(c.Database.DatabaseName) == 0 {
+		errs = append(errs, errors.New("Database.DatabaseName is required"))
+	}
+
+	switch c.Source.Provider {
+	case "", constants.SourceProviderBitbucket:
+		if c.BitBucket.Url == nil || c.BitBucket.Url.URL == nil || len(c.BitBucket.Url.Host) == 0 {
+			errs = append(errs, fmt.Errorf("BitBucket.Url is required for the source provider %q and must be an absolute URL", constants.SourceProviderBitbucket))
+		}
+		if len(c.BitBucket.ProjectName) == 0 || len(c.BitBucket.Repository) == 0 {
+			errs = append(errs, fmt.Errorf("BitBucket.ProjectName and BitBucket.Repository are required for the source provider %q", constants.SourceProviderBitbucket))
+		}
+	case constants.SourceProviderGitHub:
+		if len(c.GitHub.Owner) == 0 || len(c.GitHub.Repository) == 0 {
+			errs = append(errs, fmt.Errorf("GitHub.Owner and GitHub.Repository are required for the source provider %q", constants.SourceProviderGitHub))
+		}
+	}
+
+	// 0 selects the default cost
+	if c.Auth.BcryptCost != 0 && (c.Auth.BcryptCost < bcrypt.MinCost || c.Auth.BcryptCost > bcrypt.MaxCost) {
+		errs = append(errs, fmt.Errorf("Auth.BcryptCost must be within [%d,%d], got %d", bcrypt.MinCost, bcrypt.MaxCost, c.Auth.BcryptCost))
+	}
+
+	return errors.Join(errs...)
+}
+
+// applyEnvOverrides overwrites the configuration values with the environment variables of envOverrides that are set (and not empty)
+func applyEnvOverrides(c *Configuration) {
+	for _, override := range envOverrides {
+		if value := os.Getenv(override.name); len(value) > 0 {
+			*override.value(c) = value
+		}
+	}
+}
+
+// ValidateSigningKey returns an error if the JWT signing key is empty or a publicly known default
+func ValidateSigningKey(signingKey string) error {
+	if len(signingKey) == 0 {
+		return fmt.Errorf("no signing key configured; set Auth.SigningKey or %s", SigningKeyEnvVar)
+	}
+
+	if slices.Contains(knownSigningKeys, signingKey) {
+		return fmt.Errorf("the signing key is a publicly known default; configure a secret one")
+	}
+
+	return nil
 }
 
 func Config() *Configuration {
-	return config
+	return current.Load()
 }
 
 func Port() string {
-	return config.ListeningPort
+	return Config().ListeningPort
 }
 
 func Address() string {
-	return config.ListeningAddress
+	return Config().ListeningAddress
 }
 
 func DbHost() string {
-	return config.Database.Host
+	return Config().Database.Host
 }
 
 func DbName() string {
-	return config.Database.DatabaseName
+	return Config().Database.DatabaseName
 }
 
 func DbUser() string {
-	return config.Database.Username
+	return Config().Database.Username
 }
 
 func DbPassword() string {
-	return config.Database.Password
+	return Config().Database.Password
 }
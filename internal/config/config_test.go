@@ -0,0 +1,281 @@
+package config_test
+
+import (
+	"dice-sorensen-similarity-search/internal/config"
+	"encoding/json"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const configFile = `{
+	"Database": {"Host": "localhost", "Password": "file-db-password"},
+	"BitBucket": {"AccessToken": "file-bitbucket-token", "WebhookSecret": "file-webhook-secret"},
+	"GitHub": {"AccessToken": "file-github-token"},
+	"Auth": {"SigningKey": "file-signing-key"}
+}`
+
+func decodeConfig(t *testing.T) *config.Configuration {
+	t.Helper()
+
+	var c config.Configuration
+	if err := json.Unmarshal([]byte(configFile), &c); err != nil {
+		t.Fatalf("error decoding config: %v", err)
+	}
+
+	return &c
+}
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Setenv(config.DbPasswordEnvVar, "env-db-password")
+	t.Setenv(config.BitbucketTokenEnvVar, "env-bitbucket-token")
+	t.Setenv(config.GitHubTokenEnvVar, "env-github-token")
+	t.Setenv(config.JwtKeyEnvVar, "env-signing-key")
+
+	c := decodeConfig(t)
+	config.ApplyEnvOverrides(c)
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{name: "dbPassword", got: c.Database.Password, want: "env-db-password"},
+		{name: "bitbucketToken", got: c.BitBucket.AccessToken, want: "env-bitbucket-token"},
+		{name: "githubToken", got: c.GitHub.AccessToken, want: "env-github-token"},
+		{name: "signingKey", got: c.Auth.SigningKey, want: "env-signing-key"},
+		{name: "webhookSecretNotOverridden", got: c.BitBucket.WebhookSecret, want: "file-webhook-secret"},
+		{name: "dbHostNotOverridable", got: c.Database.Host, want: "localhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, tt.got)
+			}
+		})
+	}
+}
+
+func TestApplyEnvOverrides_emptyVariableKeepsFileValue(t *testing.T) {
+	t.Setenv(config.DbPasswordEnvVar, "")
+
+	c := decodeConfig(t)
+	config.ApplyEnvOverrides(c)
+
+	if c.Database.Password != "file-db-password" {
+		t.Errorf("want the file value to be kept, got %q", c.Database.Password)
+	}
+}
+
+func TestApplyEnvOverrides_precedenceOfSigningKeyVariables(t *testing.T) {
+	t.Setenv(config.SigningKeyEnvVar, "legacy-signing-key")
+	t.Setenv(config.JwtKeyEnvVar, "env-signing-key")
+
+	c := decodeConfig(t)
+	config.ApplyEnvOverrides(c)
+
+	if c.Auth.SigningKey != "env-signing-key" {
+		t.Errorf("want %s to take precedence over %s, got %q", config.JwtKeyEnvVar, config.SigningKeyEnvVar, c.Auth.SigningKey)
+	}
+}
+
+const validConfigFile = `{
+	"ListeningPort": "8080",
+	"Database": {"Host": "localhost", "DatabaseName": "docs"},
+	"BitBucket": {"Url": "https://bitbucket.example.com/rest", "ProjectName": "DOCS", "Repository": "handbook"}
+}`
+
+func TestConfiguration_Validate(t *testing.T) {
+	var c config.Configuration
+	if err := json.Unmarshal([]byte(validConfigFile), &c); err != nil {
+		t.Fatalf("error decoding config: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Errorf("want a valid configuration, got %v", err)
+	}
+}
+
+func TestConfiguration_Validate_noSource(t *testing.T) {
+	var c config.Configuration
+	if err := json.Unmarshal([]byte(`{"ListeningPort": "8080", "Database": {"Host": "localhost", "DatabaseName": "docs"}, "Source": {"Provider": "none"}}`), &c); err != nil {
+		t.Fatalf("error decoding config: %v", err)
+	}
+
+	// neither the Bitbucket nor the GitHub fields are required
+	if err := c.Validate(); err != nil {
+		t.Errorf("want a valid configuration, got %v", err)
+	}
+}
+
+func TestConfiguration_Validate_invalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		configFile string
+		wantErrors []string
+	}{
+		{
+			name:       "empty",
+			configFile: `{}`,
+			wantErrors: []string{"ListeningPort is required", "Database.Host is required", "Database.DatabaseName is required", "BitBucket.Url is required", "BitBucket.ProjectName and BitBucket.Repository are required"},
+		},
+		{
+			name:       "relativeBitbucketUrl",
+			configFile: `{"ListeningPort": "8080", "Database": {"Host": "localhost", "DatabaseName": "docs"}, "BitBucket": {"Url": "bitbucket/rest", "ProjectName": "DOCS", "Repository": "handbook"}}`,
+			wantErrors: []string{"BitBucket.Url is required"},
+		},
+		{
+			name:       "missingGitHubRepository",
+			configFile: `{"ListeningPort": "8080", "Database": {"Host": "localhost", "DatabaseName": "docs"}, "Source": {"Provider": "github"}, "GitHub": {"Owner": "octo"}}`,
+			wantErrors: []string{"GitHub.Owner and GitHub.Repository are required"},
+		},
+		{
+			name:       "missingDatabaseHost",
+			configFile: `{"ListeningPort": "8080", "Database": {"DatabaseName": "docs"}, "Source": {"Provider": "github"}, "GitHub": {"Owner": "octo", "Repository": "docs"}}`,
+			wantErrors: []string{"Database.Host is required"},
+		},
+		{
+			name:       "bcryptCostOutOfRange",
+			configFile: `{"ListeningPort": "8080", "Database": {"Host": "localhost", "DatabaseName": "docs"}, "Source": {"Provider": "none"}, "Auth": {"BcryptCost": 32}}`,
+			wantErrors: []string{"Auth.BcryptCost must be within [4,31]"},
+		},
+		{
+			name:       "bcryptCostBelowMinimum",
+			configFile: `{"ListeningPort": "8080", "Database": {"Host": "localhost", "DatabaseName": "docs"}, "Source": {"Provider": "none"}, "Auth": {"BcryptCost": 3}}`,
+			wantErrors: []string{"Auth.BcryptCost must be within [4,31]"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var c config.Configuration
+			if err := json.Unmarshal([]byte(tt.configFile), &c); err != nil {
+				t.Fatalf("error decoding config: %v", err)
+			}
+
+			err := c.Validate()
+			if err == nil {
+				t.Fatal("want an error for an invalid configuration")
+			}
+
+			gotErrors := strings.Split(err.Error(), "\n")
+			if len(gotErrors) != len(tt.wantErrors) {
+				t.Errorf("want %d errors, got %d: %v", len(tt.wantErrors), len(gotErrors), err)
+				return
+			}
+
+			for i, want := range tt.wantErrors {
+				if !strings.HasPrefix(gotErrors[i], want) {
+					t.Errorf("want error %d to start with %q, got %q", i, want, gotErrors[i])
+				}
+			}
+		})
+	}
+}
+
+func writeConfigFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("error writing config file: %v", err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, path, `{
+		"Logging": {"Level": "info"},
+		"ListeningPort": "8080",
+		"Database": {"Host": "localhost", "DatabaseName": "docs"},
+		"BitBucket": {"Url": "https://bitbucket.example.com/rest", "ProjectName": "DOCS", "Repository": "handbook"},
+		"Auth": {"SigningKey": "signing-key"}
+	}`)
+
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+	config.Use(path, c)
+
+	writeConfigFile(t, path, `{
+		"Logging": {"Level": "debug"},
+		"ListeningPort": "8080",
+		"Database": {"Host": "db.example.com", "DatabaseName": "docs"},
+		"BitBucket": {"Url": "https://bitbucket.example.com/rest", "ProjectName": "DOCS", "Repository": "handbook"},
+		"Auth": {"SigningKey": "signing-key"},
+		"Search": {"TitleWeight": 0.5, "MaxPageSize": 20, "MinCharCount": 10}
+	}`)
+
+	reloaded, restartRequired, err := config.Reload()
+	if err != nil {
+		t.Fatalf("Reload error: %v", err)
+	}
+
+	if reloaded != config.Config() {
+		t.Error("want the reloaded config to be the current one")
+		return
+	}
+
+	if reloaded.Logging.Level != zapcore.DebugLevel {
+		t.Errorf("want the log level %s, got %s", zapcore.DebugLevel, reloaded.Logging.Level)
+		return
+	}
+
+	if reloaded.Search.TitleWeight != 0.5 || reloaded.Search.MaxPageSize != 20 || reloaded.Search.MinCharCount != 10 {
+		t.Errorf("want the reloaded search thresholds, got title weight %v, max page size %d, and min char count %d",
+			reloaded.Search.TitleWeight, reloaded.Search.MaxPageSize, reloaded.Search.MinCharCount)
+		return
+	}
+
+	if reloaded.Database.Host != "localhost" {
+		t.Errorf("want the database host to require a restart, got %q", reloaded.Database.Host)
+		return
+	}
+
+	if want := []string{"Database"}; !cmp.Equal(want, restartRequired) {
+		t.Error(cmp.Diff(want, restartRequired))
+		return
+	}
+}
+
+func TestReload_invalidConfigKeepsCurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	writeConfigFile(t, path, `{
+		"Logging": {"Level": "info"},
+		"ListeningPort": "8080",
+		"Database": {"Host": "localhost", "DatabaseName": "docs"},
+		"BitBucket": {"Url": "https://bitbucket.example.com/rest", "ProjectName": "DOCS", "Repository": "handbook"},
+		"Auth": {"SigningKey": "signing-key"}
+	}`)
+
+	c, err := config.Load(path)
+	if err != nil {
+		t.Fatalf("error loading config: %v", err)
+	}
+	config.Use(path, c)
+
+	for name, content := range map[string]string{
+		"invalidJson":        `{"Logging": `,
+		"unknownEngine":      strings.Replace(validConfigFile, `"ListeningPort"`, `"Auth": {"SigningKey": "signing-key"}, "Search": {"Engine": "lucene"}, "ListeningPort"`, 1),
+		"unknownBackend":     strings.Replace(validConfigFile, `"ListeningPort"`, `"Auth": {"SigningKey": "signing-key"}, "Search": {"Backend": "solr"}, "ListeningPort"`, 1),
+		"fullTextWithPgTrgm": strings.Replace(validConfigFile, `"ListeningPort"`, `"Auth": {"SigningKey": "signing-key"}, "Search": {"Engine": "pg_trgm", "Backend": "fulltext"}, "ListeningPort"`, 1),
+	} {
+		t.Run(name, func(t *testing.T) {
+			writeConfigFile(t, path, content)
+
+			if _, _, err := config.Reload(); err == nil {
+				t.Error("want an error for an invalid config file")
+				return
+			}
+
+			if config.Config() != c {
+				t.Error("want the current config to be kept")
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package config
+
+// exports unexported identifiers for the tests of the package config_test
+var ApplyEnvOverrides = applyEnvOverrides
+var Load = load
+
+// Use makes the configuration read from the config file at the given path the current one, like InitConfig
+func Use(path string, c *Configuration) {
+	filePath = path
+	current.Store(c)
+}
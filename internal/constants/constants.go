@@ -4,4 +4,48 @@ const (
 	Bitbucket = iota
 	MarkdownDoc
 	Auth
+	Readiness
+	Metrics
+)
+
+// DefaultDocsRoot is the name of the repository's root-level folder containing the Markdown files
+// if no other folder name is configured
+const DefaultDocsRoot = "markdowns"
+
+// DefaultMarkdownExtension is the extension of the Markdown files if no other extensions are configured
+const DefaultMarkdownExtension = ".md"
+
+// sources of the Markdown files selectable via config
+const (
+	SourceProviderBitbucket = "bitbucket"
+	SourceProviderGitHub    = "github"
+	// SourceProviderNone serves the Markdown files stored in the database without syncing any (e.g., for local development)
+	SourceProviderNone = "none"
+)
+
+// search engines selectable via config
+const (
+	// SearchEngineSimple matches contents via LIKE and ranks them in Go; it works with any Postgres database
+	SearchEngineSimple = "simple"
+	// SearchEnginePgTrgm ranks contents in SQL and requires the pg_trgm extension
+	SearchEnginePgTrgm = "pg_trgm"
+)
+
+// search backends selectable via config for matching contents
+const (
+	// SearchBackendLike matches the contents containing the search term literally via LIKE
+	SearchBackendLike = "like"
+	// SearchBackendFullText matches the contents containing the search term's words or their stems via Postgres' full-text search
+	// and ranks them by ts_rank in SQL
+	SearchBackendFullText = "fulltext"
+)
+
+// similarity metrics selectable via config for ranking search matches in Go
+const (
+	// SimilarityMetricSorensenDice ranks by the Sorensen-Dice coefficient of the trigrams
+	SimilarityMetricSorensenDice = "sorensen_dice"
+	// SimilarityMetricJaccard ranks by the Jaccard index of the trigrams
+	SimilarityMetricJaccard = "jaccard"
+	// SimilarityMetricCosine ranks by the cosine similarity of the trigrams
+	SimilarityMetricCosine = "cosine"
 )
@@ -1,11 +1,17 @@
 package controllers
 
 import (
+	"context"
 	"dice-sorensen-similarity-search/internal/api"
+	"dice-sorensen-similarity-search/internal/environment"
 	"github.com/gin-gonic/gin"
 	"net/http"
+	"time"
 )
 
+// readinessTimeout bounds the database ping of a readiness check
+const readinessTimeout = 2 * time.Second
+
 func GetHeartBeat(c *gin.Context) {
 	c.AbortWithStatus(http.StatusOK)
 }
@@ -13,3 +19,30 @@ func GetHeartBeat(c *gin.Context) {
 func GetStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "running", nil))
 }
+
+// ReadinessController reports whether the instance can serve requests, i.e. whether its database is reachable.
+// As opposed to GetHeartBeat, which is a pure liveness check.
+type ReadinessController struct {
+	*environment.Env
+}
+
+// GetReadiness pings the database and responds with 503 if it is unreachable
+//
+// @ID getReadiness
+// @Summary Check readiness
+// @Tags status
+// @Router /readiness [get]
+// @Success		200	{object}	api.RestJsonResponse{data=string}
+// @Failure 503 {object} api.RestJsonResponse{data=string}
+func (rc *ReadinessController) GetReadiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := rc.Ping(ctx); err != nil {
+		rc.LogErrorf(nil, "readiness check failed: %v", err)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, api.NewErrorResponse("database unreachable"))
+		return
+	}
+
+	c.JSON(http.StatusOK, api.NewGenericResponse(api.Success, "ready", nil))
+}
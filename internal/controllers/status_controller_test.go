@@ -0,0 +1,60 @@
+package controllers_test
+
+import (
+	"context"
+	"dice-sorensen-similarity-search/internal/controllers"
+	"dice-sorensen-similarity-search/internal/database"
+	"dice-sorensen-similarity-search/internal/environment"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// mockRepository fails the ping with pingErr (if set); all other methods are no-ops
+type mockRepository struct {
+	database.NullRepository
+	pingErr error
+}
+
+func (m *mockRepository) Ping(_ context.Context) error {
+	return m.pingErr
+}
+
+func TestGetReadiness(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		pingErr    error
+		wantStatus int
+	}{
+		{name: "databaseReachable", pingErr: nil, wantStatus: http.StatusOK},
+		{name: "databaseUnreachable", pingErr: errors.New("connection refused"), wantStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := &controllers.ReadinessController{Env: environment.Environment(&mockRepository{pingErr: tt.pingErr}, nil)}
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/readiness", nil)
+
+			ctrl.GetReadiness(c)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, w.Code)
+				return
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Errorf("want a JSON body, got %q: %v", w.Body.String(), err)
+				return
+			}
+		})
+	}
+}
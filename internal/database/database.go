@@ -2,6 +2,7 @@ package database
 
 import (
 	"dice-sorensen-similarity-search/internal/config"
+	"dice-sorensen-similarity-search/internal/constants"
 	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/models"
 	"fmt"
@@ -48,6 +49,14 @@ func InitDatabase(c *config.Configuration, l logging.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	// Name used to be unique on its own; AutoMigrate creates the composite unique index on (path, name),
+	// but does not reliably drop the former constraint, whose name depends on the GORM version that created the table
+	err = db.Exec("ALTER TABLE IF EXISTS markdown_meta DROP CONSTRAINT IF EXISTS markdown_meta_name_key, DROP CONSTRAINT IF EXISTS uni_markdown_meta_name").Error
+	if err != nil {
+		l.LogErrorf(nil, "error dropping the unique constraint on markdown_meta.name: %v", err)
+		return nil, err
+	}
+
 	err = db.AutoMigrate(&models.MarkdownMeta{})
 	if err != nil {
 		l.LogErrorf(nil, "error auto migrating models.MarkdownContent: %v", err)
@@ -60,5 +69,35 @@ func InitDatabase(c *config.Configuration, l logging.Logger) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if c.Search.Engine == constants.SearchEnginePgTrgm {
+		err = db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error
+		if err != nil {
+			l.LogErrorf(nil, "error creating extension pg_trgm: %v", err)
+			return nil, err
+		}
+
+		// the GIN index speeds up both the LIKE filter and similarity()
+		err = db.Exec("CREATE INDEX IF NOT EXISTS idx_markdown_contents_content_trgm ON markdown_contents USING GIN (content gin_trgm_ops)").Error
+		if err != nil {
+			l.LogErrorf(nil, "error creating trigram index on markdown_contents: %v", err)
+			return nil, err
+		}
+	}
+
+	if c.Search.Backend == constants.SearchBackendFullText {
+		// the generated column keeps the tsvector in sync with the content; AutoMigrate leaves it alone since no field maps to it
+		err = db.Exec("ALTER TABLE markdown_contents ADD COLUMN IF NOT EXISTS content_tsv tsvector GENERATED ALWAYS AS (to_tsvector('" + FullTextSearchConfig + "', content)) STORED").Error
+		if err != nil {
+			l.LogErrorf(nil, "error adding the tsvector column to markdown_contents: %v", err)
+			return nil, err
+		}
+
+		err = db.Exec("CREATE INDEX IF NOT EXISTS idx_markdown_contents_content_tsv ON markdown_contents USING GIN (content_tsv)").Error
+		if err != nil {
+			l.LogErrorf(nil, "error creating full-text index on markdown_contents: %v", err)
+			return nil, err
+		}
+	}
+
 	return db, nil
 }
@@ -2,24 +2,47 @@ package database
 
 import (
 	"context"
+	"dice-sorensen-similarity-search/internal/constants"
 	"dice-sorensen-similarity-search/internal/models"
+	"errors"
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"math"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 )
 
+// uniqueViolation is the Postgres error code raised if a unique constraint is violated
+const uniqueViolation = "23505"
+
+// ErrDuplicateUsername is returned by CreateUser if a user with the same username already exists
+var ErrDuplicateUsername = errors.New("username already exists")
+
+// ErrMarkdownNotFound is returned by FindMarkdownContentByName and FindMarkdownContentByPath if there is no such Markdown file
+var ErrMarkdownNotFound = errors.New("markdown not found")
+
 // Repository defines data access methods for interacting with Markdown-related
 // database records, including Markdown metadata, content, and user login credentials.
 //
 // @Summary Interface for Markdown data storage operations
 type Repository interface {
 
-	// DeleteMarkdownMetasByIds deletes Markdown meta records with the given IDs.
+	// Ping checks that the database is reachable by running a trivial query
+	Ping(ctx context.Context) error
+
+	// WithTransaction runs fn atomically: the calls made with the context passed to fn are committed together
+	// if fn returns nil and rolled back otherwise.
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+
+	// DeleteMarkdownMetasByIds soft-deletes Markdown meta records with the given IDs; see PurgeSoftDeletedMarkdowns.
 	//
 	// Param metaIds body []uint true "List of Markdown meta IDs to delete"
 	DeleteMarkdownMetasByIds(ctx context.Context, metaIds []uint) error
 
-	// DeleteMarkdownContentsByIds deletes markdown content records with the given IDs.
+	// DeleteMarkdownContentsByIds soft-deletes markdown content records with the given IDs; see PurgeSoftDeletedMarkdowns.
 	//
 	// Param contentIds body []uint true "List of Markdown content IDs to delete"
 	DeleteMarkdownContentsByIds(ctx context.Context, contentIds []uint) error
@@ -29,41 +52,162 @@ type Repository interface {
 	// Param username path string true "Username"
 	FindUserLoginCredentials(ctx context.Context, username string, user *models.User) error
 
+	// CreateUser inserts the given user; returns ErrDuplicateUsername if the username is already taken.
+	//
+	// Param user body models.User true "The user with an already hashed password"
+	CreateUser(ctx context.Context, user *models.User) error
+
 	// FindAllMarkdownMetas retrieves all Markdown metadata records from the database.
 	FindAllMarkdownMetas(ctx context.Context, markdownMetas *[]models.MarkdownMeta) error
 
-	// FindMarkdownMetasWhereCharCountGreaterThan retrieves all Markdown metadata records from the database.
+	// FindMarkdownContentHashes retrieves the content hash of every Markdown file stored in the database.
+	FindMarkdownContentHashes(ctx context.Context, contentHashes *[]models.MarkdownContentHash) error
+
+	// FindMarkdownMetasWhereCharCountGreaterThan retrieves the Markdown metadata records with more than x characters.
+	// The characters are counted as runes (not bytes); hence, a file with multibyte characters counts each of them once.
 	FindMarkdownMetasWhereCharCountGreaterThan(ctx context.Context, x int, markdownMetas *[]models.MarkdownMeta) error
 
 	// FindMarkdownContentByName fetches Markdown content by file name.
+	// If files with the same name reside in different folders, any of them is returned; see FindMarkdownContentByPath.
+	// It returns ErrMarkdownNotFound if there is no such Markdown file.
 	//
 	// Param name path string true "Markdown file name"
 	FindMarkdownContentByName(ctx context.Context, name string, markdownContents *models.MarkdownContent) error
 
+	// FindMarkdownContentByPath fetches Markdown content by the path of its folder and its file name;
+	// it returns ErrMarkdownNotFound if there is no such Markdown file.
+	//
+	// Param path path string true "Markdown folder path (e.g. markdowns/Gateway)"
+	// Param name path string true "Markdown file name"
+	FindMarkdownContentByPath(ctx context.Context, path, name string, markdownContent *models.MarkdownContent) error
+
 	// FindMarkdownContentIdsByMetaIds fetches content IDs by related Markdown meta IDs.
 	//
 	// Param metaIds body []uint true "Meta IDs to search"
 	FindMarkdownContentIdsByMetaIds(ctx context.Context, markdownMetaIds []uint, markdownContentIds *[]uint) error
 
-	FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm string, markdowns *[]models.MarkdownContent) error
+	// FindVisibleMarkdowns fetches the contents of all Markdown files that can be found by a search,
+	// i.e., excluding the hidden ones and the ones with fewer characters than the minimum.
+	FindVisibleMarkdowns(ctx context.Context, markdowns *[]models.MarkdownContent) error
+
+	// FindMarkdownsBySearchTermSimple fetches the Markdown contents containing the search term.
+	//
+	// Param searchTerm body string true "The term to search for"
+	// Param pathPrefix body string false "Restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)"
+	FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.MarkdownContent) error
+
+	// FindMarkdownsBySearchTermWithOptions fetches the Markdown contents containing the search term
+	// the way the options specify (case sensitivity, whole words).
+	//
+	// Param searchTerm body string true "The term to search for"
+	// Param options body SearchOptions true "How the term is matched"
+	FindMarkdownsBySearchTermWithOptions(ctx context.Context, searchTerm string, options SearchOptions, markdowns *[]models.MarkdownContent) error
+
+	// FindMarkdownsBySearchTerms fetches the Markdown contents containing all (matchAll) or any of the search terms.
+	//
+	// Param searchTerms body []string true "The terms to search for; if empty, nothing is fetched"
+	// Param matchAll body bool true "Whether all terms (AND) or any term (OR) must be contained"
+	// Param pathPrefix body string false "Restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)"
+	FindMarkdownsBySearchTerms(ctx context.Context, searchTerms []string, matchAll bool, pathPrefix string, markdowns *[]models.MarkdownContent) error
+
+	// FindMarkdownsBySearchTermRanked fetches the Markdown contents containing the search term,
+	// scored by their similarity to it and ordered by descending similarity.
+	//
+	// Param searchTerm body string true "The term to search for"
+	// Param pathPrefix body string false "Restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)"
+	FindMarkdownsBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error
 
-	CountMarkdownsMatchesBySearchTermSimple(ctx context.Context, searchTerm string, matchCount *int) error
+	// FindMarkdownsByFullText fetches the Markdown contents containing the search term's words or their stems
+	// (see FullTextSearchConfig), scored by their full-text rank within [0,1) and ordered by descending rank.
+	//
+	// Param searchTerm body string true "The words to search for"
+	// Param pathPrefix body string false "Restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)"
+	FindMarkdownsByFullText(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error
 
-	// UpsertMarkdownMetas inserts or updates Markdown meta records.
+	// FindMarkdownsBySearchTermPaged fetches one page of the Markdown contents containing the search term
+	// and scoring at least the minimum similarity, ordered by descending similarity.
+	//
+	// Param searchTerm body string true "The term to search for"
+	// Param pathPrefix body string false "Restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)"
+	// Param minSimilarity body float64 false "The minimum similarity of a match"
+	// Param pageNumber body int true "The 1-based page number; values less than 1 are treated as 1"
+	// Param pageSize body int true "The page size; values less than 1 are treated as 1"
+	FindMarkdownsBySearchTermPaged(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, pageNumber, pageSize int, markdowns *[]models.ScoredMarkdownContent) error
+
+	// CountMarkdownsMatchesBySearchTermRanked counts the Markdown contents containing the search term
+	// and scoring at least the minimum similarity (within the folder subtree starting with the path prefix, if any).
+	CountMarkdownsMatchesBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, matchCount *int) error
+
+	// FindMarkdownDocumentsPaged fetches one page of the stored Markdown files without their contents,
+	// ordered the way the options specify.
+	//
+	// Param options body DocumentListOptions true "How the files are ordered and whether hidden ones are included"
+	// Param pageNumber body int true "The 1-based page number; values less than 1 are treated as 1"
+	// Param pageSize body int true "The page size; values less than 1 are treated as 1"
+	FindMarkdownDocumentsPaged(ctx context.Context, options DocumentListOptions, pageNumber, pageSize int, documents *[]models.MarkdownDocument) error
+
+	// CountMarkdownDocuments counts the stored Markdown files, excluding the hidden ones unless includeHidden is set.
+	CountMarkdownDocuments(ctx context.Context, includeHidden bool, documentCount *int) error
+
+	// CountAllMarkdowns aggregates the stats of all stored Markdown files (see models.MarkdownStats) in a single query;
+	// unlike the searches, it includes hidden files and files with fewer characters than the minimum.
+	CountAllMarkdowns(ctx context.Context, stats *models.MarkdownStats) error
+
+	// PurgeSoftDeletedMarkdowns permanently deletes the Markdown meta and content records soft-deleted before deletedBefore
+	// and returns the number of purged meta records.
+	//
+	// Param deletedBefore body time.Time true "The records soft-deleted earlier are purged"
+	PurgeSoftDeletedMarkdowns(ctx context.Context, deletedBefore time.Time, purgedMetas *int64) error
+
+	// UpsertMarkdownMetas inserts or updates Markdown meta records; soft-deleted records are restored.
 	//
 	// Param markdownMetas body []models.MarkdownMeta true "Markdown meta data"
 	UpsertMarkdownMetas(ctx context.Context, markdownMetas []models.MarkdownMeta) error
 
-	// UpsertMarkdownContents inserts or updates Markdown content records.
+	// UpsertMarkdownContents inserts or updates Markdown content records; soft-deleted records are restored.
 	//
 	// Param markdownContents body []models.MarkdownContent true "Markdown content data"
 	UpsertMarkdownContents(ctx context.Context, markdownContents []models.MarkdownContent) error
 }
 
+// SearchOptions specify how a search term is matched against the Markdown contents
+type SearchOptions struct {
+	// CaseSensitive matches the term case-sensitively; otherwise, the case is ignored
+	CaseSensitive bool
+	// WholeWord only matches the term if it starts and ends at word boundaries, e.g., "cat" does not match "category"
+	WholeWord bool
+	// PathPrefix restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)
+	PathPrefix string
+}
+
+// properties the Markdown files can be listed by (see DocumentListOptions)
+const (
+	DocumentSortName      = "name"
+	DocumentSortUpdatedAt = "updatedAt"
+)
+
+// DocumentListOptions specify how the stored Markdown files are listed
+type DocumentListOptions struct {
+	// SortBy is DocumentSortName (default) or DocumentSortUpdatedAt; files equal with respect to it are ordered by path and name
+	SortBy string
+	// Descending reverses the order of SortBy
+	Descending bool
+	// IncludeHidden includes the hidden files (see models.MarkdownMeta.IsHidden); they are excluded by default
+	IncludeHidden bool
+}
+
 // NullRepository is a no-op implementation of the Repository interface.
 // Useful for testing or default wiring when no database operations are required.
 type NullRepository struct{}
 
+func (n *NullRepository) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (n *NullRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (n *NullRepository) DeleteMarkdownMetasByIds(ctx context.Context, metaIds []uint) error {
 	return nil
 }
@@ -76,6 +220,10 @@ func (n *NullRepository) FindUserLoginCredentials(ctx context.Context, username
 	return nil
 }
 
+func (n *NullRepository) CreateUser(ctx context.Context, user *models.User) error {
+	return nil
+}
+
 func (n *NullRepository) FindAllMarkdownMetas(ctx context.Context, markdownMetas *[]models.MarkdownMeta) error {
 	return nil
 }
@@ -88,15 +236,59 @@ func (n *NullRepository) FindMarkdownContentByName(ctx context.Context, name str
 	return nil
 }
 
+func (n *NullRepository) FindMarkdownContentByPath(ctx context.Context, path, name string, markdownContent *models.MarkdownContent) error {
+	return nil
+}
+
 func (n *NullRepository) FindMarkdownContentIdsByMetaIds(ctx context.Context, markdownMetaIds []uint, markdownContentIds *[]uint) error {
 	return nil
 }
 
-func (n *NullRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm string, markdowns *[]models.MarkdownContent) error {
+func (n *NullRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.MarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindMarkdownsBySearchTermWithOptions(ctx context.Context, searchTerm string, options SearchOptions, markdowns *[]models.MarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindMarkdownsBySearchTerms(ctx context.Context, searchTerms []string, matchAll bool, pathPrefix string, markdowns *[]models.MarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindMarkdownsBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindMarkdownsByFullText(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindMarkdownsBySearchTermPaged(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, pageNumber, pageSize int, markdowns *[]models.ScoredMarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindVisibleMarkdowns(ctx context.Context, markdowns *[]models.MarkdownContent) error {
+	return nil
+}
+
+func (n *NullRepository) FindMarkdownDocumentsPaged(ctx context.Context, options DocumentListOptions, pageNumber, pageSize int, documents *[]models.MarkdownDocument) error {
+	return nil
+}
+
+func (n *NullRepository) CountMarkdownDocuments(ctx context.Context, includeHidden bool, documentCount *int) error {
 	return nil
 }
 
-func (n *NullRepository) CountMarkdownsMatchesBySearchTermSimple(ctx context.Context, searchTerm string, matchCount *int) error {
+func (n *NullRepository) CountMarkdownsMatchesBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, matchCount *int) error {
+	return nil
+}
+
+func (n *NullRepository) CountAllMarkdowns(ctx context.Context, stats *models.MarkdownStats) error {
+	return nil
+}
+
+func (n *NullRepository) PurgeSoftDeletedMarkdowns(ctx context.Context, deletedBefore time.Time, purgedMetas *int64) error {
 	return nil
 }
 
@@ -104,6 +296,10 @@ func (n *NullRepository) UpsertMarkdownMetas(ctx context.Context, markdownMetas
 	return nil
 }
 
+func (n *NullRepository) FindMarkdownContentHashes(ctx context.Context, contentHashes *[]models.MarkdownContentHash) error {
+	return nil
+}
+
 func (n *NullRepository) UpsertMarkdownContents(ctx context.Context, markdownContents []models.MarkdownContent) error {
 	return nil
 }
@@ -114,102 +310,287 @@ var _ Repository = &NullRepository{}
 // GormRepository provides a GORM-based implementation of the Repository interface.
 type GormRepository struct {
 	*gorm.DB
+
+	// DocsRoot is the root-level folder containing the Markdown files (default: markdowns)
+	DocsRoot string
+	// MinSearchCharCount excludes Markdown files with fewer characters (runes, see models.MarkdownMeta) from search results
+	// and match counts, e.g. stubs (default: 0)
+	MinSearchCharCount uint
+	// UpsertBatchSize is the maximum number of rows per INSERT of an upsert (default: DefaultUpsertBatchSize)
+	UpsertBatchSize int
+
+	// reloadedMinSearchCharCount overrides MinSearchCharCount once set (see SetMinSearchCharCount)
+	reloadedMinSearchCharCount atomic.Pointer[uint]
+}
+
+// DefaultUpsertBatchSize is the maximum number of rows per INSERT if no (positive) UpsertBatchSize is configured;
+// it keeps the number of placeholders well below the limit of Postgres (65535)
+const DefaultUpsertBatchSize = 500
+
+func (g *GormRepository) upsertBatchSize() int {
+	if g.UpsertBatchSize <= 0 {
+		return DefaultUpsertBatchSize
+	}
+
+	return g.UpsertBatchSize
+}
+
+// SetMinSearchCharCount replaces the MinSearchCharCount of a repository in use, e.g., on a config reload;
+// it is safe to call while searches are running
+func (g *GormRepository) SetMinSearchCharCount(minSearchCharCount uint) {
+	g.reloadedMinSearchCharCount.Store(&minSearchCharCount)
+}
+
+// minSearchCharCount returns the MinSearchCharCount, unless it was replaced by SetMinSearchCharCount
+func (g *GormRepository) minSearchCharCount() uint {
+	if reloaded := g.reloadedMinSearchCharCount.Load(); reloaded != nil {
+		return *reloaded
+	}
+
+	return g.MinSearchCharCount
+}
+
+// visibleDocsRoot returns the docs root as the argument of the visiblePredicate; it is escaped,
+// so that LIKE wildcards (%, _) within it are matched literally
+func (g *GormRepository) visibleDocsRoot() string {
+	return escapeLike(g.docsRoot())
+}
+
+func (g *GormRepository) docsRoot() string {
+	if len(g.DocsRoot) == 0 {
+		return constants.DefaultDocsRoot
+	}
+
+	return g.DocsRoot
 }
 
 // ensure GormRepository implements Repository
 var _ Repository = &GormRepository{}
 
+// txKey is the context key of the transaction started by WithTransaction
+type txKey struct{}
+
+// db returns the transaction carried by ctx (see WithTransaction) or, if there is none, the database; either bound to ctx
+func (g *GormRepository) db(ctx context.Context) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+
+	return g.DB.WithContext(ctx)
+}
+
+// WithTransaction runs fn in a database transaction which is committed if fn returns nil and rolled back otherwise.
+// All repository calls with the context passed to fn take part in the transaction
+func (g *GormRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return g.db(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+func (g *GormRepository) Ping(ctx context.Context) error {
+	return g.db(ctx).
+		Exec("SELECT 1").
+		Error
+}
+
+// DeleteMarkdownMetasByIds sets deleted_at (see models.Model) instead of deleting the rows,
+// so that an accidentally emptied source does not wipe the index for good
 func (g *GormRepository) DeleteMarkdownMetasByIds(ctx context.Context, metaIds []uint) error {
-	return g.DB.
-		WithContext(ctx).
-		Exec("DELETE FROM markdown_meta WHERE id IN ?", metaIds).
+	return g.db(ctx).
+		Delete(&models.MarkdownMeta{}, metaIds).
 		Error
 }
 
+// DeleteMarkdownContentsByIds sets deleted_at like DeleteMarkdownMetasByIds
 func (g *GormRepository) DeleteMarkdownContentsByIds(ctx context.Context, contentIds []uint) error {
-	return g.DB.
-		WithContext(ctx).
-		Exec("DELETE FROM markdown_contents WHERE id IN ?", contentIds).
+	return g.db(ctx).
+		Delete(&models.MarkdownContent{}, contentIds).
 		Error
 }
 
 func (g *GormRepository) FindAllMarkdownMetas(ctx context.Context, markdownMetas *[]models.MarkdownMeta) error {
-	return g.DB.
-		WithContext(ctx).
+	return g.db(ctx).
 		Find(markdownMetas).
 		Error
 }
 
+func (g *GormRepository) FindMarkdownContentHashes(ctx context.Context, contentHashes *[]models.MarkdownContentHash) error {
+	return g.db(ctx).
+		Raw("SELECT mm.name AS name, mm.path AS path, mc.hash AS hash FROM markdown_contents mc JOIN markdown_meta mm ON mm.id = mc.meta_id WHERE " + notDeletedPredicate).
+		Scan(contentHashes).
+		Error
+}
+
 func (g *GormRepository) FindMarkdownMetasWhereCharCountGreaterThan(ctx context.Context, x int, markdownMetas *[]models.MarkdownMeta) error {
-	return g.DB.
-		WithContext(ctx).
+	return g.db(ctx).
 		Where("char_count > ?", x).
 		Find(markdownMetas).
 		Error
 }
 
 func (g *GormRepository) FindUserLoginCredentials(ctx context.Context, username string, user *models.User) error {
-	return g.DB.
-		WithContext(ctx).
+	return g.db(ctx).
 		Model(models.User{}).
 		Where("username = ?", username).
 		Take(user).
 		Error
 }
 
+func (g *GormRepository) CreateUser(ctx context.Context, user *models.User) error {
+	err := g.db(ctx).
+		Create(user).
+		Error
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return ErrDuplicateUsername
+	}
+
+	return err
+}
+
 func (g *GormRepository) FindMarkdownContentByName(ctx context.Context, name string, markdownContent *models.MarkdownContent) error {
-	return g.DB.
-		WithContext(ctx).
+	err := g.db(ctx).
 		Model(&markdownContent).
 		Joins("Meta").
 		First(&markdownContent, "name = ?", name).
 		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMarkdownNotFound
+	}
+
+	return err
+}
+
+func (g *GormRepository) FindMarkdownContentByPath(ctx context.Context, path, name string, markdownContent *models.MarkdownContent) error {
+	err := g.db(ctx).
+		Model(&markdownContent).
+		Joins("Meta").
+		First(&markdownContent, "path = ? AND name = ?", path, name).
+		Error
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrMarkdownNotFound
+	}
+
+	return err
 }
 
 func (g *GormRepository) FindMarkdownContentIdsByMetaIds(ctx context.Context, markdownMetaIds []uint, markdownContentIds *[]uint) error {
-	return g.DB.
-		WithContext(ctx).
+	return g.db(ctx).
 		Preload(clause.Associations).
-		Raw("SELECT id FROM markdown_contents WHERE meta_id IN ?", markdownMetaIds).
+		Raw("SELECT id FROM markdown_contents WHERE meta_id IN ? AND deleted_at IS NULL", markdownMetaIds).
 		Scan(&markdownContentIds).
 		Error
 }
 
-func (g *GormRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm string, markdowns *[]models.MarkdownContent) error {
+// visiblePredicate excludes hidden Markdown files (see models.MarkdownMeta.IsHidden) from a query of markdown_meta;
+// its placeholder is the escaped docs root (see visibleDocsRoot). The path and the name are joined, so that the first segment
+// below the docs root is the folder of a nested file or the name of a top-level file
+const visiblePredicate = `NOT (path || '/' || name LIKE ? || '/.%')`
+
+// notDeletedPredicate excludes soft-deleted rows (see models.Model) from a query joining markdown_contents (mc) and markdown_meta (mm);
+// unlike GORM's queries, raw queries do not exclude them automatically
+const notDeletedPredicate = `mm.deleted_at IS NULL AND mc.deleted_at IS NULL`
+
+// markdownSearchRow is a row of a search query joining markdown_contents and markdown_meta
+type markdownSearchRow struct {
+	MetaID        uint
+	MetaCreatedAt time.Time
+	MetaUpdatedAt time.Time
+	Name          string
+	Path          string
+	CharCount     uint
+
+	ContentId        uint
+	ContentCreatedAt time.Time
+	ContentUpdatedAt time.Time
+	Content          string
+
+	// Similarity is only selected by queries that rank in SQL
+	Similarity float64
+}
 
-	var markdownJoined []struct {
-		MetaID        uint
-		MetaCreatedAt time.Time
-		MetaUpdatedAt time.Time
-		Name          string
-		Path          string
-		CharCount     uint
+func (r markdownSearchRow) toMarkdownContent() models.MarkdownContent {
+	meta := models.MarkdownMeta{
+		Model:     models.Model{ID: r.MetaID, CreatedAt: r.MetaCreatedAt, UpdatedAt: r.MetaUpdatedAt},
+		Path:      r.Path,
+		Name:      r.Name,
+		CharCount: r.CharCount,
+	}
 
-		ContentId        uint
-		ContentCreatedAt time.Time
-		ContentUpdatedAt time.Time
-		Content          string
+	return models.MarkdownContent{
+		Model:   models.Model{ID: r.ContentId, CreatedAt: r.ContentCreatedAt, UpdatedAt: r.ContentUpdatedAt},
+		MetaID:  r.MetaID,
+		Meta:    meta,
+		Content: r.Content,
 	}
+}
+
+func (g *GormRepository) FindVisibleMarkdowns(ctx context.Context, markdowns *[]models.MarkdownContent) error {
+	var markdownJoined []markdownSearchRow
 
-	err := g.DB.
-		WithContext(ctx).
+	// the aliases must match the (snake_case) column names GORM derives from the fields of markdownSearchRow
+	err := g.db(ctx).
 		Raw(`
 				SELECT
-					mm.id AS MetaID, 
-				    mm.created_at AS MetaCreatedAt, 
-				    mm.updated_at AS MetaUpdatedAt, 
-				    mm.name AS Name, 
-				    mm.path AS Path, 
-				    mm.char_count AS CharCount,
-				    mc.id AS ContentId, 
-				    mc.created_at AS ContentCreatedAt, 
-				    mc.updated_at AS ContentUpdatedAt, 
-				    mc.content AS Content
+					mm.id AS meta_id, 
+				    mm.created_at AS meta_created_at, 
+				    mm.updated_at AS meta_updated_at, 
+				    mm.name AS name, 
+				    mm.path AS path, 
+				    mm.char_count AS char_count,
+				    mc.id AS content_id, 
+				    mc.created_at AS content_created_at, 
+				    mc.updated_at AS content_updated_at, 
+				    mc.content AS content
+				FROM markdown_contents mc
+				JOIN markdown_meta mm ON mm.id = mc.meta_id
+				WHERE `+visiblePredicate+`
+					AND `+notDeletedPredicate+`
+					AND char_count >= ?`,
+			g.visibleDocsRoot(), g.minSearchCharCount(),
+		).
+		Scan(&markdownJoined).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, m := range markdownJoined {
+		*markdowns = append(*markdowns, m.toMarkdownContent())
+	}
+
+	return nil
+}
+
+func (g *GormRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.MarkdownContent) error {
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(pathPrefix)
+
+	var markdownJoined []markdownSearchRow
+
+	// the aliases must match the (snake_case) column names GORM derives from the fields of markdownSearchRow
+	err := g.db(ctx).
+		Raw(`
+				SELECT
+					mm.id AS meta_id, 
+				    mm.created_at AS meta_created_at, 
+				    mm.updated_at AS meta_updated_at, 
+				    mm.name AS name, 
+				    mm.path AS path, 
+				    mm.char_count AS char_count,
+				    mc.id AS content_id, 
+				    mc.created_at AS content_created_at, 
+				    mc.updated_at AS content_updated_at, 
+				    mc.content AS content
 				FROM markdown_contents mc
 				JOIN markdown_meta mm ON mm.id = mc.meta_id
 				WHERE content LIKE '%'|| ? ||'%' 
-					AND path NOT LIKE 'markdowns/.%'`,
-			searchTerm,
+					AND `+visiblePredicate+`
+					AND `+notDeletedPredicate+`
+					AND char_count >= ?`+prefixPredicate,
+			append([]any{searchTerm, g.visibleDocsRoot(), g.minSearchCharCount()}, prefixArgs...)...,
 		).
 		Scan(&markdownJoined).
 		Error
@@ -217,65 +598,414 @@ func (g *GormRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, se
 		return err
 	}
 
-	if len(markdownJoined) == 0 {
-		return nil
+	for _, m := range markdownJoined {
+		*markdowns = append(*markdowns, m.toMarkdownContent())
+	}
+
+	return nil
+}
+
+// FindMarkdownsBySearchTermWithOptions matches the term via ILIKE or LIKE (CaseSensitive);
+// whole words are matched via a case-insensitive (~*) or case-sensitive (~) regular expression enclosing the term in word boundaries (\m, \M)
+func (g *GormRepository) FindMarkdownsBySearchTermWithOptions(ctx context.Context, searchTerm string, options SearchOptions, markdowns *[]models.MarkdownContent) error {
+	predicate, term := contentPredicate(searchTerm, options)
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(options.PathPrefix)
+
+	var markdownJoined []markdownSearchRow
+
+	// the aliases must match the (snake_case) column names GORM derives from the fields of markdownSearchRow
+	err := g.db(ctx).
+		Raw(`
+				SELECT
+					mm.id AS meta_id, 
+				    mm.created_at AS meta_created_at, 
+				    mm.updated_at AS meta_updated_at, 
+				    mm.name AS name, 
+				    mm.path AS path, 
+				    mm.char_count AS char_count,
+				    mc.id AS content_id, 
+				    mc.created_at AS content_created_at, 
+				    mc.updated_at AS content_updated_at, 
+				    mc.content AS content
+				FROM markdown_contents mc
+				JOIN markdown_meta mm ON mm.id = mc.meta_id
+				WHERE `+predicate+`
+					AND `+visiblePredicate+`
+					AND `+notDeletedPredicate+`
+					AND char_count >= ?`+prefixPredicate,
+			append([]any{term, g.visibleDocsRoot(), g.minSearchCharCount()}, prefixArgs...)...,
+		).
+		Scan(&markdownJoined).
+		Error
+	if err != nil {
+		return err
 	}
 
 	for _, m := range markdownJoined {
-		meta := models.MarkdownMeta{
-			Path: m.Path,
-			Name: m.Name,
-		}
+		*markdowns = append(*markdowns, m.toMarkdownContent())
+	}
+
+	return nil
+}
 
-		content := models.MarkdownContent{
-			Meta:    meta,
-			Content: m.Content,
+// contentPredicate returns the predicate on the content matching the search term the way the options specify,
+// together with the argument of its placeholder
+func contentPredicate(searchTerm string, options SearchOptions) (string, any) {
+	if options.WholeWord {
+		operator := "~*"
+		if options.CaseSensitive {
+			operator = "~"
 		}
 
-		*markdowns = append(*markdowns, content)
+		// the term is escaped, so that its regex metacharacters are matched literally
+		return "content " + operator + " ?", `\m` + regexp.QuoteMeta(searchTerm) + `\M`
+	}
+
+	operator := "ILIKE"
+	if options.CaseSensitive {
+		operator = "LIKE"
+	}
+
+	return "content " + operator + " '%'|| ? ||'%'", searchTerm
+}
+
+// pathPrefixPredicate returns the predicate restricting a search to the folder subtree below the docs root starting with the prefix,
+// together with the argument of its placeholder; without a prefix, neither a predicate nor an argument is returned.
+// The prefix is escaped, so that LIKE wildcards (%, _) within it are matched literally
+func (g *GormRepository) pathPrefixPredicate(pathPrefix string) (string, []any) {
+	pathPrefix = strings.Trim(pathPrefix, "/")
+	if len(pathPrefix) == 0 {
+		return "", nil
+	}
+
+	return `
+					AND path LIKE ?`, []any{escapeLike(g.docsRoot()+"/"+pathPrefix) + "%"}
+}
+
+// likeEscaper escapes the wildcards of LIKE patterns with Postgres' default escape character (backslash)
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
+// FindMarkdownsBySearchTerms composes one LIKE clause per search term; the clauses are joined by AND if matchAll is set, by OR otherwise
+func (g *GormRepository) FindMarkdownsBySearchTerms(ctx context.Context, searchTerms []string, matchAll bool, pathPrefix string, markdowns *[]models.MarkdownContent) error {
+	if len(searchTerms) == 0 {
+		return nil
+	}
+
+	operator := " OR "
+	if matchAll {
+		operator = " AND "
+	}
+
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(pathPrefix)
+
+	clauses := make([]string, 0, len(searchTerms))
+	args := make([]any, 0, len(searchTerms)+2+len(prefixArgs))
+	for _, term := range searchTerms {
+		clauses = append(clauses, "content LIKE '%'|| ? ||'%'")
+		args = append(args, term)
+	}
+	args = append(args, g.visibleDocsRoot(), g.minSearchCharCount())
+	args = append(args, prefixArgs...)
+
+	var markdownJoined []markdownSearchRow
+
+	// the aliases must match the (snake_case) column names GORM derives from the fields of markdownSearchRow
+	err := g.db(ctx).
+		Raw(`
+				SELECT
+					mm.id AS meta_id, 
+				    mm.created_at AS meta_created_at, 
+				    mm.updated_at AS meta_updated_at, 
+				    mm.name AS name, 
+				    mm.path AS path, 
+				    mm.char_count AS char_count,
+				    mc.id AS content_id, 
+				    mc.created_at AS content_created_at, 
+				    mc.updated_at AS content_updated_at, 
+				    mc.content AS content
+				FROM markdown_contents mc
+				JOIN markdown_meta mm ON mm.id = mc.meta_id
+				WHERE (`+strings.Join(clauses, operator)+`)
+					AND `+visiblePredicate+`
+					AND `+notDeletedPredicate+`
+					AND char_count >= ?`+prefixPredicate,
+			args...,
+		).
+		Scan(&markdownJoined).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, m := range markdownJoined {
+		*markdowns = append(*markdowns, m.toMarkdownContent())
+	}
+
+	return nil
+}
+
+// rankedSearchQuery selects the Markdown contents containing a search term (1st and 2nd arg) and scores them
+// by pg_trgm's similarity(); hidden Markdown files (see visiblePredicate; the docs root is the 3rd arg) and contents with fewer characters than the 4th arg are excluded.
+// Further predicates (e.g., see pathPrefixPredicate) and the ORDER BY are appended by the callers.
+//
+// The aliases must match the (snake_case) column names GORM derives from the fields of markdownSearchRow.
+const rankedSearchQuery = `
+				SELECT
+					mm.id AS meta_id, 
+				    mm.created_at AS meta_created_at, 
+				    mm.updated_at AS meta_updated_at, 
+				    mm.name AS name, 
+				    mm.path AS path, 
+				    mm.char_count AS char_count,
+				    mc.id AS content_id, 
+				    mc.created_at AS content_created_at, 
+				    mc.updated_at AS content_updated_at, 
+				    mc.content AS content,
+				    similarity(mc.content, ?) AS similarity
+				FROM markdown_contents mc
+				JOIN markdown_meta mm ON mm.id = mc.meta_id
+				WHERE content LIKE '%'|| ? ||'%' 
+					AND ` + visiblePredicate + `
+					AND ` + notDeletedPredicate + `
+					AND char_count >= ?`
+
+// FindMarkdownsBySearchTermRanked requires the pg_trgm extension;
+// the contents are scored by pg_trgm's similarity() and ordered by it in descending order
+func (g *GormRepository) FindMarkdownsBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error {
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(pathPrefix)
+
+	return g.findRankedMarkdowns(ctx, markdowns, rankedSearchQuery+prefixPredicate+`
+				ORDER BY similarity DESC, mc.id`,
+		append([]any{searchTerm, searchTerm, g.visibleDocsRoot(), g.minSearchCharCount()}, prefixArgs...)...,
+	)
+}
+
+// FindMarkdownsBySearchTermPaged requires the pg_trgm extension;
+// like FindMarkdownsBySearchTermRanked, but only the requested page of the contents scoring at least minSimilarity is selected
+func (g *GormRepository) FindMarkdownsBySearchTermPaged(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, pageNumber, pageSize int, markdowns *[]models.ScoredMarkdownContent) error {
+	limit, offset := limitAndOffset(pageNumber, pageSize)
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(pathPrefix)
+
+	args := append([]any{searchTerm, searchTerm, g.visibleDocsRoot(), g.minSearchCharCount()}, prefixArgs...)
+	return g.findRankedMarkdowns(ctx, markdowns, rankedSearchQuery+prefixPredicate+`
+					AND similarity(mc.content, ?) >= ?
+				ORDER BY similarity DESC, mc.id
+				LIMIT ? OFFSET ?`,
+		append(args, searchTerm, minSimilarity, limit, offset)...,
+	)
+}
+
+// FullTextSearchConfig is the Postgres text search configuration the contents and search terms are reduced to word stems with;
+// the tsvector column of markdown_contents is generated with it (see InitDatabase)
+const FullTextSearchConfig = "english"
+
+// fullTextSearchQuery selects the Markdown contents matching the words of a search term (1st and 2nd arg) and scores them
+// by ts_rank(); the normalization 32 maps the rank to rank/(rank+1), i.e., into [0,1) like the other similarities.
+// Hidden Markdown files (see visiblePredicate; the docs root is the 3rd arg) and contents with fewer characters than the 4th arg are excluded.
+// Further predicates (e.g., see pathPrefixPredicate) and the ORDER BY are appended by the callers.
+//
+// The aliases must match the (snake_case) column names GORM derives from the fields of markdownSearchRow.
+const fullTextSearchQuery = `
+				SELECT
+					mm.id AS meta_id, 
+				    mm.created_at AS meta_created_at, 
+				    mm.updated_at AS meta_updated_at, 
+				    mm.name AS name, 
+				    mm.path AS path, 
+				    mm.char_count AS char_count,
+				    mc.id AS content_id, 
+				    mc.created_at AS content_created_at, 
+				    mc.updated_at AS content_updated_at, 
+				    mc.content AS content,
+				    ts_rank(mc.content_tsv, plainto_tsquery('` + FullTextSearchConfig + `', ?), 32) AS similarity
+				FROM markdown_contents mc
+				JOIN markdown_meta mm ON mm.id = mc.meta_id
+				WHERE mc.content_tsv @@ plainto_tsquery('` + FullTextSearchConfig + `', ?)
+					AND ` + visiblePredicate + `
+					AND ` + notDeletedPredicate + `
+					AND char_count >= ?`
+
+// FindMarkdownsByFullText requires the tsvector column created for the "fulltext" search backend (see InitDatabase)
+func (g *GormRepository) FindMarkdownsByFullText(ctx context.Context, searchTerm, pathPrefix string, markdowns *[]models.ScoredMarkdownContent) error {
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(pathPrefix)
+
+	return g.findRankedMarkdowns(ctx, markdowns, fullTextSearchQuery+prefixPredicate+`
+				ORDER BY similarity DESC, mc.id`,
+		append([]any{searchTerm, searchTerm, g.visibleDocsRoot(), g.minSearchCharCount()}, prefixArgs...)...,
+	)
+}
+
+func (g *GormRepository) findRankedMarkdowns(ctx context.Context, markdowns *[]models.ScoredMarkdownContent, query string, args ...any) error {
+	var markdownJoined []markdownSearchRow
+
+	err := g.db(ctx).
+		Raw(query, args...).
+		Scan(&markdownJoined).
+		Error
+	if err != nil {
+		return err
+	}
+
+	for _, m := range markdownJoined {
+		*markdowns = append(*markdowns, models.ScoredMarkdownContent{MarkdownContent: m.toMarkdownContent(), Similarity: m.Similarity})
 	}
 
 	return nil
 }
 
-func (g *GormRepository) CountMarkdownsMatchesBySearchTermSimple(ctx context.Context, searchTerm string, matchCount *int) error {
-	return g.DB.
-		WithContext(ctx).
+// limitAndOffset converts a (1-based) page number and a page size into the values of LIMIT and OFFSET;
+// page numbers and page sizes less than 1 are clamped to 1
+func limitAndOffset(pageNumber, pageSize int) (limit, offset int) {
+	pageNumber = max(pageNumber, 1)
+	pageSize = max(pageSize, 1)
+
+	// an overflowing offset would be negative; the largest offset skips all rows instead
+	if pageNumber-1 > math.MaxInt/pageSize {
+		return pageSize, math.MaxInt
+	}
+
+	return pageSize, (pageNumber - 1) * pageSize
+}
+
+// CountMarkdownsMatchesBySearchTermRanked requires the pg_trgm extension;
+// only the contents scoring at least minSimilarity are counted
+func (g *GormRepository) CountMarkdownsMatchesBySearchTermRanked(ctx context.Context, searchTerm, pathPrefix string, minSimilarity float64, matchCount *int) error {
+	prefixPredicate, prefixArgs := g.pathPrefixPredicate(pathPrefix)
+
+	args := append([]any{searchTerm, g.visibleDocsRoot(), g.minSearchCharCount()}, prefixArgs...)
+	return g.db(ctx).
 		Raw(`
 				SELECT count(*)
 				FROM markdown_contents mc,
 					 markdown_meta mm
 				WHERE mc.meta_id = mm.id
 					AND content LIKE '%'|| ? ||'%' 
-					AND path NOT LIKE 'markdowns/.%'`,
-			searchTerm,
+					AND `+visiblePredicate+`
+					AND `+notDeletedPredicate+`
+					AND char_count >= ?`+prefixPredicate+`
+					AND similarity(mc.content, ?) >= ?`,
+			append(args, searchTerm, minSimilarity)...,
 		).
 		Scan(matchCount).
 		Error
 }
 
+// FindMarkdownDocumentsPaged joins the contents since their timestamps tell when a file changed last (see models.MarkdownDocument)
+func (g *GormRepository) FindMarkdownDocumentsPaged(ctx context.Context, options DocumentListOptions, pageNumber, pageSize int, documents *[]models.MarkdownDocument) error {
+	// the column is chosen from a fixed set; the options are never interpolated into the query
+	column := "mm.name"
+	if options.SortBy == DocumentSortUpdatedAt {
+		column = "mc.updated_at"
+	}
+	direction := "ASC"
+	if options.Descending {
+		direction = "DESC"
+	}
+
+	hiddenPredicate, args := g.hiddenPredicate(options.IncludeHidden)
+	limit, offset := limitAndOffset(pageNumber, pageSize)
+
+	return g.db(ctx).
+		Raw(`
+				SELECT mm.name AS name, mm.path AS path, mm.char_count AS char_count, mc.updated_at AS updated_at
+				FROM markdown_meta mm
+				JOIN markdown_contents mc ON mc.meta_id = mm.id
+				WHERE `+notDeletedPredicate+hiddenPredicate+`
+				ORDER BY `+column+` `+direction+`, mm.path, mm.name
+				LIMIT ? OFFSET ?`,
+			append(args, limit, offset)...,
+		).
+		Scan(documents).
+		Error
+}
+
+func (g *GormRepository) CountMarkdownDocuments(ctx context.Context, includeHidden bool, documentCount *int) error {
+	hiddenPredicate, args := g.hiddenPredicate(includeHidden)
+
+	return g.db(ctx).
+		Raw(`
+				SELECT count(*)
+				FROM markdown_meta mm
+				JOIN markdown_contents mc ON mc.meta_id = mm.id
+				WHERE `+notDeletedPredicate+hiddenPredicate,
+			args...,
+		).
+		Scan(documentCount).
+		Error
+}
+
+// hiddenPredicate returns the visiblePredicate (and its argument) to append to a WHERE clause unless includeHidden is set
+func (g *GormRepository) hiddenPredicate(includeHidden bool) (string, []any) {
+	if includeHidden {
+		return "", nil
+	}
+
+	return " AND " + visiblePredicate, []any{g.visibleDocsRoot()}
+}
+
+// CountAllMarkdowns counts the hidden files with visiblePredicate, so that they match the ones excluded from the searches
+func (g *GormRepository) CountAllMarkdowns(ctx context.Context, stats *models.MarkdownStats) error {
+	return g.db(ctx).
+		Raw(`
+				SELECT count(*) AS documents,
+					   coalesce(sum(char_count), 0) AS total_chars,
+					   count(*) FILTER (WHERE NOT (`+visiblePredicate+`)) AS hidden,
+					   count(*) FILTER (WHERE char_count = 0) AS empty
+				FROM markdown_meta
+				WHERE deleted_at IS NULL`,
+			g.visibleDocsRoot(),
+		).
+		Scan(stats).
+		Error
+}
+
+// PurgeSoftDeletedMarkdowns deletes the rows in a single transaction; the contents are deleted first since they reference their metas.
+// The contents of purged metas are purged regardless of their own deleted_at
+func (g *GormRepository) PurgeSoftDeletedMarkdowns(ctx context.Context, deletedBefore time.Time, purgedMetas *int64) error {
+	return g.WithTransaction(ctx, func(ctx context.Context) error {
+		err := g.db(ctx).
+			Exec("DELETE FROM markdown_contents WHERE deleted_at < ? OR meta_id IN (SELECT id FROM markdown_meta WHERE deleted_at < ?)", deletedBefore, deletedBefore).
+			Error
+		if err != nil {
+			return err
+		}
+
+		result := g.db(ctx).
+			Exec("DELETE FROM markdown_meta WHERE deleted_at < ?", deletedBefore)
+		*purgedMetas = result.RowsAffected
+
+		return result.Error
+	})
+}
+
+// UpsertMarkdownMetas resets deleted_at on conflict, so that a soft-deleted Markdown file reappearing in the source is restored
 func (g *GormRepository) UpsertMarkdownMetas(ctx context.Context, markdownMetas []models.MarkdownMeta) error {
-	return g.DB.
-		WithContext(ctx).
+	return g.db(ctx).
 		Clauses(clause.OnConflict{
-			// update all columns to new value on `name` conflict except primary keys
+			// update all columns to new value on `(path, name)` conflict except primary keys
 			// and those columns having default values from sql func
-			Columns:   []clause.Column{{Name: "name"}},
+			Columns:   []clause.Column{{Name: "path"}, {Name: "name"}},
 			UpdateAll: true,
 		}).
-		Create(&markdownMetas).
+		// the batches are inserted within a single transaction
+		CreateInBatches(&markdownMetas, g.upsertBatchSize()).
 		Error
 }
 
+// UpsertMarkdownContents resets deleted_at on conflict like UpsertMarkdownMetas
 func (g *GormRepository) UpsertMarkdownContents(ctx context.Context, markdownContents []models.MarkdownContent) error {
-	return g.DB.
-		WithContext(ctx).
+	return g.db(ctx).
 		Clauses(clause.OnConflict{
 			// update all columns to new value on `meta_id` conflict except primary keys
 			// and those columns having default values from sql func
 			Columns:   []clause.Column{{Name: "meta_id"}},
 			UpdateAll: true,
 		}).
-		Create(&markdownContents).
+		// the batches are inserted within a single transaction
+		CreateInBatches(&markdownContents, g.upsertBatchSize()).
 		Error
 }
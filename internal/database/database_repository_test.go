@@ -7,17 +7,21 @@ import (
 	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/models"
+	"errors"
 	"fmt"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/go-cmp/cmp"
+	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"log/slog"
+	"math"
 	"moul.io/zapgorm2"
 	"os"
+	"regexp"
 	"testing"
 	"time"
 )
@@ -130,7 +134,8 @@ func TestGormRepository_FindAllMarkdownMetas(t *testing.T) {
 	}
 
 	// NOTE: ExpectedQuery expects a regex string as param
-	sqlMock.ExpectQuery("^SELECT \\* FROM \"markdown_meta\"").
+	// soft-deleted metas are excluded (see models.Model)
+	sqlMock.ExpectQuery("^SELECT \\* FROM \"markdown_meta\" WHERE \"markdown_meta\"\\.\"deleted_at\" IS NULL").
 		WillReturnRows(markdownMetaRows)
 
 	var got []models.MarkdownMeta
@@ -187,7 +192,7 @@ func TestGormRepository_FindMarkdownMetasWhereCharCountGreaterThan(t *testing.T)
 	}
 
 	// NOTE: ExpectedQuery expects a regex string as param
-	sqlMock.ExpectQuery("^SELECT \\* FROM \"markdown_meta\" WHERE char_count > \\$1").
+	sqlMock.ExpectQuery("^SELECT \\* FROM \"markdown_meta\" WHERE char_count > \\$1 AND \"markdown_meta\"\\.\"deleted_at\" IS NULL").
 		WillReturnRows(markdownMetaRows)
 
 	var got []models.MarkdownMeta
@@ -208,9 +213,12 @@ func TestGormRepository_FindMarkdownMetasWhereCharCountGreaterThan(t *testing.T)
 }
 
 func TestGormRepository_DeleteMarkdownMetasByIds(t *testing.T) {
-	sqlMock.ExpectExec("^DELETE FROM markdown_meta WHERE id IN \\(\\$1,\\$2,\\$3\\)").
-		WithArgs(3, 4, 5).
+	// the metas are soft-deleted, i.e., they are kept and can be restored (see TestGormRepository_UpsertMarkdownMetas)
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("^UPDATE \"markdown_meta\" SET \"deleted_at\"=\\$1 WHERE \"markdown_meta\"\\.\"id\" IN \\(\\$2,\\$3,\\$4\\) AND \"markdown_meta\"\\.\"deleted_at\" IS NULL").
+		WithArgs(sqlmock.AnyArg(), 3, 4, 5).
 		WillReturnResult(sqlmock.NewResult(0, 3))
+	sqlMock.ExpectCommit()
 
 	err := env.DeleteMarkdownMetasByIds(context.Background(), []uint{3, 4, 5})
 	if err != nil {
@@ -219,9 +227,11 @@ func TestGormRepository_DeleteMarkdownMetasByIds(t *testing.T) {
 }
 
 func TestGormRepository_DeleteMarkdownContentsByIds(t *testing.T) {
-	sqlMock.ExpectExec("^DELETE FROM markdown_contents WHERE id IN \\(\\$1,\\$2,\\$3\\)").
-		WithArgs(1, 2, 3).
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectExec("^UPDATE \"markdown_contents\" SET \"deleted_at\"=\\$1 WHERE \"markdown_contents\"\\.\"id\" IN \\(\\$2,\\$3,\\$4\\) AND \"markdown_contents\"\\.\"deleted_at\" IS NULL").
+		WithArgs(sqlmock.AnyArg(), 1, 2, 3).
 		WillReturnResult(sqlmock.NewResult(0, 3))
+	sqlMock.ExpectCommit()
 
 	err := env.DeleteMarkdownContentsByIds(context.Background(), []uint{1, 2, 3})
 	if err != nil {
@@ -229,6 +239,16 @@ func TestGormRepository_DeleteMarkdownContentsByIds(t *testing.T) {
 	}
 }
 
+func TestGormRepository_Ping(t *testing.T) {
+	sqlMock.ExpectExec("^SELECT 1$").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := env.Ping(context.Background())
+	if err != nil {
+		t.Fatalf("Ping error: %v", err)
+	}
+}
+
 func TestGormRepository_FindUserLoginCredentials(t *testing.T) {
 
 	want := models.User{
@@ -236,12 +256,13 @@ func TestGormRepository_FindUserLoginCredentials(t *testing.T) {
 		Username: "username",
 		Password: "hashed_password",
 		Email:    "test@email.com",
+		Roles:    models.Roles{models.RoleAdmin},
 	}
 
-	sqlMock.ExpectQuery("^SELECT \\* FROM \"users\" WHERE username = \\$1 LIMIT \\$2").
+	sqlMock.ExpectQuery("^SELECT \\* FROM \"users\" WHERE username = \\$1 AND \"users\"\\.\"deleted_at\" IS NULL LIMIT \\$2").
 		WillReturnRows(sqlMock.
-			NewRows([]string{"id", "username", "email", "password"}).
-			AddRow(1, want.Username, want.Email, want.Password),
+			NewRows([]string{"id", "username", "email", "password", "roles"}).
+			AddRow(1, want.Username, want.Email, want.Password, "admin"),
 		)
 
 	got := models.User{}
@@ -257,6 +278,41 @@ func TestGormRepository_FindUserLoginCredentials(t *testing.T) {
 	}
 }
 
+func TestGormRepository_CreateUser(t *testing.T) {
+	user := models.User{Username: "username", Email: "test@email.com", Password: "hashed_password", Roles: models.Roles{models.RoleReader, models.RoleAdmin}}
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery("^INSERT INTO \"users\" \\(\"created_at\",\"updated_at\",\"deleted_at\",\"username\",\"email\",\"password\",\"roles\"\\) VALUES .* RETURNING \"id\"").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), nil, user.Username, user.Email, user.Password, "reader,admin").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	sqlMock.ExpectCommit()
+
+	err := env.CreateUser(context.Background(), &user)
+	if err != nil {
+		t.Fatalf("CreateUser error: %v", err)
+	}
+
+	if user.ID != 7 {
+		t.Errorf("want ID 7, got %d", user.ID)
+		return
+	}
+}
+
+func TestGormRepository_CreateUser_duplicateUsername(t *testing.T) {
+	user := models.User{Username: "username", Password: "hashed_password"}
+
+	sqlMock.ExpectBegin()
+	sqlMock.ExpectQuery("^INSERT INTO \"users\"").
+		WillReturnError(&pgconn.PgError{Code: "23505"})
+	sqlMock.ExpectRollback()
+
+	err := env.CreateUser(context.Background(), &user)
+	if !errors.Is(err, database.ErrDuplicateUsername) {
+		t.Errorf("want ErrDuplicateUsername, got %v", err)
+		return
+	}
+}
+
 func TestGormRepository_FindMarkdownContentByName(t *testing.T) {
 
 	want := models.MarkdownContent{
@@ -269,7 +325,7 @@ func TestGormRepository_FindMarkdownContentByName(t *testing.T) {
 		Content: "# Heading 1\n\n### Heading 3",
 	}
 
-	sqlMock.ExpectQuery("^SELECT .* FROM \"markdown_contents\" LEFT JOIN \"markdown_meta\" \"Meta\" ON \"markdown_contents\"\\.\"meta_id\" = \"Meta\"\\.\"id\" WHERE name = \\$1 ORDER BY \"markdown_contents\"\\.\"id\" LIMIT \\$2").
+	sqlMock.ExpectQuery("^SELECT .* FROM \"markdown_contents\" LEFT JOIN \"markdown_meta\" \"Meta\" ON \"markdown_contents\"\\.\"meta_id\" = \"Meta\"\\.\"id\" AND \"Meta\"\\.\"deleted_at\" IS NULL WHERE name = \\$1 AND \"markdown_contents\"\\.\"deleted_at\" IS NULL ORDER BY \"markdown_contents\"\\.\"id\" LIMIT \\$2").
 		//WithArgs("Getting-Started").
 		WillReturnRows(sqlMock.
 			NewRows([]string{"id", "meta_id", "content"}).
@@ -287,10 +343,61 @@ func TestGormRepository_FindMarkdownContentByName(t *testing.T) {
 	}
 }
 
+func TestGormRepository_FindMarkdownContentByName_notFound(t *testing.T) {
+	sqlMock.ExpectQuery("^SELECT .* FROM \"markdown_contents\" LEFT JOIN \"markdown_meta\" \"Meta\" .* WHERE name = \\$1").
+		WithArgs("ghost", 1).
+		WillReturnRows(sqlMock.NewRows([]string{"id", "meta_id", "content"}))
+
+	got := models.MarkdownContent{}
+	err := env.FindMarkdownContentByName(context.Background(), "ghost", &got)
+	if !errors.Is(err, database.ErrMarkdownNotFound) {
+		t.Errorf("want ErrMarkdownNotFound, got %v", err)
+		return
+	}
+}
+
+func TestGormRepository_FindMarkdownContentByPath(t *testing.T) {
+	want := models.MarkdownContent{
+		Model:   models.Model{ID: 1},
+		MetaID:  61,
+		Content: "# Onboarding",
+	}
+
+	sqlMock.ExpectQuery("^SELECT .* FROM \"markdown_contents\" LEFT JOIN \"markdown_meta\" \"Meta\" ON \"markdown_contents\"\\.\"meta_id\" = \"Meta\"\\.\"id\" AND \"Meta\"\\.\"deleted_at\" IS NULL WHERE \\(path = \\$1 AND name = \\$2\\) AND \"markdown_contents\"\\.\"deleted_at\" IS NULL ORDER BY \"markdown_contents\"\\.\"id\" LIMIT \\$3").
+		WithArgs("markdowns/Gateway", "1-Onboarding", 1).
+		WillReturnRows(sqlMock.
+			NewRows([]string{"id", "meta_id", "content"}).
+			AddRow(want.ID, want.MetaID, want.Content))
+
+	got := models.MarkdownContent{}
+	err := env.FindMarkdownContentByPath(context.Background(), "markdowns/Gateway", "1-Onboarding", &got)
+	if err != nil {
+		t.Fatalf("FindMarkdownContentByPath error: %v", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGormRepository_FindMarkdownContentByPath_notFound(t *testing.T) {
+	sqlMock.ExpectQuery("^SELECT .* FROM \"markdown_contents\" LEFT JOIN \"markdown_meta\" \"Meta\" .* WHERE \\(path = \\$1 AND name = \\$2\\)").
+		WithArgs("markdowns/Other-Folder", "1-Onboarding", 1).
+		WillReturnRows(sqlMock.NewRows([]string{"id", "meta_id", "content"}))
+
+	got := models.MarkdownContent{}
+	err := env.FindMarkdownContentByPath(context.Background(), "markdowns/Other-Folder", "1-Onboarding", &got)
+	if !errors.Is(err, database.ErrMarkdownNotFound) {
+		t.Errorf("want ErrMarkdownNotFound, got %v", err)
+		return
+	}
+}
+
 func TestGormRepository_FindMarkdownContentIdsByMetaIds(t *testing.T) {
 	wantIds := []uint{10, 11, 12}
 
-	sqlMock.ExpectQuery("^SELECT id FROM markdown_contents WHERE meta_id IN \\(\\$1,\\$2,\\$3\\)").
+	sqlMock.ExpectQuery("^SELECT id FROM markdown_contents WHERE meta_id IN \\(\\$1,\\$2,\\$3\\) AND deleted_at IS NULL").
 		WithArgs(3, 4, 5).
 		WillReturnRows(sqlMock.
 			NewRows([]string{"id"}).
@@ -311,6 +418,739 @@ func TestGormRepository_FindMarkdownContentIdsByMetaIds(t *testing.T) {
 	}
 }
 
+func TestGormRepository_FindMarkdownsBySearchTermSimple(t *testing.T) {
+	createdAt := parseTime("2025-01-01 10:00:00.000000 +00:00")
+	updatedAt := parseTime("2025-01-02 10:00:00.000000 +00:00")
+
+	want := []models.MarkdownContent{
+		{
+			Model:  models.Model{ID: 7, CreatedAt: createdAt, UpdatedAt: updatedAt},
+			MetaID: 3,
+			Meta: models.MarkdownMeta{
+				Model:     models.Model{ID: 3, CreatedAt: createdAt, UpdatedAt: updatedAt},
+				Name:      "Getting-Started",
+				Path:      "markdowns/01_Intro",
+				CharCount: 11,
+			},
+			Content: "hello world",
+		},
+	}
+
+	sqlMock.ExpectQuery("SELECT .* FROM markdown_contents mc JOIN markdown_meta mm ON mm.id = mc.meta_id").
+		WithArgs("hello", "markdowns", 0).
+		WillReturnRows(sqlMock.
+			NewRows([]string{
+				"meta_id", "meta_created_at", "meta_updated_at", "name", "path", "char_count",
+				"content_id", "content_created_at", "content_updated_at", "content",
+			}).
+			AddRow(3, createdAt, updatedAt, "Getting-Started", "markdowns/01_Intro", 11, 7, createdAt, updatedAt, "hello world"))
+
+	var got []models.MarkdownContent
+	err := env.FindMarkdownsBySearchTermSimple(context.Background(), "hello", "", &got)
+	if err != nil {
+		t.Fatalf("FindMarkdownsBySearchTermSimple error: %v", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGormRepository_FindVisibleMarkdowns(t *testing.T) {
+	createdAt := parseTime("2025-01-01 10:00:00.000000 +00:00")
+	updatedAt := parseTime("2025-01-02 10:00:00.000000 +00:00")
+
+	want := []models.MarkdownContent{
+		{
+			Model:  models.Model{ID: 7, CreatedAt: createdAt, UpdatedAt: updatedAt},
+			MetaID: 3,
+			Meta: models.MarkdownMeta{
+				Model:     models.Model{ID: 3, CreatedAt: createdAt, UpdatedAt: updatedAt},
+				Name:      "Getting-Started",
+				Path:      "markdowns/01_Intro",
+				CharCount: 11,
+			},
+			Content: "hello world",
+		},
+	}
+
+	sqlMock.ExpectQuery("SELECT .* FROM markdown_contents mc JOIN markdown_meta mm ON mm.id = mc.meta_id\\s+WHERE NOT \\(path \\|\\| '/' \\|\\| name LIKE \\$1 .* AND char_count >= \\$2$").
+		WithArgs("markdowns", 0).
+		WillReturnRows(sqlMock.
+			NewRows([]string{
+				"meta_id", "meta_created_at", "meta_updated_at", "name", "path", "char_count",
+				"content_id", "content_created_at", "content_updated_at", "content",
+			}).
+			AddRow(3, createdAt, updatedAt, "Getting-Started", "markdowns/01_Intro", 11, 7, createdAt, updatedAt, "hello world"))
+
+	var got []models.MarkdownContent
+	err := env.FindVisibleMarkdowns(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("FindVisibleMarkdowns error: %v", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGormRepository_pathPrefix(t *testing.T) {
+	tests := []struct {
+		name       string
+		pathPrefix string
+		wantArg    string
+	}{
+		{name: "prefix", pathPrefix: "Gateway", wantArg: "markdowns/Gateway%"},
+		{name: "trimsSlashes", pathPrefix: "/Gateway/", wantArg: "markdowns/Gateway%"},
+		{name: "escapesWildcards", pathPrefix: `50%_off\`, wantArg: `markdowns/50\%\_off\\%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+			if err != nil {
+				t.Fatalf("initMockedDatabase error: %v", err)
+			}
+			defer sqlDb.Close()
+
+			repo := &database.GormRepository{DB: mockedGormDb}
+
+			// the hidden folders are still excluded
+			mock.ExpectQuery("SELECT .* WHERE content LIKE .* AND NOT \\(path \\|\\| '/' \\|\\| name LIKE \\$2 \\|\\| '/.%'\\) AND mm.deleted_at IS NULL AND mc.deleted_at IS NULL AND char_count >= \\$3 AND path LIKE \\$4").
+				WithArgs("hello", "markdowns", 0, tt.wantArg).
+				WillReturnRows(mock.NewRows([]string{"meta_id", "char_count", "content"}))
+
+			var markdowns []models.MarkdownContent
+			if err := repo.FindMarkdownsBySearchTermSimple(context.Background(), "hello", tt.pathPrefix, &markdowns); err != nil {
+				t.Fatalf("FindMarkdownsBySearchTermSimple error: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_visiblePredicateOfDocsRoot(t *testing.T) {
+	tests := []struct {
+		name     string
+		docsRoot string
+		wantArg  string
+	}{
+		{name: "default", wantArg: "markdowns"},
+		{name: "multiSegment", docsRoot: "docs/handbook", wantArg: "docs/handbook"},
+		{name: "escapesWildcards", docsRoot: "docs/hand_book%", wantArg: `docs/hand\_book\%`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+			if err != nil {
+				t.Fatalf("initMockedDatabase error: %v", err)
+			}
+			defer sqlDb.Close()
+
+			repo := &database.GormRepository{DB: mockedGormDb, DocsRoot: tt.docsRoot}
+
+			// the first segment below the docs root is hidden, be it the folder of a nested file or the name of a top-level file
+			mock.ExpectQuery("SELECT .* WHERE content LIKE .* AND NOT \\(path \\|\\| '/' \\|\\| name LIKE \\$2 \\|\\| '/.%'\\) AND").
+				WithArgs("hello", tt.wantArg, 0).
+				WillReturnRows(mock.NewRows([]string{"meta_id", "char_count", "content"}))
+
+			var markdowns []models.MarkdownContent
+			if err := repo.FindMarkdownsBySearchTermSimple(context.Background(), "hello", "", &markdowns); err != nil {
+				t.Fatalf("FindMarkdownsBySearchTermSimple error: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_minSearchCharCount(t *testing.T) {
+	mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+	if err != nil {
+		t.Fatalf("initMockedDatabase error: %v", err)
+	}
+	defer sqlDb.Close()
+
+	repo := &database.GormRepository{DB: mockedGormDb, MinSearchCharCount: 50}
+
+	mock.ExpectQuery("SELECT .* WHERE content LIKE .* AND NOT \\(path \\|\\| '/' \\|\\| name LIKE .* AND char_count >= \\$3").
+		WithArgs("hello", "markdowns", 50).
+		WillReturnRows(mock.NewRows([]string{"meta_id", "char_count", "content"}))
+
+	var markdowns []models.MarkdownContent
+	if err := repo.FindMarkdownsBySearchTermSimple(context.Background(), "hello", "", &markdowns); err != nil {
+		t.Fatalf("FindMarkdownsBySearchTermSimple error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestGormRepository_SetMinSearchCharCount(t *testing.T) {
+	mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+	if err != nil {
+		t.Fatalf("initMockedDatabase error: %v", err)
+	}
+	defer sqlDb.Close()
+
+	repo := &database.GormRepository{DB: mockedGormDb, MinSearchCharCount: 50}
+	repo.SetMinSearchCharCount(10)
+
+	mock.ExpectQuery("SELECT .* WHERE content LIKE .* AND NOT \\(path \\|\\| '/' \\|\\| name LIKE .* AND char_count >= \\$3").
+		WithArgs("hello", "markdowns", 10).
+		WillReturnRows(mock.NewRows([]string{"meta_id", "char_count", "content"}))
+
+	var markdowns []models.MarkdownContent
+	if err := repo.FindMarkdownsBySearchTermSimple(context.Background(), "hello", "", &markdowns); err != nil {
+		t.Fatalf("FindMarkdownsBySearchTermSimple error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
+func TestGormRepository_searchesWithinPathPrefix(t *testing.T) {
+	prefix := "markdowns/Gateway%"
+
+	tests := []struct {
+		name      string
+		wantQuery string
+		wantArgs  []driver.Value
+		search    func(repo *database.GormRepository) error
+	}{
+		{
+			name:      "terms",
+			wantQuery: "SELECT .* AND char_count >= \\$4 AND path LIKE \\$5$",
+			wantArgs:  []driver.Value{"kafka", "retry", "markdowns", 0, prefix},
+			search: func(repo *database.GormRepository) error {
+				var markdowns []models.MarkdownContent
+				return repo.FindMarkdownsBySearchTerms(context.Background(), []string{"kafka", "retry"}, false, "Gateway", &markdowns)
+			},
+		},
+		{
+			name:      "ranked",
+			wantQuery: "SELECT .* AND char_count >= \\$4 AND path LIKE \\$5 ORDER BY similarity DESC, mc\\.id$",
+			wantArgs:  []driver.Value{"hello", "hello", "markdowns", 0, prefix},
+			search: func(repo *database.GormRepository) error {
+				var markdowns []models.ScoredMarkdownContent
+				return repo.FindMarkdownsBySearchTermRanked(context.Background(), "hello", "Gateway", &markdowns)
+			},
+		},
+		{
+			name:      "fullText",
+			wantQuery: "SELECT .* plainto_tsquery.* AND char_count >= \\$4 AND path LIKE \\$5 ORDER BY similarity DESC, mc\\.id$",
+			wantArgs:  []driver.Value{"hello", "hello", "markdowns", 0, prefix},
+			search: func(repo *database.GormRepository) error {
+				var markdowns []models.ScoredMarkdownContent
+				return repo.FindMarkdownsByFullText(context.Background(), "hello", "Gateway", &markdowns)
+			},
+		},
+		{
+			name:      "paged",
+			wantQuery: "SELECT .* AND char_count >= \\$4 AND path LIKE \\$5 AND similarity\\(mc\\.content, \\$6\\) >= \\$7 ORDER BY similarity DESC, mc\\.id LIMIT \\$8 OFFSET \\$9$",
+			wantArgs:  []driver.Value{"hello", "hello", "markdowns", 0, prefix, "hello", 0.3, 5, 0},
+			search: func(repo *database.GormRepository) error {
+				var markdowns []models.ScoredMarkdownContent
+				return repo.FindMarkdownsBySearchTermPaged(context.Background(), "hello", "Gateway", 0.3, 1, 5, &markdowns)
+			},
+		},
+		{
+			name:      "countRanked",
+			wantQuery: "SELECT count\\(\\*\\) .* AND char_count >= \\$3 AND path LIKE \\$4 AND similarity\\(mc\\.content, \\$5\\) >= \\$6$",
+			wantArgs:  []driver.Value{"hello", "markdowns", 0, prefix, "hello", 0.3},
+			search: func(repo *database.GormRepository) error {
+				var matchCount int
+				return repo.CountMarkdownsMatchesBySearchTermRanked(context.Background(), "hello", "Gateway", 0.3, &matchCount)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+			if err != nil {
+				t.Fatalf("initMockedDatabase error: %v", err)
+			}
+			defer sqlDb.Close()
+
+			mock.ExpectQuery(tt.wantQuery).
+				WithArgs(tt.wantArgs...).
+				WillReturnRows(mock.NewRows([]string{"count"}))
+
+			if err := tt.search(&database.GormRepository{DB: mockedGormDb}); err != nil {
+				t.Fatalf("search error: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_searchesExcludeSoftDeleted(t *testing.T) {
+	tests := []struct {
+		name   string
+		search func(repo *database.GormRepository) error
+	}{
+		{name: "simple", search: func(repo *database.GormRepository) error {
+			var markdowns []models.MarkdownContent
+			return repo.FindMarkdownsBySearchTermSimple(context.Background(), "hello", "", &markdowns)
+		}},
+		{name: "withOptions", search: func(repo *database.GormRepository) error {
+			var markdowns []models.MarkdownContent
+			return repo.FindMarkdownsBySearchTermWithOptions(context.Background(), "hello", database.SearchOptions{WholeWord: true}, &markdowns)
+		}},
+		{name: "terms", search: func(repo *database.GormRepository) error {
+			var markdowns []models.MarkdownContent
+			return repo.FindMarkdownsBySearchTerms(context.Background(), []string{"hello", "world"}, true, "", &markdowns)
+		}},
+		{name: "ranked", search: func(repo *database.GormRepository) error {
+			var markdowns []models.ScoredMarkdownContent
+			return repo.FindMarkdownsBySearchTermRanked(context.Background(), "hello", "", &markdowns)
+		}},
+		{name: "paged", search: func(repo *database.GormRepository) error {
+			var markdowns []models.ScoredMarkdownContent
+			return repo.FindMarkdownsBySearchTermPaged(context.Background(), "hello", "", 0.1, 1, 5, &markdowns)
+		}},
+		{name: "countRanked", search: func(repo *database.GormRepository) error {
+			var matchCount int
+			return repo.CountMarkdownsMatchesBySearchTermRanked(context.Background(), "hello", "", 0.1, &matchCount)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+			if err != nil {
+				t.Fatalf("initMockedDatabase error: %v", err)
+			}
+			defer sqlDb.Close()
+
+			// the soft-deleted metas and contents are kept in the database, but must not be found
+			mock.ExpectQuery("SELECT .* AND mm\\.deleted_at IS NULL AND mc\\.deleted_at IS NULL AND char_count >= ").
+				WillReturnRows(mock.NewRows([]string{"count"}))
+
+			if err := tt.search(&database.GormRepository{DB: mockedGormDb}); err != nil {
+				t.Fatalf("search error: %v", err)
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_FindMarkdownsBySearchTerms(t *testing.T) {
+	createdAt := parseTime("2025-01-01 10:00:00.000000 +00:00")
+	updatedAt := parseTime("2025-01-02 10:00:00.000000 +00:00")
+
+	tests := []struct {
+		name      string
+		matchAll  bool
+		wantWhere string
+	}{
+		{name: "and", matchAll: true, wantWhere: `WHERE \(content LIKE '%'\|\| \$1 \|\|'%' AND content LIKE '%'\|\| \$2 \|\|'%'\)`},
+		{name: "or", matchAll: false, wantWhere: `WHERE \(content LIKE '%'\|\| \$1 \|\|'%' OR content LIKE '%'\|\| \$2 \|\|'%'\)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := []models.MarkdownContent{
+				{
+					Model:  models.Model{ID: 7, CreatedAt: createdAt, UpdatedAt: updatedAt},
+					MetaID: 3,
+					Meta: models.MarkdownMeta{
+						Model:     models.Model{ID: 3, CreatedAt: createdAt, UpdatedAt: updatedAt},
+						Name:      "Consumers",
+						Path:      "markdowns/01_Intro",
+						CharCount: 17,
+					},
+					Content: "kafka retry topic",
+				},
+			}
+
+			sqlMock.ExpectQuery("SELECT .* FROM markdown_contents mc JOIN markdown_meta mm ON mm.id = mc.meta_id "+tt.wantWhere).
+				WithArgs("kafka", "retry", "markdowns", 0).
+				WillReturnRows(sqlMock.
+					NewRows([]string{
+						"meta_id", "meta_created_at", "meta_updated_at", "name", "path", "char_count",
+						"content_id", "content_created_at", "content_updated_at", "content",
+					}).
+					AddRow(3, createdAt, updatedAt, "Consumers", "markdowns/01_Intro", 17, 7, createdAt, updatedAt, "kafka retry topic"))
+
+			var got []models.MarkdownContent
+			err := env.FindMarkdownsBySearchTerms(context.Background(), []string{"kafka", "retry"}, tt.matchAll, "", &got)
+			if err != nil {
+				t.Fatalf("FindMarkdownsBySearchTerms error: %v", err)
+			}
+
+			if !cmp.Equal(want, got) {
+				t.Error(cmp.Diff(want, got))
+				return
+			}
+
+			if err := sqlMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_FindMarkdownsBySearchTermWithOptions(t *testing.T) {
+	createdAt := parseTime("2025-01-01 10:00:00.000000 +00:00")
+	updatedAt := parseTime("2025-01-02 10:00:00.000000 +00:00")
+
+	tests := []struct {
+		name          string
+		options       database.SearchOptions
+		wantPredicate string
+		wantArg       string
+	}{
+		{name: "default", options: database.SearchOptions{}, wantPredicate: `WHERE content ILIKE '%'\|\| \$1 \|\|'%'`, wantArg: "c++"},
+		{name: "caseSensitive", options: database.SearchOptions{CaseSensitive: true}, wantPredicate: `WHERE content LIKE '%'\|\| \$1 \|\|'%'`, wantArg: "c++"},
+		{name: "wholeWord", options: database.SearchOptions{WholeWord: true}, wantPredicate: `WHERE content ~\* \$1`, wantArg: `\mc\+\+\M`},
+		{name: "caseSensitiveWholeWord", options: database.SearchOptions{CaseSensitive: true, WholeWord: true}, wantPredicate: `WHERE content ~ \$1`, wantArg: `\mc\+\+\M`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := []models.MarkdownContent{
+				{
+					Model:  models.Model{ID: 7, CreatedAt: createdAt, UpdatedAt: updatedAt},
+					MetaID: 3,
+					Meta: models.MarkdownMeta{
+						Model:     models.Model{ID: 3, CreatedAt: createdAt, UpdatedAt: updatedAt},
+						Name:      "Languages",
+						Path:      "markdowns/01_Intro",
+						CharCount: 16,
+					},
+					Content: "c++ and go rock",
+				},
+			}
+
+			sqlMock.ExpectQuery("SELECT .* FROM markdown_contents mc JOIN markdown_meta mm ON mm.id = mc.meta_id "+tt.wantPredicate+" AND NOT \\(path \\|\\| '/' \\|\\| name LIKE").
+				WithArgs(tt.wantArg, "markdowns", 0).
+				WillReturnRows(sqlMock.
+					NewRows([]string{
+						"meta_id", "meta_created_at", "meta_updated_at", "name", "path", "char_count",
+						"content_id", "content_created_at", "content_updated_at", "content",
+					}).
+					AddRow(3, createdAt, updatedAt, "Languages", "markdowns/01_Intro", 16, 7, createdAt, updatedAt, "c++ and go rock"))
+
+			var got []models.MarkdownContent
+			err := env.FindMarkdownsBySearchTermWithOptions(context.Background(), "c++", tt.options, &got)
+			if err != nil {
+				t.Fatalf("FindMarkdownsBySearchTermWithOptions error: %v", err)
+			}
+
+			if !cmp.Equal(want, got) {
+				t.Error(cmp.Diff(want, got))
+				return
+			}
+
+			if err := sqlMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %v", err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_FindMarkdownsBySearchTerms_noTerms(t *testing.T) {
+	var got []models.MarkdownContent
+	err := env.FindMarkdownsBySearchTerms(context.Background(), nil, true, "", &got)
+	if err != nil {
+		t.Fatalf("FindMarkdownsBySearchTerms error: %v", err)
+	}
+
+	if len(got) > 0 {
+		t.Errorf("want no matches without search terms, got %v", got)
+		return
+	}
+}
+
+func TestGormRepository_FindMarkdownsBySearchTermRanked(t *testing.T) {
+	createdAt := parseTime("2025-01-01 10:00:00.000000 +00:00")
+	updatedAt := parseTime("2025-01-02 10:00:00.000000 +00:00")
+
+	want := []models.ScoredMarkdownContent{
+		{
+			MarkdownContent: models.MarkdownContent{
+				Model:  models.Model{ID: 7, CreatedAt: createdAt, UpdatedAt: updatedAt},
+				MetaID: 3,
+				Meta: models.MarkdownMeta{
+					Model:     models.Model{ID: 3, CreatedAt: createdAt, UpdatedAt: updatedAt},
+					Name:      "Getting-Started",
+					Path:      "markdowns/01_Intro",
+					CharCount: 5,
+				},
+				Content: "hello",
+			},
+			Similarity: 1,
+		},
+		{
+			MarkdownContent: models.MarkdownContent{
+				Model:  models.Model{ID: 8, CreatedAt: createdAt, UpdatedAt: updatedAt},
+				MetaID: 4,
+				Meta: models.MarkdownMeta{
+					Model:     models.Model{ID: 4, CreatedAt: createdAt, UpdatedAt: updatedAt},
+					Name:      "Advanced",
+					Path:      "markdowns/02_Advanced",
+					CharCount: 11,
+				},
+				Content: "hello world",
+			},
+			Similarity: 0.5,
+		},
+	}
+
+	sqlMock.ExpectQuery("SELECT .* similarity\\(mc\\.content, \\$1\\) AS similarity .* ORDER BY similarity DESC, mc\\.id").
+		WithArgs("hello", "hello", "markdowns", 0).
+		WillReturnRows(sqlMock.
+			NewRows([]string{
+				"meta_id", "meta_created_at", "meta_updated_at", "name", "path", "char_count",
+				"content_id", "content_created_at", "content_updated_at", "content", "similarity",
+			}).
+			AddRow(3, createdAt, updatedAt, "Getting-Started", "markdowns/01_Intro", 5, 7, createdAt, updatedAt, "hello", 1.0).
+			AddRow(4, createdAt, updatedAt, "Advanced", "markdowns/02_Advanced", 11, 8, createdAt, updatedAt, "hello world", 0.5))
+
+	var got []models.ScoredMarkdownContent
+	err := env.FindMarkdownsBySearchTermRanked(context.Background(), "hello", "", &got)
+	if err != nil {
+		t.Fatalf("FindMarkdownsBySearchTermRanked error: %v", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGormRepository_FindMarkdownsByFullText(t *testing.T) {
+	createdAt := parseTime("2025-01-01 10:00:00.000000 +00:00")
+	updatedAt := parseTime("2025-01-02 10:00:00.000000 +00:00")
+
+	want := []models.ScoredMarkdownContent{
+		{
+			MarkdownContent: models.MarkdownContent{
+				Model:  models.Model{ID: 7, CreatedAt: createdAt, UpdatedAt: updatedAt},
+				MetaID: 3,
+				Meta: models.MarkdownMeta{
+					Model:     models.Model{ID: 3, CreatedAt: createdAt, UpdatedAt: updatedAt},
+					Name:      "Retries",
+					Path:      "markdowns/Kafka",
+					CharCount: 20,
+				},
+				Content: "retrying the consumer",
+			},
+			Similarity: 0.09,
+		},
+	}
+
+	sqlMock.ExpectQuery("SELECT .* ts_rank\\(mc\\.content_tsv, plainto_tsquery\\('english', \\$1\\), 32\\) AS similarity\\s+"+
+		"FROM markdown_contents mc\\s+JOIN markdown_meta mm ON mm\\.id = mc\\.meta_id\\s+"+
+		"WHERE mc\\.content_tsv @@ plainto_tsquery\\('english', \\$2\\)\\s+AND NOT \\(path \\|\\| '/' \\|\\| name LIKE \\$3 .* AND char_count >= \\$4\\s+"+
+		"ORDER BY similarity DESC, mc\\.id$").
+		WithArgs("retry consumers", "retry consumers", "markdowns", 0).
+		WillReturnRows(sqlMock.
+			NewRows([]string{
+				"meta_id", "meta_created_at", "meta_updated_at", "name", "path", "char_count",
+				"content_id", "content_created_at", "content_updated_at", "content", "similarity",
+			}).
+			AddRow(3, createdAt, updatedAt, "Retries", "markdowns/Kafka", 20, 7, createdAt, updatedAt, "retrying the consumer", 0.09))
+
+	var got []models.ScoredMarkdownContent
+	err := env.FindMarkdownsByFullText(context.Background(), "retry consumers", "", &got)
+	if err != nil {
+		t.Fatalf("FindMarkdownsByFullText error: %v", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGormRepository_FindMarkdownsBySearchTermPaged(t *testing.T) {
+	tests := []struct {
+		name       string
+		pageNumber int
+		pageSize   int
+		wantLimit  int
+		wantOffset int
+	}{
+		{name: "firstPage", pageNumber: 1, pageSize: 10, wantLimit: 10, wantOffset: 0},
+		{name: "thirdPage", pageNumber: 3, pageSize: 10, wantLimit: 10, wantOffset: 20},
+		{name: "zeroPageNumber", pageNumber: 0, pageSize: 5, wantLimit: 5, wantOffset: 0},
+		{name: "negativePageNumber", pageNumber: -2, pageSize: 5, wantLimit: 5, wantOffset: 0},
+		{name: "zeroPageSize", pageNumber: 2, pageSize: 0, wantLimit: 1, wantOffset: 1},
+		{name: "overflowingOffset", pageNumber: 4611686018427387904, pageSize: 4, wantLimit: 4, wantOffset: math.MaxInt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlMock.ExpectQuery("SELECT .* AND similarity\\(mc\\.content, \\$5\\) >= \\$6 ORDER BY similarity DESC, mc\\.id LIMIT \\$7 OFFSET \\$8").
+				WithArgs("hello", "hello", "markdowns", 0, "hello", 0.3, tt.wantLimit, tt.wantOffset).
+				WillReturnRows(sqlMock.
+					NewRows([]string{"content_id", "content", "similarity"}).
+					AddRow(7, "hello", 1.0))
+
+			var got []models.ScoredMarkdownContent
+			err := env.FindMarkdownsBySearchTermPaged(context.Background(), "hello", "", 0.3, tt.pageNumber, tt.pageSize, &got)
+			if err != nil {
+				t.Fatalf("FindMarkdownsBySearchTermPaged error: %v", err)
+			}
+
+			if err := sqlMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+				return
+			}
+
+			if len(got) != 1 || got[0].ID != 7 || got[0].Similarity != 1 {
+				t.Errorf("want the scanned match, got %+v", got)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_CountMarkdownsMatchesBySearchTermRanked(t *testing.T) {
+	sqlMock.ExpectQuery("SELECT count\\(\\*\\) .* AND similarity\\(mc\\.content, \\$4\\) >= \\$5").
+		WithArgs("hello", "markdowns", 0, "hello", 0.3).
+		WillReturnRows(sqlMock.NewRows([]string{"count"}).AddRow(4))
+
+	var got int
+	err := env.CountMarkdownsMatchesBySearchTermRanked(context.Background(), "hello", "", 0.3, &got)
+	if err != nil {
+		t.Fatalf("CountMarkdownsMatchesBySearchTermRanked error: %v", err)
+	}
+
+	if got != 4 {
+		t.Errorf("want count 4, got %d", got)
+		return
+	}
+}
+
+func TestGormRepository_FindMarkdownDocumentsPaged(t *testing.T) {
+	visible := regexp.QuoteMeta(` AND NOT (path || '/' || name LIKE $1 || '/.%')`)
+	updatedAt := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		options    database.DocumentListOptions
+		pageNumber int
+		pageSize   int
+		wantQuery  string
+		wantArgs   []driver.Value
+	}{
+		{
+			name:       "byNameExcludingHidden",
+			options:    database.DocumentListOptions{SortBy: database.DocumentSortName},
+			pageNumber: 1,
+			pageSize:   10,
+			wantQuery:  "WHERE mm.deleted_at IS NULL AND mc.deleted_at IS NULL" + visible + "\\s+ORDER BY mm.name ASC, mm.path, mm.name\\s+LIMIT \\$2 OFFSET \\$3$",
+			wantArgs:   []driver.Value{"markdowns", 10, 0},
+		},
+		{
+			name:       "byUpdatedAtDescending",
+			options:    database.DocumentListOptions{SortBy: database.DocumentSortUpdatedAt, Descending: true},
+			pageNumber: 3,
+			pageSize:   5,
+			wantQuery:  visible + "\\s+ORDER BY mc.updated_at DESC, mm.path, mm.name\\s+LIMIT \\$2 OFFSET \\$3$",
+			wantArgs:   []driver.Value{"markdowns", 5, 10},
+		},
+		{
+			name:       "includingHidden",
+			options:    database.DocumentListOptions{IncludeHidden: true},
+			pageNumber: 2,
+			pageSize:   5,
+			wantQuery:  "WHERE mm.deleted_at IS NULL AND mc.deleted_at IS NULL\\s+ORDER BY mm.name ASC, mm.path, mm.name\\s+LIMIT \\$1 OFFSET \\$2$",
+			wantArgs:   []driver.Value{5, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sqlMock.ExpectQuery("SELECT mm.name AS name, mm.path AS path, mm.char_count AS char_count, mc.updated_at AS updated_at\\s+" +
+				"FROM markdown_meta mm\\s+JOIN markdown_contents mc ON mc.meta_id = mm.id\\s+.*" + tt.wantQuery).
+				WithArgs(tt.wantArgs...).
+				WillReturnRows(sqlMock.NewRows([]string{"name", "path", "char_count", "updated_at"}).AddRow("Guide", "markdowns/Docs", 42, updatedAt))
+
+			var got []models.MarkdownDocument
+			if err := env.FindMarkdownDocumentsPaged(context.Background(), tt.options, tt.pageNumber, tt.pageSize, &got); err != nil {
+				t.Fatalf("FindMarkdownDocumentsPaged error: %v", err)
+			}
+
+			if err := sqlMock.ExpectationsWereMet(); err != nil {
+				t.Errorf("unmet expectations: %v", err)
+				return
+			}
+
+			want := []models.MarkdownDocument{{Name: "Guide", Path: "markdowns/Docs", CharCount: 42, UpdatedAt: updatedAt}}
+			if !cmp.Equal(want, got) {
+				t.Error(cmp.Diff(want, got))
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_CountMarkdownDocuments(t *testing.T) {
+	sqlMock.ExpectQuery("SELECT count\\(\\*\\)\\s+FROM markdown_meta mm\\s+JOIN markdown_contents mc ON mc.meta_id = mm.id\\s+" +
+		"WHERE mm.deleted_at IS NULL AND mc.deleted_at IS NULL AND NOT \\(path \\|\\| '/' \\|\\| name LIKE \\$1").
+		WithArgs("markdowns").
+		WillReturnRows(sqlMock.NewRows([]string{"count"}).AddRow(12))
+
+	var got int
+	if err := env.CountMarkdownDocuments(context.Background(), false, &got); err != nil {
+		t.Fatalf("CountMarkdownDocuments error: %v", err)
+	}
+
+	if got != 12 {
+		t.Errorf("want count 12, got %d", got)
+		return
+	}
+}
+
+func TestGormRepository_CountAllMarkdowns(t *testing.T) {
+	// a single aggregate over the markdown_meta that are not soft-deleted; the hidden files are the ones excluded from the searches
+	sqlMock.ExpectQuery("^SELECT count\\(\\*\\) AS documents, .*coalesce\\(sum\\(char_count\\), 0\\) AS total_chars, " +
+		".*count\\(\\*\\) FILTER \\(WHERE NOT \\(" + regexp.QuoteMeta(`NOT (path || '/' || name LIKE $1 || '/.%')`) + "\\)\\) AS hidden, " +
+		".*count\\(\\*\\) FILTER \\(WHERE char_count = 0\\) AS empty\\s+FROM markdown_meta\\s+WHERE deleted_at IS NULL$").
+		WithArgs("markdowns").
+		WillReturnRows(sqlMock.NewRows([]string{"documents", "total_chars", "hidden", "empty"}).AddRow(12, 34567, 2, 1))
+
+	var got models.MarkdownStats
+	if err := env.CountAllMarkdowns(context.Background(), &got); err != nil {
+		t.Fatalf("CountAllMarkdowns error: %v", err)
+	}
+
+	want := models.MarkdownStats{Documents: 12, TotalChars: 34567, Hidden: 2, Empty: 1}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
 func TestGormRepository_UpsertMarkdownMetas(t *testing.T) {
 	want := []models.MarkdownMeta{
 		{Model: models.Model{ID: 3, CreatedAt: parseTime("2025-05-27 10:06:56.823450 +00:00"), UpdatedAt: parseTime("2025-06-18 09:22:38.894670 +00:00")}, Name: "1-Onboarding", Path: "markdowns/Gateway", CharCount: 1234},
@@ -334,7 +1174,8 @@ func TestGormRepository_UpsertMarkdownMetas(t *testing.T) {
 	}
 
 	sqlMock.ExpectBegin()
-	sqlMock.ExpectQuery("^INSERT INTO \"markdown_meta\" \\(\"created_at\",\"updated_at\",\"name\",\"path\",\"char_count\",\"id\"\\) VALUES .* ON CONFLICT \\(\"name\"\\) DO UPDATE SET .* RETURNING \"id\"").
+	// a soft-deleted meta reappearing in the source is restored
+	sqlMock.ExpectQuery("^INSERT INTO \"markdown_meta\" \\(\"created_at\",\"updated_at\",\"deleted_at\",\"name\",\"path\",\"char_count\",\"id\"\\) VALUES .* ON CONFLICT \\(\"path\",\"name\"\\) DO UPDATE SET .*\"deleted_at\"=\"excluded\"\\.\"deleted_at\".* RETURNING \"id\"").
 		WithArgs(args...).
 		WillReturnRows(rows)
 	sqlMock.ExpectCommit()
@@ -345,10 +1186,106 @@ func TestGormRepository_UpsertMarkdownMetas(t *testing.T) {
 	}
 }
 
+func TestGormRepository_WithTransaction(t *testing.T) {
+	tests := []struct {
+		name              string
+		upsertContentsErr error
+	}{
+		{name: "commit", upsertContentsErr: nil},
+		{name: "rollback", upsertContentsErr: errors.New("value too long for type character varying")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+			if err != nil {
+				t.Fatalf("initMockedDatabase error: %v", err)
+			}
+			defer sqlDb.Close()
+
+			repo := &database.GormRepository{DB: mockedGormDb}
+
+			// all statements are sent within a single transaction
+			mock.ExpectBegin()
+			mock.ExpectExec("^UPDATE \"markdown_meta\" SET \"deleted_at\"=\\$1 WHERE \"markdown_meta\"\\.\"id\" = \\$2").
+				WithArgs(sqlmock.AnyArg(), 7).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("^INSERT INTO \"markdown_meta\" .* ON CONFLICT \\(\"path\",\"name\"\\) DO UPDATE SET .* RETURNING \"id\"").
+				WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+			contentInsert := mock.ExpectQuery("^INSERT INTO \"markdown_contents\" .* ON CONFLICT \\(\"meta_id\"\\) DO UPDATE SET .* RETURNING \"id\"")
+			if tt.upsertContentsErr != nil {
+				contentInsert.WillReturnError(tt.upsertContentsErr)
+				mock.ExpectRollback()
+			} else {
+				contentInsert.WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+				mock.ExpectCommit()
+			}
+
+			err = repo.WithTransaction(context.Background(), func(ctx context.Context) error {
+				if err := repo.DeleteMarkdownMetasByIds(ctx, []uint{7}); err != nil {
+					return err
+				}
+				if err := repo.UpsertMarkdownMetas(ctx, []models.MarkdownMeta{{Name: "Onboarding", Path: "markdowns"}}); err != nil {
+					return err
+				}
+				return repo.UpsertMarkdownContents(ctx, []models.MarkdownContent{{MetaID: 1, Content: "# Onboarding"}})
+			})
+			if !errors.Is(err, tt.upsertContentsErr) {
+				t.Errorf("want error %v, got %v", tt.upsertContentsErr, err)
+				return
+			}
+
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+				return
+			}
+		})
+	}
+}
+
+func TestGormRepository_UpsertMarkdownMetas_inBatches(t *testing.T) {
+	mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+	if err != nil {
+		t.Fatalf("initMockedDatabase error: %v", err)
+	}
+	defer sqlDb.Close()
+
+	repo := &database.GormRepository{DB: mockedGormDb, UpsertBatchSize: 2}
+
+	metas := []models.MarkdownMeta{
+		{Name: "1-Onboarding", Path: "markdowns/Gateway"},
+		{Name: "2-Data-Preparation", Path: "markdowns/Gateway"},
+		{Name: "3-Visualization", Path: "markdowns/Gateway"},
+		{Name: "4-Technical-Docs", Path: "markdowns/Gateway"},
+		{Name: "5-OpenTelemetry", Path: "markdowns/Gateway"},
+	}
+
+	// 5 rows with a batch size of 2 result in 3 INSERTs within a single transaction
+	mock.ExpectBegin()
+	for id := 1; id <= 5; id += 2 {
+		rows := sqlmock.NewRows([]string{"id"}).AddRow(id)
+		if id < 5 {
+			rows.AddRow(id + 1)
+		}
+		mock.ExpectQuery("^INSERT INTO \"markdown_meta\" .* ON CONFLICT \\(\"path\",\"name\"\\) DO UPDATE SET .* RETURNING \"id\"").
+			WillReturnRows(rows)
+	}
+	mock.ExpectCommit()
+
+	if err := repo.UpsertMarkdownMetas(context.Background(), metas); err != nil {
+		t.Fatalf("UpsertMarkdownMetas error: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
 func flattenMarkdownMetas(metas []models.MarkdownMeta) []driver.Value {
 	args := make([]driver.Value, 0, len(metas))
 	for _, m := range metas {
-		args = append(args, m.CreatedAt, m.UpdatedAt, m.Name, m.Path, m.CharCount, m.ID)
+		args = append(args, m.CreatedAt, m.UpdatedAt, nil, m.Name, m.Path, m.CharCount, m.ID)
 	}
 
 	return args
@@ -360,6 +1297,7 @@ func TestGormRepository_UpsertMarkdownContents(t *testing.T) {
 			Model:   models.Model{ID: 1, CreatedAt: time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2025, 6, 18, 9, 0, 0, 0, time.UTC)},
 			Content: "# Introduction\nThis is the intro content.",
 			MetaID:  3,
+			Hash:    "3f0b7c3c",
 		},
 		{
 			Model:   models.Model{ID: 2, CreatedAt: time.Date(2025, 6, 2, 10, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2025, 6, 18, 9, 5, 0, 0, time.UTC)},
@@ -405,7 +1343,7 @@ func TestGormRepository_UpsertMarkdownContents(t *testing.T) {
 	}
 
 	sqlMock.ExpectBegin()
-	sqlMock.ExpectQuery("^INSERT INTO \"markdown_contents\" \\(\"created_at\",\"updated_at\",\"content\",\"meta_id\",\"id\"\\) VALUES .* ON CONFLICT \\(\"meta_id\"\\) DO UPDATE SET .*").
+	sqlMock.ExpectQuery("^INSERT INTO \"markdown_contents\" \\(\"created_at\",\"updated_at\",\"deleted_at\",\"content\",\"meta_id\",\"hash\",\"id\"\\) VALUES .* ON CONFLICT \\(\"meta_id\"\\) DO UPDATE SET .*\"deleted_at\"=\"excluded\"\\.\"deleted_at\"").
 		WithArgs(args...).
 		WillReturnRows(rows)
 	sqlMock.ExpectCommit()
@@ -416,10 +1354,73 @@ func TestGormRepository_UpsertMarkdownContents(t *testing.T) {
 	}
 }
 
+func TestGormRepository_FindMarkdownContentHashes(t *testing.T) {
+	want := []models.MarkdownContentHash{
+		{Name: "Getting-Started", Path: "markdowns", Hash: "3f0b7c3c"},
+		{Name: "Setup", Path: "markdowns/Gateway", Hash: "a1d2e3f4"},
+	}
+
+	rows := sqlmock.NewRows([]string{"name", "path", "hash"})
+	for _, h := range want {
+		rows.AddRow(h.Name, h.Path, h.Hash)
+	}
+
+	// the hashes of soft-deleted contents are excluded, so that the content of a restored meta is restored, too
+	sqlMock.ExpectQuery("SELECT mm.name AS name, mm.path AS path, mc.hash AS hash FROM markdown_contents mc JOIN markdown_meta mm ON mm.id = mc.meta_id WHERE mm.deleted_at IS NULL AND mc.deleted_at IS NULL").
+		WillReturnRows(rows)
+
+	var got []models.MarkdownContentHash
+	err := env.FindMarkdownContentHashes(context.Background(), &got)
+	if err != nil {
+		t.Fatalf("FindMarkdownContentHashes error: %v", err)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGormRepository_PurgeSoftDeletedMarkdowns(t *testing.T) {
+	mockedGormDb, sqlDb, mock, err := initMockedDatabase()
+	if err != nil {
+		t.Fatalf("initMockedDatabase error: %v", err)
+	}
+	defer sqlDb.Close()
+
+	repo := &database.GormRepository{DB: mockedGormDb}
+	deletedBefore := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// the contents are purged first since they reference their metas; both within a single transaction
+	mock.ExpectBegin()
+	mock.ExpectExec("^DELETE FROM markdown_contents WHERE deleted_at < \\$1 OR meta_id IN \\(SELECT id FROM markdown_meta WHERE deleted_at < \\$2\\)").
+		WithArgs(deletedBefore, deletedBefore).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("^DELETE FROM markdown_meta WHERE deleted_at < \\$1").
+		WithArgs(deletedBefore).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	var purged int64
+	if err := repo.PurgeSoftDeletedMarkdowns(context.Background(), deletedBefore, &purged); err != nil {
+		t.Fatalf("PurgeSoftDeletedMarkdowns error: %v", err)
+	}
+
+	if purged != 3 {
+		t.Errorf("want 3 purged metas, got %d", purged)
+		return
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+		return
+	}
+}
+
 func flattenMarkdownContents(contents []models.MarkdownContent) []driver.Value {
 	args := make([]driver.Value, 0, len(contents))
 	for _, c := range contents {
-		args = append(args, c.CreatedAt, c.UpdatedAt, c.Content, c.MetaID, c.ID)
+		args = append(args, c.CreatedAt, c.UpdatedAt, nil, c.Content, c.MetaID, c.Hash, c.ID)
 	}
 
 	return args
@@ -444,6 +1445,15 @@ func TestNullRepository_DeleteMarkdownContentsByIds(t *testing.T) {
 	}
 }
 
+func TestNullRepository_Ping(t *testing.T) {
+	repo := &database.NullRepository{}
+	err := repo.Ping(context.Background())
+	if err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+		return
+	}
+}
+
 func TestNullRepository_FindUserLoginCredentials(t *testing.T) {
 	repo := &database.NullRepository{}
 	var user models.User
@@ -454,6 +1464,15 @@ func TestNullRepository_FindUserLoginCredentials(t *testing.T) {
 	}
 }
 
+func TestNullRepository_CreateUser(t *testing.T) {
+	repo := &database.NullRepository{}
+	err := repo.CreateUser(context.Background(), &models.User{Username: "testuser"})
+	if err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+		return
+	}
+}
+
 func TestNullRepository_FindAllMarkdownMetas(t *testing.T) {
 	repo := &database.NullRepository{}
 	var markdownMetas []models.MarkdownMeta
@@ -484,6 +1503,36 @@ func TestNullRepository_FindMarkdownContentIdsByMetaIds(t *testing.T) {
 	}
 }
 
+func TestNullRepository_FindMarkdownsBySearchTermRanked(t *testing.T) {
+	repo := &database.NullRepository{}
+	var markdowns []models.ScoredMarkdownContent
+	err := repo.FindMarkdownsBySearchTermRanked(context.Background(), "test", "", &markdowns)
+	if err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+		return
+	}
+}
+
+func TestNullRepository_FindMarkdownsBySearchTermPaged(t *testing.T) {
+	repo := &database.NullRepository{}
+	var markdowns []models.ScoredMarkdownContent
+	err := repo.FindMarkdownsBySearchTermPaged(context.Background(), "test", "", 0, 1, 5, &markdowns)
+	if err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+		return
+	}
+}
+
+func TestNullRepository_FindMarkdownDocumentsPaged(t *testing.T) {
+	repo := &database.NullRepository{}
+	var documents []models.MarkdownDocument
+	err := repo.FindMarkdownDocumentsPaged(context.Background(), database.DocumentListOptions{}, 1, 5, &documents)
+	if err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+		return
+	}
+}
+
 func TestNullRepository_UpsertMarkdownMetas(t *testing.T) {
 	repo := &database.NullRepository{}
 	err := repo.UpsertMarkdownMetas(context.Background(), []models.MarkdownMeta{})
@@ -501,3 +1550,13 @@ func TestNullRepository_UpsertMarkdownContents(t *testing.T) {
 		return
 	}
 }
+
+func TestNullRepository_PurgeSoftDeletedMarkdowns(t *testing.T) {
+	repo := &database.NullRepository{}
+	var purged int64
+	err := repo.PurgeSoftDeletedMarkdowns(context.Background(), time.Now(), &purged)
+	if err != nil {
+		t.Errorf("Expected nil error, got %v", err)
+		return
+	}
+}
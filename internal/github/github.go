@@ -0,0 +1,255 @@
+package github
+
+import (
+	"context"
+	"dice-sorensen-similarity-search/internal/bitbucket"
+	"dice-sorensen-similarity-search/internal/config"
+	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/logging"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultBaseUrl is the base URL of the GitHub REST API if no other (e.g. GitHub Enterprise) URL is configured
+const DefaultBaseUrl = "https://api.github.com"
+
+// defaultRevision selects the repository's default branch
+const defaultRevision = "HEAD"
+
+// GitHubReader is an implementation of bitbucket.BitbucketReader that reads the Markdown files from a GitHub repository
+// using the GitHub REST API.
+//
+// The project name passed to its methods is the owner (user or organization) of the repository.
+type GitHubReader struct {
+	*environment.Env
+	Client *http.Client
+
+	// BaseUrl is the base URL of the GitHub REST API (default: https://api.github.com)
+	BaseUrl string
+	// AccessToken is sent as bearer token; it is optional for public repositories
+	AccessToken string
+	// DocsRoot is the root-level folder containing the Markdown files (default: markdowns)
+	DocsRoot string
+	// Extensions are the file extensions of Markdown files (default: .md)
+	Extensions []string
+}
+
+// ensure GitHubReader implements bitbucket.BitbucketReader
+var _ bitbucket.BitbucketReader = &GitHubReader{}
+
+// treeResponse is the response of GET /repos/{owner}/{repo}/git/trees/{tree_sha}
+type treeResponse struct {
+	Tree []struct {
+		Path string `json:"path"`
+		Type string `json:"type"`
+	} `json:"tree"`
+	Truncated bool `json:"truncated"`
+}
+
+// contentResponse is an element of the response of GET /repos/{owner}/{repo}/contents/{path} for a folder
+type contentResponse struct {
+	Name string `json:"name"`
+}
+
+func (ghr *GitHubReader) docsRoot() string {
+	if len(ghr.DocsRoot) == 0 {
+		return constants.DefaultDocsRoot
+	}
+
+	return ghr.DocsRoot
+}
+
+func (ghr *GitHubReader) baseUrl() string {
+	if len(ghr.BaseUrl) == 0 {
+		return DefaultBaseUrl
+	}
+
+	return strings.TrimSuffix(ghr.BaseUrl, "/")
+}
+
+// ReadMarkdownFileStructureRecursively reads the repository's tree at the given revision (see ReadFileContentAtRevision)
+// in a single request, returning the paths of all Markdown files located under the root-level docs root directory (e.g., `markdowns/`).
+// Only files with one of the Extensions (default: .md) are included; other files are skipped and logged at debug level.
+//
+// The GitHub API does not paginate trees; hence, start and limit are ignored.
+func (ghr *GitHubReader) ReadMarkdownFileStructureRecursively(ctx context.Context, projectName, repoName, revision string, _, _ int) ([]string, error) {
+	ref := shortRef(revision)
+	if len(ref) == 0 {
+		ref = defaultRevision
+	}
+
+	var tree treeResponse
+	err := ghr.getJson(ctx, repoPath(projectName, repoName, "git", "trees", ref)+"?recursive=1", &tree)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file structure from GitHub: %w", err)
+	}
+
+	if tree.Truncated {
+		ghr.LogWarn(nil, "GitHub truncated the file structure; some Markdown files may be missing")
+	}
+
+	docsRootPrefix := ghr.docsRoot() + "/"
+
+	var filePaths []string
+	for _, entry := range tree.Tree {
+		if entry.Type != "blob" || !strings.HasPrefix(entry.Path, docsRootPrefix) {
+			continue
+		}
+
+		if !bitbucket.HasMarkdownExtension(entry.Path, ghr.Extensions) {
+			ghr.LogDebugf(nil, "skipping %s: file extension is not markdown", entry.Path)
+			continue
+		}
+
+		filePaths = append(filePaths, entry.Path)
+	}
+
+	return filePaths, nil
+}
+
+// ReadRepoRootFolderContent returns the names of all direct children (files and folders) of the repository's root folder
+func (ghr *GitHubReader) ReadRepoRootFolderContent(ctx context.Context, projectName, repoName string) ([]string, error) {
+	var contents []contentResponse
+	err := ghr.getJson(ctx, repoPath(projectName, repoName, "contents")+"/", &contents)
+	if err != nil {
+		return nil, fmt.Errorf("error reading root folder from GitHub: %w", err)
+	}
+
+	rootFolderChildren := make([]string, 0, len(contents))
+	for _, v := range contents {
+		rootFolderChildren = append(rootFolderChildren, v.Name)
+	}
+
+	return rootFolderChildren, nil
+}
+
+// ReadFileContentAtRevision retrieves the raw contents of the specified file at a given revision.
+//
+// Note: fully qualified branch and tag refs (e.g. refs/tags/v1.0.0) are shortened because GitHub expects a branch name,
+// tag name, or commit hash; if the revision is empty, the file is read from the repository's default branch.
+func (ghr *GitHubReader) ReadFileContentAtRevision(ctx context.Context, projectName, repoName, filePath, revision string) (string, error) {
+	requestPath := repoPath(projectName, repoName, "contents") + "/" + escapePath(filePath)
+	if ref := shortRef(revision); len(ref) > 0 {
+		requestPath += "?ref=" + url.QueryEscape(ref)
+	}
+
+	body, err := ghr.get(ctx, requestPath, "application/vnd.github.raw+json")
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// getJson sends a GET request to the given path of the GitHub API and decodes the JSON response into v
+func (ghr *GitHubReader) getJson(ctx context.Context, requestPath string, v any) error {
+	body, err := ghr.get(ctx, requestPath, "application/vnd.github+json")
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+// get sends a GET request to the given path of the GitHub API; responses other than 200 are returned as errors.
+// The request is canceled once the context is done
+func (ghr *GitHubReader) get(ctx context.Context, requestPath, accept string) ([]byte, error) {
+	if ghr.Client == nil {
+		return nil, fmt.Errorf("GitHub API not initialized")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ghr.baseUrl()+requestPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "cim-api")
+	if len(ghr.AccessToken) > 0 {
+		req.Header.Set("Authorization", "Bearer "+ghr.AccessToken)
+	}
+
+	res, err := ghr.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API responded with %d to GET %s", res.StatusCode, requestPath)
+	}
+
+	return body, nil
+}
+
+// repoPath returns the (escaped) API path of the given repository followed by the given path segments
+func repoPath(owner, repo string, segments ...string) string {
+	p := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	for _, s := range segments {
+		p += "/" + url.PathEscape(s)
+	}
+
+	return p
+}
+
+// escapePath escapes the segments of a slash-separated file path
+func escapePath(filePath string) string {
+	segments := strings.Split(filePath, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// shortRef strips the prefix of fully qualified branch and tag refs
+func shortRef(revision string) string {
+	for _, prefix := range []string{"refs/heads/", "refs/tags/"} {
+		if strings.HasPrefix(revision, prefix) {
+			return strings.TrimPrefix(revision, prefix)
+		}
+	}
+
+	return revision
+}
+
+// InitGitHub creates an instance of *GitHubReader with the provided configuration;
+// the given context bounds the request checking the repository's access
+func InitGitHub(ctx context.Context, c *config.Configuration, env *environment.Env) (*GitHubReader, error) {
+	env.LogInfo(logging.GetLogTypeInitialization(), "initializing GitHub API")
+
+	if len(c.GitHub.Owner) == 0 || len(c.GitHub.Repository) == 0 {
+		return nil, fmt.Errorf("github owner or repository is not set")
+	}
+
+	reader := &GitHubReader{
+		Env:         env,
+		Client:      &http.Client{Timeout: 30 * time.Second},
+		AccessToken: c.GitHub.AccessToken,
+		DocsRoot:    c.BitBucket.DocsRoot,
+		Extensions:  c.BitBucket.Extensions,
+	}
+	if c.GitHub.Url != nil {
+		reader.BaseUrl = c.GitHub.Url.String()
+	}
+
+	_, err := reader.ReadRepoRootFolderContent(ctx, c.GitHub.Owner, c.GitHub.Repository)
+	if err != nil {
+		return nil, err
+	}
+
+	env.LogDebug(logging.GetLogTypeInitialization(), "GitHub API initialized")
+
+	return reader, nil
+}
@@ -0,0 +1,216 @@
+package github_test
+
+import (
+	"context"
+	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/github"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// mockTransport answers requests with the response registered for their URL (path and query); other requests get a 404
+type mockTransport struct {
+	responses map[string]string
+	requests  []*http.Request
+}
+
+func (m *mockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	m.requests = append(m.requests, req)
+
+	key := req.URL.EscapedPath()
+	if len(req.URL.RawQuery) > 0 {
+		key += "?" + req.URL.RawQuery
+	}
+
+	body, ok := m.responses[key]
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusNotFound
+		body = `{"message":"Not Found"}`
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+		Request:    req,
+	}, nil
+}
+
+func newMockReader(transport *mockTransport) *github.GitHubReader {
+	return &github.GitHubReader{
+		Env:         environment.Null(),
+		Client:      &http.Client{Transport: transport},
+		BaseUrl:     "https://github.example.com/api/v3/",
+		AccessToken: "token",
+	}
+}
+
+func TestGitHubReader_ReadMarkdownFileStructureRecursively(t *testing.T) {
+	transport := &mockTransport{responses: map[string]string{
+		"/api/v3/repos/octo/docs/git/trees/HEAD?recursive=1": `{
+			"tree": [
+				{"path": "README.md", "type": "blob"},
+				{"path": "markdowns", "type": "tree"},
+				{"path": "markdowns/Gateway", "type": "tree"},
+				{"path": "markdowns/Gateway/1-Onboarding.md", "type": "blob"},
+				{"path": "markdowns/Getting-Started.md", "type": "blob"},
+				{"path": "markdowns-archive/Old.md", "type": "blob"}
+			],
+			"truncated": false
+		}`,
+	}}
+
+	got, err := newMockReader(transport).ReadMarkdownFileStructureRecursively(context.Background(), "octo", "docs", "", 0, 150)
+	if err != nil {
+		t.Fatalf("ReadMarkdownFileStructureRecursively error: %v", err)
+	}
+
+	want := []string{"markdowns/Gateway/1-Onboarding.md", "markdowns/Getting-Started.md"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+
+	req := transport.requests[0]
+	if got := req.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("want the access token to be sent as bearer token, got %q", got)
+		return
+	}
+}
+
+func TestGitHubReader_ReadMarkdownFileStructureRecursively_revision(t *testing.T) {
+	transport := &mockTransport{responses: map[string]string{
+		"/api/v3/repos/octo/docs/git/trees/HEAD?recursive=1":    `{"tree": [{"path": "markdowns/Default.md", "type": "blob"}]}`,
+		"/api/v3/repos/octo/docs/git/trees/v1.0.0?recursive=1":  `{"tree": [{"path": "markdowns/Tag.md", "type": "blob"}]}`,
+		"/api/v3/repos/octo/docs/git/trees/a1b2c3d?recursive=1": `{"tree": [{"path": "markdowns/Commit.md", "type": "blob"}]}`,
+	}}
+
+	tests := []struct {
+		name     string
+		revision string
+		want     []string
+	}{
+		{name: "defaultBranch", revision: "", want: []string{"markdowns/Default.md"}},
+		{name: "tag", revision: "refs/tags/v1.0.0", want: []string{"markdowns/Tag.md"}},
+		{name: "commit", revision: "a1b2c3d", want: []string{"markdowns/Commit.md"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newMockReader(transport).ReadMarkdownFileStructureRecursively(context.Background(), "octo", "docs", tt.revision, 0, 150)
+			if err != nil {
+				t.Fatalf("ReadMarkdownFileStructureRecursively error: %v", err)
+			}
+
+			if !cmp.Equal(tt.want, got) {
+				t.Error(cmp.Diff(tt.want, got))
+				return
+			}
+		})
+	}
+}
+
+func TestGitHubReader_ReadMarkdownFileStructureRecursively_extensions(t *testing.T) {
+	transport := &mockTransport{responses: map[string]string{
+		"/api/v3/repos/octo/docs/git/trees/HEAD?recursive=1": `{
+			"tree": [
+				{"path": "markdowns/1-Onboarding.md", "type": "blob"},
+				{"path": "markdowns/2-Data-Preparation.mdx", "type": "blob"},
+				{"path": "markdowns/3-Visualization.markdown", "type": "blob"},
+				{"path": "markdowns/diagram.png", "type": "blob"}
+			]
+		}`,
+	}}
+
+	reader := newMockReader(transport)
+	reader.Extensions = []string{".mdx", ".markdown"}
+
+	got, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "octo", "docs", "", 0, 150)
+	if err != nil {
+		t.Fatalf("ReadMarkdownFileStructureRecursively error: %v", err)
+	}
+
+	want := []string{"markdowns/2-Data-Preparation.mdx", "markdowns/3-Visualization.markdown"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGitHubReader_ReadRepoRootFolderContent(t *testing.T) {
+	transport := &mockTransport{responses: map[string]string{
+		"/api/v3/repos/octo/docs/contents/": `[{"name": "README.md", "type": "file"}, {"name": "markdowns", "type": "dir"}]`,
+	}}
+
+	got, err := newMockReader(transport).ReadRepoRootFolderContent(context.Background(), "octo", "docs")
+	if err != nil {
+		t.Fatalf("ReadRepoRootFolderContent error: %v", err)
+	}
+
+	want := []string{"README.md", "markdowns"}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGitHubReader_ReadFileContentAtRevision(t *testing.T) {
+	transport := &mockTransport{responses: map[string]string{
+		"/api/v3/repos/octo/docs/contents/markdowns/Getting%20Started.md":              "# Default branch",
+		"/api/v3/repos/octo/docs/contents/markdowns/Getting%20Started.md?ref=v1.0.0":   "# Tag",
+		"/api/v3/repos/octo/docs/contents/markdowns/Getting%20Started.md?ref=a1b2c3d":  "# Commit",
+		"/api/v3/repos/octo/docs/contents/markdowns/Getting%20Started.md?ref=release1": "# Branch",
+	}}
+
+	tests := []struct {
+		name     string
+		revision string
+		want     string
+	}{
+		{name: "defaultBranch", revision: "", want: "# Default branch"},
+		{name: "tag", revision: "refs/tags/v1.0.0", want: "# Tag"},
+		{name: "branch", revision: "refs/heads/release1", want: "# Branch"},
+		{name: "commit", revision: "a1b2c3d", want: "# Commit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newMockReader(transport).ReadFileContentAtRevision(context.Background(), "octo", "docs", "markdowns/Getting Started.md", tt.revision)
+			if err != nil {
+				t.Fatalf("ReadFileContentAtRevision error: %v", err)
+			}
+
+			if got != tt.want {
+				t.Errorf("want %q, got %q", tt.want, got)
+				return
+			}
+
+			req := transport.requests[len(transport.requests)-1]
+			if got := req.Header.Get("Accept"); got != "application/vnd.github.raw+json" {
+				t.Errorf("want the raw content to be requested, got Accept %q", got)
+				return
+			}
+		})
+	}
+}
+
+func TestGitHubReader_ReadFileContentAtRevision_notFound(t *testing.T) {
+	_, err := newMockReader(&mockTransport{}).ReadFileContentAtRevision(context.Background(), "octo", "docs", "markdowns/missing.md", "")
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("want an error mentioning the status 404, got %v", err)
+		return
+	}
+}
+
+func TestGitHubReader_notInitialized(t *testing.T) {
+	reader := &github.GitHubReader{Env: environment.Null()}
+
+	if _, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "octo", "docs", "", 0, 150); err == nil {
+		t.Error("want an error without an HTTP client")
+		return
+	}
+}
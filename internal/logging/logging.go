@@ -6,10 +6,12 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"maps"
 	"moul.io/zapgorm2"
 	"os"
 	"runtime"
 	"strings"
+	"sync/atomic"
 )
 
 type Logger interface {
@@ -35,9 +37,122 @@ type NullLogger struct{}
 // ensure NullLogger implements Logger
 var _ Logger = &NullLogger{}
 
+// level and consoleLevel are shared by all loggers, so that SetLevels changes them without re-creating the loggers
+var (
+	level        = zap.NewAtomicLevel()
+	consoleLevel = zap.NewAtomicLevel()
+)
+
+// subTypeLevels maps the subtypes of GetLogType to the levels overriding level and consoleLevel for their log lines;
+// it is shared by all loggers likewise
+var subTypeLevels atomic.Pointer[map[string]zapcore.Level]
+
+// SetLevels sets the log levels of all loggers to the configured ones
+func SetLevels(c *config.Configuration) {
+	level.SetLevel(c.Logging.Level)
+	consoleLevel.SetLevel(c.Logging.ConsoleLogLevel)
+
+	levels := maps.Clone(c.Logging.SubTypeLevels)
+	subTypeLevels.Store(&levels)
+}
+
+// subTypeLevel returns the level configured for the subtype, if any
+func subTypeLevel(subType string) (zapcore.Level, bool) {
+	levels := subTypeLevels.Load()
+	if levels == nil || len(subType) == 0 {
+		return zapcore.InvalidLevel, false
+	}
+
+	l, ok := (*levels)[subType]
+	return l, ok
+}
+
+// lowestSubTypeLevel returns the lowest of the levels configured for the subtypes, if any
+func lowestSubTypeLevel() (zapcore.Level, bool) {
+	levels := subTypeLevels.Load()
+	if levels == nil || len(*levels) == 0 {
+		return zapcore.InvalidLevel, false
+	}
+
+	lowest := zapcore.FatalLevel
+	for _, l := range *levels {
+		lowest = min(lowest, l)
+	}
+	return lowest, true
+}
+
+// WithSubTypeLevels wraps the core, so that the log lines carrying a "subType" (see GetLogType) are filtered
+// by the level configured for the subtype in Logging.SubTypeLevels instead of the core's level.
+// The log lines of all other subtypes are filtered by the core's level as before
+func WithSubTypeLevels(core zapcore.Core) zapcore.Core {
+	return &subTypeLevelCore{Core: core}
+}
+
+type subTypeLevelCore struct {
+	zapcore.Core
+	// subType is the subtype added by With, if any
+	subType string
+}
+
+// Enabled lets the log lines pass that are enabled by the core or by the level of any subtype;
+// since the fields are not known yet, Write filters them by their subtype
+func (c *subTypeLevelCore) Enabled(l zapcore.Level) bool {
+	if c.Core.Enabled(l) {
+		return true
+	}
+
+	lowest, ok := lowestSubTypeLevel()
+	return ok && lowest.Enabled(l)
+}
+
+func (c *subTypeLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	subType := c.subType
+	if s, ok := subTypeOf(fields); ok {
+		subType = s
+	}
+
+	return &subTypeLevelCore{Core: c.Core.With(fields), subType: subType}
+}
+
+func (c *subTypeLevelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *subTypeLevelCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	subType := c.subType
+	if s, ok := subTypeOf(fields); ok {
+		subType = s
+	}
+
+	enabled := c.Core.Enabled(ent.Level)
+	if l, ok := subTypeLevel(subType); ok {
+		enabled = l.Enabled(ent.Level)
+	}
+
+	if !enabled {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+// subTypeOf returns the value of the "subType" field, if any
+func subTypeOf(fields []zapcore.Field) (string, bool) {
+	for _, f := range fields {
+		if f.Key == "subType" && f.Type == zapcore.StringType {
+			return f.String, true
+		}
+	}
+	return "", false
+}
+
 func InitLogging(c *config.Configuration) *DefaultLogger {
 	var core zapcore.Core
 
+	SetLevels(c)
+
 	consoleEncoderCfg := zap.NewProductionEncoderConfig()
 	consoleEncoderCfg.EncodeTime = zapcore.RFC3339TimeEncoder
 	consoleEncoderCfg.EncodeLevel = zapcore.CapitalColorLevelEncoder
@@ -56,24 +171,24 @@ func InitLogging(c *config.Configuration) *DefaultLogger {
 		})
 		// if logfile is defined: log errors to console and configured log level to file
 		core = zapcore.NewTee(
-			zapcore.NewCore(
+			WithSubTypeLevels(zapcore.NewCore(
 				zapcore.NewConsoleEncoder(consoleEncoderCfg),
 				consoleWriteSyncer,
-				c.Logging.ConsoleLogLevel,
-			),
-			zapcore.NewCore(
+				consoleLevel,
+			)),
+			WithSubTypeLevels(zapcore.NewCore(
 				zapcore.NewJSONEncoder(fileEncoderCfg),
 				fileWriteSyncer,
-				c.Logging.Level,
-			),
+				level,
+			)),
 		)
 	} else {
 		// log configured log level to console
-		core = zapcore.NewCore(
+		core = WithSubTypeLevels(zapcore.NewCore(
 			zapcore.NewConsoleEncoder(consoleEncoderCfg),
 			consoleWriteSyncer,
-			c.Logging.Level,
-		)
+			level,
+		))
 	}
 
 	zapLogger := zap.New(core)
@@ -101,7 +216,7 @@ func InitGinLogger(c *config.Configuration) *zap.Logger {
 	ginCore := zapcore.NewCore(
 		zapcore.NewJSONEncoder(fileEncoderCfg),
 		ginW,
-		c.Logging.Level,
+		level,
 	)
 
 	ginLogger := zap.New(ginCore)
@@ -128,7 +243,7 @@ func InitGormLogger(c *config.Configuration) *zapgorm2.Logger {
 	gormCore := zapcore.NewCore(
 		zapcore.NewJSONEncoder(encoderCfg),
 		gormW,
-		c.Logging.Level,
+		level,
 	)
 	zapGormLogger := zap.New(gormCore)
 
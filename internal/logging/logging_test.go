@@ -0,0 +1,80 @@
+package logging_test
+
+import (
+	"dice-sorensen-similarity-search/internal/config"
+	"dice-sorensen-similarity-search/internal/logging"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+)
+
+func TestWithSubTypeLevels(t *testing.T) {
+	c := &config.Configuration{}
+	c.Logging.Level = zap.InfoLevel
+	c.Logging.SubTypeLevels = map[string]zapcore.Level{
+		"search":    zap.DebugLevel,
+		"bitbucket": zap.WarnLevel,
+	}
+	logging.SetLevels(c)
+	defer logging.SetLevels(&config.Configuration{})
+
+	core, logs := observer.New(zap.InfoLevel)
+	logger := logging.DefaultLogger{Logger: zap.New(logging.WithSubTypeLevels(core)).Sugar()}
+
+	logger.LogDebug(logging.GetLogType("search"), "search debug")
+	logger.LogDebug(logging.GetLogType("bitbucket"), "bitbucket debug")
+	logger.LogInfo(logging.GetLogType("bitbucket"), "bitbucket info")
+	logger.LogWarn(logging.GetLogType("bitbucket"), "bitbucket warn")
+	logger.LogDebug(logging.GetLogType("initialization"), "initialization debug")
+	logger.LogInfo(logging.GetLogType("initialization"), "initialization info")
+	logger.LogDebugf(nil, "%s debug", "untyped")
+	logger.LogInfof(nil, "%s info", "untyped")
+
+	// the subtypes without override keep the core's level
+	want := []string{"search debug", "bitbucket warn", "initialization info", "untyped info"}
+
+	var got []string
+	for _, entry := range logs.All() {
+		got = append(got, entry.Message)
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestWithSubTypeLevels_subTypeAddedByWith(t *testing.T) {
+	c := &config.Configuration{}
+	c.Logging.SubTypeLevels = map[string]zapcore.Level{"search": zap.DebugLevel}
+	logging.SetLevels(c)
+	defer logging.SetLevels(&config.Configuration{})
+
+	core, logs := observer.New(zap.InfoLevel)
+	zapLogger := zap.New(logging.WithSubTypeLevels(core)).Sugar()
+
+	logging.DefaultLogger{Logger: zapLogger.With(logging.GetLogType("search")...)}.LogDebug(nil, "search debug")
+	logging.DefaultLogger{Logger: zapLogger}.LogDebug(nil, "untyped debug")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "search debug" {
+		t.Errorf("want only the search debug line, got %v", logs.All())
+		return
+	}
+}
+
+func TestWithSubTypeLevels_noOverrides(t *testing.T) {
+	logging.SetLevels(&config.Configuration{})
+
+	core, logs := observer.New(zap.InfoLevel)
+	logger := logging.DefaultLogger{Logger: zap.New(logging.WithSubTypeLevels(core)).Sugar()}
+
+	logger.LogDebug(logging.GetLogType("search"), "search debug")
+	logger.LogInfo(logging.GetLogType("search"), "search info")
+
+	if logs.Len() != 1 || logs.All()[0].Message != "search info" {
+		t.Errorf("want only the search info line, got %v", logs.All())
+		return
+	}
+}
@@ -1,11 +1,15 @@
 package logging
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"sync"
 )
 
+// correlationIdKey is the context key of the correlation ID of a request
+type correlationIdKey struct{}
+
 var ProgressMapMutex sync.RWMutex
 var currentProgressIds = make(map[string]string)
 
@@ -60,3 +64,41 @@ func GetLogTypeInitialization() []any {
 func GetLogTypeIntervalTask() []interface{} {
 	return GetLogType("intervaltask")
 }
+
+// ContextWithCorrelationId returns a copy of ctx carrying the given correlation ID
+func ContextWithCorrelationId(ctx context.Context, correlationId string) context.Context {
+	return context.WithValue(ctx, correlationIdKey{}, correlationId)
+}
+
+// CorrelationIdFromContext returns the correlation ID carried by ctx, or an empty string if there is none
+func CorrelationIdFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	correlationId, _ := ctx.Value(correlationIdKey{}).(string)
+	return correlationId
+}
+
+// GetLogTypeWithContext works like GetLogType but adds the correlation ID carried by ctx (e.g. the request ID),
+// so that all log lines written while serving a request can be correlated.
+// An explicitly supplied correlation ID takes precedence
+func GetLogTypeWithContext(ctx context.Context, logType ...string) []any {
+	var temp []any
+	if len(logType) > 0 {
+		temp = GetLogType(logType...)
+	}
+
+	correlationId := CorrelationIdFromContext(ctx)
+	if len(correlationId) == 0 {
+		return temp
+	}
+
+	for i := 0; i < len(temp); i += 2 {
+		if temp[i] == "correlationId" {
+			return temp
+		}
+	}
+
+	return append(temp, "correlationId", correlationId)
+}
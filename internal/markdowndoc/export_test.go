@@ -0,0 +1,21 @@
+package markdowndoc
+
+import (
+	"dice-sorensen-similarity-search/internal/models"
+	"time"
+)
+
+// exports unexported identifiers for the tests of the package markdowndoc_test
+var ExtractSnippet = extractSnippet
+var MapToMarkdownSearchPage = MarkdownSearchMatchMapper.mapToMarkdownSearchPage
+var TruncateSimilarityInput = truncateSimilarityInput
+
+// SetNow replaces the clock of the SearchCache for the tests of the package markdowndoc_test
+func (sc *SearchCache) SetNow(now func() time.Time) {
+	sc.now = now
+}
+
+// ContentTrigrams exposes the trigrams the Controller ranks a content by to the tests of the package markdowndoc_test
+func (hc *Controller) ContentTrigrams(content models.MarkdownContent) []string {
+	return hc.contentTrigrams(content)
+}
@@ -1,6 +1,8 @@
 package markdowndoc
 
 import (
+	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/models"
@@ -8,15 +10,142 @@ import (
 	"fmt"
 	"github.com/samborkent/uuidv7"
 	"golang.org/x/text/collate"
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+	"math"
+	"net/url"
 	"regexp"
 	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 type MarkdownSearchPayload struct {
 	Term     string
 	Pageable Pageable
+	// MinSimilarity excludes matches whose similarity is below the given value; it must be within [0,1]
+	MinSimilarity float64
+	// Mode selects how the space-separated words of the term are matched (default: SearchModePhrase);
+	// a term wrapped in double quotes is always matched as a phrase
+	Mode SearchMode
+	// CaseSensitive matches the term case-sensitively; WholeWord only matches the term as a whole word (e.g., "cat" does not match "category").
+	// Both are only supported by the phrase mode of the simple search engine; if neither is set, the term is matched as before (LIKE)
+	CaseSensitive bool
+	WholeWord     bool
+	// PathPrefix restricts the search to the folder subtree below the docs root starting with the prefix (e.g. Gateway)
+	// in every mode, search engine, and search backend
+	PathPrefix string
+	// Highlights adds the offsets of the term's occurrences within each match's snippet (see MarkdownSearchMatch.Highlights),
+	// so that front-ends can style the occurrences themselves
+	Highlights bool
+	// Scorer selects a registered Scorer (see RegisterScorer) the matches are ranked by instead of the configured one;
+	// it is only supported by the simple search engine
+	Scorer string
+}
+
+// hasSearchOptions reports whether the payload asks for a case-sensitive or whole-word match
+func (p MarkdownSearchPayload) hasSearchOptions() bool {
+	return p.CaseSensitive || p.WholeWord
+}
+
+func (p MarkdownSearchPayload) searchOptions() database.SearchOptions {
+	return database.SearchOptions{CaseSensitive: p.CaseSensitive, WholeWord: p.WholeWord, PathPrefix: p.PathPrefix}
+}
+
+// SearchMode selects how the words of a search term are matched against the Markdown contents
+type SearchMode string
+
+const (
+	// SearchModePhrase matches the contents containing the term as a whole
+	SearchModePhrase SearchMode = "PHRASE"
+	// SearchModeAll matches the contents containing all words of the term
+	SearchModeAll SearchMode = "AND"
+	// SearchModeAny matches the contents containing any word of the term
+	SearchModeAny SearchMode = "OR"
+)
+
+// unquotePhrase strips the double quotes of a term wrapped in them; quoted is false if the term is not wrapped in quotes
+func unquotePhrase(term string) (phrase string, quoted bool) {
+	trimmed := strings.TrimSpace(term)
+	if len(trimmed) < 2 || !strings.HasPrefix(trimmed, `"`) || !strings.HasSuffix(trimmed, `"`) {
+		return term, false
+	}
+
+	return strings.TrimSpace(trimmed[1 : len(trimmed)-1]), true
+}
+
+// searchTerms splits the given term into its space-separated words (see SearchModeAll and SearchModeAny);
+// a double-quoted phrase is kept as one word without its quotes, and an unterminated phrase extends to the end of the term
+func searchTerms(term string) []string {
+	var terms []string
+	for i, part := range strings.Split(term, `"`) {
+		if i%2 == 0 {
+			terms = append(terms, strings.Fields(part)...)
+			continue
+		}
+
+		if phrase := strings.TrimSpace(part); len(phrase) > 0 {
+			terms = append(terms, phrase)
+		}
+	}
+
+	return terms
+}
+
+// searchMode normalizes the given mode; an empty mode is SearchModePhrase
+func searchMode(m SearchMode) (SearchMode, error) {
+	mode := SearchMode(strings.ToUpper(strings.TrimSpace(string(m))))
+	switch mode {
+	case "":
+		return SearchModePhrase, nil
+	case SearchModePhrase, SearchModeAll, SearchModeAny:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown search mode %q; must be %q, %q, or %q", m, SearchModePhrase, SearchModeAll, SearchModeAny)
+	}
+}
+
+// SimilarityPayload holds the two strings whose similarity is computed
+type SimilarityPayload struct {
+	A string `json:"a"`
+	B string `json:"b"`
+}
+
+// SimilarityResponse holds the Sorensen-Dice similarity of two strings together with
+// the unique trigrams of each string (for debugging)
+type SimilarityResponse struct {
+	Similarity float64  `json:"similarity"`
+	TrigramsA  []string `json:"trigramsA"`
+	TrigramsB  []string `json:"trigramsB"`
+}
+
+// NavigationSearchPayload holds the query the navigation items are fuzzy-matched against
+type NavigationSearchPayload struct {
+	Query string `json:"query"`
+	// Limit is the maximum number of returned matches (default: DefaultMaxNavigationMatches)
+	Limit int `json:"limit"`
+}
+
+// NavigationSearchMatch is a navigation item (a folder or a Markdown file) matching the query of a navigation search
+type NavigationSearchMatch struct {
+	Href       string `json:"href"`
+	Label      string `json:"label"`
+	ParentHref string `json:"parentHref"`
+	// Folder is set if the item has children; otherwise, it is a Markdown file
+	Folder     bool    `json:"folder"`
+	Similarity float64 `json:"similarity"`
+}
+
+// RelatedDocument references a Markdown file whose content is similar to the one of another Markdown file (see GetRelatedDocuments)
+type RelatedDocument struct {
+	Name       string  `json:"name"`
+	Path       string  `json:"path"`
+	Similarity float64 `json:"similarity"`
 }
 
 type MarkdownSearchMatch struct {
@@ -27,6 +156,28 @@ type MarkdownSearchMatch struct {
 	MatchingText    string `json:"matchingText"`
 	TextBeforeMatch string `json:"textBeforeMatch"`
 	TextAfterMatch  string `json:"textAfterMatch"`
+	// UpdatedAt is the time the content of the Markdown file was written last, i.e., the sync that found it new or changed;
+	// the meta's timestamp is not used since the metas are re-written on every sync
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Highlights are the (case-insensitive) occurrences of the term within the snippet, i.e., TextBeforeMatch + MatchingText + TextAfterMatch;
+	// they are only present if requested (see MarkdownSearchPayload.Highlights)
+	Highlights []Highlight `json:"highlights,omitempty"`
+	// Debug is only present if requested (see GetMarkdownSearchTermMatches)
+	Debug *SearchMatchDebug `json:"debug,omitempty"`
+}
+
+// Highlight is an occurrence of the search term within a snippet; Start (inclusive) and End (exclusive) are rune offsets, not byte offsets
+type Highlight struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// SearchMatchDebug holds diagnostics for tuning the relevance of search matches
+type SearchMatchDebug struct {
+	QueryTrigramCount   int `json:"queryTrigramCount"`
+	ContentTrigramCount int `json:"contentTrigramCount"`
+	// IntersectionCount is the number of trigrams the query and the content have in common
+	IntersectionCount int `json:"intersectionCount"`
 }
 
 type Page[T any] struct {
@@ -34,6 +185,48 @@ type Page[T any] struct {
 	TotalPages    int      `json:"totalPages"`
 	Content       []T      `json:"content"`
 	Pageable      Pageable `json:"pageable"`
+
+	// NumberOfElements is the number of elements of this page (i.e., the length of Content)
+	NumberOfElements int `json:"numberOfElements"`
+	// First and Last tell whether this is the first or the last page; an empty result (TotalPages 0) is both
+	First bool `json:"first"`
+	Last  bool `json:"last"`
+	// HasNext and HasPrevious tell whether there is a page after or before this one
+	HasNext     bool `json:"hasNext"`
+	HasPrevious bool `json:"hasPrevious"`
+
+	// Suggestions are the names of the Markdown files closest to the search term if it matched few files ("did you mean")
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// PageSummary is the metadata of a page without its content, e.g., the last line of a page streamed as newline-delimited JSON
+// (see MimeTypeNDJSON)
+type PageSummary struct {
+	TotalElements int      `json:"totalElements"`
+	TotalPages    int      `json:"totalPages"`
+	Pageable      Pageable `json:"pageable"`
+	HasNext       bool     `json:"hasNext"`
+	HasPrevious   bool     `json:"hasPrevious"`
+}
+
+// summary returns the metadata of the page without its content
+func (p *Page[T]) summary() PageSummary {
+	return PageSummary{
+		TotalElements: p.TotalElements,
+		TotalPages:    p.TotalPages,
+		Pageable:      p.Pageable,
+		HasNext:       p.HasNext,
+		HasPrevious:   p.HasPrevious,
+	}
+}
+
+// setNavigation populates the fields of the page that are computed from its (1-based) page number and TotalPages
+func (p *Page[T]) setNavigation() {
+	p.NumberOfElements = len(p.Content)
+	p.HasPrevious = p.Pageable.PageNumber > 1
+	p.HasNext = p.Pageable.PageNumber < p.TotalPages
+	p.First = !p.HasPrevious
+	p.Last = !p.HasNext
 }
 
 type Pageable struct {
@@ -42,6 +235,64 @@ type Pageable struct {
 	Sort       Sort `json:"sort"`
 }
 
+// DefaultPageSize is the page size used if a Pageable has no (positive) page size and no (positive) default is configured
+const DefaultPageSize = 5
+
+// DefaultMaxPageSize is the largest page size allowed if no (positive) maximum is configured
+const DefaultMaxPageSize = 100
+
+// Normalize defaults a missing page size to defaultPageSize (DefaultPageSize if not positive), clamps the page size
+// to maxPageSize (DefaultMaxPageSize if not positive), and floors the page number at 1.
+// A negative page size is rejected
+func (p *Pageable) Normalize(defaultPageSize, maxPageSize int) error {
+	if p.PageSize < 0 {
+		return fmt.Errorf("the page size (%d) must not be negative", p.PageSize)
+	}
+
+	if defaultPageSize <= 0 {
+		defaultPageSize = DefaultPageSize
+	}
+	if maxPageSize <= 0 {
+		maxPageSize = DefaultMaxPageSize
+	}
+
+	if p.PageSize == 0 {
+		p.PageSize = defaultPageSize
+	}
+	p.PageSize = min(p.PageSize, maxPageSize)
+
+	p.PageNumber = max(p.PageNumber, 1)
+
+	// the offset of the page, (pageNumber-1)*pageSize, must not overflow
+	if p.PageNumber-1 > math.MaxInt/p.PageSize {
+		return fmt.Errorf("the page number (%d) is too large for the page size (%d)", p.PageNumber, p.PageSize)
+	}
+
+	return nil
+}
+
+// overrideFromQuery replaces the page number and the page size by the query parameters pageNumber and pageSize if present,
+// e.g., if a client follows a link of the Link header of a search response
+func (p *Pageable) overrideFromQuery(query url.Values) error {
+	if query.Has("pageNumber") {
+		pageNumber, err := strconv.Atoi(query.Get("pageNumber"))
+		if err != nil {
+			return fmt.Errorf("the page number (%s) is not an integer", query.Get("pageNumber"))
+		}
+		p.PageNumber = pageNumber
+	}
+
+	if query.Has("pageSize") {
+		pageSize, err := strconv.Atoi(query.Get("pageSize"))
+		if err != nil {
+			return fmt.Errorf("the page size (%s) is not an integer", query.Get("pageSize"))
+		}
+		p.PageSize = pageSize
+	}
+
+	return nil
+}
+
 type Sort struct {
 	defaultDirection Direction `json:"-"`
 	Orders           []Order   `json:"orders"`
@@ -73,15 +324,46 @@ type NavigationItem struct {
 	Label    string            `json:"label"`
 	Parent   *NavigationItem   `json:"-"`
 	Children []*NavigationItem `json:"children"`
+
+	// ParentHref is the Href of Parent (empty for roots); unlike Parent, it can be serialized without a cycle
+	ParentHref string `json:"parentHref"`
+	// HasChildren is only set by PruneNavigationItemTrees: it tells whether the item has children, even if they were
+	// pruned (i.e., Children is empty); the pruned children can be fetched with the tree of the item's root
+	HasChildren bool `json:"hasChildren,omitempty"`
 }
 
 type NavigationItemTreeService struct {
 	*environment.Env
 	*collate.Collator
+
+	// DocsRoot is the root-level folder containing the Markdown files (default: markdowns)
+	DocsRoot string
+	// MaxDepth is the maximum depth of a navigation item (roots have depth 1); Markdown files beyond it are dropped.
+	// If it is not positive, the depth is unlimited.
+	MaxDepth int
+	// CollapseSingleChild merges a folder with its sole child if that child is a folder, too (e.g., "A/B/C" instead of A > B > C)
+	CollapseSingleChild bool
+	// LandingPageNames are the names of the top-level Markdown files excluded from the navigation since the frontend
+	// renders them separately (default: DefaultLandingPageName)
+	LandingPageNames []string
 }
 
+// DefaultLandingPageName is the name of the top-level Markdown file excluded from the navigation
+// if no LandingPageNames are configured
+const DefaultLandingPageName = "Landing-Page"
+
+// DefaultSnippetWindow is the number of characters shown before and after a search match
+// if no (positive) SnippetWindow is configured
+const DefaultSnippetWindow = 80
+
 type MarkdownSearchMatchMapper struct {
 	*environment.Env
+
+	// SnippetWindow is the number of characters shown before and after a search match
+	SnippetWindow int
+	// MaxSnippetLength is the maximum number of characters of a snippet, i.e., TextBeforeMatch + MatchingText + TextAfterMatch;
+	// longer snippets are truncated with an ellipsis (see capSnippet). If it is not positive, the length is unlimited.
+	MaxSnippetLength int
 }
 
 // itemTreesLister implements the interface [collate.Lister]
@@ -132,7 +414,7 @@ func (l itemTreesLister) Bytes(i int) []byte {
 
 // BuildNavigationItemTrees constructs a hierarchical navigation structure from a slice of MarkdownMeta objects.
 //
-// It parses metadata paths, removes the "markdowns/" prefix, and assembles nested navigation trees,
+// It parses metadata paths, removes the docs root prefix (e.g., "markdowns/"), and assembles nested navigation trees,
 // linking items by parent-child relationships.
 // The function ensures correct parent-child relationships and links items under the same parent.
 //
@@ -142,72 +424,70 @@ func (l itemTreesLister) Bytes(i int) []byte {
 // @Return A slice containing the root navigation items with their complete tree structure.
 func (n NavigationItemTreeService) BuildNavigationItemTrees(markdownMetas []models.MarkdownMeta) []*NavigationItem {
 
-	var rootNavigationItems []*NavigationItem
+	index := navigationItemIndex{itemsByPath: make(map[string]*NavigationItem)}
 
+	docsRoot := n.docsRoot()
+	landingPageNames := n.landingPageNames()
 	for _, v := range markdownMetas {
+		// e.g., "markdowns/Gateway/" or "markdowns//Gateway" would otherwise result in folders with an empty Href
+		v.Path = normalizePath(v.Path)
 		if len(v.Path) <= 0 {
 			n.LogErrorf(nil, fmt.Sprintf("markdown meta %s has empty path", v.Name))
 			continue
 		}
 
 		pathElements := strings.Split(v.Path, "/")
-		if !strings.HasPrefix(pathElements[0], "markdowns") {
+		if pathElements[0] != docsRoot {
 			continue
 		}
 
-		// Top-level Markdowns (i.e., Markdown files residing under the markdowns/ folder in Bitbucket)
-		// must be added directly to the root navigation items since their Path is "markdowns".
+		// skip hidden folders and top-level files (including their children)
+		if v.IsHidden(docsRoot) {
+			n.LogDebugf(nil, "skip processing hidden element: %s/%s", v.Path, v.Name)
+			continue
+		}
+
+		// Top-level Markdowns (i.e., Markdown files residing under the docs root folder (e.g., markdowns/) in Bitbucket)
+		// must be added directly to the root navigation items since their Path is the docs root (e.g., "markdowns").
 		// In other words, splitting and truncating their Path won't work
-		// as for Markdown files residing under some folder that is beneath the folder markdowns/
+		// as for Markdown files residing under some folder that is beneath the docs root folder
 		if len(pathElements) == 1 {
 			// The landing page needs special handling in the frontend.
 			// Therefore, it should not be part of the side navigation items.
-			if v.Name == "Landing-Page" {
+			if slices.Contains(landingPageNames, v.Name) {
 				n.LogDebugf(nil, "skip processing top-level element: %s", v.Name)
 				continue
 			}
 
 			n.LogDebugf(nil, "processing top-level element w/o children: %s", v.Name)
 
-			root := NavigationItem{
-				Uuid:  uuidv7.New().String(),
-				Label: strings.ReplaceAll(v.Name, "_", " "),
-				Href:  v.Name,
-			}
-			rootNavigationItems = append(rootNavigationItems, &root)
+			index.child("", v.Name)
 
 			continue
 		}
 
-		// removes "markdowns" from path elements (this is only supposed for non-top-level files)
+		// removes the docs root (e.g., "markdowns") from path elements (this is only supposed for non-top-level files)
 		pathElements = pathElements[1:]
 
-		parent := NavigationItem{
-			Uuid:  uuidv7.New().String(),
-			Label: strings.ReplaceAll(pathElements[0], "_", " "),
-			Href:  pathElements[0],
+		// the Markdown file is one level below its folder
+		if n.MaxDepth > 0 && len(pathElements)+1 > n.MaxDepth {
+			n.LogWarnf(logging.GetLogType("markdown-doc"), "dropping markdown %s since its path %s exceeds the maximum navigation depth of %d", v.Name, v.Path, n.MaxDepth)
+			continue
 		}
 
-		bottomToRootTree := n.createNavItemTree(pathElements[1:], &parent)
-
-		bottomMostNavItem := NavigationItem{
-			Uuid:  uuidv7.New().String(),
-			Label: strings.ReplaceAll(v.Name, "_", " "),
-			Href:  v.Name,
+		// links the Markdown file to its folders; folders shared with previous Markdown files are reused
+		var itemPath string
+		for _, pathElement := range pathElements {
+			_, itemPath = index.child(itemPath, pathElement)
 		}
-
-		bottomToRootTree.Children = append(bottomToRootTree.Children, &bottomMostNavItem)
-
-		root := n.findRoot(bottomToRootTree)
-
-		rootNavigationItems = append(rootNavigationItems, root)
+		index.child(itemPath, v.Name)
 	}
 
-	rootNavigationItems = n.linkChildrenWithTheSameParent(rootNavigationItems)
+	rootNavigationItems := index.roots
+	sortNavigationItemsByHref(rootNavigationItems)
 
-	visibleRootNavigationItems := n.removeDotPrefixedRoots(rootNavigationItems)
-	if visibleRootNavigationItems != nil {
-		rootNavigationItems = visibleRootNavigationItems
+	if n.CollapseSingleChild {
+		collapseSingleChildFolders(rootNavigationItems)
 	}
 
 	// sort roots based on top-level Href
@@ -216,157 +496,217 @@ func (n NavigationItemTreeService) BuildNavigationItemTrees(markdownMetas []mode
 
 	n.removeNumberPrefixFromRoots(rootNavigationItems)
 
+	// must run last since the roots' Hrefs are final only after their number prefixes were removed
+	setParentHrefs(rootNavigationItems, "")
+
 	return rootNavigationItems
 }
 
-// createNavItemTree recursively assembles a navigation tree from a sequence of path elements.
+// normalizePath removes the leading and trailing slashes of the given path and collapses consecutive slashes into one,
+// so that splitting the path by slashes results in no empty path elements.
 //
-// It constructs parent-child relationships by creating a new NavigationItem for each path element.
-// While descending from the provided parent node, the recursion continues until all path elements are processed.
+// For example:
 //
-// ID createNavTreeBranch
-// Summary Build a branch of the navigation tree
-// Param pathElements body []string true "Path components"
-// Param navItem body NavigationItem true "Parent navigation item"
-// Return The bottom-most child containing a recursive parent tree (from bottom to top)
-func (n NavigationItemTreeService) createNavItemTree(pathElements []string, navItem *NavigationItem) *NavigationItem {
+//	Input:  "markdowns//Gateway/"
+//	Output: "markdowns/Gateway"
+func normalizePath(path string) string {
+	return strings.Join(strings.FieldsFunc(path, func(r rune) bool { return r == '/' }), "/")
+}
 
-	// early return in case the path has only one element
-	if len(pathElements) == 0 && navItem.Parent == nil {
-		return navItem
+// navigationItemIndex indexes the navigation items by their path below the docs root (e.g., "Gateway/Visualization"),
+// so that BuildNavigationItemTrees links the items of the same folder in a single pass (instead of merging whole trees per level)
+type navigationItemIndex struct {
+	itemsByPath map[string]*NavigationItem
+	roots       []*NavigationItem
+}
+
+// child returns the navigation item with the given Href beneath the item with the given parent path (roots have an empty parent path),
+// and the path of the returned item.
+// The item is created and appended to its parent's children (or to the roots) on first use; later calls return the same item.
+func (idx *navigationItemIndex) child(parentPath, href string) (*NavigationItem, string) {
+	path := href
+	if len(parentPath) > 0 {
+		path = parentPath + "/" + href
+	}
+
+	if item, ok := idx.itemsByPath[path]; ok {
+		return item, path
 	}
 
-	if len(pathElements) == 0 {
-		return navItem
+	item := &NavigationItem{
+		Uuid:  uuidv7.New().String(),
+		Label: strings.ReplaceAll(href, "_", " "),
+		Href:  href,
 	}
+	idx.itemsByPath[path] = item
 
-	child := &NavigationItem{
-		Uuid:   uuidv7.New().String(),
-		Label:  strings.ReplaceAll(pathElements[0], "_", " "),
-		Href:   pathElements[0],
-		Parent: navItem,
+	if len(parentPath) == 0 {
+		idx.roots = append(idx.roots, item)
+		return item, path
 	}
 
-	navItem.Children = append(navItem.Children, child)
+	// the parent was indexed before since the path elements are processed from top to bottom
+	parent := idx.itemsByPath[parentPath]
+	item.Parent = parent
+	parent.Children = append(parent.Children, item)
 
-	return n.createNavItemTree(pathElements[1:], child)
+	return item, path
 }
 
-// findRoot traverses upward in a navigation tree to find the root element
-//
-// This function follows parent pointers recursively until it finds the top-most parent with no parent.
-//
-// ID findNavigationRoot
-// Summary Traverse a navigation item tree to find its root
-// Param navItemPtr body NavigationItem true "Starting navigation node"
-// Return The root element of the navigation tree
-func (n NavigationItemTreeService) findRoot(navItemPtr *NavigationItem) *NavigationItem {
-	if navItemPtr.Parent == nil {
-		return navItemPtr
-	}
+// sortNavigationItemsByHref sorts the given navigation items and (recursively) their children by Href
+func sortNavigationItemsByHref(navigationItems []*NavigationItem) {
+	sort.Slice(navigationItems, func(a, b int) bool {
+		return strings.Compare(navigationItems[a].Href, navigationItems[b].Href) == -1
+	})
 
-	return n.findRoot(navItemPtr.Parent)
+	for _, item := range navigationItems {
+		sortNavigationItemsByHref(item.Children)
+	}
 }
 
-// linkChildrenWithTheSameParent merges navigation trees that share the same parent Href
-// eliminating duplicates.
-//
-// It ensures that navigation items with identical Href values are combined under a single parent,
-// eliminating redundant entries while preserving hierarchical relationships.
-//
-// ID linkSiblingNavigationItems
-// Summary Merge navigation items with the same parent into unified subtrees
-// Param navigationItemTrees body []NavigationItem true "Navigation trees to merge"
-// Return A consolidated list of root navigation items with children correctly linked
-func (n NavigationItemTreeService) linkChildrenWithTheSameParent(navigationItemTrees []*NavigationItem) []*NavigationItem {
+// collapseSingleChildFolders merges each folder with its sole child as long as that child is a folder, too;
+// the merged node keeps the folder's Uuid and Parent and combines the Hrefs and Labels (e.g., "A/B" and "A / B").
+// Leaves (i.e., Markdown files) are never merged.
+func collapseSingleChildFolders(navigationItems []*NavigationItem) {
+	for _, item := range navigationItems {
+		for len(item.Children) == 1 && len(item.Children[0].Children) > 0 {
+			child := item.Children[0]
+
+			item.Href = item.Href + "/" + child.Href
+			item.Label = item.Label + " / " + child.Label
+			item.Children = child.Children
+
+			for _, grandchild := range item.Children {
+				grandchild.Parent = item
+			}
+		}
 
-	if len(navigationItemTrees) == 0 {
-		return navigationItemTrees
+		collapseSingleChildFolders(item.Children)
 	}
+}
 
-	treesByHrefAndUuid := utils.SliceToMap(navigationItemTrees, func(navigationItem *NavigationItem) string {
-		return navigationItem.Href + ":" + navigationItem.Uuid
-	})
+// setParentHrefs sets the ParentHref of the given navigation items and (recursively) of their children
+func setParentHrefs(navigationItems []*NavigationItem, parentHref string) {
+	for _, item := range navigationItems {
+		item.ParentHref = parentHref
+		setParentHrefs(item.Children, item.Href)
+	}
+}
 
-	uuidsByHref := make(map[string][]string)
-	for _, v := range navigationItemTrees {
-		if uuids, ok := uuidsByHref[v.Href]; ok {
-			uuidsByHref[v.Href] = append(uuids, v.Uuid)
+// PruneNavigationItemTrees removes the navigation items deeper than depth levels below the roots (depth 0 keeps only the roots)
+// and marks every remaining item that has children, pruned or not, with HasChildren; the trees are modified in place.
+//
+// ID pruneNavigationItemTrees
+// Param navigationItemTrees body []*NavigationItem true "Navigation trees as built by BuildNavigationItemTrees"
+// Param depth query int true "The number of levels kept below the roots"
+func (n NavigationItemTreeService) PruneNavigationItemTrees(navigationItemTrees []*NavigationItem, depth int) {
+	for _, item := range navigationItemTrees {
+		item.HasChildren = len(item.Children) > 0
+		if depth <= 0 {
+			item.Children = []*NavigationItem{}
 			continue
 		}
 
-		uuidsByHref[v.Href] = []string{v.Uuid}
+		n.PruneNavigationItemTrees(item.Children, depth-1)
 	}
+}
 
-	for _, self := range navigationItemTrees {
-		uuids, ok := uuidsByHref[self.Href]
-		// jump to the next iteration
-		// if self.Href references an already processed (and removed) navigation item
-		if !ok {
-			continue
-		}
+// BuildBreadcrumb returns the ancestor chain of the leaf with the given Href, ordered from its root to the leaf itself,
+// or nil if no leaf has the given Href.
+//
+// The returned items are copies without children; hence, the breadcrumb does not contain the (whole) trees.
+//
+// ID buildBreadcrumb
+// Param navigationItemTrees body []*NavigationItem true "Navigation trees as built by BuildNavigationItemTrees"
+// Param href path string true "Href of the leaf"
+func (n NavigationItemTreeService) BuildBreadcrumb(navigationItemTrees []*NavigationItem, href string) []NavigationItem {
+	leaf := findLeaf(navigationItemTrees, href)
+	if leaf == nil {
+		return nil
+	}
 
-		for _, uuid := range uuids {
-			// jump to the next iteration to prevent linking self's children twice
-			if self.Uuid == uuid {
-				continue
-			}
+	var breadcrumb []NavigationItem
+	for item := leaf; item != nil; item = item.Parent {
+		breadcrumb = append(breadcrumb, NavigationItem{Uuid: item.Uuid, Href: item.Href, Label: item.Label, ParentHref: item.ParentHref})
+	}
+	slices.Reverse(breadcrumb)
 
-			key := self.Href + ":" + uuid
-			twin, ok := treesByHrefAndUuid[key]
-			if !ok {
-				n.LogErrorf(logging.GetLogType("markdown-doc"), "could not find a navigation item tree for %s; please check if the map was created correctly", key)
-				continue
-			}
+	return breadcrumb
+}
 
-			// links children into one slice and removes twin
-			self.Children = append(self.Children, twin.Children...)
-			delete(treesByHrefAndUuid, key)
+// findLeaf searches the navigation trees depth-first for a leaf (i.e., a Markdown file) with the given Href
+func findLeaf(navigationItemTrees []*NavigationItem, href string) *NavigationItem {
+	for _, item := range navigationItemTrees {
+		if len(item.Children) == 0 {
+			if item.Href == href {
+				return item
+			}
+			continue
 		}
 
-		// removes the map entry of the href that was currently processed
-		delete(uuidsByHref, self.Href)
+		if leaf := findLeaf(item.Children, href); leaf != nil {
+			return leaf
+		}
 	}
 
-	navigationItemTrees = make([]*NavigationItem, 0, len(treesByHrefAndUuid))
-	for _, v := range treesByHrefAndUuid {
-		navigationItemTrees = append(navigationItemTrees, v)
-	}
+	return nil
+}
 
-	sort.Slice(navigationItemTrees, func(a, b int) bool {
-		return strings.Compare(navigationItemTrees[a].Href, navigationItemTrees[b].Href) == -1
-	})
+// SearchNavigationItems fuzzy-matches the labels and Hrefs of all navigation items (folders and Markdown files) against the query
+// and returns at most limit matches, the most similar first (see TrigramSorensenDiceSimilarity); the labels and Hrefs are compared
+// as titles (see markdownTitle), so that a misspelled name still matches. Items below minNavigationSimilarity are dropped.
+//
+// ID searchNavigationItems
+// Param navigationItemTrees body []*NavigationItem true "Navigation trees as built by BuildNavigationItemTrees"
+// Param query body string true "The (possibly misspelled) name of a folder or Markdown file"
+// Param limit body int true "The maximum number of matches"
+func (n NavigationItemTreeService) SearchNavigationItems(navigationItemTrees []*NavigationItem, query string, limit int) []NavigationSearchMatch {
+	matches := make([]NavigationSearchMatch, 0)
+
+	var visit func(items []*NavigationItem)
+	visit = func(items []*NavigationItem) {
+		for _, item := range items {
+			similarity := max(
+				TrigramSorensenDiceSimilarity(query, markdownTitle(item.Label)),
+				TrigramSorensenDiceSimilarity(query, markdownTitle(item.Href)),
+			)
+			if similarity >= minNavigationSimilarity {
+				matches = append(matches, NavigationSearchMatch{
+					Href:       item.Href,
+					Label:      item.Label,
+					ParentHref: item.ParentHref,
+					Folder:     len(item.Children) > 0,
+					Similarity: similarity,
+				})
+			}
 
-	// traverses the children of each tree recursively (down to the bottom-most children of each tree)
-	for i := 0; i < len(navigationItemTrees); i++ {
-		navigationItemTrees[i].Children = n.linkChildrenWithTheSameParent(navigationItemTrees[i].Children)
+			visit(item.Children)
+		}
 	}
+	visit(navigationItemTrees)
+
+	// the items of the same similarity keep their (navigation) order
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].Similarity > matches[b].Similarity
+	})
 
-	return navigationItemTrees
+	return matches[:min(len(matches), limit)]
 }
 
-// removeDotPrefixedRoots removes roots (including their children) whose Href are prefixed with a dot (.)
-// It runs in O(n) time.
-//
-// ID removeDotPrefixedRoots
-// Param rootNavigationItems body []*NavigationItem true "root navigation items"
-func (n NavigationItemTreeService) removeDotPrefixedRoots(rootNavigationItems []*NavigationItem) []*NavigationItem {
-	compiledRegex, err := regexp.Compile("^\\.")
-	if err != nil {
-		n.LogErrorf(nil, "failed to compile regex: %s", err.Error())
-		return nil
+func (n NavigationItemTreeService) docsRoot() string {
+	if len(n.DocsRoot) == 0 {
+		return constants.DefaultDocsRoot
 	}
 
-	visibleRootNavigationItems := make([]*NavigationItem, 0, len(rootNavigationItems))
-	for _, v := range rootNavigationItems {
-		// skip a hidden top-level element
-		if compiledRegex.MatchString(v.Href) {
-			continue
-		}
-		visibleRootNavigationItems = append(visibleRootNavigationItems, v)
+	return n.DocsRoot
+}
+
+func (n NavigationItemTreeService) landingPageNames() []string {
+	if len(n.LandingPageNames) == 0 {
+		return []string{DefaultLandingPageName}
 	}
 
-	return visibleRootNavigationItems
+	return n.LandingPageNames
 }
 
 // removeNumberPrefixFromRoots removes number prefixes from a root's Label and Href properties
@@ -410,17 +750,25 @@ func (n NavigationItemTreeService) removeNumberPrefixFromRoots(rootNavigationIte
 	}
 }
 
-func (m MarkdownSearchMatchMapper) mapToMarkdownSearchPage(payload MarkdownSearchPayload, pageSize, matchCount int, searchMatches []models.MarkdownContent) (Page[MarkdownSearchMatch], error) {
+// mapToMarkdownSearchPage maps the search matches of the requested page to a page of MarkdownSearchMatch.
+// Matches of the same document (path and name) are deduplicated (see dedupeSearchMatches); the match count is reduced accordingly.
+// This only matters for pages ranked and paginated by the database; the others are paginated after deduplicating all matches
+func (m MarkdownSearchMatchMapper) mapToMarkdownSearchPage(payload MarkdownSearchPayload, pageSize, matchCount int, searchMatches []models.ScoredMarkdownContent) (Page[MarkdownSearchMatch], error) {
 	if searchMatches == nil {
 		return Page[MarkdownSearchMatch]{}, fmt.Errorf("search matches must not be nil")
 	}
 
-	matches := make([]MarkdownSearchMatch, 0, len(searchMatches))
-	for _, v := range searchMatches {
-		pathElements := strings.Split(v.Meta.Path, "/")
-		// removes "markdowns" from path elements (this is only supposed for non-top-level files)
-		pathElements = pathElements[1:]
-		path := strings.Join(pathElements, "/")
+	dedupedMatches := dedupeSearchMatches(searchMatches)
+	matchCount -= len(searchMatches) - len(dedupedMatches)
+
+	matches := make([]MarkdownSearchMatch, 0, len(dedupedMatches))
+	for _, v := range dedupedMatches {
+		// removes the docs root (e.g., "markdowns") from path elements (this is only supposed for non-top-level files);
+		// a path without any slash is treated as top-level, i.e., it results in an empty path
+		var path string
+		if pathElements := strings.Split(v.Meta.Path, "/"); len(pathElements) > 1 {
+			path = strings.Join(pathElements[1:], "/")
+		}
 
 		label := v.Meta.Name
 		if len(path) == 0 {
@@ -442,12 +790,23 @@ func (m MarkdownSearchMatchMapper) mapToMarkdownSearchPage(payload MarkdownSearc
 			}
 		}
 
+		textBeforeMatch, matchingText, textAfterMatch := extractSnippet(v.Content, payload.Term, m.snippetWindow())
+		if m.MaxSnippetLength > 0 {
+			textBeforeMatch, matchingText, textAfterMatch = capSnippet(textBeforeMatch, matchingText, textAfterMatch, m.MaxSnippetLength)
+		}
+
 		match := MarkdownSearchMatch{
-			Label:        strings.ReplaceAll(label, "_", " "),
-			Href:         v.Meta.Name,
-			Path:         path,
-			PrettyPath:   strings.ReplaceAll(path, "_", " "),
-			MatchingText: payload.Term,
+			Label:           strings.ReplaceAll(label, "_", " "),
+			Href:            v.Meta.Name,
+			Path:            path,
+			PrettyPath:      strings.ReplaceAll(path, "_", " "),
+			MatchingText:    matchingText,
+			TextBeforeMatch: textBeforeMatch,
+			TextAfterMatch:  textAfterMatch,
+			UpdatedAt:       v.UpdatedAt,
+		}
+		if payload.Highlights {
+			match.Highlights = highlights(textBeforeMatch+matchingText+textAfterMatch, payload.Term)
 		}
 
 		matches = append(matches, match)
@@ -458,20 +817,187 @@ func (m MarkdownSearchMatchMapper) mapToMarkdownSearchPage(payload MarkdownSearc
 
 	totalPages := utils.CalculateTotalPages(matchCount, pageSize)
 
-	orders := make([]Order, 0)
-	orders = append(orders, Order{Property: "similarity", Direction: DESC})
-	payload.Pageable.Sort.Orders = orders
-
 	page := Page[MarkdownSearchMatch]{
 		Content:       matches,
 		Pageable:      payload.Pageable,
 		TotalElements: matchCount,
 		TotalPages:    totalPages,
 	}
+	page.setNavigation()
 
 	return page, nil
 }
 
+// dedupeSearchMatches keeps one match per document (path and name), i.e., the one with the highest similarity;
+// it takes the position of the document's first match. Duplicates on other pages are not known and, hence, not removed
+func dedupeSearchMatches(searchMatches []models.ScoredMarkdownContent) []models.ScoredMarkdownContent {
+	return dedupeByDocument(searchMatches, func(v models.ScoredMarkdownContent) (models.MarkdownMeta, float64) {
+		return v.Meta, v.Similarity
+	})
+}
+
+// dedupeRankedSearchMatches is like dedupeSearchMatches but for all matches, before they are sorted and paginated
+func dedupeRankedSearchMatches(matchesWithSimilarity []MatchesWithSimilarity) []MatchesWithSimilarity {
+	return dedupeByDocument(matchesWithSimilarity, func(v MatchesWithSimilarity) (models.MarkdownMeta, float64) {
+		return v.content.Meta, v.similarity
+	})
+}
+
+// dedupeByDocument keeps the match with the highest similarity per document (path and name) at the position of its first match
+func dedupeByDocument[T any](matches []T, scoredMeta func(T) (models.MarkdownMeta, float64)) []T {
+	type document struct{ path, name string }
+
+	indexes := make(map[document]int, len(matches))
+	deduped := make([]T, 0, len(matches))
+	for _, v := range matches {
+		meta, similarity := scoredMeta(v)
+		key := document{path: meta.Path, name: meta.Name}
+
+		i, ok := indexes[key]
+		if !ok {
+			indexes[key] = len(deduped)
+			deduped = append(deduped, v)
+			continue
+		}
+
+		if _, dedupedSimilarity := scoredMeta(deduped[i]); similarity > dedupedSimilarity {
+			deduped[i] = v
+		}
+	}
+
+	return deduped
+}
+
+func (m MarkdownSearchMatchMapper) snippetWindow() int {
+	if m.SnippetWindow <= 0 {
+		return DefaultSnippetWindow
+	}
+
+	return m.SnippetWindow
+}
+
+// extractSnippet locates the first (case-insensitive) occurrence of term inside content
+// and returns the matched substring together with up to window characters before and after it.
+//
+// The bounds are computed on runes, so multibyte characters are never split.
+// If the term does not occur literally (e.g., it only matched fuzzily based on trigrams),
+// the matching text is empty and the first window characters of content are returned as text after the match.
+//
+// For example (window = 4):
+//
+//	Input:  content = "Use Kafka for streaming", term = "kafka"
+//	Output: "Use ", "Kafka", " for"
+func extractSnippet(content, term string, window int) (textBeforeMatch, matchingText, textAfterMatch string) {
+	contentRunes := []rune(content)
+	termRunes := []rune(term)
+
+	start := indexFoldRunes(contentRunes, termRunes)
+	if start < 0 || len(termRunes) == 0 {
+		end := min(window, len(contentRunes))
+		return "", "", string(contentRunes[:end])
+	}
+
+	end := start + len(termRunes)
+	snippetStart := max(0, start-window)
+	snippetEnd := min(len(contentRunes), end+window)
+
+	return string(contentRunes[snippetStart:start]), string(contentRunes[start:end]), string(contentRunes[end:snippetEnd])
+}
+
+// snippetEllipsis marks the text cut off by capSnippet
+const snippetEllipsis = "…"
+
+// capSnippet truncates a snippet (see extractSnippet) to at most maxLength characters including the ellipses; the bounds
+// are computed on runes, so multibyte characters are never split. The matching text is kept in the middle: the text before
+// the match loses its beginning, the text after the match its end. Characters the one side does not need are left to
+// the other one. A matching text longer than maxLength is truncated itself, and the texts before and after the match are dropped.
+//
+// For example (maxLength = 11):
+//
+//	Input:  "Use our ", "Kafka", " for streaming"
+//	Output: "…r ", "Kafka", " f…"
+func capSnippet(textBeforeMatch, matchingText, textAfterMatch string, maxLength int) (string, string, string) {
+	before, match, after := []rune(textBeforeMatch), []rune(matchingText), []rune(textAfterMatch)
+	if len(before)+len(match)+len(after) <= maxLength {
+		return textBeforeMatch, matchingText, textAfterMatch
+	}
+
+	if len(match) >= maxLength {
+		return "", string(match[:maxLength-1]) + snippetEllipsis, ""
+	}
+
+	remaining := maxLength - len(match)
+	beforeLength := min(len(before), remaining/2)
+	afterLength := min(len(after), remaining-beforeLength)
+	beforeLength = min(len(before), remaining-afterLength)
+
+	if beforeLength < len(before) {
+		if beforeLength > 0 {
+			textBeforeMatch = snippetEllipsis + string(before[len(before)-beforeLength+1:])
+		} else {
+			textBeforeMatch = ""
+		}
+	}
+	if afterLength < len(after) {
+		if afterLength > 0 {
+			textAfterMatch = string(after[:afterLength-1]) + snippetEllipsis
+		} else {
+			textAfterMatch = ""
+		}
+	}
+
+	return textBeforeMatch, matchingText, textAfterMatch
+}
+
+// highlights locates the non-overlapping, case-insensitive occurrences of term inside the snippet;
+// the offsets are rune offsets within the snippet (like extractSnippet's bounds), so multibyte characters count once.
+//
+// For example:
+//
+//	Input:  snippet = "Kafka, kafka", term = "kafka"
+//	Output: [{0 5} {7 12}]
+func highlights(snippet, term string) []Highlight {
+	snippetRunes := []rune(snippet)
+	termRunes := []rune(term)
+	if len(termRunes) == 0 {
+		return nil
+	}
+
+	var result []Highlight
+	for offset := 0; offset+len(termRunes) <= len(snippetRunes); {
+		i := indexFoldRunes(snippetRunes[offset:], termRunes)
+		if i < 0 {
+			break
+		}
+
+		start := offset + i
+		result = append(result, Highlight{Start: start, End: start + len(termRunes)})
+		offset = start + len(termRunes)
+	}
+
+	return result
+}
+
+// indexFoldRunes returns the index of the first case-insensitive occurrence of sub in s, or -1 if sub is not present;
+// in contrast to strings.Index, the returned index is a rune index (not a byte index)
+func indexFoldRunes(s, sub []rune) int {
+	for i := 0; i+len(sub) <= len(s); i++ {
+		matches := true
+		for j := range sub {
+			if unicode.ToLower(s[i+j]) != unicode.ToLower(sub[j]) {
+				matches = false
+				break
+			}
+		}
+
+		if matches {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // removeNumberPrefixFromPathRoots removes numeric prefixes from the root elements
 // of paths in a slice of MarkdownSearchMatch.
 //
@@ -541,31 +1067,89 @@ func (m MarkdownSearchMatchMapper) removeNumberPrefixFromPrettyPathRoots(markdow
 	}
 }
 
-func (m MarkdownSearchMatchMapper) removeMatchesWithDotPrefixedPath(markdownSearchMatches []MarkdownSearchMatch) []MarkdownSearchMatch {
-	compiledRegex, err := regexp.Compile("^\\.")
-	if err != nil {
-		m.LogErrorf(nil, "failed to compile regex: %s", err.Error())
-		return nil
+// MaxSimilarityInputBytes is the maximum number of bytes of a text whose trigrams are extracted (see TransformToUniqueTrigrams),
+// be it a search term, a content, or a string compared by TrigramSorensenDiceSimilarity; longer texts are truncated,
+// so that a pathologically large input (e.g., a generated API dump) does not blow up the memory with its trigrams
+const MaxSimilarityInputBytes = 1 << 20
+
+// TrigramSorensenDiceSimilarity computes the Sorensen-Dice coefficient of the unique trigrams of two strings;
+// only the first MaxSimilarityInputBytes of each string are compared
+func TrigramSorensenDiceSimilarity(a, b string) float64 {
+	return TrigramSetSorensenDiceSimilarity(TransformToUniqueTrigrams(a), TransformToUniqueTrigrams(b))
+}
+
+// JaccardSimilarity computes the Jaccard index of the unique trigrams of two strings;
+// only the first MaxSimilarityInputBytes of each string are compared
+func JaccardSimilarity(a, b string) float64 {
+	return TrigramSetJaccardSimilarity(TransformToUniqueTrigrams(a), TransformToUniqueTrigrams(b))
+}
+
+// CosineTrigramSimilarity computes the cosine similarity of the unique trigrams of two strings;
+// only the first MaxSimilarityInputBytes of each string are compared
+func CosineTrigramSimilarity(a, b string) float64 {
+	return TrigramSetCosineSimilarity(TransformToUniqueTrigrams(a), TransformToUniqueTrigrams(b))
+}
+
+// truncateSimilarityInput truncates the string to at most MaxSimilarityInputBytes without splitting a multibyte character
+func truncateSimilarityInput(s string) string {
+	if len(s) <= MaxSimilarityInputBytes {
+		return s
 	}
 
-	visibleMarkdownSearchMatches := make([]MarkdownSearchMatch, 0, len(markdownSearchMatches))
-	for _, v := range markdownSearchMatches {
-		// skip a hidden top-level element
-		if compiledRegex.MatchString(v.Path) {
-			continue
-		}
-		visibleMarkdownSearchMatches = append(visibleMarkdownSearchMatches, v)
+	end := MaxSimilarityInputBytes
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
 	}
 
-	return visibleMarkdownSearchMatches
+	return s[:end]
 }
 
-func TrigramSorensenDiceSimilarity(a, b string) float64 {
+// TrigramSetSorensenDiceSimilarity computes the Sorensen-Dice coefficient of two precomputed sets of unique trigrams
+// (see TransformToUniqueTrigrams); it is 0 if both sets are empty
+func TrigramSetSorensenDiceSimilarity(aTrigrams, bTrigrams []string) float64 {
+	intersectionCount := trigramIntersectionCount(aTrigrams, bTrigrams)
 
-	aTrigrams := TransformToUniqueTrigrams(a)
-	bTrigrams := TransformToUniqueTrigrams(b)
+	// Sorensen-Dice coefficient
+	//   SDC = 2 * |A ∩ B| / (|A| + |B|)
+	trigramCount := len(aTrigrams) + len(bTrigrams)
+	if trigramCount == 0 {
+		return 0
+	}
+
+	return 2 * float64(intersectionCount) / float64(trigramCount)
+}
 
-	aCount, bCount := len(aTrigrams), len(bTrigrams)
+// TrigramSetJaccardSimilarity computes the Jaccard index of two precomputed sets of unique trigrams
+// (see TransformToUniqueTrigrams); it is 0 if both sets are empty
+func TrigramSetJaccardSimilarity(aTrigrams, bTrigrams []string) float64 {
+	intersectionCount := trigramIntersectionCount(aTrigrams, bTrigrams)
+
+	// Jaccard index
+	//   J = |A ∩ B| / |A ∪ B| = |A ∩ B| / (|A| + |B| - |A ∩ B|)
+	unionCount := len(aTrigrams) + len(bTrigrams) - intersectionCount
+	if unionCount == 0 {
+		return 0
+	}
+
+	return float64(intersectionCount) / float64(unionCount)
+}
+
+// TrigramSetCosineSimilarity computes the cosine similarity of two precomputed sets of unique trigrams
+// (see TransformToUniqueTrigrams); it is 0 if either set is empty
+func TrigramSetCosineSimilarity(aTrigrams, bTrigrams []string) float64 {
+	if len(aTrigrams) == 0 || len(bTrigrams) == 0 {
+		return 0
+	}
+
+	intersectionCount := trigramIntersectionCount(aTrigrams, bTrigrams)
+
+	// cosine similarity of the sets' binary occurrence vectors
+	//   cos = |A ∩ B| / sqrt(|A| * |B|)
+	return float64(intersectionCount) / math.Sqrt(float64(len(aTrigrams))*float64(len(bTrigrams)))
+}
+
+// trigramIntersectionCount counts the trigrams contained in both sets of unique trigrams
+func trigramIntersectionCount(aTrigrams, bTrigrams []string) int {
 	aTrigramsByTrigram := make(map[string]struct{}, len(aTrigrams))
 	for _, v := range aTrigrams {
 		aTrigramsByTrigram[v] = struct{}{}
@@ -579,19 +1163,116 @@ func TrigramSorensenDiceSimilarity(a, b string) float64 {
 		intersectionCount++
 	}
 
-	// Sorensen-Dice coefficient
-	//   SDC = 2 * |A ∩ B| / (|A| + |B|)
-	return 2 * float64(intersectionCount) / float64(aCount+bCount)
+	return intersectionCount
 }
 
+// nonWordCharacters separates the words of a text; letters, combining marks, digits, and underscores are word characters
+var nonWordCharacters = regexp.MustCompile(`[^\p{L}\p{M}\p{N}_]+`)
+
+// TransformToUniqueTrigrams splits the given text into words and returns the sorted, unique trigrams of the padded, lower-cased words.
+//
+// The text is NFC-normalized first, so composed and decomposed spellings of the same character (e.g. "é" and "e\u0301") yield the same trigrams.
+// Trigrams consist of three characters (runes), not bytes.
+// Each word is padded with two leading spaces and one trailing space (see TrigramPaddingLeading).
+// Only the first MaxSimilarityInputBytes of the text are considered.
 func TransformToUniqueTrigrams(a string) []string {
+	return transformToUniqueTrigrams(a, trigramOptions{})
+}
+
+// TransformToUniqueFoldedTrigrams is like TransformToUniqueTrigrams but strips accents before extracting trigrams,
+// so "café" and "cafe" yield the same trigrams
+func TransformToUniqueFoldedTrigrams(a string) []string {
+	return transformToUniqueTrigrams(a, trigramOptions{foldAccents: true})
+}
+
+// TrigramPadding selects how the words are padded before their trigrams are extracted;
+// the padding decides how much the start and the end of a word weigh in the similarity.
+//
+// For example, the Sorensen-Dice similarity of "hello" and "hell" is
+//   - 0.8 (4/5) with TrigramPaddingNone: "hel", "ell", "llo" vs. "hel", "ell"
+//   - 0.73 (8/11) with TrigramPaddingLeading: "  h", " he", "hel", "ell", "llo", "lo " vs. "  h", " he", "hel", "ell", "ll "
+//   - 0.62 (8/13) with TrigramPaddingSymmetric: the trigrams of TrigramPaddingLeading plus "o  " vs. plus "l  "
+type TrigramPadding string
+
+const (
+	// TrigramPaddingNone does not pad the words; the common prefix weighs as much as any other common part,
+	// but words with fewer than three characters yield no trigrams at all
+	TrigramPaddingNone TrigramPadding = "none"
+	// TrigramPaddingLeading pads each word with two leading spaces and one trailing space (like pg_trgm);
+	// matching word starts weigh more than matching word ends
+	TrigramPaddingLeading TrigramPadding = "leading"
+	// TrigramPaddingSymmetric pads each word with two spaces on both sides; word starts and word ends weigh the same,
+	// so words differing in their last character are less similar than with TrigramPaddingLeading
+	TrigramPaddingSymmetric TrigramPadding = "symmetric"
+)
+
+// TransformToUniqueTrigramsWithPadding is like TransformToUniqueTrigrams but pads the words as selected;
+// an unknown padding falls back to TrigramPaddingLeading
+func TransformToUniqueTrigramsWithPadding(a string, padding TrigramPadding) []string {
+	return transformToUniqueTrigrams(a, trigramOptions{padding: padding})
+}
+
+// TrigramMode selects whether the trigrams are extracted per word or from the whole text
+type TrigramMode string
+
+const (
+	// TrigramModePerWord extracts the trigrams of each word; no trigram spans two words,
+	// so "hello world" and "helloworld" share the trigrams of "hello" only (see TransformToUniqueTrigrams)
+	TrigramModePerWord TrigramMode = "per-word"
+	// TrigramModeWholeString extracts the trigrams of the whole text with its words separated by single spaces;
+	// the trigrams spanning two words (e.g., "o w" of "hello world") weigh the order of the words in the similarity
+	TrigramModeWholeString TrigramMode = "whole-string"
+)
+
+// TransformToUniqueTrigramsWithMode is like TransformToUniqueTrigrams but extracts the trigrams as selected;
+// an unknown mode falls back to TrigramModePerWord
+func TransformToUniqueTrigramsWithMode(a string, mode TrigramMode) []string {
+	return transformToUniqueTrigrams(a, trigramOptions{wholeString: mode == TrigramModeWholeString})
+}
+
+// pad pads the word as selected by the padding
+func (padding TrigramPadding) pad(word string) []rune {
+	switch padding {
+	case TrigramPaddingNone:
+		return []rune(word)
+	case TrigramPaddingSymmetric:
+		return []rune("  " + word + "  ")
+	default:
+		return []rune("  " + word + " ")
+	}
+}
+
+// trigramOptions select how transformToUniqueTrigrams extracts trigrams; the zero value selects the defaults of TransformToUniqueTrigrams
+type trigramOptions struct {
+	foldAccents bool
+	// stopWords are expected to be normalized (see normalizeText)
+	stopWords map[string]struct{}
+	// padding defaults to TrigramPaddingLeading
+	padding TrigramPadding
+	// wholeString selects TrigramModeWholeString instead of TrigramModePerWord
+	wholeString bool
+}
+
+// transformToUniqueTrigrams extracts the unique trigrams of the given text (see TransformToUniqueTrigrams);
+// the stop words are dropped before padding
+func transformToUniqueTrigrams(a string, options trigramOptions) []string {
 	if len(a) == 0 {
 		return []string{}
 	}
 
 	// split on non-word characters
-	re := regexp.MustCompile(`\W+`)
-	words := re.Split(a, -1)
+	words := nonWordCharacters.Split(normalizeText(truncateSimilarityInput(a), options.foldAccents), -1)
+	if len(options.stopWords) > 0 {
+		words = slices.DeleteFunc(words, func(word string) bool {
+			_, ok := options.stopWords[word]
+			return ok
+		})
+	}
+
+	if options.wholeString {
+		// the separators are reduced to single spaces, e.g., "hello, world!" results in "hello world"
+		words = []string{strings.Join(slices.DeleteFunc(words, func(word string) bool { return len(word) == 0 }), " ")}
+	}
 
 	var trigramCount int
 	for _, word := range words {
@@ -605,13 +1286,10 @@ func TransformToUniqueTrigrams(a string) []string {
 	uniqueTrigrams := make(map[string]struct{}, trigramCount)
 
 	for _, word := range words {
-		word = strings.ToLower(word)
-		padded := "  " + word + " "
+		padded := options.padding.pad(word)
 
-		for i := 0; i < 1+len(word); i++ {
-			t := padded[:3]
-			uniqueTrigrams[t] = struct{}{}
-			padded = padded[1:]
+		for i := 0; i+3 <= len(padded); i++ {
+			uniqueTrigrams[string(padded[i:i+3])] = struct{}{}
 		}
 	}
 
@@ -626,3 +1304,25 @@ func TransformToUniqueTrigrams(a string) []string {
 
 	return trigrams
 }
+
+// normalizeText NFC-normalizes and lower-cases the given text; if foldAccents is set, accents are stripped as well
+func normalizeText(a string, foldAccents bool) string {
+	a = norm.NFC.String(a)
+	if foldAccents {
+		a = foldAccentsOf(a)
+	}
+
+	return strings.ToLower(a)
+}
+
+// foldAccentsOf removes the diacritical marks of the given text (e.g. "é" becomes "e"); the result is NFC-normalized
+func foldAccentsOf(a string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	folded, _, err := transform.String(t, a)
+	if err != nil {
+		return a
+	}
+
+	return folded
+}
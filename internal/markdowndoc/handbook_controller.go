@@ -1,23 +1,48 @@
 package markdowndoc
 
 import (
+	"cmp"
+	"context"
+	"crypto/sha256"
 	"dice-sorensen-similarity-search/internal/api"
+	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
 	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/models"
+	"dice-sorensen-similarity-search/internal/tracing"
+	"dice-sorensen-similarity-search/internal/utils"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
 	"io"
 	"net/http"
+	"net/url"
+	"path"
 	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // Api defines HTTP endpoints for accessing markdown content and navigation metadata.
 type Api interface {
 	GetNavigationItemsTrees(c *gin.Context)
+	GetBreadcrumb(c *gin.Context)
+	GetNavigationItemTree(c *gin.Context)
+	GetNavigationSearchMatches(c *gin.Context)
 	GetMarkdownByName(c *gin.Context)
+	GetMarkdownByPath(c *gin.Context)
 	GetMarkdownSearchTermMatches(c *gin.Context)
+	GetSimilarity(c *gin.Context)
+	GetStats(c *gin.Context)
+	GetDocuments(c *gin.Context)
+	GetRelatedDocuments(c *gin.Context)
 }
 
 // Controller handles API operations related to markdown metadata and content.
@@ -27,6 +52,80 @@ type Controller struct {
 	*environment.Env
 	NavigationItemTreeService
 	MarkdownSearchMatchMapper
+
+	// TrigramCache stores the trigrams of the searched contents; if nil, they are computed on every search
+	TrigramCache *TrigramCache
+	// SearchEngine selects how search matches are ranked: in Go (default) or in the database (pg_trgm)
+	SearchEngine string
+	// SearchBackend selects how the simple SearchEngine matches contents: literally via LIKE (default) or via Postgres' full-text search,
+	// which also ranks the matches (by ts_rank) instead of the SimilarityMetric
+	SearchBackend string
+	// SimilarityMetric selects the metric search matches are ranked by in Go (default: Sorensen-Dice coefficient)
+	SimilarityMetric string
+	// Scorer is the name of a registered Scorer (see RegisterScorer) search matches are ranked by instead of the SimilarityMetric;
+	// empty for the SimilarityMetric. A search can select another one (see MarkdownSearchPayload.Scorer)
+	Scorer string
+	// TitleWeight is the weight within [0,1] of the similarity of a Markdown file's title (i.e., its name) to the search term;
+	// the similarity of its content is weighted by 1-TitleWeight (default: 0, i.e. only the content counts).
+	// It only applies to the ranking in Go; the contents must still contain the search term to match
+	TitleWeight float64
+	// Tokenizer extracts the trigrams of search terms and contents; if nil, accents are kept and no stop words are removed.
+	// The database still pre-selects the contents that contain the search term literally
+	Tokenizer *Tokenizer
+	// SearchAnalytics logs each search (term, result count, highest similarity on the page, and page size) with the subtype "search"
+	SearchAnalytics bool
+	// DefaultPageSize is the page size of search results if the payload has none (default: DefaultPageSize);
+	// the applied page size is returned in the page's Pageable
+	DefaultPageSize int
+	// MaxPageSize is the largest page size of search results; larger ones are clamped (default: DefaultMaxPageSize)
+	MaxPageSize int
+	// SearchMetrics records the duration and the number of matches of successful searches; it is optional
+	SearchMetrics SearchMetrics
+	// SearchCache stores the pages of recent searches; if nil, every search is queried and ranked
+	SearchCache *SearchCache
+	// SuggestionThreshold is the match count below which the search page suggests the closest Markdown names
+	// (default: DefaultSuggestionThreshold); if it is negative, no names are suggested
+	SuggestionThreshold int
+	// MaxSuggestions is the maximum number of suggested Markdown names (default: DefaultMaxSuggestions)
+	MaxSuggestions int
+	// MaxRelatedDocuments is the maximum number of related documents a Markdown file is returned with (default: DefaultMaxRelatedDocuments)
+	MaxRelatedDocuments int
+
+	// reloadedThresholds override TitleWeight and MaxPageSize once set (see SetSearchThresholds)
+	reloadedThresholds atomic.Pointer[searchThresholds]
+}
+
+// searchThresholds are the search settings that can be replaced while the Controller is in use
+type searchThresholds struct {
+	titleWeight float64
+	maxPageSize int
+}
+
+const (
+	// DefaultSuggestionThreshold is the match count below which names are suggested if no SuggestionThreshold is configured
+	DefaultSuggestionThreshold = 3
+	// DefaultMaxSuggestions is the maximum number of suggested names if no (positive) MaxSuggestions is configured
+	DefaultMaxSuggestions = 3
+	// minSuggestionSimilarity is the similarity a Markdown name must at least have to the search term to be suggested;
+	// names sharing only a trigram or two with the term are no plausible corrections
+	minSuggestionSimilarity = 0.3
+
+	// DefaultMaxNavigationMatches is the maximum number of navigation search matches if the payload sets no (positive) limit
+	DefaultMaxNavigationMatches = 10
+	// minNavigationSimilarity is the similarity a navigation item must at least have to the query of a navigation search
+	minNavigationSimilarity = 0.3
+
+	// DefaultMaxRelatedDocuments is the maximum number of related documents if no (positive) MaxRelatedDocuments is configured
+	DefaultMaxRelatedDocuments = 5
+
+	// MimeTypeNDJSON is the accepted media type writing the search matches of a page as newline-delimited JSON objects
+	MimeTypeNDJSON = "application/x-ndjson"
+)
+
+// SearchMetrics records metrics of search requests
+type SearchMetrics interface {
+	// ObserveSearch records the duration of a search request and the number of matches it returned
+	ObserveSearch(duration time.Duration, matches int)
 }
 
 type MatchesWithSimilarity struct {
@@ -35,28 +134,177 @@ type MatchesWithSimilarity struct {
 }
 
 // GetNavigationItemsTrees returns the navigation structure for all available markdown files.
+// For huge doc sets, the query parameter depth limits the trees to the roots plus depth levels; the items whose
+// children were pruned are marked with HasChildren (see PruneNavigationItemTrees) and can be expanded via GetNavigationItemTree.
 //
 // @ID getNavigationItemTrees
 // @Summary Get navigation item trees for markdown files
 // @Tags navigation
 // @Router /markdown-doc/navigation-items [get]
+// @Param depth query int false "the number of levels returned below the roots (default: all)"
 // @Success	200	{object} api.RestJsonResponse{data=[]markdowndoc.NavigationItem}
+// @Failure 400
 // @Failure 500
 func (hc *Controller) GetNavigationItemsTrees(c *gin.Context) {
 	ctx := c.Request.Context()
 
+	depth := -1
+	if depthParam, ok := c.GetQuery("depth"); ok {
+		var err error
+		depth, err = strconv.Atoi(depthParam)
+		if err != nil || depth < 0 {
+			msg := fmt.Sprintf("did not build navigation because of an invalid depth %q; must be a non-negative integer", depthParam)
+			hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+			return
+		}
+	}
+
 	var markdownMetas []models.MarkdownMeta
 	if err := hc.FindMarkdownMetasWhereCharCountGreaterThan(ctx, 0, &markdownMetas); err != nil {
-		hc.LogError(logging.GetLogType("markdown-doc"), err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown meta info: %s", err.Error()))
 		return
 	}
 
 	trees := hc.BuildNavigationItemTrees(markdownMetas)
+	if depth >= 0 {
+		hc.PruneNavigationItemTrees(trees, depth)
+	}
 	c.JSON(http.StatusOK, trees)
 }
 
+// GetBreadcrumb returns the ancestor chain of the navigation item with the given Href (root first, the item itself last).
+//
+// @ID getBreadcrumb
+// @Summary Get the breadcrumb of a markdown file
+// @Tags navigation
+// @Router /markdown-doc/breadcrumb/{href} [get]
+// @Param href path string true "Href of the markdown file's navigation item"
+// @Success	200	{object} []markdowndoc.NavigationItem
+// @Failure 400
+// @Failure 404
+// @Failure 500
+func (hc *Controller) GetBreadcrumb(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	href := c.Param("href")
+	if len(href) <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse("path variable 'href' is missing"))
+		return
+	}
+
+	var markdownMetas []models.MarkdownMeta
+	if err := hc.FindMarkdownMetasWhereCharCountGreaterThan(ctx, 0, &markdownMetas); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown meta info: %s", err.Error()))
+		return
+	}
+
+	breadcrumb := hc.BuildBreadcrumb(hc.BuildNavigationItemTrees(markdownMetas), href)
+	if breadcrumb == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, api.NewErrorResponsef("no navigation item found for href %s", href))
+		return
+	}
+
+	c.JSON(http.StatusOK, breadcrumb)
+}
+
+// GetNavigationItemTree returns the navigation tree of a single root (i.e., a top-level folder) and its descendants,
+// e.g., to render one expanded section without loading all trees.
+//
+// @ID getNavigationItemTree
+// @Summary Get the navigation item tree of a root
+// @Tags navigation
+// @Router /markdown-doc/tree/{root} [get]
+// @Param root path string true "Href of the root navigation item"
+// @Success	200	{object} markdowndoc.NavigationItem
+// @Failure 400
+// @Failure 404
+// @Failure 500
+func (hc *Controller) GetNavigationItemTree(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	root := c.Param("root")
+	if len(root) <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse("path variable 'root' is missing"))
+		return
+	}
+
+	var markdownMetas []models.MarkdownMeta
+	if err := hc.FindMarkdownMetasWhereCharCountGreaterThan(ctx, 0, &markdownMetas); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown meta info: %s", err.Error()))
+		return
+	}
+
+	// the roots' Hrefs are final (e.g., without number prefixes) only once the whole trees are built;
+	// therefore, the matching root is extracted afterward instead of filtering the metas beforehand
+	for _, tree := range hc.BuildNavigationItemTrees(markdownMetas) {
+		if tree.Href == root {
+			c.JSON(http.StatusOK, tree)
+			return
+		}
+	}
+
+	c.AbortWithStatusJSON(http.StatusNotFound, api.NewErrorResponsef("no navigation item tree found for root %s", root))
+}
+
+// GetNavigationSearchMatches fuzzy-matches the folders and Markdown files of the navigation by their names,
+// e.g., to jump to a folder or file whose name is only roughly known; the Markdown contents are not searched.
+//
+// @ID getNavigationSearchMatches
+// @Summary Fuzzy-search the navigation items by their names
+// @Tags navigation
+// @Router /markdown-doc/navigation-search [post]
+// @Param payload body markdowndoc.NavigationSearchPayload true "The query and the maximum number of matches"
+// @Success	200	{object} []markdowndoc.NavigationSearchMatch
+// @Failure 400
+// @Failure 500
+func (hc *Controller) GetNavigationSearchMatches(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		msg := fmt.Sprintf("error while reading request body: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	var payload NavigationSearchPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		msg := fmt.Sprintf("error while unmarshaling request body: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	if len(strings.TrimSpace(payload.Query)) == 0 {
+		msg := "did not perform navigation search because no query was present"
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	limit := payload.Limit
+	if limit <= 0 {
+		limit = DefaultMaxNavigationMatches
+	}
+
+	var markdownMetas []models.MarkdownMeta
+	if err := hc.FindMarkdownMetasWhereCharCountGreaterThan(ctx, 0, &markdownMetas); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown meta info: %s", err.Error()))
+		return
+	}
+
+	matches := hc.SearchNavigationItems(hc.BuildNavigationItemTrees(markdownMetas), strings.TrimSpace(payload.Query), limit)
+	c.JSON(http.StatusOK, matches)
+}
+
 // GetMarkdownByName returns the markdown content associated with the provided name.
+// The response carries an ETag and a Last-Modified header; a conditional request for unchanged content results in 304 Not Modified.
 //
 // @ID getMarkdownByName
 // @Summary Get markdown content by file name
@@ -64,7 +312,9 @@ func (hc *Controller) GetNavigationItemsTrees(c *gin.Context) {
 // @Router /markdown-doc/markdown/{name} [get]
 // @Param name path string true "Markdown file name without extension"
 // @Success 200 {object} map[string]string "Returns markdown content"
+// @Success 304 "The content matches the client's If-None-Match or If-Modified-Since"
 // @Failure 400
+// @Failure 404
 // @Failure 500
 func (hc *Controller) GetMarkdownByName(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -77,12 +327,120 @@ func (hc *Controller) GetMarkdownByName(c *gin.Context) {
 
 	var markdownContent models.MarkdownContent
 	err := hc.FindMarkdownContentByName(ctx, name, &markdownContent)
+	if errors.Is(err, database.ErrMarkdownNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, api.NewErrorResponsef("no markdown found with name %s", name))
+		return
+	}
 	if err != nil {
-		hc.LogError(logging.GetLogType("markdown-doc"), err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), err)
 		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown meta info: %s", err))
 		return
 	}
 
+	etag := markdownETag(markdownContent)
+	// overrides the "no-store" of the CORS middleware: the content may be cached by the browser, but must be revalidated
+	c.Header("Cache-Control", "private, no-cache")
+	c.Header("ETag", etag)
+	if !markdownContent.UpdatedAt.IsZero() {
+		c.Header("Last-Modified", markdownContent.UpdatedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if isNotModified(c.Request, etag, markdownContent.UpdatedAt) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	response := struct {
+		Content string `json:"content"`
+	}{
+		Content: markdownContent.Content,
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// markdownETag returns a weak ETag of the content, derived from its hash (see models.MarkdownContent.Hash);
+// it is weak since the response may be compressed (see middlewares.GzipHandler)
+func markdownETag(markdownContent models.MarkdownContent) string {
+	hash := markdownContent.Hash
+	if len(hash) <= 0 {
+		// contents stored before their hash was introduced
+		sum := sha256.Sum256([]byte(markdownContent.Content))
+		hash = hex.EncodeToString(sum[:])
+	}
+
+	return `W/"` + hash + `"`
+}
+
+// isNotModified reports whether the client's cached copy is still up to date.
+// If-None-Match takes precedence over If-Modified-Since (see RFC 9110, section 13.2.2);
+// the latter is ignored if the modification time is unknown.
+func isNotModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if ifNoneMatch := req.Header.Get("If-None-Match"); len(ifNoneMatch) > 0 {
+		for _, candidate := range strings.Split(ifNoneMatch, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// weak comparison, i.e., the W/ prefix is ignored
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	ifModifiedSince := req.Header.Get("If-Modified-Since")
+	if len(ifModifiedSince) <= 0 || lastModified.IsZero() {
+		return false
+	}
+
+	since, err := http.ParseTime(ifModifiedSince)
+	if err != nil {
+		return false
+	}
+
+	// the header has a precision of seconds
+	return !lastModified.Truncate(time.Second).After(since)
+}
+
+// GetMarkdownByPath returns the markdown content stored at the provided path.
+// Unlike GetMarkdownByName, it tells apart files that have the same name but reside in different folders.
+//
+// @ID getMarkdownByPath
+// @Summary Get markdown content by file path
+// @Tags markdown
+// @Router /markdown-doc/markdown-by-path [get]
+// @Param path query string true "Markdown folder path and file name without extension (e.g. markdowns/Gateway/1-Onboarding)"
+// @Success 200 {object} map[string]string "Returns markdown content"
+// @Failure 400
+// @Failure 404
+// @Failure 500
+func (hc *Controller) GetMarkdownByPath(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	filePath := strings.Trim(c.Query("path"), "/")
+	if len(filePath) <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse("query parameter 'path' is missing"))
+		return
+	}
+
+	folderPath, name := path.Split(filePath)
+	folderPath = strings.TrimSuffix(folderPath, "/")
+	if len(folderPath) <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponsef("query parameter 'path' must contain the folder of the file: %s", filePath))
+		return
+	}
+
+	var markdownContent models.MarkdownContent
+	err := hc.FindMarkdownContentByPath(ctx, folderPath, name, &markdownContent)
+	if errors.Is(err, database.ErrMarkdownNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, api.NewErrorResponsef("no markdown found at path %s", filePath))
+		return
+	}
+	if err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown content: %s", err))
+		return
+	}
+
 	response := struct {
 		Content string `json:"content"`
 	}{
@@ -91,13 +449,36 @@ func (hc *Controller) GetMarkdownByName(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// GetMarkdownSearchTermMatches returns the requested page of Markdown contents matching the search term.
+// If the query parameter debug is true, each match includes its trigram counts (see SearchMatchDebug).
+// The Link header points to the first, previous, next, and last page (see paginationLinks); the query parameters
+// pageNumber and pageSize of these links take precedence over the payload's pageable.
+// If the client accepts MimeTypeNDJSON, the page's matches are written one JSON object per line instead of the Page,
+// followed by a line with the page's metadata (see writeSearchPageNDJSON).
 func (hc *Controller) GetMarkdownSearchTermMatches(c *gin.Context) {
+	start := time.Now()
 	ctx := c.Request.Context()
 
+	debug, err := strconv.ParseBool(c.DefaultQuery("debug", "false"))
+	if err != nil {
+		msg := fmt.Sprintf("did not perform search because of an invalid debug flag: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
 	body, err := io.ReadAll(c.Request.Body)
+	// the body is capped by middlewares.BodyLimitHandler
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		msg := fmt.Sprintf("did not perform search because the request body exceeds %d bytes", maxBytesErr.Limit)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, api.NewErrorResponse(msg))
+		return
+	}
 	if err != nil {
 		msg := fmt.Sprintf("error while reading request body: %s", err)
-		hc.LogError(logging.GetLogType("markdown-doc"), msg)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
 		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
 		return
 	}
@@ -106,71 +487,830 @@ func (hc *Controller) GetMarkdownSearchTermMatches(c *gin.Context) {
 	err = json.Unmarshal(body, &payload)
 	if err != nil {
 		msg := fmt.Sprintf("error while unmarshaling request body: %s", err)
-		hc.LogError(logging.GetLogType("markdown-doc"), msg)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	// a term of whitespace only is treated as empty, since it has no trigrams and would match every Markdown
+	phrase, quoted := unquotePhrase(payload.Term)
+	payload.Term = strings.TrimSpace(phrase)
+
+	err = payload.Pageable.overrideFromQuery(c.Request.URL.Query())
+	if err != nil {
+		msg := fmt.Sprintf("did not perform search because of an invalid pageable: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	err = payload.Pageable.Normalize(hc.DefaultPageSize, hc.thresholds().maxPageSize)
+	if err != nil {
+		msg := fmt.Sprintf("did not perform search because of an invalid pageable: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
 		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
 		return
 	}
 
 	if len(payload.Term) <= 0 {
 		msg := "did not perform search because no search term was present"
-		hc.LogError(logging.GetLogType("markdown-doc"), msg)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	if payload.MinSimilarity < 0 || payload.MinSimilarity > 1 {
+		msg := fmt.Sprintf("did not perform search because the minimum similarity (%v) is not within [0,1]", payload.MinSimilarity)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
 		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
 		return
 	}
 
-	pageSize := 5
-	if payload.Pageable.PageSize > 0 {
-		pageSize = payload.Pageable.PageSize
+	orders, err := searchOrders(payload.Pageable.Sort)
+	if err != nil {
+		msg := fmt.Sprintf("did not perform search because of an invalid sort: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
 	}
+	payload.Pageable.Sort.Orders = orders
 
-	searchMatches := make([]models.MarkdownContent, 0)
-	err = hc.FindMarkdownsBySearchTermSimple(ctx, payload.Term, &searchMatches)
+	mode, err := searchMode(payload.Mode)
 	if err != nil {
-		msg := fmt.Sprintf("error reading Markdown search matches: %s", err)
-		hc.LogError(logging.GetLogType("markdown-doc"), msg)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(msg))
+		msg := fmt.Sprintf("did not perform search because of an invalid mode: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+	if quoted {
+		mode = SearchModePhrase
+	}
+	databaseRanking := hc.databaseRanking()
+	if mode != SearchModePhrase && len(databaseRanking) > 0 {
+		msg := fmt.Sprintf("did not perform search because the mode %q is not supported by %s", mode, databaseRanking)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+	if payload.hasSearchOptions() && (mode != SearchModePhrase || len(databaseRanking) > 0) {
+		msg := "did not perform search because case-sensitive and whole-word searches are only supported by the phrase mode of the simple search engine with the like search backend"
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
 		return
 	}
+	if len(payload.Scorer) > 0 {
+		if _, ok := LookupScorer(payload.Scorer); !ok {
+			msg := fmt.Sprintf("did not perform search because of the unknown scorer %q; must be one of %s", payload.Scorer, strings.Join(ScorerNames(), ", "))
+			hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+			return
+		}
+		if len(databaseRanking) > 0 {
+			msg := fmt.Sprintf("did not perform search because scorers are not supported by %s", databaseRanking)
+			hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+			return
+		}
+	}
+	payload.Mode = mode
+	pageSize := payload.Pageable.PageSize
 
-	matchesWithSimilarity := make([]MatchesWithSimilarity, 0, pageSize)
-	for i, v := range searchMatches {
-		if i >= pageSize {
-			break
+	// pages with debug information are neither served from nor stored into the SearchCache
+	cacheKey := searchCacheKey(payload)
+	result, cached := searchResult{}, false
+	if !debug {
+		result, cached = hc.SearchCache.get(cacheKey)
+	}
+	if !cached {
+		result, err = hc.search(ctx, payload, pageSize, debug)
+		if err != nil {
+			msg := err.Error()
+			hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(msg))
+			return
 		}
 
-		s := TrigramSorensenDiceSimilarity(v.Content, payload.Term)
+		if !debug {
+			hc.SearchCache.put(cacheKey, result)
+		}
+	}
+	page := result.page
+
+	if hc.SearchMetrics != nil {
+		hc.SearchMetrics.ObserveSearch(time.Since(start), len(page.Content))
+	}
+
+	if hc.SearchAnalytics {
+		keyVal := append(logging.GetLogTypeWithContext(ctx, "search"),
+			"term", payload.Term, "resultCount", page.TotalElements, "topSimilarity", result.topSimilarity, "pageSize", pageSize, "cached", cached)
+		hc.LogInfo(keyVal, "search performed")
+	}
+
+	c.Header("Link", paginationLinks(c.Request.URL, page.Pageable.PageNumber, pageSize, page.TotalPages))
+	if acceptsNDJSON(c.GetHeader("Accept")) {
+		hc.writeSearchPageNDJSON(c, page)
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// writeSearchPageNDJSON writes each match of the (already ranked and paginated) page as a JSON object followed by a newline
+// and flushes it, so that clients can render the first matches before the last ones are serialized.
+// The last line is the page's metadata, e.g., {"summary": {"totalElements": 12, "totalPages": 3, ...}} (see PageSummary)
+func (hc *Controller) writeSearchPageNDJSON(c *gin.Context, page Page[MarkdownSearchMatch]) {
+	c.Header("Content-Type", MimeTypeNDJSON)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	for _, match := range page.Content {
+		if c.Request.Context().Err() != nil {
+			return
+		}
+
+		if err := encoder.Encode(match); err != nil {
+			hc.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), "error while writing search match: %s", err)
+			return
+		}
+		c.Writer.Flush()
+	}
+
+	if err := encoder.Encode(gin.H{"summary": page.summary()}); err != nil {
+		hc.LogErrorf(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), "error while writing search summary: %s", err)
+		return
+	}
+	c.Writer.Flush()
+}
+
+// acceptsNDJSON reports whether the Accept header lists MimeTypeNDJSON
+func acceptsNDJSON(accept string) bool {
+	for _, mediaType := range strings.Split(accept, ",") {
+		name, _, _ := strings.Cut(mediaType, ";")
+		if strings.EqualFold(strings.TrimSpace(name), MimeTypeNDJSON) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// paginationLinks builds the value of a Link header (RFC 8288) pointing to the first, previous, next, and last page.
+// The links are the (relative) request URL with the page's pageNumber and pageSize as query parameters;
+// the previous and the next page are omitted at the boundaries like Page.HasPrevious and Page.HasNext.
+// An empty result (TotalPages 0) has a single page, which is both the first and the last one.
+func paginationLinks(requestUrl *url.URL, pageNumber, pageSize, totalPages int) string {
+	link := func(pageNumber int, rel string) string {
+		query := requestUrl.Query()
+		query.Set("pageNumber", strconv.Itoa(pageNumber))
+		query.Set("pageSize", strconv.Itoa(pageSize))
+
+		target := url.URL{Path: requestUrl.Path, RawQuery: query.Encode()}
+		return fmt.Sprintf("<%s>; rel=%q", target.String(), rel)
+	}
+
+	links := []string{link(1, "first")}
+	if pageNumber > 1 {
+		links = append(links, link(pageNumber-1, "prev"))
+	}
+	if pageNumber < totalPages {
+		links = append(links, link(pageNumber+1, "next"))
+	}
+	links = append(links, link(max(totalPages, 1), "last"))
+
+	return strings.Join(links, ", ")
+}
+
+// databaseRanking names the SearchEngine or SearchBackend that ranks the search matches in the database instead of in Go,
+// e.g., `the "pg_trgm" search engine`; it is empty if they are ranked in Go, which supports all modes, options, and scorers
+func (hc *Controller) databaseRanking() string {
+	switch {
+	case hc.SearchEngine == constants.SearchEnginePgTrgm:
+		return fmt.Sprintf("the %q search engine", constants.SearchEnginePgTrgm)
+	case hc.SearchBackend == constants.SearchBackendFullText:
+		return fmt.Sprintf("the %q search backend", constants.SearchBackendFullText)
+	default:
+		return ""
+	}
+}
+
+// search fetches and ranks the requested page of search matches (in Go or in the database, see SearchEngine and SearchBackend)
+// and maps it; the returned errors are meant to be shown to the client
+func (hc *Controller) search(ctx context.Context, payload MarkdownSearchPayload, pageSize int, debug bool) (searchResult, error) {
+	var requestedPage []models.ScoredMarkdownContent
+	var matchCount int
+	var err error
+	switch {
+	case hc.SearchEngine == constants.SearchEnginePgTrgm:
+		requestedPage, matchCount, err = hc.searchPageInDatabase(ctx, payload, pageSize)
+	case hc.SearchBackend == constants.SearchBackendFullText:
+		requestedPage, matchCount, err = hc.searchPageByFullText(ctx, payload, pageSize)
+	default:
+		requestedPage, matchCount, err = hc.searchPage(ctx, payload, pageSize)
+	}
+	if err != nil {
+		return searchResult{}, err
+	}
+
+	page, err := hc.mapToMarkdownSearchPage(payload, pageSize, matchCount, requestedPage)
+	if err != nil {
+		return searchResult{}, fmt.Errorf("error mapping to page response: %s", err)
+	}
+
+	if page.TotalElements < hc.suggestionThreshold() {
+		page.Suggestions = hc.suggestMarkdownNames(ctx, payload.Term)
+	}
+
+	if debug {
+		// the page's content is deduplicated; so must be the contents the debug information is computed for
+		hc.addSearchMatchDebug(payload.Term, dedupeSearchMatches(requestedPage), page.Content)
+	}
+
+	var topSimilarity float64
+	for _, v := range requestedPage {
+		topSimilarity = max(topSimilarity, v.Similarity)
+	}
+
+	return searchResult{page: page, topSimilarity: topSimilarity}, nil
+}
+
+// rankSearchMatches fetches all Markdown contents matching the search term (see SearchMode) and scores them by their
+// trigram-based similarity (see SimilarityMetric) or by the selected Scorer; matches below the minimum similarity are dropped.
+// The similarity is computed against the trigrams of all words of the term, regardless of the mode.
+// Matches of the same document are deduplicated (see dedupeRankedSearchMatches), so that no page repeats a document of another one.
+// The matches are sorted by the payload's orders (default: similarity in descending order).
+func (hc *Controller) rankSearchMatches(ctx context.Context, payload MarkdownSearchPayload) ([]MatchesWithSimilarity, error) {
+	searchMatches := make([]models.MarkdownContent, 0)
+
+	findCtx, findSpan := tracing.Start(ctx, "FindMarkdownsBySearchTerm", attribute.String("search.mode", string(payload.Mode)))
+	var err error
+	switch {
+	case payload.Mode == SearchModeAll || payload.Mode == SearchModeAny:
+		err = hc.FindMarkdownsBySearchTerms(findCtx, searchTerms(payload.Term), payload.Mode == SearchModeAll, payload.PathPrefix, &searchMatches)
+	case payload.hasSearchOptions():
+		err = hc.FindMarkdownsBySearchTermWithOptions(findCtx, payload.Term, payload.searchOptions(), &searchMatches)
+	default:
+		err = hc.FindMarkdownsBySearchTermSimple(findCtx, payload.Term, payload.PathPrefix, &searchMatches)
+	}
+	tracing.End(findSpan, err)
+	if err != nil {
+		return nil, err
+	}
+
+	_, rankSpan := tracing.Start(ctx, "RankSearchMatches", attribute.Int("search.candidates", len(searchMatches)))
+	defer rankSpan.End()
+
+	// the similarity must be computed for all matches (not only for the requested page);
+	// otherwise, the best match might never make it into the first page
+	scorer, trigramSetScorer := hc.scorer(payload)
+	if scorer == nil && trigramSetScorer == nil {
+		trigramSetScorer = hc.trigramSetSimilarity
+	}
+	termTrigrams := hc.uniqueTrigrams(payload.Term)
+	titleWeight := hc.thresholds().titleWeight
+	matchesWithSimilarity := make([]MatchesWithSimilarity, 0, len(searchMatches))
+	for _, v := range searchMatches {
+		var s, titleSimilarity float64
+		if scorer != nil {
+			s = scorer(v.Content, payload.Term)
+		} else {
+			s = trigramSetScorer(hc.contentTrigrams(v), termTrigrams)
+		}
+		if titleWeight > 0 {
+			if scorer != nil {
+				titleSimilarity = scorer(markdownTitle(v.Meta.Name), payload.Term)
+			} else {
+				titleSimilarity = trigramSetScorer(hc.uniqueTrigrams(markdownTitle(v.Meta.Name)), termTrigrams)
+			}
+			s = titleWeight*titleSimilarity + (1-titleWeight)*s
+		}
+		if s < payload.MinSimilarity {
+			continue
+		}
 		matchesWithSimilarity = append(matchesWithSimilarity, MatchesWithSimilarity{content: v, similarity: s})
 	}
 
-	// sorts matches based on similarity in descending order (the most similar match is the first element)
-	slices.SortFunc(matchesWithSimilarity, func(a, b MatchesWithSimilarity) int {
-		if a.similarity > b.similarity {
-			return -1
+	matchesWithSimilarity = dedupeRankedSearchMatches(matchesWithSimilarity)
+	sortSearchMatches(matchesWithSimilarity, payload.Pageable.Sort.Orders)
+
+	return matchesWithSimilarity, nil
+}
+
+// searchPage ranks all search matches in Go and returns the requested page together with the total match count
+func (hc *Controller) searchPage(ctx context.Context, payload MarkdownSearchPayload, pageSize int) ([]models.ScoredMarkdownContent, int, error) {
+	matchesWithSimilarity, err := hc.rankSearchMatches(ctx, payload)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading Markdown search matches: %w", err)
+	}
+
+	requestedPage := make([]models.ScoredMarkdownContent, 0, pageSize)
+	for _, v := range paginate(matchesWithSimilarity, payload.Pageable.PageNumber, pageSize) {
+		requestedPage = append(requestedPage, models.ScoredMarkdownContent{MarkdownContent: v.content, Similarity: v.similarity})
+	}
+
+	// all matches are ranked anyway; counting them in the database would take another query
+	// and count the matches dropped because of the minimum similarity as well
+	return requestedPage, len(matchesWithSimilarity), nil
+}
+
+// searchPageInDatabase leaves scoring, ranking, and paginating to the database (pg_trgm);
+// hence, the similarity is pg_trgm's and not the Sorensen-Dice coefficient.
+// The database only ranks by similarity; other orders are applied to all scored matches in Go.
+func (hc *Controller) searchPageInDatabase(ctx context.Context, payload MarkdownSearchPayload, pageSize int) ([]models.ScoredMarkdownContent, int, error) {
+	if !isDefaultSearchOrder(payload.Pageable.Sort.Orders) {
+		return hc.sortedSearchPageInDatabase(ctx, payload, pageSize)
+	}
+
+	scoredMatches := make([]models.ScoredMarkdownContent, 0, pageSize)
+	err := hc.FindMarkdownsBySearchTermPaged(ctx, payload.Term, payload.PathPrefix, payload.MinSimilarity, payload.Pageable.PageNumber, pageSize, &scoredMatches)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading Markdown search matches: %w", err)
+	}
+
+	var matchCount int
+	err = hc.CountMarkdownsMatchesBySearchTermRanked(ctx, payload.Term, payload.PathPrefix, payload.MinSimilarity, &matchCount)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting Markdown search matches: %w", err)
+	}
+
+	return scoredMatches, matchCount, nil
+}
+
+// sortedSearchPageInDatabase scores all search matches in the database (pg_trgm),
+// sorts them by the payload's orders, and returns the requested page together with the total match count
+func (hc *Controller) sortedSearchPageInDatabase(ctx context.Context, payload MarkdownSearchPayload, pageSize int) ([]models.ScoredMarkdownContent, int, error) {
+	scoredMatches := make([]models.ScoredMarkdownContent, 0)
+	err := hc.FindMarkdownsBySearchTermRanked(ctx, payload.Term, payload.PathPrefix, &scoredMatches)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading Markdown search matches: %w", err)
+	}
+
+	requestedPage, matchCount := pageScoredSearchMatches(scoredMatches, payload, pageSize)
+	return requestedPage, matchCount, nil
+}
+
+// searchPageByFullText leaves matching and scoring to Postgres' full-text search (see SearchBackend);
+// hence, the similarity is the full-text rank and not the Sorensen-Dice coefficient
+func (hc *Controller) searchPageByFullText(ctx context.Context, payload MarkdownSearchPayload, pageSize int) ([]models.ScoredMarkdownContent, int, error) {
+	scoredMatches := make([]models.ScoredMarkdownContent, 0)
+	err := hc.FindMarkdownsByFullText(ctx, payload.Term, payload.PathPrefix, &scoredMatches)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading Markdown search matches: %w", err)
+	}
+
+	requestedPage, matchCount := pageScoredSearchMatches(scoredMatches, payload, pageSize)
+	return requestedPage, matchCount, nil
+}
+
+// pageScoredSearchMatches drops the matches below the payload's minimum similarity, dedupes (see dedupeRankedSearchMatches)
+// and sorts the others by the payload's orders, and returns the requested page together with the number of remaining matches
+func pageScoredSearchMatches(scoredMatches []models.ScoredMarkdownContent, payload MarkdownSearchPayload, pageSize int) ([]models.ScoredMarkdownContent, int) {
+	matchesWithSimilarity := make([]MatchesWithSimilarity, 0, len(scoredMatches))
+	for _, v := range scoredMatches {
+		if v.Similarity < payload.MinSimilarity {
+			continue
+		}
+		matchesWithSimilarity = append(matchesWithSimilarity, MatchesWithSimilarity{content: v.MarkdownContent, similarity: v.Similarity})
+	}
+
+	matchesWithSimilarity = dedupeRankedSearchMatches(matchesWithSimilarity)
+	sortSearchMatches(matchesWithSimilarity, payload.Pageable.Sort.Orders)
+
+	requestedPage := make([]models.ScoredMarkdownContent, 0, pageSize)
+	for _, v := range paginate(matchesWithSimilarity, payload.Pageable.PageNumber, pageSize) {
+		requestedPage = append(requestedPage, models.ScoredMarkdownContent{MarkdownContent: v.content, Similarity: v.similarity})
+	}
+
+	return requestedPage, len(matchesWithSimilarity)
+}
+
+func (hc *Controller) suggestionThreshold() int {
+	if hc.SuggestionThreshold == 0 {
+		return DefaultSuggestionThreshold
+	}
+
+	return hc.SuggestionThreshold
+}
+
+func (hc *Controller) maxSuggestions() int {
+	if hc.MaxSuggestions <= 0 {
+		return DefaultMaxSuggestions
+	}
+
+	return hc.MaxSuggestions
+}
+
+func (hc *Controller) maxRelatedDocuments() int {
+	if hc.MaxRelatedDocuments <= 0 {
+		return DefaultMaxRelatedDocuments
+	}
+
+	return hc.MaxRelatedDocuments
+}
+
+// suggestMarkdownNames returns the names of the (visible) Markdown files that are most similar to the search term
+// (see TrigramSorensenDiceSimilarity), the most similar first; the names are compared as titles (see markdownTitle).
+//
+// Suggestions are a courtesy; hence, if the names cannot be read, the error is logged and no names are suggested.
+func (hc *Controller) suggestMarkdownNames(ctx context.Context, term string) []string {
+	var markdownMetas []models.MarkdownMeta
+	if err := hc.FindMarkdownMetasWhereCharCountGreaterThan(ctx, 0, &markdownMetas); err != nil {
+		hc.LogWarnf(logging.GetLogTypeWithContext(ctx, "markdown-doc"), "could not suggest Markdown names: %s", err)
+		return nil
+	}
+
+	type suggestion struct {
+		name       string
+		similarity float64
+	}
+
+	var suggestions []suggestion
+	seen := make(map[string]struct{})
+	docsRoot := hc.docsRoot()
+	for _, v := range markdownMetas {
+		// hidden Markdown files must not be revealed by suggestions
+		if v.IsHidden(docsRoot) {
+			continue
 		}
-		return 1
+
+		if _, ok := seen[v.Name]; ok {
+			continue
+		}
+		seen[v.Name] = struct{}{}
+
+		if similarity := TrigramSorensenDiceSimilarity(term, markdownTitle(v.Name)); similarity >= minSuggestionSimilarity {
+			suggestions = append(suggestions, suggestion{name: v.Name, similarity: similarity})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(a, b int) bool {
+		return suggestions[a].similarity > suggestions[b].similarity
 	})
 
-	firstPage := make([]models.MarkdownContent, 0, pageSize)
-	for _, v := range matchesWithSimilarity {
-		firstPage = append(firstPage, v.content)
+	suggestions = suggestions[:min(len(suggestions), hc.maxSuggestions())]
+
+	names := make([]string, 0, len(suggestions))
+	for _, v := range suggestions {
+		names = append(names, v.name)
 	}
 
-	var matchCount int
-	err = hc.CountMarkdownsMatchesBySearchTermSimple(ctx, payload.Term, &matchCount)
+	return names
+}
+
+// markdownTitle returns the name of a Markdown file the way it reads as a title, i.e., with spaces instead of underscores and dashes
+func markdownTitle(name string) string {
+	return strings.NewReplacer("_", " ", "-", " ").Replace(name)
+}
+
+// addSearchMatchDebug sets the trigram counts of the given matches; the i-th match must be mapped from the i-th content
+func (hc *Controller) addSearchMatchDebug(term string, contents []models.ScoredMarkdownContent, matches []MarkdownSearchMatch) {
+	termTrigrams := hc.uniqueTrigrams(term)
+	for i := range min(len(contents), len(matches)) {
+		contentTrigrams := hc.contentTrigrams(contents[i].MarkdownContent)
+		matches[i].Debug = &SearchMatchDebug{
+			QueryTrigramCount:   len(termTrigrams),
+			ContentTrigramCount: len(contentTrigrams),
+			IntersectionCount:   trigramIntersectionCount(termTrigrams, contentTrigrams),
+		}
+	}
+}
+
+// SetSearchThresholds replaces the TitleWeight and the MaxPageSize of a Controller in use, e.g., on a config reload;
+// it is safe to call while searches are running. The cached search pages are invalidated since they were ranked by the previous thresholds
+func (hc *Controller) SetSearchThresholds(titleWeight float64, maxPageSize int) {
+	hc.reloadedThresholds.Store(&searchThresholds{titleWeight: titleWeight, maxPageSize: maxPageSize})
+
+	if hc.SearchCache != nil {
+		hc.SearchCache.Invalidate()
+	}
+}
+
+// thresholds returns the TitleWeight and the MaxPageSize, unless they were replaced by SetSearchThresholds
+func (hc *Controller) thresholds() searchThresholds {
+	if reloaded := hc.reloadedThresholds.Load(); reloaded != nil {
+		return *reloaded
+	}
+
+	return searchThresholds{titleWeight: hc.TitleWeight, maxPageSize: hc.MaxPageSize}
+}
+
+// scorer returns the scorer selected by the payload or else the configured one, either a built-in TrigramSetScorer or a
+// registered Scorer; both are nil if neither selects one, in which case the matches are ranked by the SimilarityMetric
+func (hc *Controller) scorer(payload MarkdownSearchPayload) (Scorer, TrigramSetScorer) {
+	name := payload.Scorer
+	if len(name) == 0 {
+		name = hc.Scorer
+	}
+
+	if trigramSetScorer, ok := trigramSetScorers[name]; ok {
+		return nil, trigramSetScorer
+	}
+
+	scorer, _ := LookupScorer(name)
+	return scorer, nil
+}
+
+// trigramSetSimilarity computes the similarity of two sets of unique trigrams using the configured SimilarityMetric
+func (hc *Controller) trigramSetSimilarity(aTrigrams, bTrigrams []string) float64 {
+	switch hc.SimilarityMetric {
+	case constants.SimilarityMetricJaccard:
+		return TrigramSetJaccardSimilarity(aTrigrams, bTrigrams)
+	case constants.SimilarityMetricCosine:
+		return TrigramSetCosineSimilarity(aTrigrams, bTrigrams)
+	default:
+		return TrigramSetSorensenDiceSimilarity(aTrigrams, bTrigrams)
+	}
+}
+
+// uniqueTrigrams returns the unique trigrams of the given text as extracted by the Tokenizer
+func (hc *Controller) uniqueTrigrams(a string) []string {
+	return hc.Tokenizer.UniqueTrigrams(a)
+}
+
+// contentTrigrams returns the unique trigrams of the given content, served from the TrigramCache if one is set
+func (hc *Controller) contentTrigrams(content models.MarkdownContent) []string {
+	if hc.TrigramCache == nil {
+		return hc.uniqueTrigrams(content.Content)
+	}
+
+	return hc.TrigramCache.Trigrams(content, hc.Tokenizer)
+}
+
+// GetStats returns the number of stored Markdown files, their total characters, and the number of hidden and empty files.
+//
+// @ID getMarkdownStats
+// @Summary Get stats of the stored markdown files
+// @Tags markdown
+// @Router /markdown-doc/stats [get]
+// @Success 200 {object} models.MarkdownStats
+// @Failure 500
+func (hc *Controller) GetStats(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var stats models.MarkdownStats
+	if err := hc.CountAllMarkdowns(ctx, &stats); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error counting markdowns: %s", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetDocuments returns the requested page of the stored Markdown files without their contents, e.g., to browse all of them.
+// The query parameters pageNumber and pageSize select the page (default: the first page of the configured page size);
+// sort (name or updatedAt, default: name) and direction (ASC or DESC, default: ASC) select the order.
+// Hidden files are excluded unless includeHidden is true. The Link header points to the first, previous, next, and last page.
+//
+// @ID getDocuments
+// @Summary List the stored markdown files
+// @Tags markdown
+// @Router /markdown-doc/documents [get]
+// @Param pageNumber query int false "the 1-based page number"
+// @Param pageSize query int false "the page size"
+// @Param sort query string false "name or updatedAt"
+// @Param direction query string false "ASC or DESC"
+// @Param includeHidden query bool false "include the hidden files"
+// @Success 200 {object} markdowndoc.Page[models.MarkdownDocument]
+// @Failure 400
+// @Failure 500
+func (hc *Controller) GetDocuments(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	options, order, err := documentListOptions(c.Request.URL.Query())
 	if err != nil {
-		msg := fmt.Sprintf("error counting Markdown search matches: %s", err)
-		hc.LogError(logging.GetLogType("markdown-doc"), msg)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(msg))
+		msg := fmt.Sprintf("did not list documents because of an invalid query: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
 		return
 	}
 
-	page, err := hc.mapToMarkdownSearchPage(payload, pageSize, matchCount, firstPage)
+	pageable := Pageable{Sort: NewSort([]Order{order})}
+	err = pageable.overrideFromQuery(c.Request.URL.Query())
+	if err == nil {
+		err = pageable.Normalize(hc.DefaultPageSize, hc.thresholds().maxPageSize)
+	}
 	if err != nil {
-		msg := fmt.Sprintf("error mapping to page response: %s", err)
-		hc.LogError(logging.GetLogType("markdown-doc"), msg)
-		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(msg))
+		msg := fmt.Sprintf("did not list documents because of an invalid pageable: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	var documentCount int
+	if err := hc.CountMarkdownDocuments(ctx, options.IncludeHidden, &documentCount); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error counting documents: %s", err))
+		return
+	}
+
+	documents := make([]models.MarkdownDocument, 0, pageable.PageSize)
+	if err := hc.FindMarkdownDocumentsPaged(ctx, options, pageable.PageNumber, pageable.PageSize, &documents); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error listing documents: %s", err))
 		return
 	}
 
+	page := Page[models.MarkdownDocument]{
+		Content:       documents,
+		Pageable:      pageable,
+		TotalElements: documentCount,
+		TotalPages:    utils.CalculateTotalPages(documentCount, pageable.PageSize),
+	}
+	page.setNavigation()
+
+	c.Header("Link", paginationLinks(c.Request.URL, page.Pageable.PageNumber, page.Pageable.PageSize, page.TotalPages))
 	c.JSON(http.StatusOK, page)
 }
+
+// documentListOptions parses the query parameters sort, direction, and includeHidden of GetDocuments;
+// it also returns the order for the page's Pageable
+func documentListOptions(query url.Values) (database.DocumentListOptions, Order, error) {
+	options := database.DocumentListOptions{SortBy: database.DocumentSortName}
+	if query.Has("sort") {
+		switch sortBy := strings.TrimSpace(query.Get("sort")); sortBy {
+		case database.DocumentSortName, database.DocumentSortUpdatedAt:
+			options.SortBy = sortBy
+		default:
+			return options, Order{}, fmt.Errorf("unknown sort property %q; must be %q or %q", sortBy, database.DocumentSortName, database.DocumentSortUpdatedAt)
+		}
+	}
+
+	direction := ASC
+	if query.Has("direction") {
+		direction = Direction(strings.ToUpper(strings.TrimSpace(query.Get("direction"))))
+		if direction != ASC && direction != DESC {
+			return options, Order{}, fmt.Errorf("unknown sort direction %q; must be %q or %q", query.Get("direction"), ASC, DESC)
+		}
+	}
+	options.Descending = direction == DESC
+
+	if query.Has("includeHidden") {
+		includeHidden, err := strconv.ParseBool(query.Get("includeHidden"))
+		if err != nil {
+			return options, Order{}, fmt.Errorf("the includeHidden flag (%s) is not a boolean", query.Get("includeHidden"))
+		}
+		options.IncludeHidden = includeHidden
+	}
+
+	return options, Order{Property: options.SortBy, Direction: direction}, nil
+}
+
+// GetRelatedDocuments returns the Markdown files whose contents are the most similar to the content of the named one
+// (see TrigramSorensenDiceSimilarity), the most similar first; the named file itself is excluded.
+// The query parameter limit caps the number of returned files (default and maximum: the configured MaxRelatedDocuments).
+// Like a search, it considers neither hidden files nor files with fewer characters than the minimum.
+//
+// @ID getRelatedDocuments
+// @Summary Get the markdown files similar to a markdown file
+// @Tags markdown
+// @Router /markdown-doc/related/{name} [get]
+// @Param name path string true "Markdown file name without extension"
+// @Param limit query int false "the maximum number of related files"
+// @Success 200 {object} []markdowndoc.RelatedDocument
+// @Failure 400
+// @Failure 404
+// @Failure 500
+func (hc *Controller) GetRelatedDocuments(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	name := c.Param("name")
+	if len(name) <= 0 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse("path variable 'name' is missing"))
+		return
+	}
+
+	limit := hc.maxRelatedDocuments()
+	if query := c.Query("limit"); len(query) > 0 {
+		requested, err := strconv.Atoi(query)
+		if err != nil || requested <= 0 {
+			msg := fmt.Sprintf("did not find related documents because the limit (%s) is not a positive integer", query)
+			hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), msg)
+			c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+			return
+		}
+		limit = min(limit, requested)
+	}
+
+	var markdownContent models.MarkdownContent
+	err := hc.FindMarkdownContentByName(ctx, name, &markdownContent)
+	if errors.Is(err, database.ErrMarkdownNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, api.NewErrorResponsef("no markdown found with name %s", name))
+		return
+	}
+	if err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown content: %s", err))
+		return
+	}
+
+	var candidates []models.MarkdownContent
+	if err := hc.FindVisibleMarkdowns(ctx, &candidates); err != nil {
+		hc.LogError(logging.GetLogTypeWithContext(ctx, "markdown-doc"), err)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponsef("error reading markdown contents: %s", err))
+		return
+	}
+
+	trigrams := hc.contentTrigrams(markdownContent)
+	related := make([]RelatedDocument, 0, len(candidates))
+	for _, candidate := range candidates {
+		// names are not unique across folders; hence, the file itself is told apart by its path, too
+		if candidate.Meta.Path == markdownContent.Meta.Path && candidate.Meta.Name == markdownContent.Meta.Name {
+			continue
+		}
+
+		related = append(related, RelatedDocument{
+			Name:       candidate.Meta.Name,
+			Path:       candidate.Meta.Path,
+			Similarity: TrigramSetSorensenDiceSimilarity(trigrams, hc.contentTrigrams(candidate)),
+		})
+	}
+
+	// the files of the same similarity are ordered by path and name, so that the result is deterministic
+	slices.SortStableFunc(related, func(a, b RelatedDocument) int {
+		return cmp.Or(
+			cmp.Compare(b.Similarity, a.Similarity),
+			strings.Compare(a.Path, b.Path),
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+
+	c.JSON(http.StatusOK, related[:min(len(related), limit)])
+}
+
+// GetSimilarity computes the trigram-based Sorensen-Dice similarity of two arbitrary strings.
+//
+// @ID getSimilarity
+// @Summary Compare two strings using the trigram-based Sorensen-Dice coefficient
+// @Tags markdown
+// @Router /markdown-doc/similarity [post]
+// @Param payload body markdowndoc.SimilarityPayload true "The strings to compare"
+// @Success 200 {object} markdowndoc.SimilarityResponse
+// @Failure 400
+// @Failure 500
+func (hc *Controller) GetSimilarity(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		msg := fmt.Sprintf("error while reading request body: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	var payload SimilarityPayload
+	err = json.Unmarshal(body, &payload)
+	if err != nil {
+		msg := fmt.Sprintf("error while unmarshaling request body: %s", err)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	if len(payload.A) <= 0 || len(payload.B) <= 0 {
+		msg := "did not compute similarity because 'a' or 'b' was not present"
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusBadRequest, api.NewErrorResponse(msg))
+		return
+	}
+
+	trigramsA, trigramsB := hc.uniqueTrigrams(payload.A), hc.uniqueTrigrams(payload.B)
+	similarity := TrigramSetSorensenDiceSimilarity(trigramsA, trigramsB)
+	if !(similarity >= 0 && similarity <= 1) {
+		msg := fmt.Sprintf("computed similarity (%v) is not within [0,1]", similarity)
+		hc.LogError(logging.GetLogTypeWithContext(c.Request.Context(), "markdown-doc"), msg)
+		c.AbortWithStatusJSON(http.StatusInternalServerError, api.NewErrorResponse(msg))
+		return
+	}
+
+	response := SimilarityResponse{
+		Similarity: similarity,
+		TrigramsA:  trigramsA,
+		TrigramsB:  trigramsB,
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// paginate returns the elements of the page with the given (1-based) page number.
+// Page numbers less than 1 are treated as the first page.
+// If the page lies beyond the last element, an empty slice is returned.
+func paginate[T any](elements []T, pageNumber, pageSize int) []T {
+	if pageSize <= 0 {
+		return []T{}
+	}
+
+	if pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	// checked before computing the start, which could overflow for a page far beyond the elements
+	if pageNumber-1 > len(elements)/pageSize {
+		return []T{}
+	}
+
+	start := (pageNumber - 1) * pageSize
+	if start >= len(elements) {
+		return []T{}
+	}
+
+	end := min(start+pageSize, len(elements))
+
+	return elements[start:end]
+}
@@ -3,24 +3,39 @@ package markdowndoc_test
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"dice-sorensen-similarity-search/internal/constants"
 	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/markdowndoc"
 	"dice-sorensen-similarity-search/internal/models"
+	"dice-sorensen-similarity-search/internal/tracing"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
-	"log/slog"
 	"math"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 // ####################### valid behavior tests
@@ -64,6 +79,346 @@ func TestGetNavigationItemsTrees_Success(t *testing.T) {
 			return
 		}
 	}
+
+	for _, root := range got {
+		if root.ParentHref != "" {
+			t.Errorf("want root %s to have an empty parentHref, got %s", root.Href, root.ParentHref)
+			return
+		}
+
+		for _, child := range root.Children {
+			if child.ParentHref != root.Href {
+				t.Errorf("want child %s to have parentHref %s, got %s", child.Href, root.Href, child.ParentHref)
+				return
+			}
+		}
+	}
+}
+
+func performNavigationItemsTreesRequest(ctrl *markdowndoc.Controller, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/navigation-items?"+query, nil)
+
+	ctrl.GetNavigationItemsTrees(c)
+
+	return w
+}
+
+func TestGetNavigationItemsTrees_Success_depth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	repo.markdownMetas = []models.MarkdownMeta{
+		{Name: "File1", Path: "markdowns/1_Root/Level1/Level2", CharCount: 350},
+		{Name: "File2", Path: "markdowns/1_Root/Level1", CharCount: 350},
+		{Name: "Other", Path: "markdowns/Sibling", CharCount: 350},
+		{Name: "Top_Level", Path: "markdowns", CharCount: 350},
+	}
+
+	w := performNavigationItemsTreesRequest(newMockController(repo), "depth=1")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+
+	var got []*markdowndoc.NavigationItem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshalling error: %v", err)
+	}
+
+	// the roots and their children are returned; "hasChildren" tells whether an item has (possibly pruned) children
+	want := []string{"Root hasChildren", "Level1 (Root) hasChildren", "Sibling hasChildren", "Other (Sibling)", "Top_Level"}
+
+	var gotItems []string
+	var collect func(item *markdowndoc.NavigationItem)
+	collect = func(item *markdowndoc.NavigationItem) {
+		description := item.Href
+		if len(item.ParentHref) > 0 {
+			description += " (" + item.ParentHref + ")"
+		}
+		if item.HasChildren {
+			description += " hasChildren"
+		}
+		gotItems = append(gotItems, description)
+
+		for _, child := range item.Children {
+			collect(child)
+		}
+	}
+	for _, tree := range got {
+		collect(tree)
+	}
+
+	if !cmp.Equal(want, gotItems) {
+		t.Error(cmp.Diff(want, gotItems))
+		return
+	}
+}
+
+func TestGetNavigationItemsTrees_BadRequestBecauseInvalidDepth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	for _, query := range []string{"depth=-1", "depth=one"} {
+		w := performNavigationItemsTreesRequest(newMockController(newMockRepository()), query)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want 400", query, w.Code)
+			return
+		}
+	}
+}
+
+// performBreadcrumbRequest requests the breadcrumb of the given href from the given controller
+func performBreadcrumbRequest(ctrl *markdowndoc.Controller, href string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/breadcrumb/"+href, nil)
+	c.Params = gin.Params{{Key: "href", Value: href}}
+
+	ctrl.GetBreadcrumb(c)
+
+	return w
+}
+
+func TestGetBreadcrumb_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	nestedRepo := newMockRepository()
+	nestedRepo.markdownMetas = []models.MarkdownMeta{
+		{Name: "File1", Path: "markdowns/1_Root/Level1/Level2", CharCount: 350},
+		{Name: "File2", Path: "markdowns/1_Root/Level1", CharCount: 350},
+	}
+
+	tests := []struct {
+		name      string
+		repo      *mockRepository
+		href      string
+		wantHrefs []string
+	}{
+		{name: "leafBelowRoot", repo: newMockRepository(), href: "1_Onboarding", wantHrefs: []string{"Gateway", "1_Onboarding"}},
+		{name: "nestedLeaf", repo: nestedRepo, href: "File1", wantHrefs: []string{"Root", "Level1", "Level2", "File1"}},
+		// File2's branch is merged into File1's; its ancestors must nevertheless be the merged (and prefix-free) ones
+		{name: "leafOfMergedBranch", repo: nestedRepo, href: "File2", wantHrefs: []string{"Root", "Level1", "File2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performBreadcrumbRequest(newMockController(tt.repo), tt.href)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200", w.Code)
+			}
+
+			var got []markdowndoc.NavigationItem
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshalling error: %v", err)
+			}
+
+			gotHrefs := make([]string, 0, len(got))
+			for _, v := range got {
+				gotHrefs = append(gotHrefs, v.Href)
+			}
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+		})
+	}
+}
+
+func TestGetBreadcrumb_NotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	// folders and Markdown files without content are not leaves of the navigation
+	for _, href := range []string{"does_not_exist", "Gateway", "Empty_Markdown"} {
+		w := performBreadcrumbRequest(ctrl, href)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("for href %s got status %d, want 404", href, w.Code)
+			return
+		}
+	}
+}
+
+func performTreeRequest(ctrl *markdowndoc.Controller, root string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/tree/"+root, nil)
+	c.Params = gin.Params{{Key: "root", Value: root}}
+
+	ctrl.GetNavigationItemTree(c)
+
+	return w
+}
+
+func TestGetNavigationItemTree_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	repo.markdownMetas = []models.MarkdownMeta{
+		{Name: "File1", Path: "markdowns/1_Root/Level1/Level2", CharCount: 350},
+		{Name: "File2", Path: "markdowns/1_Root/Level1", CharCount: 350},
+		{Name: "Other", Path: "markdowns/Sibling", CharCount: 350},
+		{Name: "Top_Level", Path: "markdowns", CharCount: 350},
+	}
+
+	// the number prefix of the root is removed, as in the navigation item trees
+	w := performTreeRequest(newMockController(repo), "Root")
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+
+	var got markdowndoc.NavigationItem
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unmarshalling error: %v", err)
+	}
+
+	want := []string{"Root", "Level1 (Root)", "File2 (Level1)", "Level2 (Level1)", "File1 (Level2)"}
+
+	var gotHrefs []string
+	var collect func(item markdowndoc.NavigationItem)
+	collect = func(item markdowndoc.NavigationItem) {
+		href := item.Href
+		if len(item.ParentHref) > 0 {
+			href += " (" + item.ParentHref + ")"
+		}
+		gotHrefs = append(gotHrefs, href)
+
+		for _, child := range item.Children {
+			collect(*child)
+		}
+	}
+	collect(got)
+
+	if !cmp.Equal(want, gotHrefs) {
+		t.Error(cmp.Diff(want, gotHrefs))
+		return
+	}
+}
+
+func performNavigationSearchRequest(t *testing.T, ctrl *markdowndoc.Controller, payload markdowndoc.NavigationSearchPayload) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Request = httptest.NewRequest(http.MethodPost, "/markdown-doc/navigation-search", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	ctrl.GetNavigationSearchMatches(c)
+
+	return w
+}
+
+func TestGetNavigationSearchMatches_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	repo.markdownMetas = []models.MarkdownMeta{
+		{Name: "Welcome", Path: "markdowns/1_Root/Onboarding", CharCount: 350},
+		{Name: "Laptop_Setup", Path: "markdowns/1_Root/Onboarding", CharCount: 350},
+		{Name: "Deployment", Path: "markdowns/1_Root/Operations", CharCount: 350},
+	}
+
+	tests := []struct {
+		name     string
+		payload  markdowndoc.NavigationSearchPayload
+		wantTop  string
+		wantLen  int
+		noResult bool
+	}{
+		{name: "misspelledFolder", payload: markdowndoc.NavigationSearchPayload{Query: "Onbaording"}, wantTop: "Onboarding"},
+		{name: "misspelledFile", payload: markdowndoc.NavigationSearchPayload{Query: "laptop setpu"}, wantTop: "Laptop_Setup"},
+		{name: "limit", payload: markdowndoc.NavigationSearchPayload{Query: "Onboarding", Limit: 1}, wantTop: "Onboarding", wantLen: 1},
+		{name: "noMatch", payload: markdowndoc.NavigationSearchPayload{Query: "xyz"}, noResult: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performNavigationSearchRequest(t, newMockController(repo), tt.payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("got status %d, want 200", w.Code)
+			}
+
+			var got []markdowndoc.NavigationSearchMatch
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("unmarshalling error: %v", err)
+			}
+
+			if tt.noResult {
+				if len(got) != 0 {
+					t.Errorf("want no matches, got %v", got)
+				}
+				return
+			}
+
+			if len(got) == 0 || got[0].Href != tt.wantTop {
+				t.Errorf("want %s on top, got %v", tt.wantTop, got)
+				return
+			}
+
+			if tt.wantLen > 0 && len(got) != tt.wantLen {
+				t.Errorf("want %d match(es), got %d", tt.wantLen, len(got))
+				return
+			}
+		})
+	}
+}
+
+func TestGetNavigationSearchMatches_BadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	w := performNavigationSearchRequest(t, newMockController(newMockRepository()), markdowndoc.NavigationSearchPayload{Query: "  "})
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("got status %d, want 400", w.Code)
+		return
+	}
+}
+
+func TestGetNavigationItemTree_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		root     string
+		wantCode int
+	}{
+		{name: "missingRoot", root: "", wantCode: http.StatusBadRequest},
+		{name: "unknownRoot", root: "does_not_exist", wantCode: http.StatusNotFound},
+		// only roots have a tree of their own
+		{name: "nestedFolder", root: "Level1", wantCode: http.StatusNotFound},
+	}
+
+	repo := newMockRepository()
+	repo.markdownMetas = []models.MarkdownMeta{
+		{Name: "File2", Path: "markdowns/1_Root/Level1", CharCount: 350},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performTreeRequest(newMockController(repo), tt.root)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("got status %d, want %d", w.Code, tt.wantCode)
+				return
+			}
+		})
+	}
 }
 
 func newMockController(repo database.Repository) *markdowndoc.Controller {
@@ -193,48 +548,55 @@ func getExpectedNavigationItemTree() []*markdowndoc.NavigationItem {
 
 	wantGatewayChildren := []*markdowndoc.NavigationItem{
 		{
-			Label:    "1 Onboarding",
-			Href:     "1_Onboarding",
-			Parent:   nil,
-			Children: nil,
+			Label:      "1 Onboarding",
+			Href:       "1_Onboarding",
+			Parent:     nil,
+			ParentHref: "Gateway",
+			Children:   nil,
 		},
 		{
-			Label:    "2 Data Preparation",
-			Href:     "2_Data_Preparation",
-			Parent:   nil,
-			Children: nil,
+			Label:      "2 Data Preparation",
+			Href:       "2_Data_Preparation",
+			Parent:     nil,
+			ParentHref: "Gateway",
+			Children:   nil,
 		},
 		{
-			Label:    "3 Visualization",
-			Href:     "3_Visualization",
-			Parent:   nil,
-			Children: nil,
+			Label:      "3 Visualization",
+			Href:       "3_Visualization",
+			Parent:     nil,
+			ParentHref: "Gateway",
+			Children:   nil,
 		},
 		{
-			Label:    "4 Technical Docs",
-			Href:     "4_Technical_Docs",
-			Parent:   nil,
-			Children: nil,
+			Label:      "4 Technical Docs",
+			Href:       "4_Technical_Docs",
+			Parent:     nil,
+			ParentHref: "Gateway",
+			Children:   nil,
 		},
 		{
-			Label:    "5 OpenTelemetry",
-			Href:     "5_OpenTelemetry",
-			Parent:   nil,
-			Children: nil,
+			Label:      "5 OpenTelemetry",
+			Href:       "5_OpenTelemetry",
+			Parent:     nil,
+			ParentHref: "Gateway",
+			Children:   nil,
 		},
 	}
 	wantGuidelinesChildren := []*markdowndoc.NavigationItem{
 		{
-			Label:    "Getting Started",
-			Href:     "Getting_Started",
-			Parent:   nil,
-			Children: nil,
+			Label:      "Getting Started",
+			Href:       "Getting_Started",
+			Parent:     nil,
+			ParentHref: "Guidelines",
+			Children:   nil,
 		},
 		{
-			Label:    "Migration Guide",
-			Href:     "Migration_Guide",
-			Parent:   nil,
-			Children: nil,
+			Label:      "Migration Guide",
+			Href:       "Migration_Guide",
+			Parent:     nil,
+			ParentHref: "Guidelines",
+			Children:   nil,
 		},
 	}
 
@@ -288,72 +650,310 @@ func TestGetMarkdownByName_Success(t *testing.T) {
 	}
 }
 
-func TestGetMarkdownSearchTermMatches_Success(t *testing.T) {
-	gin.SetMode(gin.TestMode)
+func TestGetMarkdownByName_conditional(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 12, 30, 15, 500, time.UTC)
+	wantETag := `W/"4a1c"`
+	wantLastModified := "Sat, 01 Mar 2025 12:30:15 GMT"
+
+	tests := []struct {
+		name     string
+		header   map[string]string
+		wantCode int
+	}{
+		{name: "firstFetch", wantCode: http.StatusOK},
+		{name: "matchingETag", header: map[string]string{"If-None-Match": wantETag}, wantCode: http.StatusNotModified},
+		{name: "matchingStrongETag", header: map[string]string{"If-None-Match": `"other", "4a1c"`}, wantCode: http.StatusNotModified},
+		{name: "otherETag", header: map[string]string{"If-None-Match": `W/"other"`}, wantCode: http.StatusOK},
+		{name: "notModifiedSince", header: map[string]string{"If-Modified-Since": wantLastModified}, wantCode: http.StatusNotModified},
+		{name: "modifiedSince", header: map[string]string{"If-Modified-Since": "Sat, 01 Mar 2025 12:30:14 GMT"}, wantCode: http.StatusOK},
+		{
+			name:     "eTagTakesPrecedence",
+			header:   map[string]string{"If-None-Match": `W/"other"`, "If-Modified-Since": wantLastModified},
+			wantCode: http.StatusOK,
+		},
+	}
 
-	mockedRepo := newMockRepository()
-	ctrl := newMockController(mockedRepo)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
 
-	wantTerm := "this"
-	wantPageSize := 13
-	wantNumberOfMatches := 9
+			c.Params = []gin.Param{{Key: "name", Value: "guide"}}
 
-	payload := markdowndoc.MarkdownSearchPayload{
-		Term: wantTerm,
-		Pageable: markdowndoc.Pageable{
-			PageSize:   wantPageSize,
-			PageNumber: 1,
-		},
-	}
+			mock := &mockRepository{
+				markdownContent: map[string]models.MarkdownContent{
+					"guide": {Model: models.Model{UpdatedAt: updatedAt}, Content: "# Welcome", Hash: "4a1c"},
+				},
+			}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		t.Fatalf("failed to marshal payload: %v", err)
-	}
+			ctrl := newMockController(mock)
 
-	req, err := http.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(body))
-	if err != nil {
-		t.Fatalf("failed to create request: %v", err)
+			c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/markdown/guide", nil)
+			for k, v := range tt.header {
+				c.Request.Header.Set(k, v)
+			}
+
+			ctrl.GetMarkdownByName(c)
+			c.Writer.WriteHeaderNow()
+
+			if w.Code != tt.wantCode {
+				t.Errorf("expected %d, got %d", tt.wantCode, w.Code)
+				return
+			}
+
+			if got := w.Header().Get("ETag"); got != wantETag {
+				t.Errorf("want ETag %s, got %s", wantETag, got)
+				return
+			}
+
+			if got := w.Header().Get("Last-Modified"); got != wantLastModified {
+				t.Errorf("want Last-Modified %s, got %s", wantLastModified, got)
+				return
+			}
+
+			if gotContent := strings.Contains(w.Body.String(), "Welcome"); gotContent != (tt.wantCode == http.StatusOK) {
+				t.Errorf("want content in the body: %t, got body %q", tt.wantCode == http.StatusOK, w.Body.String())
+				return
+			}
+		})
 	}
-	req.Header.Set("Content-Type", "application/json")
+}
 
+func TestGetMarkdownByName_eTagWithoutHash(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
-	c.Request = req
 
-	ctrl.GetMarkdownSearchTermMatches(c)
+	c.Params = []gin.Param{{Key: "name", Value: "guide"}}
 
-	if w.Code != http.StatusOK {
-		t.Errorf("expected status 200 OK, got %d", w.Code)
-		return
+	mock := &mockRepository{
+		markdownContent: map[string]models.MarkdownContent{
+			"guide": {Content: "# Welcome"},
+		},
 	}
 
-	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
-	err = json.Unmarshal(w.Body.Bytes(), &page)
-	if err != nil {
-		t.Errorf("failed to unmarshal response: %v", err)
-		return
-	}
+	ctrl := newMockController(mock)
 
-	if len(page.Content) != wantNumberOfMatches {
-		t.Errorf("want %d matches, got %d", wantNumberOfMatches, len(page.Content))
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/markdown/guide", nil)
+
+	ctrl.GetMarkdownByName(c)
+
+	// the SHA-256 of "# Welcome"
+	sum := sha256.Sum256([]byte("# Welcome"))
+	wantETag := `W/"` + hex.EncodeToString(sum[:]) + `"`
+	if got := w.Header().Get("ETag"); got != wantETag {
+		t.Errorf("want ETag %s, got %s", wantETag, got)
 		return
 	}
 
-	compiledRegex, err := regexp.Compile("^\\d+")
-	if err != nil {
-		t.Errorf("failed to compile regex: %v", err)
+	if got := w.Header().Get("Last-Modified"); len(got) > 0 {
+		t.Errorf("want no Last-Modified for an unknown modification time, got %s", got)
 		return
 	}
+}
 
-	for _, match := range page.Content {
-		if strings.HasPrefix(match.Path, ".") {
-			t.Errorf("want 0 hidden elements, got label \"%s\" / path \"%s\"", match.Label, match.Path)
-			return
-		}
+func TestGetStats_Success(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
 
-		if compiledRegex.MatchString(match.Path) {
-			t.Errorf("want 0 prefixed path roots, got label \"%s\" / path \"%s\"", match.Label, match.Path)
+	mock := &mockRepository{
+		stats: models.MarkdownStats{Documents: 12, TotalChars: 34567, Hidden: 2, Empty: 1},
+	}
+
+	ctrl := newMockController(mock)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/stats", nil)
+
+	ctrl.GetStats(c)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+		return
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	want := map[string]any{"documents": 12.0, "totalChars": 34567.0, "hidden": 2.0, "empty": 1.0}
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestGetStats_DBError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	mock := &mockRepository{statsErr: errors.New("db failure")}
+
+	ctrl := newMockController(mock)
+
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/stats", nil)
+
+	ctrl.GetStats(c)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+		return
+	}
+}
+
+func performDocumentsRequest(ctrl *markdowndoc.Controller, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/documents?"+query, nil)
+
+	ctrl.GetDocuments(c)
+
+	return w
+}
+
+func TestGetDocuments_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	day := func(d int) time.Time { return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC) }
+	mock := &mockRepository{
+		documents: []models.MarkdownDocument{
+			{Name: "Kafka", Path: "markdowns/Messaging", CharCount: 10, UpdatedAt: day(2)},
+			{Name: "Draft", Path: "markdowns/.drafts", CharCount: 20, UpdatedAt: day(4)},
+			{Name: "Auth", Path: "markdowns/Security", CharCount: 30, UpdatedAt: day(1)},
+			{Name: "Guide", Path: "markdowns", CharCount: 40, UpdatedAt: day(3)},
+		},
+	}
+	ctrl := newMockController(mock)
+
+	tests := []struct {
+		name              string
+		query             string
+		wantNames         []string
+		wantTotalElements int
+		wantTotalPages    int
+	}{
+		{name: "defaults", query: "", wantNames: []string{"Auth", "Guide", "Kafka"}, wantTotalElements: 3, wantTotalPages: 1},
+		{name: "byNameDescending", query: "direction=desc", wantNames: []string{"Kafka", "Guide", "Auth"}, wantTotalElements: 3, wantTotalPages: 1},
+		{name: "byUpdatedAtDescending", query: "sort=updatedAt&direction=DESC&pageSize=2&pageNumber=1", wantNames: []string{"Guide", "Kafka"}, wantTotalElements: 3, wantTotalPages: 2},
+		{name: "secondPage", query: "sort=updatedAt&direction=DESC&pageSize=2&pageNumber=2", wantNames: []string{"Auth"}, wantTotalElements: 3, wantTotalPages: 2},
+		{name: "includingHidden", query: "sort=updatedAt&direction=DESC&includeHidden=true", wantNames: []string{"Draft", "Guide", "Kafka", "Auth"}, wantTotalElements: 4, wantTotalPages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performDocumentsRequest(ctrl, tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var page markdowndoc.Page[models.MarkdownDocument]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			gotNames := make([]string, 0, len(page.Content))
+			for _, document := range page.Content {
+				gotNames = append(gotNames, document.Name)
+			}
+
+			if !cmp.Equal(tt.wantNames, gotNames) {
+				t.Error(cmp.Diff(tt.wantNames, gotNames))
+				return
+			}
+
+			if page.TotalElements != tt.wantTotalElements || page.TotalPages != tt.wantTotalPages {
+				t.Errorf("want %d elements on %d pages, got %d on %d", tt.wantTotalElements, tt.wantTotalPages, page.TotalElements, page.TotalPages)
+				return
+			}
+
+			if w.Header().Get("Link") == "" {
+				t.Error("want a Link header")
+				return
+			}
+		})
+	}
+}
+
+func TestGetDocuments_BadRequest(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(&mockRepository{})
+
+	for _, query := range []string{"sort=size", "direction=up", "includeHidden=maybe", "pageNumber=second"} {
+		t.Run(query, func(t *testing.T) {
+			if w := performDocumentsRequest(ctrl, query); w.Code != http.StatusBadRequest {
+				t.Errorf("expected status 400 Bad Request, got %d", w.Code)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := newMockRepository()
+	ctrl := newMockController(mockedRepo)
+
+	wantTerm := "this"
+	wantPageSize := 13
+	wantNumberOfMatches := 9
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term: wantTerm,
+		Pageable: markdowndoc.Pageable{
+			PageSize:   wantPageSize,
+			PageNumber: 1,
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.GetMarkdownSearchTermMatches(c)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 OK, got %d", w.Code)
+		return
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Errorf("failed to unmarshal response: %v", err)
+		return
+	}
+
+	if len(page.Content) != wantNumberOfMatches {
+		t.Errorf("want %d matches, got %d", wantNumberOfMatches, len(page.Content))
+		return
+	}
+
+	compiledRegex, err := regexp.Compile("^\\d+")
+	if err != nil {
+		t.Errorf("failed to compile regex: %v", err)
+		return
+	}
+
+	for _, match := range page.Content {
+		if strings.HasPrefix(match.Path, ".") {
+			t.Errorf("want 0 hidden elements, got label \"%s\" / path \"%s\"", match.Label, match.Path)
+			return
+		}
+
+		if compiledRegex.MatchString(match.Path) {
+			t.Errorf("want 0 prefixed path roots, got label \"%s\" / path \"%s\"", match.Label, match.Path)
 			return
 		}
 
@@ -394,7 +994,7 @@ func TestGetMarkdownSearchTermMatches_Success(t *testing.T) {
 		}
 	}
 
-	wantTotalElements := 100
+	wantTotalElements := wantNumberOfMatches
 	if page.TotalElements != wantTotalElements {
 		t.Errorf("want %d total elements, got %d", wantTotalElements, page.TotalElements)
 		return
@@ -436,37 +1036,1955 @@ func TestGetMarkdownSearchTermMatches_Success_matchesOnlyHiddenMarkdowns(t *test
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-	c.Request = req
-
-	ctrl.GetMarkdownSearchTermMatches(c)
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.GetMarkdownSearchTermMatches(c)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status 200 OK, got %d", w.Code)
+		return
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Errorf("failed to unmarshal response: %v", err)
+		return
+	}
+
+	if len(page.Content) != wantNumberOfMatches {
+		t.Errorf("want %d matches, got %d", wantNumberOfMatches, len(page.Content))
+		return
+	}
+
+	if page.TotalElements != wantTotalElements {
+		t.Errorf("want %d total elements, got %d", wantTotalElements, page.TotalElements)
+		return
+	}
+
+	wantTotalPages := int(math.Ceil(float64(wantTotalElements) / float64(wantPageSize)))
+	if page.TotalPages != wantTotalPages {
+		t.Errorf("want %d total pages, got %d", wantTotalPages, page.TotalPages)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_bestMatchBeyondFirstPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	wantTerm := "kafka"
+	wantBestMatch := "kafka_overview"
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "weak_match_1", Path: "markdowns/Gateway"}, Content: "this document barely mentions kafka between a lot of unrelated words"},
+			{Meta: models.MarkdownMeta{Name: "weak_match_2", Path: "markdowns/Gateway"}, Content: "another long document about grafana dashboards that mentions kafka once"},
+			{Meta: models.MarkdownMeta{Name: "weak_match_3", Path: "markdowns/Gateway"}, Content: "the onboarding guide describes many steps and lists kafka as a dependency"},
+			{Meta: models.MarkdownMeta{Name: wantBestMatch, Path: "markdowns/Gateway"}, Content: "kafka"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	// the best match is the last row returned by the repository (i.e., on the second page)
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term: wantTerm,
+		Pageable: markdowndoc.Pageable{
+			PageSize:   2,
+			PageNumber: 1,
+		},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(page.Content) != 2 {
+		t.Fatalf("want 2 matches, got %d", len(page.Content))
+	}
+
+	if page.Content[0].Href != wantBestMatch {
+		t.Errorf("want best match %s on top of the first page, got %s", wantBestMatch, page.Content[0].Href)
+		return
+	}
+
+	// the second page must not contain the best match again
+	payload.Pageable.PageNumber = 2
+	w = performSearchRequest(t, ctrl, payload)
+
+	err = json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(page.Content) != 2 {
+		t.Fatalf("want 2 matches on the second page, got %d", len(page.Content))
+	}
+
+	for _, match := range page.Content {
+		if match.Href == wantBestMatch {
+			t.Errorf("want best match %s only on the first page, but found it on the second page", wantBestMatch)
+			return
+		}
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_minSimilarity(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "weak_match", Path: "markdowns/Gateway"}, Content: "this document barely mentions kafka between a lot of unrelated words"},
+			{Meta: models.MarkdownMeta{Name: "strong_match", Path: "markdowns/Gateway"}, Content: "kafka streams"},
+			{Meta: models.MarkdownMeta{Name: "exact_match", Path: "markdowns/Gateway"}, Content: "kafka"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	wantPageSize := 1
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:          "kafka",
+		MinSimilarity: 0.5,
+		Pageable: markdowndoc.Pageable{
+			PageSize:   wantPageSize,
+			PageNumber: 1,
+		},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	// the weak match must not be counted (even though the mocked database count is 100)
+	wantTotalElements := 2
+	if page.TotalElements != wantTotalElements {
+		t.Errorf("want %d total elements, got %d", wantTotalElements, page.TotalElements)
+		return
+	}
+
+	wantTotalPages := 2
+	if page.TotalPages != wantTotalPages {
+		t.Errorf("want %d total pages, got %d", wantTotalPages, page.TotalPages)
+		return
+	}
+
+	if len(page.Content) != wantPageSize || page.Content[0].Href != "exact_match" {
+		t.Errorf("want exact_match as only match on the first page, got %v", page.Content)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_linkHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "kafka_1", Path: "markdowns/Kafka"}, Content: "kafka"},
+			{Meta: models.MarkdownMeta{Name: "kafka_2", Path: "markdowns/Kafka"}, Content: "kafka"},
+			{Meta: models.MarkdownMeta{Name: "kafka_3", Path: "markdowns/Kafka"}, Content: "kafka"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	tests := []struct {
+		name       string
+		pageNumber int
+		query      string
+		wantPage   int
+		wantLinks  map[string]int
+	}{
+		{name: "first", pageNumber: 1, wantPage: 1, wantLinks: map[string]int{"first": 1, "next": 2, "last": 3}},
+		{name: "middle", pageNumber: 2, wantPage: 2, wantLinks: map[string]int{"first": 1, "prev": 1, "next": 3, "last": 3}},
+		{name: "last", pageNumber: 3, wantPage: 3, wantLinks: map[string]int{"first": 1, "prev": 2, "last": 3}},
+		{name: "followedLink", pageNumber: 1, query: "debug=false&pageNumber=3&pageSize=1", wantPage: 3, wantLinks: map[string]int{"first": 1, "prev": 2, "last": 3}},
+	}
+
+	linkPattern := regexp.MustCompile(`^<([^>]+)>; rel="(\w+)"$`)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// with a minimum similarity, the matches are counted instead of taking the mocked database count (100)
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term:          "kafka",
+				MinSimilarity: 0.5,
+				Pageable:      markdowndoc.Pageable{PageNumber: tt.pageNumber, PageSize: 1},
+			}
+
+			w := performSearchRequestWithQuery(t, ctrl, payload, tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if page.Pageable.PageNumber != tt.wantPage {
+				t.Errorf("want page %d, got %d", tt.wantPage, page.Pageable.PageNumber)
+				return
+			}
+
+			gotLinks := make(map[string]int)
+			for _, link := range strings.Split(w.Header().Get("Link"), ", ") {
+				match := linkPattern.FindStringSubmatch(link)
+				if match == nil {
+					t.Fatalf("malformed link %q", link)
+				}
+
+				target, err := url.Parse(match[1])
+				if err != nil {
+					t.Fatalf("malformed link target %q: %v", match[1], err)
+				}
+
+				if target.Path != "/search" || target.Query().Get("pageSize") != "1" {
+					t.Errorf("want a link to /search with page size 1, got %s", target)
+					return
+				}
+
+				if tt.query != "" && target.Query().Get("debug") != "false" {
+					t.Errorf("want the other query parameters to be kept, got %s", target)
+					return
+				}
+
+				gotLinks[match[2]], err = strconv.Atoi(target.Query().Get("pageNumber"))
+				if err != nil {
+					t.Fatalf("malformed page number in %s: %v", target, err)
+				}
+			}
+
+			if !cmp.Equal(tt.wantLinks, gotLinks) {
+				t.Error(cmp.Diff(tt.wantLinks, gotLinks))
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_invalidPageNumberInQuery(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	payload := markdowndoc.MarkdownSearchPayload{Term: "this"}
+	if w := performSearchRequestWithQuery(t, ctrl, payload, "pageNumber=second"); w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 Bad Request, got %d", w.Code)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_similarityMetric(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// for the term "hell", the Sorensen-Dice coefficient is 0.73, the Jaccard index 0.57, and the cosine similarity 0.73
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "hello", Path: "markdowns/Greetings"}, Content: "hello"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		metric           string
+		wantTotalMatches int
+	}{
+		{name: "defaultsToSorensenDice", metric: "", wantTotalMatches: 1},
+		{name: "sorensenDice", metric: constants.SimilarityMetricSorensenDice, wantTotalMatches: 1},
+		{name: "jaccard", metric: constants.SimilarityMetricJaccard, wantTotalMatches: 0},
+		{name: "cosine", metric: constants.SimilarityMetricCosine, wantTotalMatches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(mockedRepo)
+			ctrl.SimilarityMetric = tt.metric
+
+			payload := markdowndoc.MarkdownSearchPayload{Term: "hell", MinSimilarity: 0.6}
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if page.TotalElements != tt.wantTotalMatches {
+				t.Errorf("want %d matches above the minimum similarity, got %d", tt.wantTotalMatches, page.TotalElements)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_scorer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// ranks the longest content first, unlike the Sorensen-Dice coefficient, which ranks the exact match first
+	markdowndoc.RegisterScorer("test-longest", func(a, b string) float64 {
+		return float64(len(a)) / 100
+	})
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "exact", Path: "markdowns/Greetings"}, Content: "hello"},
+			{Meta: models.MarkdownMeta{Name: "longest", Path: "markdowns/Greetings"}, Content: "hello, world; hello, moon; hello, sun"},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		scorer        string
+		payloadScorer string
+		wantBestMatch string
+	}{
+		{name: "defaultsToSimilarityMetric", wantBestMatch: "exact"},
+		{name: "trigramDice", scorer: markdowndoc.ScorerTrigramDice, wantBestMatch: "exact"},
+		{name: "configured", scorer: "test-longest", wantBestMatch: "longest"},
+		{name: "selectedByPayload", scorer: markdowndoc.ScorerTrigramDice, payloadScorer: "test-longest", wantBestMatch: "longest"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(mockedRepo)
+			ctrl.Scorer = tt.scorer
+
+			payload := markdowndoc.MarkdownSearchPayload{Term: "hello", Scorer: tt.payloadScorer}
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if len(page.Content) != 2 || page.Content[0].Href != tt.wantBestMatch {
+				t.Errorf("want best match %s on top of the first page, got %v", tt.wantBestMatch, page.Content)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_trigramDiceScorerRanksLikeDefault(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// compared as raw strings, "cat s" would outrank "the cat"; the Tokenizer drops the stop word, though
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Model: models.Model{ID: 1}, Meta: models.MarkdownMeta{Name: "catS", Path: "markdowns/Pets"}, Content: "cat s"},
+			{Model: models.Model{ID: 2}, Meta: models.MarkdownMeta{Name: "theCat", Path: "markdowns/Pets"}, Content: "the cat"},
+		},
+	}
+
+	search := func(scorer string) (string, *markdowndoc.TrigramCache) {
+		ctrl := newMockController(mockedRepo)
+		ctrl.Tokenizer = markdowndoc.NewTokenizer(false, []string{"the"})
+		ctrl.TrigramCache = markdowndoc.NewTrigramCache()
+		ctrl.Scorer = scorer
+
+		payload := markdowndoc.MarkdownSearchPayload{Term: "cat"}
+		w := performSearchRequest(t, ctrl, payload)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+
+		return w.Body.String(), ctrl.TrigramCache
+	}
+
+	want, _ := search("")
+	got, trigramCache := search(markdowndoc.ScorerTrigramDice)
+
+	if want != got {
+		t.Errorf("want the trigram-dice scorer to rank like the default, got\n%s\nwant\n%s", got, want)
+		return
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	if err := json.Unmarshal([]byte(got), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(page.Content) != 2 || page.Content[0].Href != "theCat" {
+		t.Errorf("want best match theCat on top of the first page, got %v", page.Content)
+		return
+	}
+
+	if _, misses := trigramCache.Stats(); misses != 2 {
+		t.Errorf("want the trigrams of both contents to be read from the trigram cache, got %d misses", misses)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_unknownScorer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	payload := markdowndoc.MarkdownSearchPayload{Term: "hello", Scorer: "unknown"}
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 Bad Request, got %d", w.Code)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_ndjson(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "hello", Path: "markdowns/Greetings"}, Content: "hello"},
+			{Meta: models.MarkdownMeta{Name: "hello_world", Path: "markdowns/Greetings"}, Content: "hello world"},
+			{Meta: models.MarkdownMeta{Name: "hello_moon", Path: "markdowns/Greetings"}, Content: "hello moon"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	body, err := json.Marshal(markdowndoc.MarkdownSearchPayload{Term: "hello"})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/search", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Request.Header.Set("Accept", "application/x-ndjson, application/json;q=0.9")
+
+	ctrl.GetMarkdownSearchTermMatches(c)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	if got := w.Header().Get("Content-Type"); got != markdowndoc.MimeTypeNDJSON {
+		t.Errorf("want Content-Type %s, got %s", markdowndoc.MimeTypeNDJSON, got)
+		return
+	}
+
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("want 3 lines of matches and a summary line, got %d: %q", len(lines), w.Body.String())
+	}
+
+	var summary struct {
+		Summary markdowndoc.PageSummary `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(lines[3]), &summary); err != nil {
+		t.Fatalf("the summary line %q is no JSON object: %v", lines[3], err)
+	}
+	if summary.Summary.TotalElements != 3 || summary.Summary.TotalPages != 1 {
+		t.Errorf("want 3 matches on 1 page in the summary, got %+v", summary.Summary)
+		return
+	}
+
+	var gotHrefs []string
+	for _, line := range lines[:3] {
+		var match markdowndoc.MarkdownSearchMatch
+		if err := json.Unmarshal([]byte(line), &match); err != nil {
+			t.Fatalf("the line %q is no JSON object: %v", line, err)
+		}
+		gotHrefs = append(gotHrefs, match.Href)
+	}
+
+	// the streamed matches keep the order of the page
+	if gotHrefs[0] != "hello" {
+		t.Errorf("want the exact match first, got %v", gotHrefs)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_foldAccents(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// unless accents are folded, the trigrams of "café" make the content less similar to the term
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "cafe", Path: "markdowns/Menu"}, Content: "cafe café"},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		foldAccents      bool
+		wantTotalMatches int
+	}{
+		{name: "accentsKept", foldAccents: false, wantTotalMatches: 0},
+		{name: "accentsFolded", foldAccents: true, wantTotalMatches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(mockedRepo)
+			ctrl.Tokenizer = markdowndoc.NewTokenizer(tt.foldAccents, nil)
+
+			payload := markdowndoc.MarkdownSearchPayload{Term: "cafe", MinSimilarity: 0.9}
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if page.TotalElements != tt.wantTotalMatches {
+				t.Errorf("want %d matches above the minimum similarity, got %d", tt.wantTotalMatches, page.TotalElements)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_rankedInDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the scores deliberately differ from the Sorensen-Dice coefficient to verify that the database's ranking is kept
+	mockedRepo := &mockRepository{
+		scoredMarkdownContentsForSearch: []models.ScoredMarkdownContent{
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "weak_match", Path: "markdowns/Gateway"}, Content: "this document barely mentions kafka"}, Similarity: 0.9},
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "exact_match", Path: "markdowns/Gateway"}, Content: "kafka"}, Similarity: 0.6},
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "dropped_match", Path: "markdowns/Gateway"}, Content: "kafka streams"}, Similarity: 0.1},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+	ctrl.SearchEngine = constants.SearchEnginePgTrgm
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:          "kafka",
+		MinSimilarity: 0.5,
+		Pageable: markdowndoc.Pageable{
+			PageSize:   1,
+			PageNumber: 2,
+		},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	var gotHrefs []string
+	for _, v := range page.Content {
+		gotHrefs = append(gotHrefs, v.Href)
+	}
+
+	// the second page holds the second best match
+	wantHrefs := []string{"exact_match"}
+	if !cmp.Equal(wantHrefs, gotHrefs) {
+		t.Error(cmp.Diff(wantHrefs, gotHrefs))
+		return
+	}
+
+	// the dropped match must not be counted
+	wantTotalElements := 2
+	if page.TotalElements != wantTotalElements {
+		t.Errorf("want %d total elements, got %d", wantTotalElements, page.TotalElements)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_fullTextBackend(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the matches need not contain the term literally since the full-text search matches word stems
+	mockedRepo := &mockRepository{
+		scoredMarkdownContentsForSearch: []models.ScoredMarkdownContent{
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "retrying", Path: "markdowns/Kafka"}, Content: "retrying failed consumers"}, Similarity: 0.2},
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "retries", Path: "markdowns/Kafka"}, Content: "retries of a consumer"}, Similarity: 0.4},
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "dropped", Path: "markdowns/Kafka"}, Content: "a consumer retried once"}, Similarity: 0.01},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+	ctrl.SearchBackend = constants.SearchBackendFullText
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:          "retry consumer",
+		MinSimilarity: 0.1,
+		Pageable:      markdowndoc.Pageable{PageSize: 10, PageNumber: 1},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if mockedRepo.fullTextTerm != payload.Term || mockedRepo.searchCalls != 0 {
+		t.Errorf("want a full-text search for %q instead of a LIKE search, got %q and %d LIKE search(es)", payload.Term, mockedRepo.fullTextTerm, mockedRepo.searchCalls)
+		return
+	}
+
+	var gotHrefs []string
+	for _, v := range page.Content {
+		gotHrefs = append(gotHrefs, v.Href)
+	}
+
+	// ranked by the full-text rank; the match below the minimum similarity is dropped
+	wantHrefs := []string{"retries", "retrying"}
+	if !cmp.Equal(wantHrefs, gotHrefs) {
+		t.Error(cmp.Diff(wantHrefs, gotHrefs))
+		return
+	}
+
+	if page.TotalElements != len(wantHrefs) {
+		t.Errorf("want %d total elements, got %d", len(wantHrefs), page.TotalElements)
+		return
+	}
+}
+
+// sortedSearchHrefs performs the search with the given orders and returns the hrefs of the matches on the first page
+func sortedSearchHrefs(t *testing.T, ctrl *markdowndoc.Controller, orders []markdowndoc.Order) []string {
+	t.Helper()
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term: "kafka",
+		Pageable: markdowndoc.Pageable{
+			PageSize:   10,
+			PageNumber: 1,
+			Sort:       markdowndoc.Sort{Orders: orders},
+		},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	err := json.Unmarshal(w.Body.Bytes(), &page)
+	if err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	var gotHrefs []string
+	for _, v := range page.Content {
+		gotHrefs = append(gotHrefs, v.Href)
+	}
+
+	return gotHrefs
+}
+
+func TestGetMarkdownSearchTermMatches_Success_sorted(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Streaming"}, Content: "kafka consumers"},
+			{Meta: models.MarkdownMeta{Name: "brokers", Path: "markdowns/Streaming"}, Content: "kafka"},
+			{Meta: models.MarkdownMeta{Name: "Alerts", Path: "markdowns/Monitoring"}, Content: "this document barely mentions kafka between a lot of unrelated words"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	tests := []struct {
+		name      string
+		orders    []markdowndoc.Order
+		wantHrefs []string
+	}{
+		{
+			name:      "defaultSimilarityDescending",
+			orders:    nil,
+			wantHrefs: []string{"brokers", "Consumers", "Alerts"},
+		},
+		{
+			name:      "nameAscending",
+			orders:    []markdowndoc.Order{{Property: "name", Direction: markdowndoc.ASC}},
+			wantHrefs: []string{"Alerts", "brokers", "Consumers"},
+		},
+		{
+			name:      "nameWithoutDirection",
+			orders:    []markdowndoc.Order{{Property: "name"}},
+			wantHrefs: []string{"Alerts", "brokers", "Consumers"},
+		},
+		{
+			name:      "similarityAscending",
+			orders:    []markdowndoc.Order{{Property: "similarity", Direction: markdowndoc.ASC}},
+			wantHrefs: []string{"Alerts", "Consumers", "brokers"},
+		},
+		{
+			name: "pathDescendingThenNameAscending",
+			orders: []markdowndoc.Order{
+				{Property: "path", Direction: markdowndoc.DESC},
+				{Property: "name", Direction: markdowndoc.ASC},
+			},
+			wantHrefs: []string{"brokers", "Consumers", "Alerts"},
+		},
+		{
+			name: "pathAscendingThenNameDescending",
+			orders: []markdowndoc.Order{
+				{Property: "path", Direction: markdowndoc.ASC},
+				{Property: "name", Direction: markdowndoc.DESC},
+			},
+			wantHrefs: []string{"Alerts", "Consumers", "brokers"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotHrefs := sortedSearchHrefs(t, ctrl, tt.orders)
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_sortedInDatabase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		scoredMarkdownContentsForSearch: []models.ScoredMarkdownContent{
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "brokers", Path: "markdowns/Streaming"}, Content: "kafka"}, Similarity: 0.9},
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Streaming"}, Content: "kafka consumers"}, Similarity: 0.6},
+			{MarkdownContent: models.MarkdownContent{Meta: models.MarkdownMeta{Name: "Alerts", Path: "markdowns/Monitoring"}, Content: "kafka alerts"}, Similarity: 0.6},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+	ctrl.SearchEngine = constants.SearchEnginePgTrgm
+
+	orders := []markdowndoc.Order{
+		{Property: "similarity", Direction: markdowndoc.DESC},
+		{Property: "name", Direction: markdowndoc.ASC},
+	}
+	gotHrefs := sortedSearchHrefs(t, ctrl, orders)
+
+	wantHrefs := []string{"brokers", "Alerts", "Consumers"}
+	if !cmp.Equal(wantHrefs, gotHrefs) {
+		t.Error(cmp.Diff(wantHrefs, gotHrefs))
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseInvalidSort(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	tests := []struct {
+		name  string
+		order markdowndoc.Order
+	}{
+		{name: "unknownProperty", order: markdowndoc.Order{Property: "content", Direction: markdowndoc.ASC}},
+		{name: "unknownDirection", order: markdowndoc.Order{Property: "name", Direction: "UP"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term: "this",
+				Pageable: markdowndoc.Pageable{
+					Sort: markdowndoc.Sort{Orders: []markdowndoc.Order{tt.order}},
+				},
+			}
+
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("want status 400, got %d", w.Code)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_mode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Retries", Path: "markdowns/Streaming"}, Content: "kafka consumer retry policy"},
+			{Meta: models.MarkdownMeta{Name: "Brokers", Path: "markdowns/Streaming"}, Content: "kafka brokers"},
+			{Meta: models.MarkdownMeta{Name: "Backoff", Path: "markdowns/Resilience"}, Content: "retry with backoff"},
+			{Meta: models.MarkdownMeta{Name: "Hidden", Path: "markdowns/.drafts"}, Content: "kafka retry"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	tests := []struct {
+		name      string
+		mode      markdowndoc.SearchMode
+		wantHrefs []string
+	}{
+		{name: "defaultsToPhrase", mode: "", wantHrefs: nil},
+		{name: "phrase", mode: markdowndoc.SearchModePhrase, wantHrefs: nil},
+		{name: "and", mode: markdowndoc.SearchModeAll, wantHrefs: []string{"Retries"}},
+		{name: "andLowerCase", mode: "and", wantHrefs: []string{"Retries"}},
+		{name: "or", mode: markdowndoc.SearchModeAny, wantHrefs: []string{"Backoff", "Brokers", "Retries"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term: "kafka retry",
+				Mode: tt.mode,
+				Pageable: markdowndoc.Pageable{
+					PageSize:   10,
+					PageNumber: 1,
+					Sort:       markdowndoc.Sort{Orders: []markdowndoc.Order{{Property: "name", Direction: markdowndoc.ASC}}},
+				},
+			}
+
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			var gotHrefs []string
+			for _, v := range page.Content {
+				gotHrefs = append(gotHrefs, v.Href)
+			}
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+
+			// the phrase mode's total is counted by the (mocked) database
+			if tt.wantHrefs != nil && page.TotalElements != len(tt.wantHrefs) {
+				t.Errorf("want %d matches, got %d", len(tt.wantHrefs), page.TotalElements)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_dedupesAcrossPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the same document appears with an outdated and a current content row, which would be ranked on different pages
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Streaming"}, Content: "kafka"},
+			{Meta: models.MarkdownMeta{Name: "Brokers", Path: "markdowns/Streaming"}, Content: "kafka brokers"},
+			{Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Streaming"}, Content: "kafka consumers and more"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	var gotHrefs []string
+	for pageNumber := 1; pageNumber <= 3; pageNumber++ {
+		payload := markdowndoc.MarkdownSearchPayload{
+			Term:     "kafka",
+			Pageable: markdowndoc.Pageable{PageSize: 1, PageNumber: pageNumber},
+		}
+
+		w := performSearchRequest(t, ctrl, payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+
+		var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if page.TotalElements != 2 || page.TotalPages != 2 {
+			t.Errorf("want 2 deduplicated matches on 2 pages, got %d on %d", page.TotalElements, page.TotalPages)
+			return
+		}
+
+		for _, v := range page.Content {
+			gotHrefs = append(gotHrefs, v.Href)
+		}
+	}
+
+	wantHrefs := []string{"Consumers", "Brokers"}
+	if !cmp.Equal(wantHrefs, gotHrefs) {
+		t.Error(cmp.Diff(wantHrefs, gotHrefs))
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_equalSimilarityTieBreaker(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// the same content results in the same similarity
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Beta", Path: "markdowns/Streaming"}, Content: "kafka streams"},
+			{Meta: models.MarkdownMeta{Name: "alpha", Path: "markdowns/Streaming"}, Content: "kafka streams"},
+			{Meta: models.MarkdownMeta{Name: "alpha", Path: "markdowns/Messaging"}, Content: "kafka streams"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "kafka",
+		Pageable: markdowndoc.Pageable{PageSize: 10, PageNumber: 1},
+	}
+
+	// ties are broken by name (case-insensitively) and path, regardless of the order the database returns the matches in
+	wantPaths := []string{"Messaging/alpha", "Streaming/alpha", "Streaming/Beta"}
+	for i := range 3 {
+		slices.Reverse(mockedRepo.markdownContentsForSearch)
+
+		w := performSearchRequest(t, ctrl, payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+
+		var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		var gotPaths []string
+		for _, v := range page.Content {
+			gotPaths = append(gotPaths, v.Path+"/"+v.Href)
+		}
+
+		if !cmp.Equal(wantPaths, gotPaths) {
+			t.Errorf("call %d: %s", i+1, cmp.Diff(wantPaths, gotPaths))
+			return
+		}
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_searchOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Categories", Path: "markdowns/Catalog"}, Content: "every category has an owner"},
+			{Meta: models.MarkdownMeta{Name: "Cat", Path: "markdowns/Catalog"}, Content: "the cat command prints files"},
+			{Meta: models.MarkdownMeta{Name: "Title", Path: "markdowns/Catalog"}, Content: "Cat is an alias of type"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	tests := []struct {
+		name          string
+		caseSensitive bool
+		wholeWord     bool
+		wantHrefs     []string
+	}{
+		{name: "wholeWord", caseSensitive: false, wholeWord: true, wantHrefs: []string{"Cat", "Title"}},
+		{name: "caseSensitive", caseSensitive: true, wholeWord: false, wantHrefs: []string{"Cat", "Categories"}},
+		{name: "caseSensitiveWholeWord", caseSensitive: true, wholeWord: true, wantHrefs: []string{"Cat"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term:          "cat",
+				CaseSensitive: tt.caseSensitive,
+				WholeWord:     tt.wholeWord,
+				Pageable: markdowndoc.Pageable{
+					PageSize:   10,
+					PageNumber: 1,
+					Sort:       markdowndoc.Sort{Orders: []markdowndoc.Order{{Property: "name", Direction: markdowndoc.ASC}}},
+				},
+			}
+
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			var gotHrefs []string
+			for _, v := range page.Content {
+				gotHrefs = append(gotHrefs, v.Href)
+			}
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+
+			// the total is the number of matches, since the database only counts the default matches
+			if page.TotalElements != len(tt.wantHrefs) {
+				t.Errorf("want %d matches, got %d", len(tt.wantHrefs), page.TotalElements)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_pathPrefix(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Onboarding", Path: "markdowns/Gateway"}, Content: "kafka onboarding"},
+			{Meta: models.MarkdownMeta{Name: "Routing", Path: "markdowns/Gateway/Routes"}, Content: "kafka routing"},
+			{Meta: models.MarkdownMeta{Name: "Brokers", Path: "markdowns/Streaming"}, Content: "kafka brokers"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:       "kafka",
+		PathPrefix: "Gateway",
+		Pageable: markdowndoc.Pageable{
+			PageSize:   10,
+			PageNumber: 1,
+			Sort:       markdowndoc.Sort{Orders: []markdowndoc.Order{{Property: "name", Direction: markdowndoc.ASC}}},
+		},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	var gotHrefs []string
+	for _, v := range page.Content {
+		gotHrefs = append(gotHrefs, v.Href)
+	}
+
+	wantHrefs := []string{"Onboarding", "Routing"}
+	if !cmp.Equal(wantHrefs, gotHrefs) {
+		t.Error(cmp.Diff(wantHrefs, gotHrefs))
+		return
+	}
+
+	if page.TotalElements != len(wantHrefs) {
+		t.Errorf("want the matches to be counted within the path prefix, got %d", page.TotalElements)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_pathPrefixOfEveryRanking(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	contents := []models.MarkdownContent{
+		{Meta: models.MarkdownMeta{Name: "Onboarding", Path: "markdowns/Gateway"}, Content: "kafka retry onboarding"},
+		{Meta: models.MarkdownMeta{Name: "Brokers", Path: "markdowns/Streaming"}, Content: "kafka retry brokers"},
+	}
+	scored := []models.ScoredMarkdownContent{
+		{MarkdownContent: contents[1], Similarity: 0.9},
+		{MarkdownContent: contents[0], Similarity: 0.5},
+	}
+
+	tests := []struct {
+		name          string
+		mode          markdowndoc.SearchMode
+		searchEngine  string
+		searchBackend string
+		orders        []markdowndoc.Order
+	}{
+		{name: "modeAll", mode: markdowndoc.SearchModeAll},
+		{name: "modeAny", mode: markdowndoc.SearchModeAny},
+		{name: "pgTrgm", searchEngine: constants.SearchEnginePgTrgm},
+		{name: "pgTrgmSorted", searchEngine: constants.SearchEnginePgTrgm, orders: []markdowndoc.Order{{Property: "name", Direction: markdowndoc.ASC}}},
+		{name: "fullText", searchBackend: constants.SearchBackendFullText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(&mockRepository{markdownContentsForSearch: contents, scoredMarkdownContentsForSearch: scored})
+			ctrl.SearchEngine = tt.searchEngine
+			ctrl.SearchBackend = tt.searchBackend
+
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term:       "kafka retry",
+				Mode:       tt.mode,
+				PathPrefix: "Gateway",
+				Pageable:   markdowndoc.Pageable{PageSize: 10, PageNumber: 1, Sort: markdowndoc.Sort{Orders: tt.orders}},
+			}
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if len(page.Content) != 1 || page.Content[0].Href != "Onboarding" || page.TotalElements != 1 {
+				t.Errorf("want only the match within the path prefix, got %d of %+v", page.TotalElements, page.Content)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseUnsupportedSearchOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		mode         markdowndoc.SearchMode
+		searchEngine string
+		payload      markdowndoc.MarkdownSearchPayload
+	}{
+		{name: "modeAll", mode: markdowndoc.SearchModeAll, searchEngine: constants.SearchEngineSimple, payload: markdowndoc.MarkdownSearchPayload{WholeWord: true}},
+		{name: "pgTrgm", mode: markdowndoc.SearchModePhrase, searchEngine: constants.SearchEnginePgTrgm, payload: markdowndoc.MarkdownSearchPayload{WholeWord: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(newMockRepository())
+			ctrl.SearchEngine = tt.searchEngine
+
+			payload := tt.payload
+			payload.Term = "kafka retry"
+			payload.Mode = tt.mode
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("want status 400, got %d", w.Code)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_quotedPhrase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Adjacent", Path: "markdowns/Database"}, Content: "tune the connection pool of the kafka client"},
+			{Meta: models.MarkdownMeta{Name: "NonAdjacent", Path: "markdowns/Database"}, Content: "each connection is taken from a pool used by kafka"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		term      string
+		mode      markdowndoc.SearchMode
+		wantHrefs []string
+	}{
+		{name: "phrase", term: `"connection pool"`, wantHrefs: []string{"Adjacent"}},
+		{name: "phraseOverridesMode", term: ` "connection pool" `, mode: markdowndoc.SearchModeAny, wantHrefs: []string{"Adjacent"}},
+		{name: "phraseWithinAnd", term: `"connection pool" kafka`, mode: markdowndoc.SearchModeAll, wantHrefs: []string{"Adjacent"}},
+		{name: "unquotedAnd", term: `connection pool`, mode: markdowndoc.SearchModeAll, wantHrefs: []string{"Adjacent", "NonAdjacent"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(mockedRepo)
+
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term: tt.term,
+				Mode: tt.mode,
+				Pageable: markdowndoc.Pageable{
+					PageSize:   10,
+					PageNumber: 1,
+					Sort:       markdowndoc.Sort{Orders: []markdowndoc.Order{{Property: "name", Direction: markdowndoc.ASC}}},
+				},
+			}
+
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			var gotHrefs []string
+			for _, v := range page.Content {
+				gotHrefs = append(gotHrefs, v.Href)
+			}
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseEmptyQuotedPhrase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	w := performSearchRequest(t, ctrl, markdowndoc.MarkdownSearchPayload{Term: `"  "`})
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want status 400, got %d", w.Code)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_debug(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "hello", Path: "markdowns/Greetings"}, Content: "hello"},
+		},
+	}
+	ctrl := newMockController(mockedRepo)
+
+	// "hell" has the trigrams "  h", " he", "hel", "ell", "ll " of which the first four are shared with "hello"
+	tests := []struct {
+		name      string
+		query     string
+		wantDebug *markdowndoc.SearchMatchDebug
+	}{
+		{name: "offByDefault", query: "", wantDebug: nil},
+		{name: "off", query: "debug=false", wantDebug: nil},
+		{name: "on", query: "debug=true", wantDebug: &markdowndoc.SearchMatchDebug{QueryTrigramCount: 5, ContentTrigramCount: 6, IntersectionCount: 4}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performSearchRequestWithQuery(t, ctrl, markdowndoc.MarkdownSearchPayload{Term: "hell"}, tt.query)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			if got := bytes.Contains(w.Body.Bytes(), []byte(`"debug"`)); got != (tt.wantDebug != nil) {
+				t.Errorf("want the debug block to be present: %t, got %t", tt.wantDebug != nil, got)
+				return
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if len(page.Content) != 1 {
+				t.Fatalf("want 1 match, got %d", len(page.Content))
+			}
+
+			if !cmp.Equal(tt.wantDebug, page.Content[0].Debug) {
+				t.Error(cmp.Diff(tt.wantDebug, page.Content[0].Debug))
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_searchAnalytics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "hello", Path: "markdowns/Greetings"}, Content: "hello"},
+		},
+	}
+
+	for _, enabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("enabled=%t", enabled), func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			ctrl := newMockController(mockedRepo)
+			ctrl.Logger = logging.DefaultLogger{Logger: zap.New(core).Sugar()}
+			ctrl.SearchAnalytics = enabled
+
+			for _, term := range []string{"hell", "hello"} {
+				w := performSearchRequest(t, ctrl, markdowndoc.MarkdownSearchPayload{Term: term, Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}})
+				if w.Code != http.StatusOK {
+					t.Fatalf("expected status 200 OK, got %d", w.Code)
+				}
+			}
+
+			searchLogs := logs.FilterField(zap.String("subType", "search")).All()
+			if !enabled {
+				if len(searchLogs) != 0 {
+					t.Errorf("want no search logs, got %d", len(searchLogs))
+				}
+				return
+			}
+
+			if len(searchLogs) != 2 {
+				t.Fatalf("want a search log per search, got %d", len(searchLogs))
+			}
+
+			// "hell" shares 4 of its 5 trigrams with the 6 trigrams of "hello"
+			want := map[string]any{"term": "hell", "resultCount": int64(1), "topSimilarity": 8.0 / 11.0, "pageSize": int64(10)}
+			got := searchLogs[0].ContextMap()
+			for key, wantValue := range want {
+				if !cmp.Equal(wantValue, got[key], cmpopts.EquateApprox(0, 1e-9)) {
+					t.Errorf("want %s=%v, got %v", key, wantValue, got[key])
+				}
+			}
+
+			if got := searchLogs[1].ContextMap()["topSimilarity"]; got != 1.0 {
+				t.Errorf("want the top similarity 1 for the identical term, got %v", got)
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseInvalidDebugFlag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	w := performSearchRequestWithQuery(t, ctrl, markdowndoc.MarkdownSearchPayload{Term: "hell"}, "debug=maybe")
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want status 400, got %d", w.Code)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseInvalidMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		mode          markdowndoc.SearchMode
+		searchEngine  string
+		searchBackend string
+	}{
+		{name: "unknownMode", mode: "XOR", searchEngine: constants.SearchEngineSimple},
+		{name: "unsupportedByPgTrgm", mode: markdowndoc.SearchModeAll, searchEngine: constants.SearchEnginePgTrgm},
+		{name: "unsupportedByFullText", mode: markdowndoc.SearchModeAll, searchEngine: constants.SearchEngineSimple, searchBackend: constants.SearchBackendFullText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(newMockRepository())
+			ctrl.SearchEngine = tt.searchEngine
+			ctrl.SearchBackend = tt.searchBackend
+
+			payload := markdowndoc.MarkdownSearchPayload{Term: "kafka retry", Mode: tt.mode}
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("want status 400, got %d", w.Code)
+				return
+			}
+		})
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseMinSimilarityOutOfRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	for _, minSimilarity := range []float64{-0.1, 1.1} {
+		payload := markdowndoc.MarkdownSearchPayload{
+			Term:          "this",
+			MinSimilarity: minSimilarity,
+		}
+
+		w := performSearchRequest(t, ctrl, payload)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("want status 400 for minimum similarity %v, got %d", minSimilarity, w.Code)
+			return
+		}
+
+		if !strings.Contains(w.Body.String(), "is not within [0,1]") {
+			t.Errorf("want error message about the minimum similarity, got %s", w.Body.String())
+			return
+		}
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_defaultPageSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	ctrl.DefaultPageSize = 2
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "this",
+		Pageable: markdowndoc.Pageable{PageNumber: 1},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(page.Content) != 2 {
+		t.Errorf("want the configured default page size (2) to be applied, got %d matches", len(page.Content))
+		return
+	}
+
+	if page.Pageable.PageSize != 2 {
+		t.Errorf("want the applied page size (2) to be returned, got %d", page.Pageable.PageSize)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_suggestions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	repo.markdownMetas = []models.MarkdownMeta{
+		{Name: "Kafka_Consumers", Path: "markdowns/Streaming", CharCount: 10},
+		{Name: "Getting-Started", Path: "markdowns/Onboarding", CharCount: 10},
+		{Name: "Getting-Started", Path: "markdowns/Gateway", CharCount: 10},
+		{Name: "Getting-Started-Secretly", Path: "markdowns/.hidden", CharCount: 10},
+		{Name: "Get-Together", Path: "markdowns/Onboarding", CharCount: 10},
+	}
+	ctrl := newMockController(repo)
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term: "geting startd",
+		// the mock's count does not depend on the term; with a minimum similarity, the matches are counted instead
+		MinSimilarity: 0.1,
+		Pageable:      markdowndoc.Pageable{PageNumber: 1, PageSize: 5},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+	if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if page.TotalElements != 0 {
+		t.Fatalf("want the misspelled term to match nothing, got %d matches", page.TotalElements)
+	}
+
+	want := []string{"Getting-Started"}
+	if !cmp.Equal(want, page.Suggestions) {
+		t.Error(cmp.Diff(want, page.Suggestions))
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_noSuggestionsForEnoughMatches(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	ctrl.SuggestionThreshold = 1
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "this",
+		Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 5},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 OK, got %d", w.Code)
+	}
+
+	if strings.Contains(w.Body.String(), "suggestions") {
+		t.Errorf("want no suggestions, got %s", w.Body.String())
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_Success_titleWeight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	repo.markdownContentsForSearch = []models.MarkdownContent{
+		// the body barely mentions the term, but the title matches it exactly
+		{Content: "Dashboards, alerts, and data sources of our observability stack; see also grafana.", Meta: models.MarkdownMeta{Name: "Grafana", Path: "markdowns/Tools"}},
+		{Content: "grafana", Meta: models.MarkdownMeta{Name: "Release_Notes", Path: "markdowns/Tools"}},
+	}
+
+	tests := []struct {
+		name        string
+		titleWeight float64
+		wantHrefs   []string
+	}{
+		{name: "contentOnly", titleWeight: 0, wantHrefs: []string{"Release_Notes", "Grafana"}},
+		{name: "titleBoost", titleWeight: 0.8, wantHrefs: []string{"Grafana", "Release_Notes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(repo)
+			ctrl.TitleWeight = tt.titleWeight
+
+			payload := markdowndoc.MarkdownSearchPayload{
+				Term:     "grafana",
+				Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 5},
+			}
+
+			w := performSearchRequest(t, ctrl, payload)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d", w.Code)
+			}
+
+			var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+			if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			gotHrefs := make([]string, 0, len(page.Content))
+			for _, v := range page.Content {
+				gotHrefs = append(gotHrefs, v.Href)
+			}
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+		})
+	}
+}
+
+func TestController_SetSearchThresholds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	repo.markdownContentsForSearch = []models.MarkdownContent{
+		// the body barely mentions the term, but the title matches it exactly
+		{Content: "Dashboards, alerts, and data sources of our observability stack; see also grafana.", Meta: models.MarkdownMeta{Name: "Grafana", Path: "markdowns/Tools"}},
+		{Content: "grafana", Meta: models.MarkdownMeta{Name: "Release_Notes", Path: "markdowns/Tools"}},
+	}
+
+	ctrl := newMockController(repo)
+	ctrl.MaxPageSize = 5
+	ctrl.SearchCache = markdowndoc.NewSearchCache(time.Minute, 10)
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "grafana",
+		Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 5},
+	}
+
+	search := func() markdowndoc.Page[markdowndoc.MarkdownSearchMatch] {
+		w := performSearchRequest(t, ctrl, payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+
+		var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		return page
+	}
+
+	if page := search(); len(page.Content) != 2 || page.Content[0].Href != "Release_Notes" {
+		t.Fatalf("want both matches ranked by their contents, got %v", page.Content)
+	}
+
+	// the page cached with the previous thresholds is not served anymore
+	ctrl.SetSearchThresholds(0.8, 1)
+
+	page := search()
+	if page.Pageable.PageSize != 1 {
+		t.Errorf("want the page size clamped to the replaced maximum 1, got %d", page.Pageable.PageSize)
+		return
+	}
+
+	if len(page.Content) != 1 || page.Content[0].Href != "Grafana" {
+		t.Errorf("want the title match boosted by the replaced title weight, got %v", page.Content)
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseOverflowingPageNumber(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "this",
+		Pageable: markdowndoc.Pageable{PageNumber: 4611686018427387904, PageSize: 4},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want status 400, got %d", w.Code)
+		return
+	}
+
+	if !strings.Contains(w.Body.String(), "too large") {
+		t.Errorf("want error message about the page number, got %s", w.Body.String())
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseNegativePageSize(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "this",
+		Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: -5},
+	}
+
+	w := performSearchRequest(t, ctrl, payload)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("want status 400, got %d", w.Code)
+		return
+	}
+
+	if !strings.Contains(w.Body.String(), "must not be negative") {
+		t.Errorf("want error message about the page size, got %s", w.Body.String())
+		return
+	}
+}
+
+func performRelatedDocumentsRequest(ctrl *markdowndoc.Controller, name, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Params = gin.Params{{Key: "name", Value: name}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/related/"+name+"?"+query, nil)
+
+	ctrl.GetRelatedDocuments(c)
+
+	return w
+}
+
+func TestGetRelatedDocuments_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	kafka := models.MarkdownContent{Meta: models.MarkdownMeta{Name: "Kafka", Path: "markdowns/Messaging"}, Content: "kafka consumer groups and partitions"}
+	mock := &mockRepository{
+		markdownContent: map[string]models.MarkdownContent{"Kafka": kafka},
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "Auth", Path: "markdowns/Security"}, Content: "login with a password"},
+			kafka,
+			{Meta: models.MarkdownMeta{Name: "Partitions", Path: "markdowns/Messaging"}, Content: "kafka partitions"},
+			{Meta: models.MarkdownMeta{Name: "Draft", Path: "markdowns/.drafts"}, Content: "kafka consumer groups and partitions"},
+			{Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Messaging"}, Content: "kafka consumer groups"},
+			// same name in another folder
+			{Meta: models.MarkdownMeta{Name: "Kafka", Path: "markdowns/Archive"}, Content: "kafka consumer groups and partitions, archived"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		maxK      int
+		wantNames []string
+	}{
+		{name: "all", wantNames: []string{"Archive/Kafka", "Messaging/Consumers", "Messaging/Partitions", "Security/Auth"}},
+		{name: "limit", query: "limit=2", wantNames: []string{"Archive/Kafka", "Messaging/Consumers"}},
+		{name: "limitAboveMax", query: "limit=10", maxK: 3, wantNames: []string{"Archive/Kafka", "Messaging/Consumers", "Messaging/Partitions"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := newMockController(mock)
+			ctrl.MaxRelatedDocuments = tt.maxK
+
+			w := performRelatedDocumentsRequest(ctrl, "Kafka", tt.query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("expected status 200 OK, got %d: %s", w.Code, w.Body.String())
+			}
+
+			var related []markdowndoc.RelatedDocument
+			if err := json.Unmarshal(w.Body.Bytes(), &related); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			gotNames := make([]string, 0, len(related))
+			for i, document := range related {
+				gotNames = append(gotNames, strings.TrimPrefix(document.Path, "markdowns/")+"/"+document.Name)
+
+				if i > 0 && related[i-1].Similarity < document.Similarity {
+					t.Errorf("want the most similar documents first, got %+v", related)
+					return
+				}
+			}
+
+			if !cmp.Equal(tt.wantNames, gotNames) {
+				t.Error(cmp.Diff(tt.wantNames, gotNames))
+				return
+			}
+		})
+	}
+}
+
+func TestGetRelatedDocuments_Errors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		mock     *mockRepository
+		query    string
+		wantCode int
+	}{
+		{name: "notFound", mock: &mockRepository{}, wantCode: http.StatusNotFound},
+		{name: "invalidLimit", mock: &mockRepository{}, query: "limit=0", wantCode: http.StatusBadRequest},
+		{
+			name: "dbError",
+			mock: &mockRepository{
+				markdownContent:                    map[string]models.MarkdownContent{"Kafka": {Meta: models.MarkdownMeta{Name: "Kafka"}}},
+				findMarkdownsBySearchTermSimpleErr: errors.New("db failure"),
+			},
+			wantCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := performRelatedDocumentsRequest(newMockController(tt.mock), "Kafka", tt.query); w.Code != tt.wantCode {
+				t.Errorf("want status %d, got %d", tt.wantCode, w.Code)
+				return
+			}
+		})
+	}
+}
+
+func TestGetSimilarity_Success(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	tests := []struct {
+		name           string
+		payload        markdowndoc.SimilarityPayload
+		wantSimilarity float64
+	}{
+		{
+			name:           "identical",
+			payload:        markdowndoc.SimilarityPayload{A: "Word", B: "word"},
+			wantSimilarity: 1,
+		},
+		{
+			name:           "disjoint",
+			payload:        markdowndoc.SimilarityPayload{A: "abc", B: "xyz"},
+			wantSimilarity: 0,
+		},
+		{
+			name:           "partial",
+			payload:        markdowndoc.SimilarityPayload{A: "word", B: "words"},
+			wantSimilarity: markdowndoc.TrigramSorensenDiceSimilarity("word", "words"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performSimilarityRequest(t, ctrl, tt.payload)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("want status 200, got %d", w.Code)
+				return
+			}
+
+			var got markdowndoc.SimilarityResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("failed to unmarshal response: %v", err)
+			}
+
+			if got.Similarity < 0 || got.Similarity > 1 {
+				t.Errorf("want similarity within [0,1], got %v", got.Similarity)
+				return
+			}
+
+			if !cmp.Equal(tt.wantSimilarity, got.Similarity) {
+				t.Error(cmp.Diff(tt.wantSimilarity, got.Similarity))
+				return
+			}
+
+			wantTrigramsA := markdowndoc.TransformToUniqueTrigrams(tt.payload.A)
+			if !cmp.Equal(wantTrigramsA, got.TrigramsA) {
+				t.Error(cmp.Diff(wantTrigramsA, got.TrigramsA))
+				return
+			}
+
+			wantTrigramsB := markdowndoc.TransformToUniqueTrigrams(tt.payload.B)
+			if !cmp.Equal(wantTrigramsB, got.TrigramsB) {
+				t.Error(cmp.Diff(wantTrigramsB, got.TrigramsB))
+				return
+			}
+		})
+	}
+}
+
+func TestGetSimilarity_Success_onlyStopWords(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+	// the stop words are dropped, so neither text has trigrams
+	ctrl.Tokenizer = markdowndoc.NewTokenizer(false, []string{"the", "a"})
+
+	w := performSimilarityRequest(t, ctrl, markdowndoc.SimilarityPayload{A: "the", B: "a"})
+
+	if w.Code != http.StatusOK {
+		t.Errorf("want status 200, got %d", w.Code)
+		return
+	}
+
+	var got markdowndoc.SimilarityResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if got.Similarity != 0 {
+		t.Errorf("want similarity 0 for texts without trigrams, got %v", got.Similarity)
+		return
+	}
+}
+
+// performSimilarityRequest sends the given payload to the similarity handler of the given controller
+func performSimilarityRequest(t *testing.T, ctrl *markdowndoc.Controller, payload markdowndoc.SimilarityPayload) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "/similarity", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.GetSimilarity(c)
+
+	return w
+}
+
+// performSearchRequest sends the given payload to the search handler of the given controller
+func performSearchRequest(t *testing.T, ctrl *markdowndoc.Controller, payload markdowndoc.MarkdownSearchPayload) *httptest.ResponseRecorder {
+	t.Helper()
+
+	return performSearchRequestWithQuery(t, ctrl, payload, "")
+}
+
+// performSearchRequestWithQuery is like performSearchRequest but appends the given (encoded) query to the URL
+func performSearchRequestWithQuery(t *testing.T, ctrl *markdowndoc.Controller, payload markdowndoc.MarkdownSearchPayload, query string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	url := "/search"
+	if len(query) > 0 {
+		url += "?" + query
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = req
+
+	ctrl.GetMarkdownSearchTermMatches(c)
+
+	return w
+}
+
+func TestGetMarkdownSearchTermMatches_Tracing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recorder := tracetest.NewSpanRecorder()
+	previousProvider := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previousProvider) })
+
+	ctrl := newMockController(newMockRepository())
+
+	r := gin.New()
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+	r.POST("/markdown-doc/markdown/search", ctrl.GetMarkdownSearchTermMatches)
+
+	body, err := json.Marshal(markdowndoc.MarkdownSearchPayload{Term: "this", Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 5}})
+	if err != nil {
+		t.Fatalf("failed to marshal payload: %v", err)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/markdown-doc/markdown/search", bytes.NewBuffer(body)))
 
 	if w.Code != http.StatusOK {
-		t.Errorf("expected status 200 OK, got %d", w.Code)
-		return
+		t.Fatalf("want status 200, got %d: %s", w.Code, w.Body.String())
 	}
 
-	var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
-	err = json.Unmarshal(w.Body.Bytes(), &page)
-	if err != nil {
-		t.Errorf("failed to unmarshal response: %v", err)
-		return
+	spans := recorder.Ended()
+	spansByName := make(map[string]sdktrace.ReadOnlySpan, len(spans))
+	for _, span := range spans {
+		spansByName[span.Name()] = span
 	}
 
-	if len(page.Content) != wantNumberOfMatches {
-		t.Errorf("want %d matches, got %d", wantNumberOfMatches, len(page.Content))
-		return
+	root, ok := spansByName["/markdown-doc/markdown/search"]
+	if !ok {
+		t.Fatalf("want a root span for the request, got %d span(s)", len(spans))
 	}
 
-	if page.TotalElements != wantTotalElements {
-		t.Errorf("want %d total elements, got %d", wantTotalElements, page.TotalElements)
-		return
-	}
+	for _, name := range []string{"FindMarkdownsBySearchTerm", "RankSearchMatches"} {
+		span, ok := spansByName[name]
+		if !ok {
+			t.Errorf("want a span %s", name)
+			continue
+		}
 
-	wantTotalPages := int(math.Ceil(float64(wantTotalElements) / float64(wantPageSize)))
-	if page.TotalPages != wantTotalPages {
-		t.Errorf("want %d total pages, got %d", wantTotalPages, page.TotalPages)
-		return
+		if span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("want span %s to be a child of the request span", name)
+		}
 	}
 }
 
@@ -489,6 +3007,17 @@ func TestGetNavigationItemsTrees_DBError(t *testing.T) {
 	}
 }
 
+func TestGetDocuments_DBError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(&mockRepository{documentsErr: errors.New("db failure")})
+
+	if w := performDocumentsRequest(ctrl, ""); w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+		return
+	}
+}
+
 func TestGetMarkdownByName_MissingParam(t *testing.T) {
 	w := httptest.NewRecorder()
 	c, _ := gin.CreateTestContext(w)
@@ -523,12 +3052,97 @@ func TestGetMarkdownByName_NotFound(t *testing.T) {
 
 	ctrl.GetMarkdownByName(c)
 
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+		return
+	}
+}
+
+func TestGetMarkdownByName_DBError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+
+	c.Params = []gin.Param{{Key: "name", Value: "Getting-Started"}}
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/markdown/Getting-Started", nil)
+
+	ctrl := newMockController(&mockRepository{markdownContentByNameErr: errors.New("connection refused")})
+
+	ctrl.GetMarkdownByName(c)
+
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", w.Code)
 		return
 	}
 }
 
+// performMarkdownByPathRequest requests the Markdown content at the given path from the given controller
+func performMarkdownByPathRequest(ctrl *markdowndoc.Controller, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/markdown-doc/markdown-by-path?path="+url.QueryEscape(path), nil)
+
+	ctrl.GetMarkdownByPath(c)
+
+	return w
+}
+
+func TestGetMarkdownByPath_Success(t *testing.T) {
+	mock := &mockRepository{
+		markdownContentByPath: map[string]models.MarkdownContent{
+			"markdowns/Gateway/Guide": {Content: "# Gateway guide"},
+			"markdowns/Alloy/Guide":   {Content: "# Alloy guide"},
+		},
+	}
+
+	w := performMarkdownByPathRequest(newMockController(mock), "markdowns/Alloy/Guide")
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+		return
+	}
+
+	if !strings.Contains(w.Body.String(), "Alloy guide") {
+		t.Errorf("expected response body to contain the content of markdowns/Alloy/Guide, got %s", w.Body.String())
+		return
+	}
+}
+
+func TestGetMarkdownByPath_NotFound(t *testing.T) {
+	mock := &mockRepository{
+		markdownContentByPath: map[string]models.MarkdownContent{
+			"markdowns/Gateway/Guide": {Content: "# Gateway guide"},
+		},
+	}
+
+	w := performMarkdownByPathRequest(newMockController(mock), "markdowns/Alloy/Guide")
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", w.Code)
+		return
+	}
+}
+
+func TestGetMarkdownByPath_BadRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "missingPath", path: ""},
+		{name: "missingFolder", path: "Guide"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := performMarkdownByPathRequest(newMockController(&mockRepository{}), tt.path)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", w.Code)
+				return
+			}
+		})
+	}
+}
+
 func TestGetMarkdownSearchTermMatches_ErrorDuringFind(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -578,8 +3192,10 @@ func TestGetMarkdownSearchTermMatches_ErrorDuringCount(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	mockedRepo := newMockRepository()
-	mockedRepo.countMarkdownsMatchesBySearchTermSimpleErr = errors.New("could not count matches for search term")
+	mockedRepo.countMarkdownsMatchesBySearchTermRankedErr = errors.New("could not count matches for search term")
 	ctrl := newMockController(mockedRepo)
+	// the matches ranked in Go are counted as they are ranked; only the database counts the matches it ranks
+	ctrl.SearchEngine = constants.SearchEnginePgTrgm
 
 	wantTerm := "this"
 	wantPageSize := 9
@@ -661,6 +3277,54 @@ func TestGetMarkdownSearchTermMatches_BadRequestBecauseNoSearchTerm(t *testing.T
 	}
 }
 
+func TestGetMarkdownSearchTermMatches_BadRequestBecauseWhitespaceSearchTerm(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockedRepo := newMockRepository()
+	ctrl := newMockController(mockedRepo)
+
+	w := performSearchRequest(t, ctrl, markdowndoc.MarkdownSearchPayload{Term: " \t  "})
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("want status 400, got %d", w.Code)
+	}
+
+	if !strings.Contains(w.Body.String(), "did not perform search because no search term was present") {
+		t.Errorf("want 'did not perform search because no search term was present', got %s", w.Body.String())
+		return
+	}
+}
+
+func TestGetMarkdownSearchTermMatches_RequestEntityTooLarge(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	body := `{"term": "` + strings.Repeat("kafka ", 100) + `"}`
+	req, err := http.NewRequest(http.MethodPost, "/search", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	// like middlewares.BodyLimitHandler for a body of unknown length
+	req.Body = http.MaxBytesReader(c.Writer, req.Body, 64)
+	c.Request = req
+
+	ctrl.GetMarkdownSearchTermMatches(c)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("want status 413, got %d", w.Code)
+	}
+
+	if !strings.Contains(w.Body.String(), "exceeds 64 bytes") {
+		t.Errorf("want error message about the body size, got %s", w.Body.String())
+		return
+	}
+}
+
 func TestGetMarkdownSearchTermMatches_BadRequestBecauseInvalidJSON(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -692,39 +3356,77 @@ func TestGetMarkdownSearchTermMatches_BadRequestBecauseInvalidJSON(t *testing.T)
 	}
 }
 
+func TestGetSimilarity_BadRequestBecauseFieldMissing(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctrl := newMockController(newMockRepository())
+
+	for _, payload := range []markdowndoc.SimilarityPayload{{A: "word"}, {B: "word"}, {}} {
+		w := performSimilarityRequest(t, ctrl, payload)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("want status 400 for payload %+v, got %d", payload, w.Code)
+			return
+		}
+	}
+}
+
 // ####################### creating mocks
 type mockRepository struct {
-	findMetasErr    error
-	markdownMetas   []models.MarkdownMeta
-	markdownContent map[string]models.MarkdownContent
+	findMetasErr             error
+	markdownMetas            []models.MarkdownMeta
+	markdownContent          map[string]models.MarkdownContent
+	markdownContentByNameErr error
+	// markdownContentByPath is keyed by the folder path and the file name joined by a slash
+	markdownContentByPath map[string]models.MarkdownContent
 	// the slice below contains also markdownsWithPrefixedPath and prefixedTopLevelMarkdowns
 	markdownContentsForSearch                  []models.MarkdownContent
 	markdownsWithPrefixedPath                  []models.MarkdownContent
 	prefixedTopLevelMarkdowns                  []models.MarkdownContent
 	findMarkdownsBySearchTermSimpleErr         error
-	countMarkdownsMatchesBySearchTermSimpleErr error
+	countMarkdownsMatchesBySearchTermRankedErr error
+	// searchCalls is the number of calls of FindMarkdownsBySearchTermSimple
+	searchCalls int
+	// already scored and ordered like the database would return them
+	scoredMarkdownContentsForSearch []models.ScoredMarkdownContent
+	// fullTextTerm is the term of the last FindMarkdownsByFullText
+	fullTextTerm string
+	stats        models.MarkdownStats
+	statsErr     error
+	// documents are listed like the database would (see FindMarkdownDocumentsPaged)
+	documents    []models.MarkdownDocument
+	documentsErr error
 }
 
-func (m *mockRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, term string, results *[]models.MarkdownContent) error {
+func (m *mockRepository) FindVisibleMarkdowns(_ context.Context, results *[]models.MarkdownContent) error {
 	if m.findMarkdownsBySearchTermSimpleErr != nil {
 		return m.findMarkdownsBySearchTermSimpleErr
 	}
 
 	for _, data := range m.markdownContentsForSearch {
-		pathElements := strings.Split(data.Meta.Path, "/")
+		// like the database's visiblePredicate
+		if !data.Meta.IsHidden(constants.DefaultDocsRoot) {
+			*results = append(*results, data)
+		}
+	}
 
-		if len(pathElements) < 2 {
-			slog.Info("checking top-level Markdown file")
-			if !strings.Contains(data.Content, term) || strings.HasPrefix(data.Meta.Name, ".") {
-				continue
-			}
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, term, pathPrefix string, results *[]models.MarkdownContent) error {
+	m.searchCalls++
+	if m.findMarkdownsBySearchTermSimpleErr != nil {
+		return m.findMarkdownsBySearchTermSimpleErr
+	}
+
+	for _, data := range m.markdownContentsForSearch {
+		if !inPathPrefix(data.Meta, pathPrefix) {
+			continue
 		}
 
-		if len(pathElements) >= 2 {
-			slog.Info("checking top-level folder")
-			if !strings.Contains(data.Content, term) || strings.HasPrefix(pathElements[1], ".") {
-				continue
-			}
+		// like the database's visiblePredicate
+		if !strings.Contains(data.Content, term) || data.Meta.IsHidden(constants.DefaultDocsRoot) {
+			continue
 		}
 
 		*results = append(*results, data)
@@ -733,18 +3435,183 @@ func (m *mockRepository) FindMarkdownsBySearchTermSimple(ctx context.Context, te
 	return nil
 }
 
-func (m *mockRepository) CountMarkdownsMatchesBySearchTermSimple(ctx context.Context, term string, count *int) error {
-	if m.countMarkdownsMatchesBySearchTermSimpleErr != nil {
-		return m.countMarkdownsMatchesBySearchTermSimpleErr
+// inPathPrefix mimics the database's pathPrefixPredicate; every Markdown file is within an empty prefix
+func inPathPrefix(meta models.MarkdownMeta, pathPrefix string) bool {
+	return len(pathPrefix) == 0 || strings.HasPrefix(meta.Path, "markdowns/"+pathPrefix)
+}
+
+// scoredInPathPrefix returns the scored Markdown contents within the path prefix (see inPathPrefix)
+func scoredInPathPrefix(scored []models.ScoredMarkdownContent, pathPrefix string) []models.ScoredMarkdownContent {
+	var matches []models.ScoredMarkdownContent
+	for _, v := range scored {
+		if inPathPrefix(v.Meta, pathPrefix) {
+			matches = append(matches, v)
+		}
+	}
+
+	return matches
+}
+
+// FindMarkdownsBySearchTermWithOptions mimics the database's ILIKE and word-boundary matching with an equivalent Go regular expression
+func (m *mockRepository) FindMarkdownsBySearchTermWithOptions(_ context.Context, term string, options database.SearchOptions, results *[]models.MarkdownContent) error {
+	if m.findMarkdownsBySearchTermSimpleErr != nil {
+		return m.findMarkdownsBySearchTermSimpleErr
+	}
+
+	pattern := regexp.QuoteMeta(term)
+	if options.WholeWord {
+		pattern = `\b` + pattern + `\b`
+	}
+	if !options.CaseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re := regexp.MustCompile(pattern)
+
+	for _, data := range m.markdownContentsForSearch {
+		if data.Meta.IsHidden(constants.DefaultDocsRoot) || !inPathPrefix(data.Meta, options.PathPrefix) {
+			continue
+		}
+
+		if re.MatchString(data.Content) {
+			*results = append(*results, data)
+		}
+	}
+
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTerms(_ context.Context, terms []string, matchAll bool, pathPrefix string, results *[]models.MarkdownContent) error {
+	if m.findMarkdownsBySearchTermSimpleErr != nil {
+		return m.findMarkdownsBySearchTermSimpleErr
+	}
+
+	for _, data := range m.markdownContentsForSearch {
+		if data.Meta.IsHidden(constants.DefaultDocsRoot) || !inPathPrefix(data.Meta, pathPrefix) {
+			continue
+		}
+
+		var matched int
+		for _, term := range terms {
+			if strings.Contains(data.Content, term) {
+				matched++
+			}
+		}
+
+		if matched > 0 && (!matchAll || matched == len(terms)) {
+			*results = append(*results, data)
+		}
+	}
+
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTermRanked(_ context.Context, _, pathPrefix string, results *[]models.ScoredMarkdownContent) error {
+	if m.findMarkdownsBySearchTermSimpleErr != nil {
+		return m.findMarkdownsBySearchTermSimpleErr
+	}
+
+	*results = append(*results, scoredInPathPrefix(m.scoredMarkdownContentsForSearch, pathPrefix)...)
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownsByFullText(_ context.Context, term, pathPrefix string, results *[]models.ScoredMarkdownContent) error {
+	m.fullTextTerm = term
+	if m.findMarkdownsBySearchTermSimpleErr != nil {
+		return m.findMarkdownsBySearchTermSimpleErr
+	}
+
+	*results = append(*results, scoredInPathPrefix(m.scoredMarkdownContentsForSearch, pathPrefix)...)
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownsBySearchTermPaged(_ context.Context, _, pathPrefix string, minSimilarity float64, pageNumber, pageSize int, results *[]models.ScoredMarkdownContent) error {
+	if m.findMarkdownsBySearchTermSimpleErr != nil {
+		return m.findMarkdownsBySearchTermSimpleErr
+	}
+
+	var matches []models.ScoredMarkdownContent
+	for _, v := range scoredInPathPrefix(m.scoredMarkdownContentsForSearch, pathPrefix) {
+		if v.Similarity >= minSimilarity {
+			matches = append(matches, v)
+		}
+	}
+
+	start := min((max(pageNumber, 1)-1)*pageSize, len(matches))
+	end := min(start+pageSize, len(matches))
+	*results = append(*results, matches[start:end]...)
+	return nil
+}
+
+// visibleDocuments filters and orders the documents like the database's FindMarkdownDocumentsPaged
+func (m *mockRepository) visibleDocuments(options database.DocumentListOptions) []models.MarkdownDocument {
+	var documents []models.MarkdownDocument
+	for _, v := range m.documents {
+		if options.IncludeHidden || !(models.MarkdownMeta{Name: v.Name, Path: v.Path}).IsHidden(constants.DefaultDocsRoot) {
+			documents = append(documents, v)
+		}
+	}
+
+	slices.SortStableFunc(documents, func(a, b models.MarkdownDocument) int {
+		c := strings.Compare(a.Name, b.Name)
+		if options.SortBy == database.DocumentSortUpdatedAt {
+			c = a.UpdatedAt.Compare(b.UpdatedAt)
+		}
+		if options.Descending {
+			c = -c
+		}
+
+		if c != 0 {
+			return c
+		}
+		if c = strings.Compare(a.Path, b.Path); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return documents
+}
+
+func (m *mockRepository) FindMarkdownDocumentsPaged(_ context.Context, options database.DocumentListOptions, pageNumber, pageSize int, documents *[]models.MarkdownDocument) error {
+	if m.documentsErr != nil {
+		return m.documentsErr
+	}
+
+	visible := m.visibleDocuments(options)
+	start := min((max(pageNumber, 1)-1)*pageSize, len(visible))
+	end := min(start+pageSize, len(visible))
+	*documents = append(*documents, visible[start:end]...)
+	return nil
+}
+
+func (m *mockRepository) CountMarkdownDocuments(_ context.Context, includeHidden bool, documentCount *int) error {
+	if m.documentsErr != nil {
+		return m.documentsErr
 	}
 
-	// simulating that matches only occur in hidden elements
-	if term == "hidden" {
-		*count = 0
-		return nil
+	*documentCount = len(m.visibleDocuments(database.DocumentListOptions{IncludeHidden: includeHidden}))
+	return nil
+}
+
+func (m *mockRepository) CountAllMarkdowns(_ context.Context, stats *models.MarkdownStats) error {
+	if m.statsErr != nil {
+		return m.statsErr
+	}
+
+	*stats = m.stats
+	return nil
+}
+
+func (m *mockRepository) CountMarkdownsMatchesBySearchTermRanked(_ context.Context, _, pathPrefix string, minSimilarity float64, count *int) error {
+	if m.countMarkdownsMatchesBySearchTermRankedErr != nil {
+		return m.countMarkdownsMatchesBySearchTermRankedErr
 	}
 
-	*count = 100
+	for _, v := range scoredInPathPrefix(m.scoredMarkdownContentsForSearch, pathPrefix) {
+		if v.Similarity >= minSimilarity {
+			*count++
+		}
+	}
 	return nil
 }
 
@@ -756,6 +3623,10 @@ func (m *mockRepository) FindAllMarkdownMetas(_ context.Context, metas *[]models
 	return nil
 }
 
+func (m *mockRepository) FindMarkdownContentHashes(ctx context.Context, contentHashes *[]models.MarkdownContentHash) error {
+	return nil
+}
+
 func (m *mockRepository) FindMarkdownMetasWhereCharCountGreaterThan(ctx context.Context, x int, markdownMetas *[]models.MarkdownMeta) error {
 	if m.findMetasErr != nil {
 		return m.findMetasErr
@@ -772,9 +3643,22 @@ func (m *mockRepository) FindMarkdownMetasWhereCharCountGreaterThan(ctx context.
 }
 
 func (m *mockRepository) FindMarkdownContentByName(_ context.Context, name string, content *models.MarkdownContent) error {
+	if m.markdownContentByNameErr != nil {
+		return m.markdownContentByNameErr
+	}
+
 	c, ok := m.markdownContent[name]
 	if !ok {
-		return fmt.Errorf("not found")
+		return database.ErrMarkdownNotFound
+	}
+	*content = c
+	return nil
+}
+
+func (m *mockRepository) FindMarkdownContentByPath(_ context.Context, path, name string, content *models.MarkdownContent) error {
+	c, ok := m.markdownContentByPath[path+"/"+name]
+	if !ok {
+		return database.ErrMarkdownNotFound
 	}
 	*content = c
 	return nil
@@ -788,10 +3672,26 @@ func (m *mockRepository) DeleteMarkdownContentsByIds(_ context.Context, ids []ui
 	return nil
 }
 
+func (m *mockRepository) PurgeSoftDeletedMarkdowns(_ context.Context, _ time.Time, _ *int64) error {
+	return nil
+}
+
+func (m *mockRepository) Ping(_ context.Context) error {
+	return nil
+}
+
+func (m *mockRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 func (m *mockRepository) FindUserLoginCredentials(_ context.Context, _ string, _ *models.User) error {
 	return nil
 }
 
+func (m *mockRepository) CreateUser(_ context.Context, _ *models.User) error {
+	return nil
+}
+
 func (m *mockRepository) FindMarkdownContentIdsByMetaIds(_ context.Context, _ []uint, out *[]uint) error {
 	return nil
 }
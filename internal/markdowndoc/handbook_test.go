@@ -8,7 +8,9 @@ import (
 	"dice-sorensen-similarity-search/internal/utils"
 	"fmt"
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
 	"math"
@@ -16,6 +18,8 @@ import (
 	"regexp"
 	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 )
 
 func TestNavigationItemHierarchy(t *testing.T) {
@@ -307,6 +311,358 @@ func TestBuildNavigationItemTrees(t *testing.T) {
 	}
 }
 
+func TestBuildNavigationItemTrees_customDocsRoot(t *testing.T) {
+	markdownMetas := []models.MarkdownMeta{
+		{Name: "File1", Path: "docs/Gateway"},
+		{Name: "File2", Path: "docs/Gateway/SubFolder"},
+		{Name: "TopLevelFile", Path: "docs"},
+		{Name: "Ignored", Path: "markdowns/Guidelines"},
+		{Name: "IgnoredTopLevelFile", Path: "markdowns"},
+	}
+
+	c := collate.New(language.English)
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+	s := markdowndoc.NavigationItemTreeService{Env: env, Collator: c, DocsRoot: "docs"}
+
+	result := s.BuildNavigationItemTrees(markdownMetas)
+
+	gotHrefs := make([]string, 0, len(result))
+	for _, root := range result {
+		gotHrefs = append(gotHrefs, root.Href)
+	}
+
+	wantHrefs := []string{"Gateway", "TopLevelFile"}
+	if !cmp.Equal(wantHrefs, gotHrefs) {
+		t.Error(cmp.Diff(wantHrefs, gotHrefs))
+		return
+	}
+
+	gateway := result[0]
+	if len(gateway.Children) != 2 {
+		t.Errorf("want 2 children of Gateway, got %d", len(gateway.Children))
+		return
+	}
+}
+
+func TestBuildNavigationItemTrees_normalizesPaths(t *testing.T) {
+	markdownMetas := []models.MarkdownMeta{
+		{Name: "File1", Path: "markdowns/Gateway/"},
+		{Name: "File2", Path: "markdowns//Gateway//SubFolder/"},
+		{Name: "File3", Path: "/markdowns/Another-Folder"},
+		{Name: "TopLevelFile", Path: "markdowns/"},
+	}
+
+	c := collate.New(language.English)
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+	s := markdowndoc.NavigationItemTreeService{Env: env, Collator: c}
+
+	result := s.BuildNavigationItemTrees(markdownMetas)
+
+	want := []string{"Another-Folder", "Another-Folder/File3", "Gateway", "Gateway/File1", "Gateway/SubFolder", "Gateway/SubFolder/File2", "TopLevelFile"}
+
+	var got []string
+	var collect func(items []*markdowndoc.NavigationItem, parentPath string)
+	collect = func(items []*markdowndoc.NavigationItem, parentPath string) {
+		for _, item := range items {
+			if len(item.Href) == 0 || len(item.Label) == 0 {
+				t.Errorf("want no empty navigation items, got one below %q", parentPath)
+			}
+
+			itemPath := item.Href
+			if len(parentPath) > 0 {
+				itemPath = parentPath + "/" + item.Href
+			}
+			got = append(got, itemPath)
+
+			collect(item.Children, itemPath)
+		}
+	}
+	collect(result, "")
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestBuildNavigationItemTrees_landingPageNames(t *testing.T) {
+	markdownMetas := []models.MarkdownMeta{
+		{Name: "Home", Path: "markdowns"},
+		{Name: "index", Path: "markdowns"},
+		{Name: "Landing-Page", Path: "markdowns"},
+		{Name: "Contact", Path: "markdowns"},
+		// only top-level files are landing pages
+		{Name: "Home", Path: "markdowns/Gateway"},
+	}
+
+	tests := []struct {
+		name             string
+		landingPageNames []string
+		wantHrefs        []string
+	}{
+		{name: "default", wantHrefs: []string{"Contact", "Gateway", "Home", "index"}},
+		{name: "configured", landingPageNames: []string{"Home", "index"}, wantHrefs: []string{"Contact", "Gateway", "Landing-Page"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := environment.Null()
+			env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+			s := markdowndoc.NavigationItemTreeService{Env: env, Collator: collate.New(language.English), LandingPageNames: tt.landingPageNames}
+
+			result := s.BuildNavigationItemTrees(markdownMetas)
+
+			gotHrefs := make([]string, 0, len(result))
+			for _, root := range result {
+				gotHrefs = append(gotHrefs, root.Href)
+			}
+
+			if !cmp.Equal(tt.wantHrefs, gotHrefs) {
+				t.Error(cmp.Diff(tt.wantHrefs, gotHrefs))
+				return
+			}
+		})
+	}
+}
+
+func TestBuildNavigationItemTrees_parentHref(t *testing.T) {
+	markdownMetas := []models.MarkdownMeta{
+		{Name: "File1", Path: "markdowns/1_Root/Level1/Level2"},
+		{Name: "File2", Path: "markdowns/1_Root/Level1"},
+		{Name: "TopLevelFile", Path: "markdowns"},
+	}
+
+	c := collate.New(language.English)
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+	s := markdowndoc.NavigationItemTreeService{Env: env, Collator: c}
+
+	result := s.BuildNavigationItemTrees(markdownMetas)
+
+	gotParentHrefs := make(map[string]string)
+	var traverse func([]*markdowndoc.NavigationItem)
+	traverse = func(items []*markdowndoc.NavigationItem) {
+		for _, item := range items {
+			gotParentHrefs[item.Href] = item.ParentHref
+			traverse(item.Children)
+		}
+	}
+	traverse(result)
+
+	// the root's number prefix is removed; its children must reference the root's final Href
+	wantParentHrefs := map[string]string{
+		"Root":         "",
+		"TopLevelFile": "",
+		"Level1":       "Root",
+		"Level2":       "Level1",
+		"File1":        "Level2",
+		"File2":        "Level1",
+	}
+	if !cmp.Equal(wantParentHrefs, gotParentHrefs) {
+		t.Error(cmp.Diff(wantParentHrefs, gotParentHrefs))
+		return
+	}
+}
+
+// navigationShape renders the navigation trees as indented "Href (Label)" lines; the indentation reflects the depth
+func navigationShape(items []*markdowndoc.NavigationItem, depth int) []string {
+	var lines []string
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("%s%s (%s)", strings.Repeat("  ", depth), item.Href, item.Label))
+		lines = append(lines, navigationShape(item.Children, depth+1)...)
+	}
+
+	return lines
+}
+
+func TestBuildNavigationItemTrees_collapseSingleChild(t *testing.T) {
+	tests := []struct {
+		name          string
+		markdownMetas []models.MarkdownMeta
+		wantShape     []string
+	}{
+		{
+			name: "Deeply nested path",
+			markdownMetas: []models.MarkdownMeta{
+				{Name: "DeepFile", Path: "markdowns/Gateway/SubFolder/SubSubFolder"},
+			},
+			wantShape: []string{
+				"Gateway/SubFolder/SubSubFolder (Gateway / SubFolder / SubSubFolder)",
+				"  DeepFile (DeepFile)",
+			},
+		},
+		{
+			name: "Folder with a file and a folder is kept",
+			markdownMetas: []models.MarkdownMeta{
+				{Name: "File1", Path: "markdowns/Root/Level1/Level2"},
+				{Name: "File2", Path: "markdowns/Root/Level1"},
+			},
+			wantShape: []string{
+				"Root/Level1 (Root / Level1)",
+				"  File2 (File2)",
+				"  Level2 (Level2)",
+				"    File1 (File1)",
+			},
+		},
+		{
+			name: "Folder with a single file is kept",
+			markdownMetas: []models.MarkdownMeta{
+				{Name: "File1", Path: "markdowns/Gateway"},
+			},
+			wantShape: []string{
+				"Gateway (Gateway)",
+				"  File1 (File1)",
+			},
+		},
+		{
+			name: "Number prefix of a collapsed root is removed",
+			markdownMetas: []models.MarkdownMeta{
+				{Name: "File1", Path: "markdowns/1_Gateway/Sub_Folder"},
+				{Name: "Top_Level_Element", Path: "markdowns"},
+			},
+			wantShape: []string{
+				"Gateway/Sub_Folder (Gateway / Sub Folder)",
+				"  File1 (File1)",
+				"Top_Level_Element (Top Level Element)",
+			},
+		},
+	}
+
+	c := collate.New(language.English)
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+	s := markdowndoc.NavigationItemTreeService{Env: env, Collator: c, CollapseSingleChild: true}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := s.BuildNavigationItemTrees(tt.markdownMetas)
+
+			gotShape := navigationShape(result, 0)
+			if !cmp.Equal(tt.wantShape, gotShape) {
+				t.Error(cmp.Diff(tt.wantShape, gotShape))
+				return
+			}
+
+			// the merged folders must be the parents of the children they took over
+			for _, root := range result {
+				for _, child := range root.Children {
+					if child.ParentHref != root.Href {
+						t.Errorf("want child %s to have parentHref %s, got %s", child.Href, root.Href, child.ParentHref)
+						return
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestBuildNavigationItemTrees_maxDepth(t *testing.T) {
+	markdownMetas := []models.MarkdownMeta{
+		{Name: "DeepFile", Path: "markdowns/Gateway/SubFolder/SubSubFolder"},
+		{Name: "File2", Path: "markdowns/Gateway/SubFolder"},
+		{Name: "File1", Path: "markdowns/Gateway"},
+		{Name: "TopLevelFile", Path: "markdowns"},
+	}
+
+	tests := []struct {
+		name         string
+		maxDepth     int
+		wantShape    []string
+		wantWarnings int
+	}{
+		{
+			name:     "unlimited",
+			maxDepth: 0,
+			wantShape: []string{
+				"Gateway (Gateway)",
+				"  File1 (File1)",
+				"  SubFolder (SubFolder)",
+				"    File2 (File2)",
+				"    SubSubFolder (SubSubFolder)",
+				"      DeepFile (DeepFile)",
+				"TopLevelFile (TopLevelFile)",
+			},
+		},
+		{
+			name:     "deepFileDropped",
+			maxDepth: 3,
+			wantShape: []string{
+				"Gateway (Gateway)",
+				"  File1 (File1)",
+				"  SubFolder (SubFolder)",
+				"    File2 (File2)",
+				"TopLevelFile (TopLevelFile)",
+			},
+			wantWarnings: 1,
+		},
+		{
+			name:     "onlyTopLevelFilesLeft",
+			maxDepth: 1,
+			wantShape: []string{
+				"TopLevelFile (TopLevelFile)",
+			},
+			wantWarnings: 3,
+		},
+	}
+
+	c := collate.New(language.English)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zap.WarnLevel)
+			env := environment.Null()
+			env.Logger = logging.DefaultLogger{Logger: zap.New(core).Sugar()}
+			s := markdowndoc.NavigationItemTreeService{Env: env, Collator: c, MaxDepth: tt.maxDepth}
+
+			result := s.BuildNavigationItemTrees(markdownMetas)
+
+			gotShape := navigationShape(result, 0)
+			if !cmp.Equal(tt.wantShape, gotShape) {
+				t.Error(cmp.Diff(tt.wantShape, gotShape))
+				return
+			}
+
+			if logs.Len() != tt.wantWarnings {
+				t.Errorf("want %d warnings, got %d", tt.wantWarnings, logs.Len())
+				return
+			}
+
+			for _, entry := range logs.All() {
+				if !strings.Contains(entry.Message, "markdowns/Gateway") {
+					t.Errorf("want the warning to name the offending path, got %q", entry.Message)
+					return
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkBuildNavigationItemTrees builds a synthetic tree of 10 roots with 10 folders of 100 Markdown files each (10k leaves)
+func BenchmarkBuildNavigationItemTrees(b *testing.B) {
+	markdownMetas := make([]models.MarkdownMeta, 0, 10_000)
+	for root := range 10 {
+		for folder := range 10 {
+			for file := range 100 {
+				markdownMetas = append(markdownMetas, models.MarkdownMeta{
+					Path: fmt.Sprintf("markdowns/%d_Root/Folder-%d", root, folder),
+					Name: fmt.Sprintf("File-%d", file),
+				})
+			}
+		}
+	}
+
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+	s := markdowndoc.NavigationItemTreeService{Env: env, Collator: collate.New(language.English)}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = s.BuildNavigationItemTrees(markdownMetas)
+	}
+}
+
 func TestNavigationItemTopLevelOrder(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -461,6 +817,91 @@ func TestNavigationItemHiddenTopLevelElements(t *testing.T) {
 	}
 }
 
+func TestExtractSnippet(t *testing.T) {
+	tests := []struct {
+		name       string
+		content    string
+		term       string
+		window     int
+		wantBefore string
+		wantMatch  string
+		wantAfter  string
+	}{
+		{
+			name:       "match in the middle",
+			content:    "we use Kafka for streaming",
+			term:       "kafka",
+			window:     4,
+			wantBefore: "use ",
+			wantMatch:  "Kafka",
+			wantAfter:  " for",
+		},
+		{
+			name:       "match at the very start",
+			content:    "Kafka for streaming",
+			term:       "kafka",
+			window:     4,
+			wantBefore: "",
+			wantMatch:  "Kafka",
+			wantAfter:  " for",
+		},
+		{
+			name:       "match at the very end",
+			content:    "streaming with kafka",
+			term:       "KAFKA",
+			window:     80,
+			wantBefore: "streaming with ",
+			wantMatch:  "kafka",
+			wantAfter:  "",
+		},
+		{
+			name:       "multibyte content",
+			content:    "größte Käfer-Sammlung",
+			term:       "käfer",
+			window:     3,
+			wantBefore: "te ",
+			wantMatch:  "Käfer",
+			wantAfter:  "-Sa",
+		},
+		{
+			name:       "no literal match falls back to the first characters",
+			content:    "streaming with kafka",
+			term:       "kafak",
+			window:     9,
+			wantBefore: "",
+			wantMatch:  "",
+			wantAfter:  "streaming",
+		},
+		{
+			name:       "no literal match in a short document",
+			content:    "kafka",
+			term:       "grafana",
+			window:     80,
+			wantBefore: "",
+			wantMatch:  "",
+			wantAfter:  "kafka",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotBefore, gotMatch, gotAfter := markdowndoc.ExtractSnippet(tt.content, tt.term, tt.window)
+
+			if gotBefore != tt.wantBefore {
+				t.Errorf("want text before match %q, got %q", tt.wantBefore, gotBefore)
+			}
+
+			if gotMatch != tt.wantMatch {
+				t.Errorf("want matching text %q, got %q", tt.wantMatch, gotMatch)
+			}
+
+			if gotAfter != tt.wantAfter {
+				t.Errorf("want text after match %q, got %q", tt.wantAfter, gotAfter)
+			}
+		})
+	}
+}
+
 func TestTrigramSorensenDiceSimilarity_bounds(t *testing.T) {
 
 	term := "hello"
@@ -507,6 +948,83 @@ func TestTrigramSorensenDiceSimilarity_completely_different(t *testing.T) {
 	}
 }
 
+func TestTrigramSorensenDiceSimilarity_truncatesLargeInputs(t *testing.T) {
+	want := 1.0
+
+	// the words beyond MaxSimilarityInputBytes are not compared
+	term := strings.Repeat("a", markdowndoc.MaxSimilarityInputBytes)
+	text := term + " hello world"
+
+	got := markdowndoc.TrigramSorensenDiceSimilarity(term, text)
+
+	if got != want {
+		t.Errorf("want similiarity to be %f, got %f", want, got)
+		return
+	}
+}
+
+func TestController_ContentTrigrams_truncatesLargeContents(t *testing.T) {
+	// the words beyond MaxSimilarityInputBytes yield no trigrams
+	truncated := strings.Repeat("a", markdowndoc.MaxSimilarityInputBytes)
+	content := models.MarkdownContent{Model: models.Model{ID: 1}, Content: truncated + " hello world"}
+
+	tests := []struct {
+		name         string
+		tokenizer    *markdowndoc.Tokenizer
+		trigramCache *markdowndoc.TrigramCache
+	}{
+		{name: "withoutTokenizer"},
+		{name: "tokenizer", tokenizer: markdowndoc.NewTokenizer(true, []string{"the"})},
+		{name: "trigramCache", tokenizer: markdowndoc.NewTokenizer(true, []string{"the"}), trigramCache: markdowndoc.NewTrigramCache()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := &markdowndoc.Controller{Tokenizer: tt.tokenizer, TrigramCache: tt.trigramCache}
+
+			want := markdowndoc.TransformToUniqueTrigrams(truncated)
+			got := ctrl.ContentTrigrams(content)
+
+			if !cmp.Equal(want, got) {
+				t.Errorf("want the trigrams of the first %d bytes only, got %d trigrams", markdowndoc.MaxSimilarityInputBytes, len(got))
+				return
+			}
+		})
+	}
+}
+
+func TestTruncateSimilarityInput(t *testing.T) {
+	maxBytes := markdowndoc.MaxSimilarityInputBytes
+
+	tests := []struct {
+		name    string
+		input   string
+		wantLen int
+	}{
+		{name: "short", input: "hello", wantLen: 5},
+		{name: "exactlyTheMaximum", input: strings.Repeat("a", maxBytes), wantLen: maxBytes},
+		{name: "long", input: strings.Repeat("a", maxBytes+10), wantLen: maxBytes},
+		// "é" has 2 bytes; the maximum would split the last one
+		{name: "doesNotSplitMultibyteCharacters", input: "a" + strings.Repeat("é", maxBytes/2), wantLen: maxBytes - 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := markdowndoc.TruncateSimilarityInput(tt.input)
+
+			if len(got) != tt.wantLen {
+				t.Errorf("want %d bytes, got %d", tt.wantLen, len(got))
+				return
+			}
+
+			if !utf8.ValidString(got) {
+				t.Error("want valid UTF-8 after truncating")
+				return
+			}
+		})
+	}
+}
+
 func TestTrigramSorensenDiceSimilarity(t *testing.T) {
 	tests := []struct {
 		A, B     string
@@ -542,18 +1060,68 @@ func TestTrigramSorensenDiceSimilarity(t *testing.T) {
 	}
 }
 
-func BenchmarkTrigramSorensenDiceSimilarity_Asymmetric(b *testing.B) {
-	small := "hello"
-	large := strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 10000) // ~560,000 characters
+func TestTrigramSimilarityMetrics(t *testing.T) {
+	tests := []struct {
+		A, B        string
+		WantDice    float64
+		WantJaccard float64
+		WantCosine  float64
+	}{
+		{"hello", "hello", 1.0, 1.0, 1.0},
+		{"hello", "world", 0.0, 0.0, 0.0},
+		// 2 of 6 and 7 trigrams are shared
+		{"hello", "yellow", 0.307692, 0.181818, 0.308607},
+		// 4 of 6 and 5 trigrams are shared
+		{"hello", "hell", 0.727273, 0.571429, 0.730297},
+	}
 
-	b.Run("Small_vs_Large", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
-			_ = markdowndoc.TrigramSorensenDiceSimilarity(small, large)
+	for _, test := range tests {
+		got := map[string]float64{
+			"dice":    markdowndoc.TrigramSorensenDiceSimilarity(test.A, test.B),
+			"jaccard": markdowndoc.JaccardSimilarity(test.A, test.B),
+			"cosine":  markdowndoc.CosineTrigramSimilarity(test.A, test.B),
 		}
-	})
+		want := map[string]float64{"dice": test.WantDice, "jaccard": test.WantJaccard, "cosine": test.WantCosine}
 
-	b.Run("Large_vs_Small", func(b *testing.B) {
-		for i := 0; i < b.N; i++ {
+		if !cmp.Equal(want, got, cmpopts.EquateApprox(0, 1e-6)) {
+			t.Errorf("similarities between %q and %q: %s", test.A, test.B, cmp.Diff(want, got, cmpopts.EquateApprox(0, 1e-6)))
+		}
+	}
+}
+
+func TestTrigramSimilarityMetrics_emptyStrings(t *testing.T) {
+	if got := markdowndoc.TrigramSorensenDiceSimilarity("", ""); got != 0 {
+		t.Errorf("want Sorensen-Dice similarity 0 for empty strings, got %f", got)
+	}
+
+	// without padding, punctuation has no trigrams
+	a := markdowndoc.TransformToUniqueTrigramsWithPadding("!!!", markdowndoc.TrigramPaddingNone)
+	b := markdowndoc.TransformToUniqueTrigramsWithPadding("???", markdowndoc.TrigramPaddingNone)
+	if got := markdowndoc.TrigramSetSorensenDiceSimilarity(a, b); got != 0 {
+		t.Errorf("want Sorensen-Dice similarity 0 for empty trigram sets, got %f", got)
+	}
+
+	if got := markdowndoc.JaccardSimilarity("", ""); got != 0 {
+		t.Errorf("want Jaccard similarity 0 for empty strings, got %f", got)
+	}
+
+	if got := markdowndoc.CosineTrigramSimilarity("", "hello"); got != 0 {
+		t.Errorf("want cosine similarity 0 for an empty string, got %f", got)
+	}
+}
+
+func BenchmarkTrigramSorensenDiceSimilarity_Asymmetric(b *testing.B) {
+	small := "hello"
+	large := strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 10000) // ~560,000 characters
+
+	b.Run("Small_vs_Large", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = markdowndoc.TrigramSorensenDiceSimilarity(small, large)
+		}
+	})
+
+	b.Run("Large_vs_Small", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
 			_ = markdowndoc.TrigramSorensenDiceSimilarity(large, small)
 		}
 	})
@@ -675,6 +1243,572 @@ func TestTransformToUniqueTrigrams_fuzzy_map(t *testing.T) {
 	}
 }
 
+func TestTransformToUniqueTrigrams_unicode(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+	}{
+		{
+			name:     "composed",
+			input:    "Café",
+			expected: []string{"  c", " ca", "afé", "caf", "fé "},
+		},
+		{
+			name:     "decomposed",
+			input:    "Cafe\u0301",
+			expected: []string{"  c", " ca", "afé", "caf", "fé "},
+		},
+		{
+			name:     "multiByteCharactersAreNotDelimiters",
+			input:    "straße",
+			expected: []string{"  s", " st", "aße", "raß", "str", "tra", "ße "},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := markdowndoc.TransformToUniqueTrigrams(test.input); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("For input %q, want %q, got %q", test.input, test.expected, got)
+			}
+		})
+	}
+}
+
+func TestTrigramSorensenDiceSimilarity_unicodeNormalization(t *testing.T) {
+	composed, decomposed := "Résumé café", "Re\u0301sume\u0301 cafe\u0301"
+
+	if got := markdowndoc.TrigramSorensenDiceSimilarity(composed, decomposed); got != 1 {
+		t.Errorf("want composed and decomposed spellings to be equal (1), got %v", got)
+	}
+}
+
+func TestTransformToUniqueTrigramsWithPadding(t *testing.T) {
+	tests := []struct {
+		name           string
+		padding        markdowndoc.TrigramPadding
+		want           []string
+		wantSimilarity float64
+	}{
+		{
+			name:           "none",
+			padding:        markdowndoc.TrigramPaddingNone,
+			want:           []string{"ell", "hel", "llo"},
+			wantSimilarity: 4.0 / 5,
+		},
+		{
+			name:           "leading",
+			padding:        markdowndoc.TrigramPaddingLeading,
+			want:           []string{"  h", " he", "ell", "hel", "llo", "lo "},
+			wantSimilarity: 8.0 / 11,
+		},
+		{
+			name:           "symmetric",
+			padding:        markdowndoc.TrigramPaddingSymmetric,
+			want:           []string{"  h", " he", "ell", "hel", "llo", "lo ", "o  "},
+			wantSimilarity: 8.0 / 13,
+		},
+		{
+			name:           "unknownFallsBackToLeading",
+			padding:        "unknown",
+			want:           []string{"  h", " he", "ell", "hel", "llo", "lo "},
+			wantSimilarity: 8.0 / 11,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := markdowndoc.TransformToUniqueTrigramsWithPadding("Hello", tt.padding)
+			if !cmp.Equal(tt.want, got) {
+				t.Error(cmp.Diff(tt.want, got))
+				return
+			}
+
+			similarity := markdowndoc.TrigramSetSorensenDiceSimilarity(got, markdowndoc.TransformToUniqueTrigramsWithPadding("hell", tt.padding))
+			if math.Abs(similarity-tt.wantSimilarity) > 1e-9 {
+				t.Errorf("want the similarity of hello and hell to be %f, got %f", tt.wantSimilarity, similarity)
+				return
+			}
+		})
+	}
+}
+
+func TestTransformToUniqueTrigramsWithPadding_defaultIsLeading(t *testing.T) {
+	text := "Hello, world"
+
+	want := markdowndoc.TransformToUniqueTrigrams(text)
+	got := markdowndoc.TransformToUniqueTrigramsWithPadding(text, markdowndoc.TrigramPaddingLeading)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestTransformToUniqueTrigramsWithPadding_noneSkipsShortWords(t *testing.T) {
+	want := []string{"ell", "hel", "llo"}
+
+	got := markdowndoc.TransformToUniqueTrigramsWithPadding("hi hello", markdowndoc.TrigramPaddingNone)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+}
+
+func TestTransformToUniqueTrigramsWithMode(t *testing.T) {
+	tests := []struct {
+		name string
+		mode markdowndoc.TrigramMode
+		want []string
+	}{
+		{
+			name: "perWord",
+			mode: markdowndoc.TrigramModePerWord,
+			// the empty word after the trailing "!" yields "   "
+			want: []string{"   ", "  h", "  w", " he", " wo", "ell", "hel", "ld ", "llo", "lo ", "orl", "rld", "wor"},
+		},
+		{
+			name: "wholeString",
+			mode: markdowndoc.TrigramModeWholeString,
+			// " wo" is extracted across the words instead of "  w"; "o w" spans both words
+			want: []string{"  h", " he", " wo", "ell", "hel", "ld ", "llo", "lo ", "o w", "orl", "rld", "wor"},
+		},
+		{
+			name: "unknownFallsBackToPerWord",
+			mode: "unknown",
+			want: []string{"   ", "  h", "  w", " he", " wo", "ell", "hel", "ld ", "llo", "lo ", "orl", "rld", "wor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := markdowndoc.TransformToUniqueTrigramsWithMode("Hello, world!", tt.mode)
+			if !cmp.Equal(tt.want, got) {
+				t.Error(cmp.Diff(tt.want, got))
+				return
+			}
+		})
+	}
+}
+
+func TestTransformToUniqueTrigramsWithMode_multiWordSimilarity(t *testing.T) {
+	tests := []struct {
+		name           string
+		a, b           string
+		mode           markdowndoc.TrigramMode
+		wantSimilarity float64
+	}{
+		// the order of the words does not matter per word
+		{name: "perWord_swappedWords", a: "hello world", b: "world hello", mode: markdowndoc.TrigramModePerWord, wantSimilarity: 1},
+		{name: "wholeString_swappedWords", a: "hello world", b: "world hello", mode: markdowndoc.TrigramModeWholeString, wantSimilarity: 20.0 / 24},
+		// the separators are reduced to single spaces
+		{name: "wholeString_separators", a: "hello world", b: "hello,  world!", mode: markdowndoc.TrigramModeWholeString, wantSimilarity: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			similarity := markdowndoc.TrigramSetSorensenDiceSimilarity(
+				markdowndoc.TransformToUniqueTrigramsWithMode(tt.a, tt.mode),
+				markdowndoc.TransformToUniqueTrigramsWithMode(tt.b, tt.mode),
+			)
+
+			if math.Abs(similarity-tt.wantSimilarity) > 1e-9 {
+				t.Errorf("want the similarity of %q and %q to be %f, got %f", tt.a, tt.b, tt.wantSimilarity, similarity)
+				return
+			}
+		})
+	}
+}
+
+func TestTransformToUniqueFoldedTrigrams(t *testing.T) {
+	accented, unaccented := "Résumé café", "resume cafe"
+
+	got := markdowndoc.TransformToUniqueFoldedTrigrams(accented)
+	if want := markdowndoc.TransformToUniqueTrigrams(unaccented); !reflect.DeepEqual(got, want) {
+		t.Errorf("want accents to be folded to %q, got %q", want, got)
+	}
+
+	if similarity := markdowndoc.TrigramSorensenDiceSimilarity(accented, unaccented); similarity == 1 {
+		t.Error("want accents to be kept unless folded")
+	}
+}
+
+func TestMapToMarkdownSearchPage_singleSegmentPath(t *testing.T) {
+	mapper := markdowndoc.MarkdownSearchMatchMapper{Env: environment.Null()}
+	payload := markdowndoc.MarkdownSearchPayload{Term: "hello", Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}}
+
+	searchMatches := []models.ScoredMarkdownContent{
+		{MarkdownContent: models.MarkdownContent{Content: "hello top-level", Meta: models.MarkdownMeta{Name: "01_Getting_Started", Path: "markdowns"}}},
+		{MarkdownContent: models.MarkdownContent{Content: "hello malformed", Meta: models.MarkdownMeta{Name: "Orphan", Path: ""}}},
+		{MarkdownContent: models.MarkdownContent{Content: "hello nested", Meta: models.MarkdownMeta{Name: "Onboarding", Path: "markdowns/02_Gateway"}}},
+	}
+
+	page, err := markdowndoc.MapToMarkdownSearchPage(mapper, payload, 10, len(searchMatches), searchMatches)
+	if err != nil {
+		t.Fatalf("mapToMarkdownSearchPage error: %v", err)
+	}
+
+	type labelHrefPath struct{ Label, Href, Path string }
+
+	want := []labelHrefPath{
+		{Label: "Getting Started", Href: "01_Getting_Started", Path: ""},
+		{Label: "Orphan", Href: "Orphan", Path: ""},
+		{Label: "Onboarding", Href: "Onboarding", Path: "Gateway"},
+	}
+
+	got := make([]labelHrefPath, 0, len(page.Content))
+	for _, m := range page.Content {
+		got = append(got, labelHrefPath{Label: m.Label, Href: m.Href, Path: m.Path})
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+	}
+}
+
+func TestMapToMarkdownSearchPage_highlights(t *testing.T) {
+	mapper := markdowndoc.MarkdownSearchMatchMapper{Env: environment.Null(), SnippetWindow: 10}
+
+	tests := []struct {
+		name       string
+		content    string
+		term       string
+		highlights bool
+		want       []markdowndoc.Highlight
+	}{
+		{
+			name:       "multipleOccurrences",
+			content:    "Kafka or kafka? KAFKA!",
+			term:       "kafka",
+			highlights: true,
+			// the snippet is the whole content: "Kafka" + " or kafka?" (window: 10)
+			want: []markdowndoc.Highlight{{Start: 0, End: 5}, {Start: 9, End: 14}},
+		},
+		{
+			name:       "multibyteContent",
+			content:    "Grüße, Größe, größe",
+			term:       "größe",
+			highlights: true,
+			// rune offsets; the byte offsets of the 1st occurrence would be 9 and 16
+			want: []markdowndoc.Highlight{{Start: 7, End: 12}, {Start: 14, End: 19}},
+		},
+		{
+			name:       "withinTheSnippetBounds",
+			content:    "aaaaaaaaaaaaaaaaaaaa hello world hello aaaaaaaaaaaaaaaaaaaa hello",
+			term:       "hello",
+			highlights: true,
+			// the snippet is "aaaaaaaaa " + "hello" + " world hel"; the partial occurrence is not highlighted
+			want: []markdowndoc.Highlight{{Start: 10, End: 15}},
+		},
+		{
+			name:    "notRequested",
+			content: "Kafka or kafka",
+			term:    "kafka",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := markdowndoc.MarkdownSearchPayload{Term: tt.term, Highlights: tt.highlights, Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}}
+			searchMatches := []models.ScoredMarkdownContent{
+				{MarkdownContent: models.MarkdownContent{Content: tt.content, Meta: models.MarkdownMeta{Name: "Kafka", Path: "markdowns/Streaming"}}},
+			}
+
+			page, err := markdowndoc.MapToMarkdownSearchPage(mapper, payload, 10, len(searchMatches), searchMatches)
+			if err != nil {
+				t.Fatalf("mapToMarkdownSearchPage error: %v", err)
+			}
+
+			got := page.Content[0].Highlights
+			if !cmp.Equal(tt.want, got) {
+				t.Error(cmp.Diff(tt.want, got))
+				return
+			}
+
+			snippet := []rune(page.Content[0].TextBeforeMatch + page.Content[0].MatchingText + page.Content[0].TextAfterMatch)
+			for _, h := range got {
+				if h.Start < 0 || h.End > len(snippet) || !strings.EqualFold(string(snippet[h.Start:h.End]), tt.term) {
+					t.Errorf("want the highlight %v to select the term within the snippet %q", h, string(snippet))
+					return
+				}
+			}
+		})
+	}
+}
+
+func TestMapToMarkdownSearchPage_maxSnippetLength(t *testing.T) {
+	// the window is much larger than the cap; the umlauts and the emoji are multibyte runes
+	mapper := markdowndoc.MarkdownSearchMatchMapper{Env: environment.Null(), SnippetWindow: 100, MaxSnippetLength: 20}
+
+	tests := []struct {
+		name        string
+		content     string
+		term        string
+		wantBefore  string
+		wantMatch   string
+		wantAfter   string
+		wantAsIsLen bool
+	}{
+		{
+			name:    "truncatedOnBothSides",
+			content: strings.Repeat("äöü", 10) + " Größe " + strings.Repeat("😀ß", 10),
+			term:    "größe",
+			// 15 runes are left besides the match: 7 before and 8 after it
+			wantBefore: "…öüäöü ",
+			wantMatch:  "Größe",
+			wantAfter:  " 😀ß😀ß😀ß…",
+		},
+		{
+			name:       "shortTextBeforeMatch",
+			content:    "ä Größe " + strings.Repeat("😀ß", 20),
+			term:       "größe",
+			wantBefore: "ä ",
+			wantMatch:  "Größe",
+			// the runes the text before the match does not need are left to the text after it
+			wantAfter: " 😀ß😀ß😀ß😀ß😀ß😀…",
+		},
+		{
+			name:      "matchLongerThanTheCap",
+			content:   "ä " + strings.Repeat("ö", 30) + " ü",
+			term:      strings.Repeat("ö", 30),
+			wantMatch: strings.Repeat("ö", 19) + "…",
+		},
+		{
+			name:        "shortEnough",
+			content:     "äöü Größe 😀",
+			term:        "größe",
+			wantBefore:  "äöü ",
+			wantMatch:   "Größe",
+			wantAfter:   " 😀",
+			wantAsIsLen: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := markdowndoc.MarkdownSearchPayload{Term: tt.term, Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}}
+			searchMatches := []models.ScoredMarkdownContent{
+				{MarkdownContent: models.MarkdownContent{Content: tt.content, Meta: models.MarkdownMeta{Name: "Sizes", Path: "markdowns/Units"}}},
+			}
+
+			page, err := markdowndoc.MapToMarkdownSearchPage(mapper, payload, 10, len(searchMatches), searchMatches)
+			if err != nil {
+				t.Fatalf("mapToMarkdownSearchPage error: %v", err)
+			}
+
+			got := page.Content[0]
+			want := []string{tt.wantBefore, tt.wantMatch, tt.wantAfter}
+			if !cmp.Equal(want, []string{got.TextBeforeMatch, got.MatchingText, got.TextAfterMatch}) {
+				t.Error(cmp.Diff(want, []string{got.TextBeforeMatch, got.MatchingText, got.TextAfterMatch}))
+				return
+			}
+
+			snippet := got.TextBeforeMatch + got.MatchingText + got.TextAfterMatch
+			if !utf8.ValidString(snippet) || utf8.RuneCountInString(snippet) > mapper.MaxSnippetLength {
+				t.Errorf("want a valid snippet of at most %d runes, got %q", mapper.MaxSnippetLength, snippet)
+				return
+			}
+			if tt.wantAsIsLen == strings.Contains(snippet, "…") {
+				t.Errorf("want an ellipsis only in a truncated snippet, got %q", snippet)
+				return
+			}
+		})
+	}
+}
+
+func TestMapToMarkdownSearchPage_updatedAt(t *testing.T) {
+	mapper := markdowndoc.MarkdownSearchMatchMapper{Env: environment.Null()}
+	payload := markdowndoc.MarkdownSearchPayload{Term: "hello", Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}}
+
+	contentUpdatedAt := time.Date(2025, 3, 1, 12, 30, 0, 0, time.UTC)
+	// the meta is re-written on every sync, even if the content did not change
+	metaUpdatedAt := time.Date(2025, 6, 18, 9, 22, 38, 0, time.UTC)
+
+	searchMatches := []models.ScoredMarkdownContent{
+		{MarkdownContent: models.MarkdownContent{
+			Model:   models.Model{UpdatedAt: contentUpdatedAt},
+			Content: "hello",
+			Meta:    models.MarkdownMeta{Model: models.Model{UpdatedAt: metaUpdatedAt}, Name: "Hello", Path: "markdowns/Greetings"},
+		}},
+	}
+
+	page, err := markdowndoc.MapToMarkdownSearchPage(mapper, payload, 10, len(searchMatches), searchMatches)
+	if err != nil {
+		t.Fatalf("mapToMarkdownSearchPage error: %v", err)
+	}
+
+	if got := page.Content[0].UpdatedAt; !got.Equal(contentUpdatedAt) {
+		t.Errorf("want the content's timestamp %s, got %s", contentUpdatedAt, got)
+		return
+	}
+}
+
+func TestMapToMarkdownSearchPage_navigation(t *testing.T) {
+	mapper := markdowndoc.MarkdownSearchMatchMapper{Env: environment.Null()}
+
+	match := models.ScoredMarkdownContent{MarkdownContent: models.MarkdownContent{Content: "hello", Meta: models.MarkdownMeta{Name: "Hello", Path: "markdowns/Greetings"}}}
+
+	type navigation struct {
+		NumberOfElements                  int
+		First, Last, HasNext, HasPrevious bool
+	}
+
+	tests := []struct {
+		name          string
+		pageNumber    int
+		matchCount    int
+		searchMatches []models.ScoredMarkdownContent
+		want          navigation
+	}{
+		{
+			name:          "empty",
+			pageNumber:    1,
+			matchCount:    0,
+			searchMatches: []models.ScoredMarkdownContent{},
+			want:          navigation{NumberOfElements: 0, First: true, Last: true},
+		},
+		{
+			name:          "single",
+			pageNumber:    1,
+			matchCount:    2,
+			searchMatches: []models.ScoredMarkdownContent{match, match},
+			want:          navigation{NumberOfElements: 1, First: true, Last: true},
+		},
+		{
+			name:          "first",
+			pageNumber:    1,
+			matchCount:    5,
+			searchMatches: []models.ScoredMarkdownContent{match},
+			want:          navigation{NumberOfElements: 1, First: true, HasNext: true},
+		},
+		{
+			name:          "middle",
+			pageNumber:    3,
+			matchCount:    5,
+			searchMatches: []models.ScoredMarkdownContent{match},
+			want:          navigation{NumberOfElements: 1, HasNext: true, HasPrevious: true},
+		},
+		{
+			name:          "last",
+			pageNumber:    5,
+			matchCount:    5,
+			searchMatches: []models.ScoredMarkdownContent{match},
+			want:          navigation{NumberOfElements: 1, Last: true, HasPrevious: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payload := markdowndoc.MarkdownSearchPayload{Term: "hello", Pageable: markdowndoc.Pageable{PageNumber: tt.pageNumber, PageSize: 1}}
+
+			page, err := markdowndoc.MapToMarkdownSearchPage(mapper, payload, 1, tt.matchCount, tt.searchMatches)
+			if err != nil {
+				t.Fatalf("mapToMarkdownSearchPage error: %v", err)
+			}
+
+			got := navigation{
+				NumberOfElements: page.NumberOfElements,
+				First:            page.First,
+				Last:             page.Last,
+				HasNext:          page.HasNext,
+				HasPrevious:      page.HasPrevious,
+			}
+
+			if !cmp.Equal(tt.want, got) {
+				t.Error(cmp.Diff(tt.want, got))
+			}
+		})
+	}
+}
+
+func TestMapToMarkdownSearchPage_dedupesDocuments(t *testing.T) {
+	mapper := markdowndoc.MarkdownSearchMatchMapper{Env: environment.Null()}
+	payload := markdowndoc.MarkdownSearchPayload{Term: "kafka", Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}}
+
+	// the same document appears with an outdated and a current content row
+	searchMatches := []models.ScoredMarkdownContent{
+		{MarkdownContent: models.MarkdownContent{Content: "kafka (outdated)", Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Streaming"}}, Similarity: 0.2},
+		{MarkdownContent: models.MarkdownContent{Content: "kafka brokers", Meta: models.MarkdownMeta{Name: "Brokers", Path: "markdowns/Streaming"}}, Similarity: 0.4},
+		{MarkdownContent: models.MarkdownContent{Content: "kafka (current)", Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Streaming"}}, Similarity: 0.6},
+		{MarkdownContent: models.MarkdownContent{Content: "kafka elsewhere", Meta: models.MarkdownMeta{Name: "Consumers", Path: "markdowns/Gateway"}}, Similarity: 0.1},
+	}
+
+	page, err := markdowndoc.MapToMarkdownSearchPage(mapper, payload, 10, 12, searchMatches)
+	if err != nil {
+		t.Fatalf("mapToMarkdownSearchPage error: %v", err)
+	}
+
+	type pathAfterMatch struct{ Path, TextAfterMatch string }
+
+	want := []pathAfterMatch{
+		{Path: "Streaming", TextAfterMatch: " (current)"},
+		{Path: "Streaming", TextAfterMatch: " brokers"},
+		{Path: "Gateway", TextAfterMatch: " elsewhere"},
+	}
+
+	got := make([]pathAfterMatch, 0, len(page.Content))
+	for _, m := range page.Content {
+		got = append(got, pathAfterMatch{Path: m.Path, TextAfterMatch: m.TextAfterMatch})
+	}
+
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+
+	if page.TotalElements != 11 {
+		t.Errorf("want the duplicate to be subtracted from the match count (11), got %d", page.TotalElements)
+		return
+	}
+}
+
+func TestPageable_Normalize(t *testing.T) {
+	tests := []struct {
+		name            string
+		pageable        markdowndoc.Pageable
+		defaultPageSize int
+		maxPageSize     int
+		want            markdowndoc.Pageable
+	}{
+		{name: "unchanged", pageable: markdowndoc.Pageable{PageNumber: 2, PageSize: 10}, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 2, PageSize: 10}},
+		{name: "defaultPageSize", pageable: markdowndoc.Pageable{PageNumber: 1}, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 1, PageSize: markdowndoc.DefaultPageSize}},
+		{name: "configuredDefaultPageSize", pageable: markdowndoc.Pageable{PageNumber: 1}, defaultPageSize: 20, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 1, PageSize: 20}},
+		{name: "configuredDefaultPageSizeClampedToMax", pageable: markdowndoc.Pageable{PageNumber: 1}, defaultPageSize: 80, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 1, PageSize: 50}},
+		{name: "pageSizeClampedToMax", pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 1000}, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 1, PageSize: 50}},
+		{name: "pageSizeClampedToDefaultMax", pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 1000}, maxPageSize: 0, want: markdowndoc.Pageable{PageNumber: 1, PageSize: markdowndoc.DefaultMaxPageSize}},
+		{name: "pageNumberZero", pageable: markdowndoc.Pageable{PageNumber: 0, PageSize: 10}, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}},
+		{name: "pageNumberNegative", pageable: markdowndoc.Pageable{PageNumber: -3, PageSize: 10}, maxPageSize: 50, want: markdowndoc.Pageable{PageNumber: 1, PageSize: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.pageable
+			if err := got.Normalize(tt.defaultPageSize, tt.maxPageSize); err != nil {
+				t.Fatalf("Normalize error: %v", err)
+			}
+
+			if !cmp.Equal(tt.want, got, cmpopts.IgnoreUnexported(markdowndoc.Sort{})) {
+				t.Error(cmp.Diff(tt.want, got, cmpopts.IgnoreUnexported(markdowndoc.Sort{})))
+			}
+		})
+	}
+}
+
+func TestPageable_Normalize_negativePageSize(t *testing.T) {
+	pageable := markdowndoc.Pageable{PageNumber: 1, PageSize: -1}
+
+	if err := pageable.Normalize(0, 50); err == nil {
+		t.Error("want an error for a negative page size")
+	}
+}
+
+func TestPageable_Normalize_overflowingOffset(t *testing.T) {
+	pageable := markdowndoc.Pageable{PageNumber: 4611686018427387904, PageSize: 4}
+
+	if err := pageable.Normalize(0, 50); err == nil {
+		t.Errorf("want an error for a page number whose offset overflows, got %+v", pageable)
+	}
+}
+
 func BenchmarkTransformToUniqueTrigrams_Short_map(b *testing.B) {
 	input := "hello world"
 	for i := 0; i < b.N; i++ {
@@ -717,3 +1851,16 @@ func BenchmarkTransformToUniqueTrigrams_Large_map(b *testing.B) {
 		_ = markdowndoc.TransformToUniqueTrigrams(largeInput)
 	}
 }
+
+func BenchmarkTransformToUniqueTrigramsWithMode_Large_map(b *testing.B) {
+	// the same input as BenchmarkTransformToUniqueTrigrams_Large_map
+	largeInput := strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 10000)
+
+	for _, mode := range []markdowndoc.TrigramMode{markdowndoc.TrigramModePerWord, markdowndoc.TrigramModeWholeString} {
+		b.Run(string(mode), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = markdowndoc.TransformToUniqueTrigramsWithMode(largeInput, mode)
+			}
+		})
+	}
+}
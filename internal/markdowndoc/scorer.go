@@ -0,0 +1,62 @@
+package markdowndoc
+
+import (
+	"slices"
+	"sync"
+)
+
+// Scorer computes the similarity of two strings within [0,1]; the search calls it with a Markdown content (or title) and the term.
+// It is given the raw strings, so it bypasses the Tokenizer (FoldAccents, StopWords) and the TrigramCache
+type Scorer func(a, b string) float64
+
+// TrigramSetScorer computes the similarity of two sets of unique trigrams within [0,1];
+// the search calls it with the trigrams extracted by the Tokenizer, served from the TrigramCache if one is set
+type TrigramSetScorer func(aTrigrams, bTrigrams []string) float64
+
+// ScorerTrigramDice is the name of the Sorensen-Dice coefficient of the unique trigrams (see TrigramSorensenDiceSimilarity)
+const ScorerTrigramDice = "trigram-dice"
+
+// scorers is the registry of the named scorers selectable via config (Search.Scorer) or payload (MarkdownSearchPayload.Scorer)
+var (
+	scorersMu sync.RWMutex
+	scorers   = map[string]Scorer{
+		ScorerTrigramDice: TrigramSorensenDiceSimilarity,
+	}
+)
+
+// trigramSetScorers are the built-in scorers the search ranks by instead of the registered Scorer of the same name
+var trigramSetScorers = map[string]TrigramSetScorer{
+	ScorerTrigramDice: TrigramSetSorensenDiceSimilarity,
+}
+
+// RegisterScorer registers the scorer under the given name; a scorer already registered under the name is replaced.
+// The search keeps ranking by the built-in scorers (e.g., ScorerTrigramDice) though
+func RegisterScorer(name string, scorer Scorer) {
+	scorersMu.Lock()
+	defer scorersMu.Unlock()
+
+	scorers[name] = scorer
+}
+
+// LookupScorer returns the scorer registered under the given name
+func LookupScorer(name string) (Scorer, bool) {
+	scorersMu.RLock()
+	defer scorersMu.RUnlock()
+
+	scorer, ok := scorers[name]
+	return scorer, ok
+}
+
+// ScorerNames returns the names of all registered scorers in lexicographic order
+func ScorerNames() []string {
+	scorersMu.RLock()
+	defer scorersMu.RUnlock()
+
+	names := make([]string, 0, len(scorers))
+	for name := range scorers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	return names
+}
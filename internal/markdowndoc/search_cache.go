@@ -0,0 +1,130 @@
+package markdowndoc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultSearchCacheTTL is the time a search result is cached if no (positive) TTL is configured
+	DefaultSearchCacheTTL = time.Minute
+	// DefaultSearchCacheSize is the maximum number of cached search results if no (positive) size is configured
+	DefaultSearchCacheSize = 256
+)
+
+// searchResult is a search's page together with the highest similarity of its matches (see Controller.SearchAnalytics)
+type searchResult struct {
+	page          Page[MarkdownSearchMatch]
+	topSimilarity float64
+}
+
+type searchCacheEntry struct {
+	result    searchResult
+	expiresAt time.Time
+}
+
+// SearchCache stores the results of recent searches, so that popular searches are neither queried nor ranked again.
+//
+// Entries expire after the TTL; once the cache is full, the entry expiring next is evicted.
+// Since the results are derived from the Markdown contents, the cache must be invalidated after a sync (see bitbucket.ContentCache).
+// The cache is safe for concurrent use; a nil *SearchCache caches nothing.
+type SearchCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    map[string]searchCacheEntry
+	now        func() time.Time
+}
+
+// NewSearchCache creates an empty SearchCache (defaults: DefaultSearchCacheTTL and DefaultSearchCacheSize)
+func NewSearchCache(ttl time.Duration, maxEntries int) *SearchCache {
+	if ttl <= 0 {
+		ttl = DefaultSearchCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultSearchCacheSize
+	}
+
+	return &SearchCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]searchCacheEntry),
+		now:        time.Now,
+	}
+}
+
+// searchCacheKey identifies a search by its normalized payload; the term is trimmed, but keeps its case
+// since the default search (LIKE) matches case-sensitively
+func searchCacheKey(payload MarkdownSearchPayload) string {
+	payload.Term = strings.TrimSpace(payload.Term)
+
+	return fmt.Sprintf("%#v", payload)
+}
+
+// get returns the unexpired result cached for the key
+func (sc *SearchCache) get(key string) (searchResult, bool) {
+	if sc == nil {
+		return searchResult{}, false
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	entry, ok := sc.entries[key]
+	if !ok || !sc.now().Before(entry.expiresAt) {
+		return searchResult{}, false
+	}
+
+	return entry.result, true
+}
+
+// put caches the result for the key; the result's page must not be modified afterward since it is shared between callers
+func (sc *SearchCache) put(key string, result searchResult) {
+	if sc == nil {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	now := sc.now()
+	if _, ok := sc.entries[key]; !ok && len(sc.entries) >= sc.maxEntries {
+		sc.evict(now)
+	}
+
+	sc.entries[key] = searchCacheEntry{result: result, expiresAt: now.Add(sc.ttl)}
+}
+
+// evict removes the expired entries or, if none expired, the entry expiring next; the caller must hold the lock
+func (sc *SearchCache) evict(now time.Time) {
+	var nextKey string
+	var next time.Time
+	for key, entry := range sc.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(sc.entries, key)
+			continue
+		}
+
+		if next.IsZero() || entry.expiresAt.Before(next) {
+			nextKey, next = key, entry.expiresAt
+		}
+	}
+
+	if len(sc.entries) >= sc.maxEntries {
+		delete(sc.entries, nextKey)
+	}
+}
+
+// Invalidate removes all entries from the cache
+func (sc *SearchCache) Invalidate() {
+	if sc == nil {
+		return
+	}
+
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	clear(sc.entries)
+}
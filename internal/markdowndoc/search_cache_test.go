@@ -0,0 +1,136 @@
+package markdowndoc_test
+
+import (
+	"dice-sorensen-similarity-search/internal/markdowndoc"
+	"dice-sorensen-similarity-search/internal/models"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestSearchCache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	repo := newMockRepository()
+	ctrl := newMockController(repo)
+	ctrl.SearchCache = markdowndoc.NewSearchCache(time.Minute, 2)
+	ctrl.SearchCache.SetNow(func() time.Time { return now })
+
+	search := func(t *testing.T, term string, pageNumber int, wantSearchCalls int) {
+		t.Helper()
+
+		payload := markdowndoc.MarkdownSearchPayload{
+			Term:     term,
+			Pageable: markdowndoc.Pageable{PageNumber: pageNumber, PageSize: 1},
+		}
+
+		w := performSearchRequest(t, ctrl, payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+
+		if repo.searchCalls != wantSearchCalls {
+			t.Fatalf("want %d search(es) in the database, got %d", wantSearchCalls, repo.searchCalls)
+		}
+	}
+
+	t.Run("miss", func(t *testing.T) {
+		search(t, "this", 1, 1)
+	})
+
+	t.Run("hitOfNormalizedTerm", func(t *testing.T) {
+		search(t, " this ", 1, 1)
+	})
+
+	t.Run("missOfOtherPage", func(t *testing.T) {
+		search(t, "this", 2, 2)
+	})
+
+	t.Run("missAfterInvalidation", func(t *testing.T) {
+		// the sync invalidates the cache once the contents were stored (see bitbucket.ContentCache)
+		ctrl.SearchCache.Invalidate()
+		search(t, "this", 1, 3)
+		search(t, "this", 1, 3)
+	})
+
+	t.Run("missAfterExpiry", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		search(t, "this", 1, 4)
+	})
+
+	t.Run("missAfterEviction", func(t *testing.T) {
+		// the cache holds 2 pages; the 1st page (expiring next) is evicted for the 3rd one
+		now = now.Add(time.Second)
+		search(t, "this", 2, 5)
+		search(t, "this", 3, 6)
+		search(t, "this", 1, 7)
+	})
+}
+
+func TestSearchCache_termsDifferingInCase(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &mockRepository{
+		markdownContentsForSearch: []models.MarkdownContent{
+			{Meta: models.MarkdownMeta{Name: "upper", Path: "markdowns/Kafka"}, Content: "Kafka"},
+			{Meta: models.MarkdownMeta{Name: "lower", Path: "markdowns/Kafka"}, Content: "kafka"},
+		},
+	}
+	ctrl := newMockController(repo)
+	ctrl.SearchCache = markdowndoc.NewSearchCache(time.Minute, 2)
+
+	for _, tt := range []struct{ term, wantHref string }{{term: "Kafka", wantHref: "upper"}, {term: "kafka", wantHref: "lower"}} {
+		payload := markdowndoc.MarkdownSearchPayload{
+			Term:     tt.term,
+			Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 10},
+		}
+
+		w := performSearchRequest(t, ctrl, payload)
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+
+		var page markdowndoc.Page[markdowndoc.MarkdownSearchMatch]
+		if err := json.Unmarshal(w.Body.Bytes(), &page); err != nil {
+			t.Fatalf("failed to unmarshal response: %v", err)
+		}
+
+		if len(page.Content) != 1 || page.Content[0].Href != tt.wantHref {
+			t.Errorf("want only %s for the case-sensitive term %q, got %+v", tt.wantHref, tt.term, page.Content)
+			return
+		}
+	}
+
+	if repo.searchCalls != 2 {
+		t.Errorf("want a search in the database per case, got %d search(es)", repo.searchCalls)
+		return
+	}
+}
+
+func TestSearchCache_debugIsNotCached(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := newMockRepository()
+	ctrl := newMockController(repo)
+	ctrl.SearchCache = markdowndoc.NewSearchCache(time.Minute, 2)
+
+	payload := markdowndoc.MarkdownSearchPayload{
+		Term:     "this",
+		Pageable: markdowndoc.Pageable{PageNumber: 1, PageSize: 1},
+	}
+
+	for range 2 {
+		if w := performSearchRequestWithQuery(t, ctrl, payload, "debug=true"); w.Code != http.StatusOK {
+			t.Fatalf("expected status 200 OK, got %d", w.Code)
+		}
+	}
+
+	if repo.searchCalls != 2 {
+		t.Errorf("want every debug search in the database, got %d search(es)", repo.searchCalls)
+		return
+	}
+}
@@ -0,0 +1,93 @@
+package markdowndoc
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// properties search matches can be sorted by
+const (
+	SortPropertySimilarity = "similarity"
+	SortPropertyName       = "name"
+	SortPropertyPath       = "path"
+)
+
+// defaultSearchOrders rank the most similar match first
+func defaultSearchOrders() []Order {
+	return []Order{{Property: SortPropertySimilarity, Direction: DESC}}
+}
+
+// searchOrders validates the requested orders and normalizes their properties and directions;
+// if no orders are requested, the defaultSearchOrders apply.
+// An order without a direction is ascending.
+func searchOrders(s Sort) ([]Order, error) {
+	if len(s.Orders) == 0 {
+		return defaultSearchOrders(), nil
+	}
+
+	orders := make([]Order, 0, len(s.Orders))
+	for _, o := range s.Orders {
+		property := strings.ToLower(strings.TrimSpace(o.Property))
+		switch property {
+		case SortPropertySimilarity, SortPropertyName, SortPropertyPath:
+		default:
+			return nil, fmt.Errorf("unknown sort property %q; must be %q, %q, or %q", o.Property, SortPropertySimilarity, SortPropertyName, SortPropertyPath)
+		}
+
+		direction := Direction(strings.ToUpper(strings.TrimSpace(string(o.Direction))))
+		if len(direction) == 0 {
+			direction = ASC
+		}
+		if direction != ASC && direction != DESC {
+			return nil, fmt.Errorf("unknown sort direction %q; must be %q or %q", o.Direction, ASC, DESC)
+		}
+
+		orders = append(orders, Order{Property: property, Direction: direction})
+	}
+
+	return orders, nil
+}
+
+// isDefaultSearchOrder reports whether the orders equal the defaultSearchOrders
+func isDefaultSearchOrder(orders []Order) bool {
+	return slices.Equal(orders, defaultSearchOrders())
+}
+
+// sortSearchMatches sorts the matches by the given (validated) orders; the first order that tells two matches apart decides.
+// Matches that are equal with respect to all orders (e.g., of equal similarity) are sorted by name and path ascending,
+// so that the order, and hence the pages, do not depend on the order the database returned the matches in.
+func sortSearchMatches(matches []MatchesWithSimilarity, orders []Order) {
+	if len(orders) == 0 {
+		orders = defaultSearchOrders()
+	}
+
+	slices.SortStableFunc(matches, func(a, b MatchesWithSimilarity) int {
+		for _, o := range orders {
+			var c int
+			switch o.Property {
+			case SortPropertySimilarity:
+				c = cmp.Compare(a.similarity, b.similarity)
+			case SortPropertyName:
+				c = strings.Compare(strings.ToLower(a.content.Meta.Name), strings.ToLower(b.content.Meta.Name))
+			case SortPropertyPath:
+				c = strings.Compare(strings.ToLower(a.content.Meta.Path), strings.ToLower(b.content.Meta.Path))
+			}
+
+			if o.Direction == DESC {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+
+		return cmp.Or(
+			strings.Compare(strings.ToLower(a.content.Meta.Name), strings.ToLower(b.content.Meta.Name)),
+			strings.Compare(strings.ToLower(a.content.Meta.Path), strings.ToLower(b.content.Meta.Path)),
+			strings.Compare(a.content.Meta.Name, b.content.Meta.Name),
+			strings.Compare(a.content.Meta.Path, b.content.Meta.Path),
+		)
+	})
+}
@@ -0,0 +1,35 @@
+package markdowndoc
+
+import "strings"
+
+// Tokenizer extracts the unique trigrams of search terms and contents (see TransformToUniqueTrigrams),
+// optionally with accents stripped and stop words removed.
+// A nil Tokenizer neither folds accents nor removes stop words.
+type Tokenizer struct {
+	foldAccents bool
+	stopWords   map[string]struct{}
+}
+
+// NewTokenizer creates a Tokenizer that drops the given stop words (case-insensitively) before extracting trigrams;
+// if foldAccents is set, accents are stripped from texts and stop words alike
+func NewTokenizer(foldAccents bool, stopWords []string) *Tokenizer {
+	t := &Tokenizer{foldAccents: foldAccents, stopWords: make(map[string]struct{}, len(stopWords))}
+	for _, w := range stopWords {
+		w = normalizeText(strings.TrimSpace(w), foldAccents)
+		if len(w) == 0 {
+			continue
+		}
+		t.stopWords[w] = struct{}{}
+	}
+
+	return t
+}
+
+// UniqueTrigrams returns the sorted, unique trigrams of the given text
+func (t *Tokenizer) UniqueTrigrams(a string) []string {
+	if t == nil {
+		return TransformToUniqueTrigrams(a)
+	}
+
+	return transformToUniqueTrigrams(a, trigramOptions{foldAccents: t.foldAccents, stopWords: t.stopWords})
+}
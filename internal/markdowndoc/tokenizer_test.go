@@ -0,0 +1,55 @@
+package markdowndoc_test
+
+import (
+	"dice-sorensen-similarity-search/internal/markdowndoc"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+)
+
+func TestTokenizer_UniqueTrigrams_stopWords(t *testing.T) {
+	tokenizer := markdowndoc.NewTokenizer(false, []string{"This", " is ", "a", ""})
+
+	got := tokenizer.UniqueTrigrams("this is a sample")
+	want := markdowndoc.TransformToUniqueTrigrams("sample")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("want the trigrams of the stop words to be removed (-want +got):\n%s", diff)
+	}
+
+	for _, trigram := range got {
+		if strings.Contains(trigram, "th") || strings.Contains(trigram, " i") || trigram == " a " {
+			t.Errorf("want no trigram of a stop word, got %q", trigram)
+		}
+	}
+}
+
+func TestTokenizer_UniqueTrigrams_stopWordsFolded(t *testing.T) {
+	tokenizer := markdowndoc.NewTokenizer(true, []string{"für"})
+
+	got := tokenizer.UniqueTrigrams("Fur Kafka")
+	want := markdowndoc.TransformToUniqueTrigrams("kafka")
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("want folded stop words to match folded text (-want +got):\n%s", diff)
+	}
+}
+
+func TestTokenizer_UniqueTrigrams_defaults(t *testing.T) {
+	text := "this is a sample"
+	want := markdowndoc.TransformToUniqueTrigrams(text)
+
+	tests := []struct {
+		name      string
+		tokenizer *markdowndoc.Tokenizer
+	}{
+		{name: "nil", tokenizer: nil},
+		{name: "noStopWords", tokenizer: markdowndoc.NewTokenizer(false, nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := cmp.Diff(want, tt.tokenizer.UniqueTrigrams(text)); diff != "" {
+				t.Errorf("want all words to be kept (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package markdowndoc
+
+import (
+	"dice-sorensen-similarity-search/internal/models"
+	"sync"
+	"time"
+)
+
+// trigramCacheKey identifies a specific version of a MarkdownContent
+type trigramCacheKey struct {
+	id        uint
+	updatedAt time.Time
+	tokenizer *Tokenizer
+}
+
+// TrigramCache stores the unique trigrams of MarkdownContents, so they are not re-tokenized on every search.
+//
+// Entries are keyed by the content's ID and UpdatedAt timestamp; hence, a modified content is never served from a stale entry.
+// The cache is safe for concurrent use; the zero value is ready to use.
+type TrigramCache struct {
+	mu       sync.Mutex
+	trigrams map[trigramCacheKey][]string
+
+	hits   uint64
+	misses uint64
+}
+
+// NewTrigramCache creates an empty TrigramCache
+func NewTrigramCache() *TrigramCache {
+	return &TrigramCache{trigrams: make(map[trigramCacheKey][]string)}
+}
+
+// Trigrams returns the unique trigrams of the given content; they are computed and stored on a cache miss.
+//
+// The trigrams are extracted by the given Tokenizer, which may be nil (see Tokenizer.UniqueTrigrams).
+// Contents without an ID (i.e. not persisted) are never cached.
+// The returned slice is shared between callers and must not be modified.
+func (tc *TrigramCache) Trigrams(content models.MarkdownContent, tokenizer *Tokenizer) []string {
+	if content.ID == 0 {
+		return tokenizer.UniqueTrigrams(content.Content)
+	}
+
+	key := trigramCacheKey{id: content.ID, updatedAt: content.UpdatedAt, tokenizer: tokenizer}
+
+	tc.mu.Lock()
+	trigrams, ok := tc.trigrams[key]
+	if ok {
+		tc.hits++
+		tc.mu.Unlock()
+		return trigrams
+	}
+	tc.misses++
+	tc.mu.Unlock()
+
+	// tokenizing is done without holding the lock, so concurrent searches are not serialized
+	trigrams = tokenizer.UniqueTrigrams(content.Content)
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if tc.trigrams == nil {
+		tc.trigrams = make(map[trigramCacheKey][]string)
+	}
+	tc.trigrams[key] = trigrams
+
+	return trigrams
+}
+
+// Invalidate removes all entries from the cache
+func (tc *TrigramCache) Invalidate() {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	clear(tc.trigrams)
+}
+
+// Stats returns the number of cache hits and misses since the cache was created
+func (tc *TrigramCache) Stats() (hits, misses uint64) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	return tc.hits, tc.misses
+}
@@ -0,0 +1,99 @@
+package markdowndoc_test
+
+import (
+	"dice-sorensen-similarity-search/internal/markdowndoc"
+	"dice-sorensen-similarity-search/internal/models"
+	"github.com/google/go-cmp/cmp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrigramCache_hitRate(t *testing.T) {
+	cache := markdowndoc.NewTrigramCache()
+
+	updatedAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	contents := []models.MarkdownContent{
+		{Model: models.Model{ID: 1, UpdatedAt: updatedAt}, Content: "hello world"},
+		{Model: models.Model{ID: 2, UpdatedAt: updatedAt}, Content: "lorem ipsum"},
+	}
+
+	assertStats := func(t *testing.T, wantHits, wantMisses uint64) {
+		t.Helper()
+
+		hits, misses := cache.Stats()
+		if hits != wantHits || misses != wantMisses {
+			t.Errorf("want %d hits and %d misses, got %d hits and %d misses", wantHits, wantMisses, hits, misses)
+		}
+	}
+
+	// the first search tokenizes every content
+	for _, c := range contents {
+		got := cache.Trigrams(c, nil)
+		want := markdowndoc.TransformToUniqueTrigrams(c.Content)
+		if !cmp.Equal(want, got) {
+			t.Error(cmp.Diff(want, got))
+			return
+		}
+	}
+	assertStats(t, 0, 2)
+
+	// a repeated search is served from the cache
+	for _, c := range contents {
+		_ = cache.Trigrams(c, nil)
+	}
+	assertStats(t, 2, 2)
+
+	// a modified content (=> new updated_at) is a miss
+	modified := contents[0]
+	modified.UpdatedAt = updatedAt.Add(time.Minute)
+	modified.Content = "hello gopher"
+
+	got := cache.Trigrams(modified, nil)
+	want := markdowndoc.TransformToUniqueTrigrams(modified.Content)
+	if !cmp.Equal(want, got) {
+		t.Error(cmp.Diff(want, got))
+		return
+	}
+	assertStats(t, 2, 3)
+
+	// after invalidation, every content is tokenized again
+	cache.Invalidate()
+	for _, c := range contents {
+		_ = cache.Trigrams(c, nil)
+	}
+	assertStats(t, 2, 5)
+}
+
+func BenchmarkTrigramCache(b *testing.B) {
+	content := models.MarkdownContent{
+		Model:   models.Model{ID: 1, UpdatedAt: time.Now()},
+		Content: strings.Repeat("Lorem ipsum dolor sit amet, consectetur adipiscing elit. ", 1000),
+	}
+
+	b.Run("Uncached", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_ = markdowndoc.TransformToUniqueTrigrams(content.Content)
+		}
+	})
+
+	b.Run("Cached", func(b *testing.B) {
+		cache := markdowndoc.NewTrigramCache()
+		for i := 0; i < b.N; i++ {
+			_ = cache.Trigrams(content, nil)
+		}
+	})
+}
+
+func TestTrigramCache_tokenizer(t *testing.T) {
+	cache := markdowndoc.NewTrigramCache()
+	content := models.MarkdownContent{Model: models.Model{ID: 1}, Content: "this is a sample"}
+	tokenizer := markdowndoc.NewTokenizer(false, []string{"this", "is", "a"})
+
+	_ = cache.Trigrams(content, nil)
+
+	got := cache.Trigrams(content, tokenizer)
+	if diff := cmp.Diff(tokenizer.UniqueTrigrams(content.Content), got); diff != "" {
+		t.Errorf("want trigrams of another tokenizer not to be served from the cache (-want +got):\n%s", diff)
+	}
+}
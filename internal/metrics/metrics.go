@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+// namespace prefixes the names of all metrics exposed by the application
+const namespace = "dice_sorensen_similarity_search"
+
+// Metrics holds the Prometheus collectors of the application and the registry they are registered with.
+//
+// It implements markdowndoc.SearchMetrics and bitbucket.SyncMetrics; a nil *Metrics records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	searchDuration prometheus.Histogram
+	searchMatches  prometheus.Counter
+	syncDuration   prometheus.Histogram
+	filesIngested  prometheus.Counter
+}
+
+// New creates the collectors and registers them, together with the Go runtime and process collectors, with a new registry
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		searchDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "search_request_duration_seconds",
+			Help:      "Duration of Markdown search requests.",
+			Buckets:   prometheus.DefBuckets,
+		}),
+		searchMatches: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "search_matches_total",
+			Help:      "Number of search matches returned.",
+		}),
+		syncDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "sync_duration_seconds",
+			Help:      "Duration of syncing the Markdown files from their source into the database.",
+			// a sync reads every file from the source; hence, it takes considerably longer than a search
+			Buckets: []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300},
+		}),
+		filesIngested: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sync_files_ingested_total",
+			Help:      "Number of Markdown files read from their source and stored in the database.",
+		}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.searchDuration,
+		m.searchMatches,
+		m.syncDuration,
+		m.filesIngested,
+	)
+
+	return m
+}
+
+// Handler serves the registered metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveSearch records the duration of a search request and the number of matches it returned
+func (m *Metrics) ObserveSearch(duration time.Duration, matches int) {
+	if m == nil {
+		return
+	}
+
+	m.searchDuration.Observe(duration.Seconds())
+	m.searchMatches.Add(float64(matches))
+}
+
+// ObserveSync records the duration of a sync and the number of files it ingested
+func (m *Metrics) ObserveSync(duration time.Duration, filesIngested int) {
+	if m == nil {
+		return
+	}
+
+	m.syncDuration.Observe(duration.Seconds())
+	m.filesIngested.Add(float64(filesIngested))
+}
@@ -0,0 +1,50 @@
+package metrics_test
+
+import (
+	"dice-sorensen-similarity-search/internal/metrics"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics_Handler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	m := metrics.New()
+	m.ObserveSearch(120*time.Millisecond, 3)
+	m.ObserveSync(4*time.Second, 12)
+
+	r := gin.New()
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200, got %d", w.Code)
+	}
+
+	body := w.Body.String()
+	for _, want := range []string{
+		"dice_sorensen_similarity_search_search_request_duration_seconds_bucket",
+		"dice_sorensen_similarity_search_search_matches_total 3",
+		"dice_sorensen_similarity_search_sync_duration_seconds_count 1",
+		"dice_sorensen_similarity_search_sync_files_ingested_total 12",
+		"go_goroutines",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want the scraped metrics to contain %q", want)
+		}
+	}
+}
+
+func TestMetrics_nil(t *testing.T) {
+	var m *metrics.Metrics
+
+	// must not panic
+	m.ObserveSearch(time.Second, 1)
+	m.ObserveSync(time.Second, 1)
+}
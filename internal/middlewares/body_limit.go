@@ -0,0 +1,38 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// DefaultMaxRequestBodyBytes is the maximum size of a request body if no (positive) limit is configured
+const DefaultMaxRequestBodyBytes = 1 << 20
+
+// BodyLimitHandler caps the bodies of POST, PUT, and PATCH requests at maxBytes (default: DefaultMaxRequestBodyBytes),
+// so that a client cannot exhaust the memory of the handlers reading the whole body.
+//
+// A request declaring a larger Content-Length is rejected with 413 right away; otherwise, reading beyond the limit
+// fails with an *http.MaxBytesError, which the handlers should report with 413 as well.
+func BodyLimitHandler(maxBytes int64) gin.HandlerFunc {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxRequestBodyBytes
+	}
+
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch:
+		default:
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": "The request body is too large."})
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,55 @@
+package middlewares_test
+
+import (
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBodyLimitHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name          string
+		method        string
+		body          string
+		contentLength int64
+		wantStatus    int
+	}{
+		{name: "withinLimit", method: http.MethodPost, body: strings.Repeat("a", 16), contentLength: 16, wantStatus: http.StatusOK},
+		{name: "oversizedContentLength", method: http.MethodPost, body: strings.Repeat("a", 17), contentLength: 17, wantStatus: http.StatusRequestEntityTooLarge},
+		// e.g., a chunked request; the handler notices the limit when reading the body
+		{name: "oversizedUnknownLength", method: http.MethodPost, body: strings.Repeat("a", 17), contentLength: -1, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "notLimited", method: http.MethodDelete, body: strings.Repeat("a", 17), contentLength: 17, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middlewares.BodyLimitHandler(16))
+			r.Handle(tt.method, "/search", func(c *gin.Context) {
+				var maxBytesErr *http.MaxBytesError
+				if _, err := io.ReadAll(c.Request.Body); errors.As(err, &maxBytesErr) {
+					c.Status(http.StatusRequestEntityTooLarge)
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(tt.method, "/search", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, w.Code)
+				return
+			}
+		})
+	}
+}
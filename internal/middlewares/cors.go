@@ -1,12 +1,36 @@
 package middlewares
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"slices"
+	"strings"
+)
 
-func CORSMiddleware() gin.HandlerFunc {
+// CORSMiddleware allows cross-origin requests from the given origins.
+// An allowed origin is either "*" (any origin, but without credentials),
+// an exact origin (e.g. "https://docs.example.com"),
+// or a wildcard-subdomain pattern (e.g. "https://*.example.com", which does not match "https://example.com").
+//
+// The request's Origin is only echoed back if it is allowed; otherwise, the browser blocks the response.
+func CORSMiddleware(allowedOrigins []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		// the response depends on the requesting origin; shared caches must not mix them up
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.Request.Header.Get("Origin")
+		if len(origin) > 0 {
+			if isOriginAllowed(origin, allowedOrigins) {
+				c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+				c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
+			} else if slices.Contains(allowedOrigins, "*") {
+				// browsers reject credentials for the "*" origin
+				c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+			}
+		}
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		// lets browser clients read the request ID, e.g. to report it along with an error
+		c.Writer.Header().Set("Access-Control-Expose-Headers", RequestIdHeader)
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -28,3 +52,44 @@ func CORSMiddleware() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// isOriginAllowed reports whether the origin matches an exact or wildcard-subdomain entry of the allowed origins;
+// the "*" entry is not considered here since it must not be combined with credentials
+func isOriginAllowed(origin string, allowedOrigins []string) bool {
+	for _, allowed := range allowedOrigins {
+		if allowed == "*" {
+			continue
+		}
+
+		if strings.EqualFold(origin, allowed) {
+			return true
+		}
+
+		if strings.Contains(allowed, "://*.") && matchesWildcardSubdomain(origin, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesWildcardSubdomain reports whether the origin is a subdomain of the pattern's domain,
+// with the same scheme and port (e.g. "https://docs.example.com" matches "https://*.example.com")
+func matchesWildcardSubdomain(origin, pattern string) bool {
+	o, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+
+	p, err := url.Parse(strings.Replace(pattern, "://*.", "://", 1))
+	if err != nil {
+		return false
+	}
+
+	if !strings.EqualFold(o.Scheme, p.Scheme) || o.Port() != p.Port() {
+		return false
+	}
+
+	host, domain := strings.ToLower(o.Hostname()), strings.ToLower(p.Hostname())
+	return strings.HasSuffix(host, "."+domain) && len(host) > len(domain)+1
+}
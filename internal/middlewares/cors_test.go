@@ -0,0 +1,81 @@
+package middlewares_test
+
+import (
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name            string
+		allowedOrigins  []string
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+	}{
+		{name: "allowedOrigin", allowedOrigins: []string{"https://docs.example.com"}, origin: "https://docs.example.com", wantOrigin: "https://docs.example.com", wantCredentials: "true"},
+		{name: "disallowedOrigin", allowedOrigins: []string{"https://docs.example.com"}, origin: "https://evil.com", wantOrigin: "", wantCredentials: ""},
+		{name: "wildcardSubdomain", allowedOrigins: []string{"https://*.example.com"}, origin: "https://docs.example.com", wantOrigin: "https://docs.example.com", wantCredentials: "true"},
+		{name: "wildcardNestedSubdomain", allowedOrigins: []string{"https://*.example.com"}, origin: "https://a.docs.example.com", wantOrigin: "https://a.docs.example.com", wantCredentials: "true"},
+		{name: "wildcardDoesNotMatchApexDomain", allowedOrigins: []string{"https://*.example.com"}, origin: "https://example.com", wantOrigin: "", wantCredentials: ""},
+		{name: "wildcardDoesNotMatchSuffix", allowedOrigins: []string{"https://*.example.com"}, origin: "https://evilexample.com", wantOrigin: "", wantCredentials: ""},
+		{name: "wildcardDoesNotMatchOtherScheme", allowedOrigins: []string{"https://*.example.com"}, origin: "http://docs.example.com", wantOrigin: "", wantCredentials: ""},
+		{name: "anyOriginWithoutCredentials", allowedOrigins: []string{"*"}, origin: "https://evil.com", wantOrigin: "*", wantCredentials: ""},
+		{name: "allowlistedOriginAlongsideAnyOrigin", allowedOrigins: []string{"*", "https://docs.example.com"}, origin: "https://docs.example.com", wantOrigin: "https://docs.example.com", wantCredentials: "true"},
+		{name: "noOrigin", allowedOrigins: []string{"*"}, origin: "", wantOrigin: "", wantCredentials: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middlewares.CORSMiddleware(tt.allowedOrigins))
+			r.GET("/", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if len(tt.origin) > 0 {
+				req.Header.Set("Origin", tt.origin)
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Errorf("want Access-Control-Allow-Origin %q, got %q", tt.wantOrigin, got)
+			}
+
+			if got := w.Header().Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Errorf("want Access-Control-Allow-Credentials %q, got %q", tt.wantCredentials, got)
+				return
+			}
+		})
+	}
+}
+
+func TestCORSMiddleware_preflight(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(middlewares.CORSMiddleware([]string{"https://docs.example.com"}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/markdown-doc/markdown/search", nil)
+	req.Header.Set("Origin", "https://docs.example.com")
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNoContent {
+		t.Errorf("want status 204, got %d", w.Code)
+	}
+
+	if got := w.Header().Get("Access-Control-Allow-Origin"); got != "https://docs.example.com" {
+		t.Errorf("want the origin to be echoed back, got %q", got)
+		return
+	}
+}
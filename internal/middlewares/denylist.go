@@ -0,0 +1,75 @@
+package middlewares
+
+import (
+	"sync"
+	"time"
+)
+
+// TokenDenylist keeps track of revoked JWTs by their unique ID (jti).
+// An entry only has to be kept until the revoked token expires; afterward, the token is rejected anyway.
+type TokenDenylist interface {
+
+	// Revoke denies the token with the given ID until it expires
+	Revoke(jti string, expiresAt time.Time)
+
+	// IsRevoked reports whether the token with the given ID has been revoked
+	IsRevoked(jti string) bool
+}
+
+// MemoryDenylist is an in-memory TokenDenylist; expired entries are evicted whenever a token is revoked.
+// Its entries are lost on restart and are not shared between instances.
+type MemoryDenylist struct {
+	mu      sync.Mutex
+	entries map[string]time.Time
+}
+
+// ensure MemoryDenylist implements TokenDenylist
+var _ TokenDenylist = &MemoryDenylist{}
+
+// NewMemoryDenylist creates an empty MemoryDenylist
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{entries: map[string]time.Time{}}
+}
+
+func (d *MemoryDenylist) Revoke(jti string, expiresAt time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.evictExpired()
+	d.entries[jti] = expiresAt
+}
+
+func (d *MemoryDenylist) IsRevoked(jti string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	expiresAt, ok := d.entries[jti]
+	if !ok {
+		return false
+	}
+
+	if !time.Now().Before(expiresAt) {
+		delete(d.entries, jti)
+		return false
+	}
+
+	return true
+}
+
+// Len returns the number of entries that have not been evicted yet
+func (d *MemoryDenylist) Len() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return len(d.entries)
+}
+
+// evictExpired removes the entries of expired tokens; the caller must hold the lock
+func (d *MemoryDenylist) evictExpired() {
+	now := time.Now()
+	for jti, expiresAt := range d.entries {
+		if !now.Before(expiresAt) {
+			delete(d.entries, jti)
+		}
+	}
+}
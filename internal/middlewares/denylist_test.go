@@ -0,0 +1,49 @@
+package middlewares_test
+
+import (
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"testing"
+	"time"
+)
+
+func TestMemoryDenylist(t *testing.T) {
+	denylist := middlewares.NewMemoryDenylist()
+
+	denylist.Revoke("revoked", time.Now().Add(time.Hour))
+	denylist.Revoke("expired", time.Now().Add(-time.Second))
+
+	tests := []struct {
+		name        string
+		jti         string
+		wantRevoked bool
+	}{
+		{name: "revoked", jti: "revoked", wantRevoked: true},
+		{name: "expired", jti: "expired", wantRevoked: false},
+		{name: "unknown", jti: "unknown", wantRevoked: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := denylist.IsRevoked(tt.jti); got != tt.wantRevoked {
+				t.Errorf("want revoked %v, got %v", tt.wantRevoked, got)
+				return
+			}
+		})
+	}
+}
+
+func TestMemoryDenylist_evictsExpiredEntries(t *testing.T) {
+	denylist := middlewares.NewMemoryDenylist()
+
+	denylist.Revoke("a", time.Now().Add(-time.Second))
+	denylist.Revoke("b", time.Now().Add(-time.Second))
+	if got := denylist.Len(); got != 1 {
+		t.Fatalf("want 1 entry after revoking b, got %d", got)
+	}
+
+	denylist.Revoke("c", time.Now().Add(time.Hour))
+	if got := denylist.Len(); got != 1 {
+		t.Errorf("want only the entry of c to be left, got %d entries", got)
+		return
+	}
+}
@@ -0,0 +1,8 @@
+package middlewares
+
+import "time"
+
+// SetNow replaces the clock of the RateLimiter for the tests of the package middlewares_test
+func (l *RateLimiter) SetNow(now func() time.Time) {
+	l.now = now
+}
@@ -0,0 +1,121 @@
+package middlewares
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// compressedContentTypes are not worth compressing again
+var compressedContentTypes = []string{"image/", "video/", "audio/", "application/gzip", "application/zip", "application/x-gzip"}
+
+// gzipWriter buffers the response body, so that GzipHandler can decide on compression once the size of the body is known.
+// A handler flushing the response (e.g. a stream) turns the buffering off; the body is then sent as is.
+type gzipWriter struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	streaming bool
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.body.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	if w.streaming {
+		return w.ResponseWriter.WriteString(s)
+	}
+	return w.body.WriteString(s)
+}
+
+func (w *gzipWriter) Flush() {
+	if !w.streaming {
+		w.streaming = true
+		_, _ = w.ResponseWriter.Write(w.body.Bytes())
+		w.body.Reset()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// GzipHandler compresses response bodies of at least minSize bytes with gzip if the client accepts it (Accept-Encoding).
+//
+// Responses that already have a Content-Encoding (e.g. the pre-compressed /metrics) or a compressed content type
+// (e.g. images) are sent as is.
+func GzipHandler(minSize int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// the response depends on the accepted encodings; shared caches must not mix them up
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &gzipWriter{ResponseWriter: original}
+		c.Writer = writer
+
+		c.Next()
+
+		c.Writer = original
+
+		if writer.streaming {
+			return
+		}
+
+		if writer.body.Len() == 0 || writer.body.Len() < minSize || !isCompressible(original.Header()) {
+			_, _ = original.Write(writer.body.Bytes())
+			return
+		}
+
+		original.Header().Set("Content-Encoding", "gzip")
+		original.Header().Del("Content-Length")
+
+		gz := gzip.NewWriter(original)
+		_, _ = gz.Write(writer.body.Bytes())
+		_ = gz.Close()
+	}
+}
+
+// acceptsGzip reports whether the Accept-Encoding header lists gzip (or *) without a quality value of 0
+func acceptsGzip(acceptEncoding string) bool {
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		name = strings.TrimSpace(name)
+		if !strings.EqualFold(name, "gzip") && name != "*" {
+			continue
+		}
+
+		quality, found := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !found {
+			return true
+		}
+
+		q, err := strconv.ParseFloat(quality, 64)
+		return err == nil && q > 0
+	}
+
+	return false
+}
+
+// isCompressible reports whether the response is neither encoded already nor of a compressed content type
+func isCompressible(header http.Header) bool {
+	if len(header.Get("Content-Encoding")) > 0 {
+		return false
+	}
+
+	contentType := header.Get("Content-Type")
+	for _, compressed := range compressedContentTypes {
+		if strings.HasPrefix(contentType, compressed) {
+			return false
+		}
+	}
+
+	return true
+}
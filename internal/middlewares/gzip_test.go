@@ -0,0 +1,112 @@
+package middlewares_test
+
+import (
+	"compress/gzip"
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzipHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	largeMarkdown := strings.Repeat("# Onboarding\nWelcome to the team. ", 100)
+
+	tests := []struct {
+		name            string
+		acceptEncoding  string
+		contentEncoding string
+		body            string
+		wantGzip        bool
+	}{
+		{name: "largePayload", acceptEncoding: "gzip, deflate, br", body: largeMarkdown, wantGzip: true},
+		{name: "smallPayload", acceptEncoding: "gzip", body: "# Onboarding", wantGzip: false},
+		{name: "gzipNotAccepted", acceptEncoding: "br", body: largeMarkdown, wantGzip: false},
+		{name: "gzipRejected", acceptEncoding: "gzip;q=0, br", body: largeMarkdown, wantGzip: false},
+		{name: "noAcceptEncoding", acceptEncoding: "", body: largeMarkdown, wantGzip: false},
+		{name: "alreadyEncoded", acceptEncoding: "gzip", contentEncoding: "br", body: largeMarkdown, wantGzip: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/markdown", middlewares.GzipHandler(1024), func(c *gin.Context) {
+				if len(tt.contentEncoding) > 0 {
+					c.Header("Content-Encoding", tt.contentEncoding)
+				}
+				c.String(http.StatusOK, tt.body)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/markdown", nil)
+			if len(tt.acceptEncoding) > 0 {
+				req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("want status %d, got %d", http.StatusOK, w.Code)
+				return
+			}
+
+			gotEncoding := w.Header().Get("Content-Encoding")
+			body := w.Body.String()
+			if tt.wantGzip {
+				if gotEncoding != "gzip" {
+					t.Errorf("want Content-Encoding gzip, got %q", gotEncoding)
+					return
+				}
+
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatalf("the body is not gzipped: %v", err)
+				}
+				decompressed, err := io.ReadAll(gz)
+				if err != nil {
+					t.Fatalf("the body is not gzipped: %v", err)
+				}
+				body = string(decompressed)
+			} else if gotEncoding != tt.contentEncoding {
+				t.Errorf("want Content-Encoding %q, got %q", tt.contentEncoding, gotEncoding)
+				return
+			}
+
+			if body != tt.body {
+				t.Errorf("want the body %q, got %q", tt.body, body)
+				return
+			}
+		})
+	}
+}
+
+func TestGzipHandler_flushedResponseIsNotBuffered(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	largeMarkdown := strings.Repeat("# Onboarding\nWelcome to the team. ", 100)
+
+	r := gin.New()
+	r.GET("/markdown", middlewares.GzipHandler(1024), func(c *gin.Context) {
+		c.String(http.StatusOK, largeMarkdown)
+		c.Writer.Flush()
+		c.String(http.StatusOK, largeMarkdown)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/markdown", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if gotEncoding := w.Header().Get("Content-Encoding"); len(gotEncoding) > 0 {
+		t.Errorf("want no Content-Encoding, got %q", gotEncoding)
+		return
+	}
+
+	if !w.Flushed || w.Body.String() != largeMarkdown+largeMarkdown {
+		t.Errorf("want the flushed body as is, got flushed %t and %d bytes", w.Flushed, w.Body.Len())
+		return
+	}
+}
@@ -2,18 +2,23 @@ package middlewares
 
 import (
 	"context"
-	"fmt"
+	"dice-sorensen-similarity-search/internal/api"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
+	"github.com/samborkent/uuidv7"
+	"net/http"
 	"strings"
 	"time"
 )
 
-var (
-	SigningKey = "79tesfUO0vy!U1wl7c8&EavOzmO2#W"
-)
+// ClaimsKey is the key of the validated *CimClaims in the gin context (see ClaimsFromContext)
+const ClaimsKey = "claims"
 
-func AuthHandler(authRoles ...string) gin.HandlerFunc {
+// AuthHandler rejects requests that do not carry a valid JWT signed with the given keys or whose JWT was revoked.
+// If authRoles are given, the token must also carry at least one of them.
+// The token's claims are stored in the gin context for the subsequent handlers (see ClaimsFromContext).
+func AuthHandler(keys *TokenKeys, denylist TokenDenylist, authRoles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		token := c.Request.Header.Get("Authorization")
@@ -45,17 +50,37 @@ func AuthHandler(authRoles ...string) gin.HandlerFunc {
 		}
 
 		// Validate token
-		_, err := ValidateToken(t[1], SigningKey)
+		validToken, err := ValidateToken(t[1], keys, denylist)
 		if err != nil {
-			c.JSON(403, gin.H{"message": "Invalid authorization token"})
+			c.AbortWithStatusJSON(http.StatusUnauthorized, TokenErrorResponse(err))
+			return
+		}
+
+		claims := validToken.Claims.(*CimClaims)
+
+		// Check roles
+		if len(authRoles) > 0 && !hasAnyRole(claims.Roles, authRoles) {
+			c.JSON(403, gin.H{"message": "Your request is not authorized. You are missing a required role."})
 			c.Abort()
 			return
 		}
 
+		c.Set(ClaimsKey, claims)
 		c.Next()
 	}
 }
 
+// ClaimsFromContext returns the claims AuthHandler validated; they are absent if the request passed AuthHandler without a token
+func ClaimsFromContext(c *gin.Context) (*CimClaims, bool) {
+	value, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+
+	claims, ok := value.(*CimClaims)
+	return claims, ok
+}
+
 func contains(slice []string, item string) bool {
 	set := make(map[string]struct{}, len(slice))
 	for _, s := range slice {
@@ -66,6 +91,17 @@ func contains(slice []string, item string) bool {
 	return ok
 }
 
+// hasAnyRole reports whether at least one of the given roles is among the required roles
+func hasAnyRole(roles []string, requiredRoles []string) bool {
+	for _, role := range roles {
+		if contains(requiredRoles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type CimClaims struct {
 	UserId   uint     `json:"user_id"`
 	Username string   `json:"username"`
@@ -73,33 +109,71 @@ type CimClaims struct {
 	jwt.StandardClaims
 }
 
-func GenerateToken(ctx context.Context, key []byte, userId uint, username string, roles []string) (string, time.Time, error) {
+const (
+	// DefaultTokenTTL is the lifetime of a generated token if no (positive) TTL is configured
+	DefaultTokenTTL = 12 * time.Hour
+	// DefaultRefreshTTL is the lifetime of a refreshed token if no (positive) TTL is configured
+	DefaultRefreshTTL = 12 * time.Hour
+)
 
-	expiresAt := time.Now().Add(12 * time.Hour)
+// GenerateToken creates a JWT signed with the given keys that expires after the given TTL (default: DefaultTokenTTL)
+func GenerateToken(ctx context.Context, keys *TokenKeys, ttl time.Duration, userId uint, username string, roles []string) (string, time.Time, error) {
+	if ttl <= 0 {
+		ttl = DefaultTokenTTL
+	}
+
+	expiresAt := time.Now().Add(ttl)
 	claims := CimClaims{
 		userId,
 		username,
 		roles,
 		jwt.StandardClaims{
+			// the unique ID allows revoking the token
+			Id:        uuidv7.New().String(),
 			ExpiresAt: expiresAt.Unix(),
 			IssuedAt:  time.Now().Unix(),
 			Issuer:    "dice-sorensen-search",
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenString, err := token.SignedString(key)
+	tokenString, err := keys.Sign(claims)
 	return tokenString, expiresAt, err
 }
 
-func ValidateToken(tokenString string, key string) (*jwt.Token, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &CimClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(key), nil
-	})
+// ErrTokenRevoked is returned by ValidateToken if the token's ID is on the denylist
+var ErrTokenRevoked = errors.New("token has been revoked")
+
+// error codes of the responses to tokens rejected by ValidateToken (see TokenErrorResponse)
+const (
+	TokenExpiredCode = "token_expired"
+	TokenInvalidCode = "token_invalid"
+)
+
+// TokenErrorResponse maps an error of ValidateToken to the body of a 401 response: TokenExpiredCode if the token has
+// merely expired, TokenInvalidCode otherwise (e.g. malformed, tampered with, or revoked)
+func TokenErrorResponse(err error) gin.H {
+	var validationErr *jwt.ValidationError
+	// a tampered token may have expired as well; only the expiry must have failed the validation
+	if errors.As(err, &validationErr) && validationErr.Errors == jwt.ValidationErrorExpired {
+		return api.NewCodedErrorResponse(TokenExpiredCode, "The authorization token has expired")
+	}
+
+	return api.NewCodedErrorResponse(TokenInvalidCode, "Invalid authorization token")
+}
+
+// ValidateToken parses the token and verifies its signature;
+// tokens signed with any other method than the one of the given keys are rejected,
+// as are tokens revoked via the given denylist (if any)
+func ValidateToken(tokenString string, keys *TokenKeys, denylist TokenDenylist) (*jwt.Token, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &CimClaims{}, keys.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims.(*CimClaims)
+	if denylist != nil && len(claims.Id) > 0 && denylist.IsRevoked(claims.Id) {
+		return nil, ErrTokenRevoked
+	}
 
-	return token, err
+	return token, nil
 }
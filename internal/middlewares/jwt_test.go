@@ -0,0 +1,412 @@
+package middlewares_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"encoding/pem"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rsaTokenKeys creates RS256 TokenKeys from a freshly generated key pair and returns the PEM encoded public key
+func rsaTokenKeys(t *testing.T) (*middlewares.TokenKeys, []byte) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal RSA public key: %v", err)
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+
+	keys, err := middlewares.NewRSATokenKeys(privateKeyPEM, publicKeyPEM)
+	if err != nil {
+		t.Fatalf("NewRSATokenKeys error: %v", err)
+	}
+
+	return keys, publicKeyPEM
+}
+
+func TestValidateToken(t *testing.T) {
+	hmacKeys := middlewares.NewHMACTokenKeys("the-configured-signing-key")
+	rsaKeys, _ := rsaTokenKeys(t)
+	otherRsaKeys, _ := rsaTokenKeys(t)
+
+	tests := []struct {
+		name          string
+		signingKeys   *middlewares.TokenKeys
+		verifyingKeys *middlewares.TokenKeys
+		wantValid     bool
+	}{
+		{name: "hs256SameKey", signingKeys: hmacKeys, verifyingKeys: hmacKeys, wantValid: true},
+		{name: "hs256OtherKey", signingKeys: hmacKeys, verifyingKeys: middlewares.NewHMACTokenKeys("another-signing-key"), wantValid: false},
+		{name: "rs256SameKeyPair", signingKeys: rsaKeys, verifyingKeys: rsaKeys, wantValid: true},
+		{name: "rs256OtherKeyPair", signingKeys: rsaKeys, verifyingKeys: otherRsaKeys, wantValid: false},
+		{name: "rs256TokenUnderHs256", signingKeys: rsaKeys, verifyingKeys: hmacKeys, wantValid: false},
+		{name: "hs256TokenUnderRs256", signingKeys: hmacKeys, verifyingKeys: rsaKeys, wantValid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := middlewares.GenerateToken(context.Background(), tt.signingKeys, time.Hour, 1, "user", []string{"admin"})
+			if err != nil {
+				t.Fatalf("GenerateToken error: %v", err)
+			}
+
+			got, err := middlewares.ValidateToken(token, tt.verifyingKeys, nil)
+
+			if tt.wantValid {
+				if err != nil || !got.Valid {
+					t.Errorf("want valid token, got error %v", err)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Error("want validation error, but got nil")
+				return
+			}
+		})
+	}
+}
+
+func TestValidateToken_rejectsAlgorithmConfusion(t *testing.T) {
+	rsaKeys, publicKeyPEM := rsaTokenKeys(t)
+
+	claims := middlewares.CimClaims{
+		Username:       "attacker",
+		Roles:          []string{"admin"},
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}
+
+	// the public key is public; an attacker could use it as HMAC secret and claim alg HS256
+	hs256Token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(publicKeyPEM)
+	if err != nil {
+		t.Fatalf("failed to sign HS256 token: %v", err)
+	}
+
+	noneToken, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatalf("failed to sign none token: %v", err)
+	}
+
+	for name, token := range map[string]string{"hs256": hs256Token, "none": noneToken} {
+		if _, err := middlewares.ValidateToken(token, rsaKeys, nil); err == nil {
+			t.Errorf("want %s token to be rejected under RS256, but it was accepted", name)
+		}
+	}
+
+	if _, err := middlewares.ValidateToken(noneToken, middlewares.NewHMACTokenKeys("secret"), nil); err == nil {
+		t.Error("want none token to be rejected under HS256, but it was accepted")
+	}
+}
+
+func TestLoadTokenKeys(t *testing.T) {
+	rsaKeys, publicKeyPEM := rsaTokenKeys(t)
+
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(rsaKeys.SigningKey.(*rsa.PrivateKey)),
+	})
+
+	dir := t.TempDir()
+	privateKeyFile := filepath.Join(dir, "private.pem")
+	publicKeyFile := filepath.Join(dir, "public.pem")
+	if err := os.WriteFile(privateKeyFile, privateKeyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicKeyFile, publicKeyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		algorithm      string
+		privateKeyFile string
+		publicKeyFile  string
+		wantAlg        string
+		wantErr        bool
+	}{
+		{name: "defaultsToHs256", algorithm: "", wantAlg: "HS256"},
+		{name: "hs256", algorithm: middlewares.AlgorithmHS256, wantAlg: "HS256"},
+		{name: "rs256", algorithm: middlewares.AlgorithmRS256, privateKeyFile: privateKeyFile, publicKeyFile: publicKeyFile, wantAlg: "RS256"},
+		{name: "rs256DerivedPublicKey", algorithm: middlewares.AlgorithmRS256, privateKeyFile: privateKeyFile, wantAlg: "RS256"},
+		{name: "rs256MissingPrivateKey", algorithm: middlewares.AlgorithmRS256, privateKeyFile: filepath.Join(dir, "missing.pem"), wantErr: true},
+		{name: "unsupportedAlgorithm", algorithm: "ES256", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := middlewares.LoadTokenKeys(tt.algorithm, "secret", tt.privateKeyFile, tt.publicKeyFile)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Error("want error, but got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("LoadTokenKeys error: %v", err)
+			}
+
+			if keys.Method.Alg() != tt.wantAlg {
+				t.Errorf("want algorithm %s, got %s", tt.wantAlg, keys.Method.Alg())
+				return
+			}
+
+			token, _, err := middlewares.GenerateToken(context.Background(), keys, time.Hour, 1, "user", nil)
+			if err != nil {
+				t.Fatalf("GenerateToken error: %v", err)
+			}
+
+			if _, err := middlewares.ValidateToken(token, keys, nil); err != nil {
+				t.Errorf("want valid token, got error %v", err)
+				return
+			}
+		})
+	}
+}
+
+func TestGenerateToken_expiresAt(t *testing.T) {
+	keys := middlewares.NewHMACTokenKeys("key")
+
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		wantTTL time.Duration
+	}{
+		{name: "configuredTTL", ttl: 30 * time.Minute, wantTTL: 30 * time.Minute},
+		{name: "defaultTTL", ttl: 0, wantTTL: middlewares.DefaultTokenTTL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			before := time.Now()
+			token, expiresAt, err := middlewares.GenerateToken(context.Background(), keys, tt.ttl, 1, "user", nil)
+			if err != nil {
+				t.Fatalf("GenerateToken error: %v", err)
+			}
+			after := time.Now()
+
+			if expiresAt.Before(before.Add(tt.wantTTL)) || expiresAt.After(after.Add(tt.wantTTL)) {
+				t.Errorf("want expiry in %v, got %v", tt.wantTTL, expiresAt.Sub(before))
+				return
+			}
+
+			parsed, err := middlewares.ValidateToken(token, keys, nil)
+			if err != nil {
+				t.Fatalf("ValidateToken error: %v", err)
+			}
+
+			claims := parsed.Claims.(*middlewares.CimClaims)
+			if claims.ExpiresAt != expiresAt.Unix() {
+				t.Errorf("want claim exp %d, got %d", expiresAt.Unix(), claims.ExpiresAt)
+				return
+			}
+		})
+	}
+}
+
+func TestAuthHandler_roles(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middlewares.NewHMACTokenKeys("key")
+
+	tests := []struct {
+		name          string
+		tokenRoles    []string
+		requiredRoles []string
+		wantStatus    int
+	}{
+		{name: "noRolesRequired", tokenRoles: nil, requiredRoles: nil, wantStatus: http.StatusOK},
+		{name: "requiredRolePresent", tokenRoles: []string{"admin"}, requiredRoles: []string{"admin"}, wantStatus: http.StatusOK},
+		{name: "oneOfRequiredRolesPresent", tokenRoles: []string{"reader"}, requiredRoles: []string{"reader", "admin"}, wantStatus: http.StatusOK},
+		{name: "requiredRoleMissing", tokenRoles: []string{"reader"}, requiredRoles: []string{"admin"}, wantStatus: http.StatusForbidden},
+		{name: "tokenWithoutRoles", tokenRoles: nil, requiredRoles: []string{"reader"}, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, _, err := middlewares.GenerateToken(context.Background(), keys, time.Hour, 1, "user", tt.tokenRoles)
+			if err != nil {
+				t.Fatalf("GenerateToken error: %v", err)
+			}
+
+			r := gin.New()
+			r.GET("/protected", middlewares.AuthHandler(keys, nil, tt.requiredRoles...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, w.Code)
+				return
+			}
+		})
+	}
+}
+
+func TestAuthHandler_storesClaims(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middlewares.NewHMACTokenKeys("key")
+	token, _, err := middlewares.GenerateToken(context.Background(), keys, time.Hour, 7, "jane", []string{"reader"})
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+
+	var got *middlewares.CimClaims
+	r := gin.New()
+	r.GET("/protected", middlewares.AuthHandler(keys, nil, "reader"), func(c *gin.Context) {
+		got, _ = middlewares.ClaimsFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200, got %d", w.Code)
+	}
+
+	if got == nil || got.UserId != 7 || got.Username != "jane" || len(got.Roles) != 1 || got.Roles[0] != "reader" {
+		t.Errorf("want the claims of the token in the context, got %+v", got)
+		return
+	}
+}
+
+func TestClaimsFromContext_absent(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	if claims, ok := middlewares.ClaimsFromContext(c); ok || claims != nil {
+		t.Errorf("want no claims without AuthHandler, got %+v", claims)
+		return
+	}
+}
+
+func TestAuthHandler_expiredToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middlewares.NewHMACTokenKeys("key")
+	token, err := keys.Sign(&middlewares.CimClaims{
+		Roles:          []string{"reader"},
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Minute).Unix()},
+	})
+	if err != nil {
+		t.Fatalf("Sign error: %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/protected", middlewares.AuthHandler(keys, nil, "reader"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("want status 401, got %d", w.Code)
+	}
+
+	if !strings.Contains(w.Body.String(), `"code":"`+middlewares.TokenExpiredCode+`"`) {
+		t.Errorf("want the code %q, got %s", middlewares.TokenExpiredCode, w.Body.String())
+		return
+	}
+}
+
+func TestGenerateToken_uniqueId(t *testing.T) {
+	keys := middlewares.NewHMACTokenKeys("key")
+
+	ids := map[string]struct{}{}
+	for range 100 {
+		token, _, err := middlewares.GenerateToken(context.Background(), keys, time.Hour, 1, "user", nil)
+		if err != nil {
+			t.Fatalf("GenerateToken error: %v", err)
+		}
+
+		parsed, err := middlewares.ValidateToken(token, keys, nil)
+		if err != nil {
+			t.Fatalf("ValidateToken error: %v", err)
+		}
+
+		id := parsed.Claims.(*middlewares.CimClaims).Id
+		if _, ok := ids[id]; ok || len(id) == 0 {
+			t.Fatalf("want a unique non-empty token ID, got %q", id)
+		}
+		ids[id] = struct{}{}
+	}
+}
+
+func TestAuthHandler_rejectsRevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middlewares.NewHMACTokenKeys("key")
+	denylist := middlewares.NewMemoryDenylist()
+
+	token, expiresAt, err := middlewares.GenerateToken(context.Background(), keys, time.Hour, 1, "user", []string{"reader"})
+	if err != nil {
+		t.Fatalf("GenerateToken error: %v", err)
+	}
+
+	r := gin.New()
+	r.GET("/protected", middlewares.AuthHandler(keys, denylist, "reader"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	request := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if got := request(); got != http.StatusOK {
+		t.Fatalf("want status 200 before revocation, got %d", got)
+	}
+
+	parsed, err := middlewares.ValidateToken(token, keys, denylist)
+	if err != nil {
+		t.Fatalf("ValidateToken error: %v", err)
+	}
+	denylist.Revoke(parsed.Claims.(*middlewares.CimClaims).Id, expiresAt)
+
+	if _, err := middlewares.ValidateToken(token, keys, denylist); !errors.Is(err, middlewares.ErrTokenRevoked) {
+		t.Errorf("want ErrTokenRevoked, got %v", err)
+	}
+
+	if got := request(); got != http.StatusUnauthorized {
+		t.Errorf("want status 401 after revocation, got %d", got)
+		return
+	}
+}
@@ -0,0 +1,142 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultLoginAttemptsPerMinute is the number of login attempts per client IP and per username if no (positive) limit is configured
+const DefaultLoginAttemptsPerMinute = 10
+
+// RateLimiter limits the attempts per key with one token bucket per key: a bucket holds up to attemptsPerMinute tokens,
+// each attempt takes one, and the bucket is refilled continuously at a rate of attemptsPerMinute per minute.
+// Its buckets are lost on restart and are not shared between instances.
+type RateLimiter struct {
+	mu                sync.Mutex
+	attemptsPerMinute float64
+	buckets           map[string]*tokenBucket
+	now               func() time.Time
+}
+
+type tokenBucket struct {
+	tokens   float64
+	filledAt time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing the given attempts per minute and key (default: DefaultLoginAttemptsPerMinute)
+func NewRateLimiter(attemptsPerMinute int) *RateLimiter {
+	if attemptsPerMinute <= 0 {
+		attemptsPerMinute = DefaultLoginAttemptsPerMinute
+	}
+
+	return &RateLimiter{
+		attemptsPerMinute: float64(attemptsPerMinute),
+		buckets:           map[string]*tokenBucket{},
+		now:               time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of each key; if a bucket is empty, no token is taken from any bucket,
+// and the time until the attempt would be allowed is returned
+func (l *RateLimiter) Allow(keys ...string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.evictFull(now)
+
+	var retryAfter time.Duration
+	buckets := make([]*tokenBucket, 0, len(keys))
+	for _, key := range keys {
+		bucket, ok := l.buckets[key]
+		if !ok {
+			bucket = &tokenBucket{tokens: l.attemptsPerMinute, filledAt: now}
+			l.buckets[key] = bucket
+		}
+		l.refill(bucket, now)
+
+		if bucket.tokens < 1 {
+			retryAfter = max(retryAfter, time.Duration((1-bucket.tokens)/l.attemptsPerMinute*float64(time.Minute)))
+		}
+		buckets = append(buckets, bucket)
+	}
+
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+
+	return true, 0
+}
+
+// refill adds the tokens accrued since the bucket was filled last; the caller must hold the lock
+func (l *RateLimiter) refill(bucket *tokenBucket, now time.Time) {
+	elapsed := now.Sub(bucket.filledAt)
+	bucket.tokens = min(l.attemptsPerMinute, bucket.tokens+elapsed.Minutes()*l.attemptsPerMinute)
+	bucket.filledAt = now
+}
+
+// evictFull removes the buckets that are full again, i.e., that do not limit anything; the caller must hold the lock
+func (l *RateLimiter) evictFull(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.filledAt).Minutes()*l.attemptsPerMinute >= l.attemptsPerMinute {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// LoginRateLimitHandler rejects login attempts with 429 (and a Retry-After header) once the client IP
+// or the username of the login request has exhausted its attempts (see RateLimiter).
+// The raw body is restored after reading the username, so later handlers can read it again.
+func LoginRateLimitHandler(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "The login request could not be read."})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		keys := []string{"ip:" + c.ClientIP()}
+		if username := loginUsername(body); len(username) > 0 {
+			keys = append(keys, "username:"+username)
+		}
+
+		allowed, retryAfter := limiter.Allow(keys...)
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, gin.H{"message": "Too many login attempts. Please try again later."})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// loginUsername extracts the (case-insensitive) username of a login request, e.g. {"data": {"username": "..."}};
+// an unreadable body results in an empty username, so that the login handler reports the error
+func loginUsername(body []byte) string {
+	var request struct {
+		Data struct {
+			Username string `json:"username"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return ""
+	}
+
+	return strings.ToLower(strings.TrimSpace(request.Data.Username))
+}
@@ -0,0 +1,125 @@
+package middlewares_test
+
+import (
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLoginRouter(limiter *middlewares.RateLimiter) *gin.Engine {
+	r := gin.New()
+	r.POST("/login", middlewares.LoginRateLimitHandler(limiter), func(c *gin.Context) {
+		// the login handler must still be able to read the body
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+
+	return r
+}
+
+func performLogin(r *gin.Engine, remoteAddr, username string) *httptest.ResponseRecorder {
+	body := `{"data": {"username": "` + username + `", "password": "secret"}}`
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+	req.RemoteAddr = remoteAddr
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestLoginRateLimitHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := middlewares.NewRateLimiter(2)
+	limiter.SetNow(func() time.Time { return now })
+	r := newLoginRouter(limiter)
+
+	for i := range 2 {
+		w := performLogin(r, "10.0.0.1:1234", "alice")
+		if w.Code != http.StatusOK {
+			t.Fatalf("attempt %d: want status 200, got %d", i+1, w.Code)
+		}
+		if !strings.Contains(w.Body.String(), `"alice"`) {
+			t.Fatalf("want the body to be restored, got %q", w.Body.String())
+		}
+	}
+
+	w := performLogin(r, "10.0.0.1:1234", "alice")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("want status 429 once the bucket is exhausted, got %d", w.Code)
+	}
+	// one attempt is refilled every 30 seconds
+	if got := w.Header().Get("Retry-After"); got != "30" {
+		t.Errorf("want Retry-After 30, got %q", got)
+		return
+	}
+
+	now = now.Add(time.Minute)
+
+	w = performLogin(r, "10.0.0.1:1234", "alice")
+	if w.Code != http.StatusOK {
+		t.Errorf("want status 200 after the window, got %d", w.Code)
+		return
+	}
+}
+
+func TestLoginRateLimitHandler_spoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := middlewares.NewRateLimiter(2)
+	limiter.SetNow(func() time.Time { return now })
+	r := newLoginRouter(limiter)
+	// as configured by default (see config.Configuration.TrustedProxies)
+	if err := r.SetTrustedProxies(nil); err != nil {
+		t.Fatalf("SetTrustedProxies error: %v", err)
+	}
+
+	for i := range 3 {
+		body := `{"data": {"username": "user` + strconv.Itoa(i) + `", "password": "secret"}}`
+		req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", "192.168.0."+strconv.Itoa(i))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		// the bucket of the remote address is exhausted, whatever client IP the header claims
+		if i == 2 && w.Code != http.StatusTooManyRequests {
+			t.Errorf("want status 429 despite a spoofed X-Forwarded-For, got %d", w.Code)
+			return
+		}
+	}
+}
+
+func TestLoginRateLimitHandler_perUsername(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+	limiter := middlewares.NewRateLimiter(2)
+	limiter.SetNow(func() time.Time { return now })
+	r := newLoginRouter(limiter)
+
+	performLogin(r, "10.0.0.1:1234", "alice")
+	performLogin(r, "10.0.0.2:1234", "Alice")
+
+	// the username's bucket is exhausted, regardless of the IP (and the case of the username)
+	if w := performLogin(r, "10.0.0.3:1234", "ALICE"); w.Code != http.StatusTooManyRequests {
+		t.Errorf("want status 429 for the exhausted username, got %d", w.Code)
+		return
+	}
+
+	// the IP's bucket was not consumed by the rejected attempt
+	if w := performLogin(r, "10.0.0.3:1234", "bob"); w.Code != http.StatusOK {
+		t.Errorf("want status 200 for another username, got %d", w.Code)
+		return
+	}
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"dice-sorensen-similarity-search/internal/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/samborkent/uuidv7"
+)
+
+// RequestIdHeader is the header a request ID is read from and echoed in
+const RequestIdHeader = "X-Request-ID"
+
+// RequestIdKey is the key the request ID is stored with in the gin context
+const RequestIdKey = "requestId"
+
+// maxRequestIdLength limits the length of client-supplied request IDs, as they end up in every log line of the request
+const maxRequestIdLength = 128
+
+// RequestIdHandler reads the request ID from the X-Request-ID header or generates a UUIDv7 if it is absent (or invalid).
+// The ID is stored in the gin context, echoed in the response header, and attached to the request's context,
+// where logging.GetLogTypeWithContext picks it up as correlation ID
+func RequestIdHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(RequestIdHeader)
+		if !isValidRequestId(requestId) {
+			requestId = uuidv7.New().String()
+		}
+
+		c.Set(RequestIdKey, requestId)
+		c.Request = c.Request.WithContext(logging.ContextWithCorrelationId(c.Request.Context(), requestId))
+		c.Header(RequestIdHeader, requestId)
+
+		c.Next()
+	}
+}
+
+// isValidRequestId reports whether the request ID is non-empty, not too long, and consists of printable ASCII characters only;
+// this prevents clients from injecting line breaks or control characters into the logs
+func isValidRequestId(requestId string) bool {
+	if len(requestId) == 0 || len(requestId) > maxRequestIdLength {
+		return false
+	}
+
+	for i := 0; i < len(requestId); i++ {
+		if requestId[i] < 0x21 || requestId[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,60 @@
+package middlewares_test
+
+import (
+	"dice-sorensen-similarity-search/internal/logging"
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"github.com/gin-gonic/gin"
+	"github.com/samborkent/uuidv7"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequestIdHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name         string
+		requestId    string
+		wantGenerate bool
+	}{
+		{name: "suppliedRequestId", requestId: "0190b2f6-5c1e-7a3b-9d4e-1f2a3b4c5d6e", wantGenerate: false},
+		{name: "missingRequestId", requestId: "", wantGenerate: true},
+		{name: "requestIdWithLineBreak", requestId: "abc\ninjected", wantGenerate: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotFromGin, gotFromContext string
+			r := gin.New()
+			r.GET("/", middlewares.RequestIdHandler(), func(c *gin.Context) {
+				gotFromGin = c.GetString(middlewares.RequestIdKey)
+				gotFromContext = logging.CorrelationIdFromContext(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if len(tt.requestId) > 0 {
+				req.Header.Set(middlewares.RequestIdHeader, tt.requestId)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			got := w.Header().Get(middlewares.RequestIdHeader)
+			if tt.wantGenerate {
+				if got == tt.requestId || !uuidv7.IsValidString(got) {
+					t.Errorf("want a generated UUIDv7, got %q", got)
+					return
+				}
+			} else if got != tt.requestId {
+				t.Errorf("want the request ID %q to be echoed, got %q", tt.requestId, got)
+				return
+			}
+
+			if gotFromGin != got || gotFromContext != got {
+				t.Errorf("want the request ID %q in the gin context and the request's context, got %q and %q", got, gotFromGin, gotFromContext)
+				return
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"os"
+)
+
+// supported JWT signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// TokenKeys bundles the signing method of JWTs with the keys used for signing and verifying them.
+//
+// For HS256, both keys are the same shared secret ([]byte);
+// for RS256, tokens are signed with an *rsa.PrivateKey and verified with an *rsa.PublicKey.
+type TokenKeys struct {
+	Method       jwt.SigningMethod
+	SigningKey   any
+	VerifyingKey any
+}
+
+// NewHMACTokenKeys creates TokenKeys for HS256 using the given shared secret
+func NewHMACTokenKeys(secret string) *TokenKeys {
+	return &TokenKeys{Method: jwt.SigningMethodHS256, SigningKey: []byte(secret), VerifyingKey: []byte(secret)}
+}
+
+// NewRSATokenKeys creates TokenKeys for RS256 using the given PEM encoded keys.
+// The public key is optional; if it is empty, it is derived from the private key.
+func NewRSATokenKeys(privateKeyPEM, publicKeyPEM []byte) (*TokenKeys, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing RSA private key: %w", err)
+	}
+
+	publicKey := &privateKey.PublicKey
+	if len(publicKeyPEM) > 0 {
+		publicKey, err = jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing RSA public key: %w", err)
+		}
+	}
+
+	return &TokenKeys{Method: jwt.SigningMethodRS256, SigningKey: privateKey, VerifyingKey: publicKey}, nil
+}
+
+// LoadTokenKeys creates the TokenKeys for the given algorithm; for RS256, the PEM encoded keys are read from the given files
+func LoadTokenKeys(algorithm, secret, privateKeyFile, publicKeyFile string) (*TokenKeys, error) {
+	switch algorithm {
+	case "", AlgorithmHS256:
+		return NewHMACTokenKeys(secret), nil
+
+	case AlgorithmRS256:
+		privateKeyPEM, err := os.ReadFile(privateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading RSA private key: %w", err)
+		}
+
+		var publicKeyPEM []byte
+		if len(publicKeyFile) > 0 {
+			publicKeyPEM, err = os.ReadFile(publicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("error reading RSA public key: %w", err)
+			}
+		}
+
+		return NewRSATokenKeys(privateKeyPEM, publicKeyPEM)
+
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", algorithm)
+	}
+}
+
+// Sign creates a signed JWT containing the given claims
+func (k *TokenKeys) Sign(claims jwt.Claims) (string, error) {
+	return jwt.NewWithClaims(k.Method, claims).SignedString(k.SigningKey)
+}
+
+// keyFunc returns the verifying key, but only if the token was signed with the configured method;
+// hence, a token cannot downgrade the algorithm (e.g. to "none" or from RS256 to HS256)
+func (k *TokenKeys) keyFunc(token *jwt.Token) (any, error) {
+	if token.Method == nil || token.Method.Alg() != k.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	// guards against key types that do not match the signing method (e.g. a misconfigured TokenKeys)
+	switch k.VerifyingKey.(type) {
+	case []byte:
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+			return k.VerifyingKey, nil
+		}
+	case *rsa.PublicKey:
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); ok {
+			return k.VerifyingKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("verifying key does not match signing method %s", k.Method.Alg())
+}
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WebhookSignatureHeader carries the HMAC-SHA256 signature of a webhook's payload, e.g. "sha256=<hex digest>"
+const WebhookSignatureHeader = "X-Hub-Signature"
+
+const webhookSignaturePrefix = "sha256="
+
+// WebhookSignatureHandler rejects webhook requests whose payload is not signed with the given secret (see WebhookSignatureHeader).
+// The raw body is restored after verification, so later handlers can read it again.
+// Without a secret, every request is rejected.
+func WebhookSignatureHandler(secret string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		signature := c.Request.Header.Get(WebhookSignatureHeader)
+		if len(secret) == 0 || !strings.HasPrefix(signature, webhookSignaturePrefix) {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "The webhook signature is missing."})
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "The webhook payload could not be read."})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !validWebhookSignature(secret, body, strings.TrimPrefix(signature, webhookSignaturePrefix)) {
+			c.JSON(http.StatusUnauthorized, gin.H{"message": "The webhook signature is invalid."})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// SignWebhookPayload returns the value of the WebhookSignatureHeader for the given payload
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return webhookSignaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validWebhookSignature compares the hex-encoded signature with the payload's HMAC in constant time
+func validWebhookSignature(secret string, payload []byte, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hmac.Equal(mac.Sum(nil), got)
+}
@@ -0,0 +1,61 @@
+package middlewares_test
+
+import (
+	"bytes"
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookSignatureHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret := "webhook-secret"
+	payload := []byte(`{"eventKey":"repo:refs_changed"}`)
+
+	tests := []struct {
+		name       string
+		secret     string
+		signature  string
+		wantStatus int
+	}{
+		{name: "validSignature", secret: secret, signature: middlewares.SignWebhookPayload(secret, payload), wantStatus: http.StatusOK},
+		{name: "signedWithAnotherSecret", secret: secret, signature: middlewares.SignWebhookPayload("another-secret", payload), wantStatus: http.StatusUnauthorized},
+		{name: "signatureOfAnotherPayload", secret: secret, signature: middlewares.SignWebhookPayload(secret, []byte("{}")), wantStatus: http.StatusUnauthorized},
+		{name: "signatureNotHex", secret: secret, signature: "sha256=not-hex", wantStatus: http.StatusUnauthorized},
+		{name: "signatureWithoutPrefix", secret: secret, signature: middlewares.SignWebhookPayload(secret, payload)[len("sha256="):], wantStatus: http.StatusUnauthorized},
+		{name: "missingSignature", secret: secret, signature: "", wantStatus: http.StatusUnauthorized},
+		{name: "noSecretConfigured", secret: "", signature: middlewares.SignWebhookPayload("", payload), wantStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotBody []byte
+			r := gin.New()
+			r.POST("/hook", middlewares.WebhookSignatureHandler(tt.secret), func(c *gin.Context) {
+				gotBody, _ = io.ReadAll(c.Request.Body)
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/hook", bytes.NewReader(payload))
+			if len(tt.signature) > 0 {
+				req.Header.Set(middlewares.WebhookSignatureHeader, tt.signature)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("want status %d, got %d", tt.wantStatus, w.Code)
+				return
+			}
+
+			if tt.wantStatus == http.StatusOK && !bytes.Equal(payload, gotBody) {
+				t.Errorf("want the handler to read the raw payload %s, got %s", payload, gotBody)
+				return
+			}
+		})
+	}
+}
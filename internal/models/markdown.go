@@ -1,10 +1,28 @@
 package models
 
+import (
+	"strings"
+	"time"
+)
+
 type MarkdownMeta struct {
 	Model
-	Name      string `gorm:"not null;unique" json:"name"`
-	Path      string `gorm:"not null" json:"path"`
-	CharCount uint   `gorm:"not null;default:0" json:"-"`
+	// Name and Path are unique together; files with the same name may reside in different folders
+	Name string `gorm:"not null;uniqueIndex:idx_markdown_meta_path_name,priority:2" json:"name"`
+	Path string `gorm:"not null;uniqueIndex:idx_markdown_meta_path_name,priority:1" json:"path"`
+	// CharCount is the number of characters (i.e., runes, not bytes) of the Markdown file's content
+	CharCount uint `gorm:"not null;default:0" json:"-"`
+}
+
+// IsHidden reports whether the Markdown file is hidden from the navigation and the search, i.e., whether the folder below the given docs root
+// (e.g., markdowns/.drafts/Draft) or, for a top-level file, its name (e.g., markdowns/.Draft) starts with a dot.
+// The docs root may have several segments (e.g., docs/handbook).
+//
+// The database applies the same predicate to its searches and counts (see database.GormRepository).
+func (m MarkdownMeta) IsHidden(docsRoot string) bool {
+	// the first segment below the docs root is the folder of a nested file or the name of a top-level file
+	belowDocsRoot := strings.TrimPrefix(m.Path+"/"+m.Name, strings.Trim(docsRoot, "/")+"/")
+	return strings.HasPrefix(belowDocsRoot, ".")
 }
 
 type MarkdownContent struct {
@@ -12,4 +30,41 @@ type MarkdownContent struct {
 	Content string       `gorm:"not null" json:"content"`
 	MetaID  uint         `json:"metaId" gorm:"not null;unique;foreignKey:MetaID;references:ID"`
 	Meta    MarkdownMeta `json:"markdownFile"`
+	// Hash is the hex-encoded SHA-256 of the content; unchanged files are not re-written when syncing
+	Hash string `gorm:"not null;default:''" json:"-"`
+}
+
+// MarkdownContentHash is the content hash of the Markdown file with the given (meta) name
+type MarkdownContentHash struct {
+	Name string
+	Path string
+	Hash string
+}
+
+// MarkdownDocument is a stored Markdown file as listed without its content
+type MarkdownDocument struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// CharCount is the number of characters (runes, see MarkdownMeta.CharCount)
+	CharCount uint `json:"charCount"`
+	// UpdatedAt is the time the content was written last; the meta's timestamp is not used since the metas are re-written on every sync
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// MarkdownStats aggregates all stored Markdown files
+type MarkdownStats struct {
+	// Documents is the number of Markdown files, including the hidden and empty ones
+	Documents int64 `json:"documents"`
+	// TotalChars is the sum of the files' character counts (runes, see MarkdownMeta.CharCount)
+	TotalChars int64 `json:"totalChars"`
+	// Hidden is the number of files hidden from the navigation and the search (see MarkdownMeta.IsHidden)
+	Hidden int64 `json:"hidden"`
+	// Empty is the number of files without content
+	Empty int64 `json:"empty"`
+}
+
+// ScoredMarkdownContent is a MarkdownContent together with its similarity to a search term
+type ScoredMarkdownContent struct {
+	MarkdownContent
+	Similarity float64
 }
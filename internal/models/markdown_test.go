@@ -0,0 +1,37 @@
+package models_test
+
+import (
+	"dice-sorensen-similarity-search/internal/models"
+	"testing"
+)
+
+func TestMarkdownMeta_IsHidden(t *testing.T) {
+	tests := []struct {
+		name     string
+		docsRoot string
+		meta     models.MarkdownMeta
+		want     bool
+	}{
+		{name: "topLevel", docsRoot: "markdowns", meta: models.MarkdownMeta{Name: "Home", Path: "markdowns"}},
+		{name: "hiddenTopLevel", docsRoot: "markdowns", meta: models.MarkdownMeta{Name: ".Draft", Path: "markdowns"}, want: true},
+		{name: "nested", docsRoot: "markdowns", meta: models.MarkdownMeta{Name: "Draft", Path: "markdowns/Gateway"}},
+		{name: "hiddenFolder", docsRoot: "markdowns", meta: models.MarkdownMeta{Name: "Draft", Path: "markdowns/.drafts"}, want: true},
+		{name: "belowHiddenFolder", docsRoot: "markdowns", meta: models.MarkdownMeta{Name: "Draft", Path: "markdowns/.drafts/Gateway"}, want: true},
+		// only the first segment below the docs root counts
+		{name: "hiddenSubFolder", docsRoot: "markdowns", meta: models.MarkdownMeta{Name: "Draft", Path: "markdowns/Gateway/.drafts"}},
+		{name: "multiSegmentDocsRootTopLevel", docsRoot: "docs/handbook", meta: models.MarkdownMeta{Name: "Home", Path: "docs/handbook"}},
+		{name: "multiSegmentDocsRootHiddenTopLevel", docsRoot: "docs/handbook", meta: models.MarkdownMeta{Name: ".Draft", Path: "docs/handbook"}, want: true},
+		{name: "multiSegmentDocsRootNested", docsRoot: "docs/handbook", meta: models.MarkdownMeta{Name: "Draft", Path: "docs/handbook/Gateway"}},
+		{name: "multiSegmentDocsRootHiddenFolder", docsRoot: "docs/handbook", meta: models.MarkdownMeta{Name: "Draft", Path: "docs/handbook/.drafts"}, want: true},
+		{name: "multiSegmentDocsRootWithSlashes", docsRoot: "/docs/handbook/", meta: models.MarkdownMeta{Name: "Draft", Path: "docs/handbook/.drafts"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.meta.IsHidden(tt.docsRoot); got != tt.want {
+				t.Errorf("want hidden %t for %s/%s below %s, got %t", tt.want, tt.meta.Path, tt.meta.Name, tt.docsRoot, got)
+				return
+			}
+		})
+	}
+}
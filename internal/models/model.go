@@ -1,9 +1,14 @@
 package models
 
-import "time"
+import (
+	"gorm.io/gorm"
+	"time"
+)
 
 type Model struct {
 	ID        uint      `gorm:"primarykey" json:"id"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	// DeletedAt is set if the record is soft-deleted; GORM's queries exclude soft-deleted records, the raw queries must do so explicitly
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
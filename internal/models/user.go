@@ -0,0 +1,105 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"golang.org/x/crypto/bcrypt"
+	"html"
+	"net/mail"
+	"strings"
+)
+
+// roles a User can have; the JWTs issued to the user carry them
+const (
+	RoleAdmin  = "admin"
+	RoleReader = "reader"
+)
+
+type User struct {
+	Model
+	Username string `gorm:"size:255;not null;unique" json:"username"`
+	Email    string `gorm:"size:255" json:"email"`
+	Password string `gorm:"size:100;not null" json:"-"`
+	// Roles default to RoleReader; existing users are readers too, an admin must be promoted in the database
+	Roles Roles `gorm:"size:255;not null;default:reader" json:"roles"`
+}
+
+// Roles are stored as a comma-separated list
+type Roles []string
+
+// GormDataType maps Roles to a string column
+func (r Roles) GormDataType() string {
+	return "string"
+}
+
+func (r Roles) Value() (driver.Value, error) {
+	return strings.Join(r, ","), nil
+}
+
+func (r *Roles) Scan(value any) error {
+	var s string
+	switch v := value.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Roles", value)
+	}
+
+	*r = nil
+	for _, role := range strings.Split(s, ",") {
+		if role = strings.TrimSpace(role); len(role) > 0 {
+			*r = append(*r, role)
+		}
+	}
+
+	return nil
+}
+
+// Hash creates a bcrypt hash of the given password with the given cost; a cost below bcrypt.MinCost selects bcrypt.DefaultCost
+func Hash(password string, cost int) ([]byte, error) {
+	return bcrypt.GenerateFromPassword([]byte(password), cost)
+}
+
+// VerifyPassword compares a bcrypt hashed password with its possible plaintext equivalent
+func VerifyPassword(hashedPassword, password string) error {
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+}
+
+// Prepare sanitizes the user's input before it is validated or persisted
+func (u *User) Prepare() {
+	u.Username = html.EscapeString(strings.TrimSpace(u.Username))
+	u.Email = html.EscapeString(strings.TrimSpace(u.Email))
+	if len(u.Roles) == 0 {
+		u.Roles = Roles{RoleReader}
+	}
+}
+
+// Validate checks that the user carries the properties required for a login;
+// the email is optional, but must be well-formed if present
+func (u *User) Validate() error {
+	if len(u.Username) == 0 {
+		return errors.New("required username")
+	}
+
+	if len(u.Password) == 0 {
+		return errors.New("required password")
+	}
+
+	if len(u.Email) > 0 {
+		if _, err := mail.ParseAddress(u.Email); err != nil {
+			return errors.New("invalid email")
+		}
+	}
+
+	for _, role := range u.Roles {
+		if role != RoleAdmin && role != RoleReader {
+			return fmt.Errorf("invalid role %q", role)
+		}
+	}
+
+	return nil
+}
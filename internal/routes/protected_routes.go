@@ -6,27 +6,57 @@ import (
 	"dice-sorensen-similarity-search/internal/constants"
 	"dice-sorensen-similarity-search/internal/markdowndoc"
 	"dice-sorensen-similarity-search/internal/middlewares"
+	"dice-sorensen-similarity-search/internal/models"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterProtectedRoutes(r *gin.Engine, controllerRegistry map[int]any) {
+// roles carried by the JWTs
+const (
+	RoleAdmin  = models.RoleAdmin
+	RoleReader = models.RoleReader
+)
 
-	authGroup := r.Group("")
+func RegisterProtectedRoutes(r *gin.Engine, controllerRegistry map[int]any, tokenKeys *middlewares.TokenKeys, denylist middlewares.TokenDenylist) {
 
-	authGroup.Use(middlewares.AuthHandler())
+	// admins may also use the read endpoints
+	readerGroup := r.Group("")
+	readerGroup.Use(middlewares.AuthHandler(tokenKeys, denylist, RoleReader, RoleAdmin))
 	{
-		// bitbucket
-		bitbucketApi := controllerRegistry[constants.Bitbucket].(bitbucket.Api)
-		authGroup.GET("/bitbucket/markdowns", bitbucketApi.FetchMarkdownsFromBitbucket)
+		// auth
+		authApi := controllerRegistry[constants.Auth].(auth.Api)
+		readerGroup.GET("/markdown-doc/token", authApi.GetAuthToken)
+		readerGroup.POST("/auth/logout", authApi.Logout)
+		readerGroup.GET("/auth/me", authApi.Me)
 
+		// markdown doc
+		markdownDocApi := controllerRegistry[constants.MarkdownDoc].(markdowndoc.Api)
+		readerGroup.GET("/markdown-doc/navigation-items", markdownDocApi.GetNavigationItemsTrees)
+		readerGroup.GET("/markdown-doc/breadcrumb/:href", markdownDocApi.GetBreadcrumb)
+		readerGroup.GET("/markdown-doc/tree/:root", markdownDocApi.GetNavigationItemTree)
+		readerGroup.POST("/markdown-doc/navigation-search", markdownDocApi.GetNavigationSearchMatches)
+		readerGroup.GET("/markdown-doc/markdown/:name", markdownDocApi.GetMarkdownByName)
+		readerGroup.GET("/markdown-doc/markdown-by-path", markdownDocApi.GetMarkdownByPath)
+		readerGroup.POST("/markdown-doc/markdown/search", markdownDocApi.GetMarkdownSearchTermMatches)
+		readerGroup.POST("/markdown-doc/similarity", markdownDocApi.GetSimilarity)
+		readerGroup.GET("/markdown-doc/documents", markdownDocApi.GetDocuments)
+		readerGroup.GET("/markdown-doc/related/:name", markdownDocApi.GetRelatedDocuments)
+	}
+
+	adminGroup := r.Group("")
+	adminGroup.Use(middlewares.AuthHandler(tokenKeys, denylist, RoleAdmin))
+	{
 		// auth
 		authApi := controllerRegistry[constants.Auth].(auth.Api)
-		authGroup.GET("/markdown-doc/token", authApi.GetAuthToken)
+		adminGroup.POST("/auth/users", authApi.CreateUser)
 
 		// markdown doc
 		markdownDocApi := controllerRegistry[constants.MarkdownDoc].(markdowndoc.Api)
-		authGroup.GET("/markdown-doc/navigation-items", markdownDocApi.GetNavigationItemsTrees)
-		authGroup.GET("/markdown-doc/markdown/:name", markdownDocApi.GetMarkdownByName)
-		authGroup.POST("/markdown-doc/markdown/search", markdownDocApi.GetMarkdownSearchTermMatches)
+		adminGroup.GET("/markdown-doc/stats", markdownDocApi.GetStats)
+
+		// bitbucket
+		bitbucketApi := controllerRegistry[constants.Bitbucket].(bitbucket.Api)
+		adminGroup.GET("/bitbucket/markdowns", bitbucketApi.FetchMarkdownsFromBitbucket)
+		adminGroup.POST("/admin/reindex", bitbucketApi.Reindex)
+		adminGroup.GET("/markdown-doc/sync-status", bitbucketApi.GetSyncStatus)
 	}
 }
@@ -0,0 +1,70 @@
+package routes_test
+
+import (
+	"dice-sorensen-similarity-search/internal/auth"
+	"dice-sorensen-similarity-search/internal/bitbucket"
+	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/database"
+	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/markdowndoc"
+	"dice-sorensen-similarity-search/internal/middlewares"
+	"dice-sorensen-similarity-search/internal/routes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRegisterProtectedRoutes_genericTokenIsNoAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	keys := middlewares.NewHMACTokenKeys("key")
+	env := environment.Environment(&database.NullRepository{}, nil)
+	controllerRegistry := map[int]any{
+		constants.Auth:        &auth.Controller{Env: env, AuthService: &auth.AuthService{Env: env}, TokenKeys: keys},
+		constants.MarkdownDoc: &markdowndoc.Controller{Env: env},
+		constants.Bitbucket:   &bitbucket.Controller{Env: env},
+	}
+
+	r := gin.New()
+	routes.RegisterProtectedRoutes(r, controllerRegistry, keys, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/markdown-doc/token", nil)
+	req.Header.Set("Authorization", "generic")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200 for the generic token, got %d: %s", w.Code, w.Body.String())
+	}
+
+	var body struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	adminRoutes := []struct{ method, path string }{
+		{method: http.MethodPost, path: "/auth/users"},
+		{method: http.MethodGet, path: "/markdown-doc/stats"},
+		{method: http.MethodGet, path: "/bitbucket/markdowns"},
+		{method: http.MethodPost, path: "/admin/reindex"},
+		{method: http.MethodGet, path: "/markdown-doc/sync-status"},
+	}
+
+	for _, route := range adminRoutes {
+		t.Run(route.method+route.path, func(t *testing.T) {
+			req := httptest.NewRequest(route.method, route.path, nil)
+			req.Header.Set("Authorization", "Bearer "+body.Data)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusForbidden {
+				t.Errorf("want status 403, got %d", w.Code)
+				return
+			}
+		})
+	}
+}
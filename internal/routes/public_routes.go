@@ -1,14 +1,19 @@
 package routes
 
 import (
+	"dice-sorensen-similarity-search/internal/auth"
 	"dice-sorensen-similarity-search/internal/bitbucket"
 	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterPublicRoutes(r *gin.Engine, controllerRegistry map[int]any) {
+func RegisterPublicRoutes(r *gin.Engine, controllerRegistry map[int]any, webhookSecret string, loginRateLimiter *middlewares.RateLimiter) {
 	//r.GET("/something", controllers.Something)
-	r.POST("/hook", func(c *gin.Context) {
+	authApi := controllerRegistry[constants.Auth].(auth.Api)
+	r.POST("/login", middlewares.LoginRateLimitHandler(loginRateLimiter), authApi.Login)
+
+	r.POST("/hook", middlewares.WebhookSignatureHandler(webhookSecret), func(c *gin.Context) {
 		bitbucketApi := controllerRegistry[constants.Bitbucket].(bitbucket.Api)
 		bitbucketApi.FetchMarkdownsFromBitbucket(c)
 	})
@@ -2,17 +2,25 @@ package routes
 
 import (
 	"dice-sorensen-similarity-search/internal/middlewares"
+	"dice-sorensen-similarity-search/internal/tracing"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func InitRouter(engine *gin.Engine, controllerRegistry map[int]any) {
-	InitMiddleware(engine)
+func InitRouter(engine *gin.Engine, controllerRegistry map[int]any, tokenKeys *middlewares.TokenKeys, denylist middlewares.TokenDenylist, allowedOrigins []string, webhookSecret string, compressionMinSize int, maxRequestBodyBytes int64, loginRateLimiter *middlewares.RateLimiter) {
+	InitMiddleware(engine, allowedOrigins, compressionMinSize, maxRequestBodyBytes)
 
-	RegisterProtectedRoutes(engine, controllerRegistry)
-	RegisterPublicRoutes(engine, controllerRegistry)
-	RegisterUtilityRoutes(engine)
+	RegisterProtectedRoutes(engine, controllerRegistry, tokenKeys, denylist)
+	RegisterPublicRoutes(engine, controllerRegistry, webhookSecret, loginRateLimiter)
+	RegisterUtilityRoutes(engine, controllerRegistry)
 }
 
-func InitMiddleware(engine *gin.Engine) {
-	engine.Use(middlewares.CORSMiddleware())
+func InitMiddleware(engine *gin.Engine, allowedOrigins []string, compressionMinSize int, maxRequestBodyBytes int64) {
+	// runs first, so that every subsequent middleware and handler can log with the request's correlation ID
+	engine.Use(middlewares.RequestIdHandler())
+	// creates the root span of each request; it uses the global tracer provider, which is a no-op unless tracing is configured
+	engine.Use(otelgin.Middleware(tracing.ServiceName))
+	engine.Use(middlewares.CORSMiddleware(allowedOrigins))
+	engine.Use(middlewares.BodyLimitHandler(maxRequestBodyBytes))
+	engine.Use(middlewares.GzipHandler(compressionMinSize))
 }
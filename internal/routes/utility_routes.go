@@ -1,11 +1,19 @@
 package routes
 
 import (
+	"dice-sorensen-similarity-search/internal/constants"
 	"dice-sorensen-similarity-search/internal/controllers"
+	"dice-sorensen-similarity-search/internal/metrics"
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterUtilityRoutes(r *gin.Engine) {
+func RegisterUtilityRoutes(r *gin.Engine, controllerRegistry map[int]any) {
 	r.GET("/heartbeat", controllers.GetHeartBeat)
 	r.GET("/status", controllers.GetStatus)
+
+	readinessController := controllerRegistry[constants.Readiness].(*controllers.ReadinessController)
+	r.GET("/readiness", readinessController.GetReadiness)
+
+	m := controllerRegistry[constants.Metrics].(*metrics.Metrics)
+	r.GET("/metrics", gin.WrapH(m.Handler()))
 }
@@ -1,24 +1,30 @@
 package main
 
 import (
+	"context"
 	"dice-sorensen-similarity-search/internal/auth"
 	"dice-sorensen-similarity-search/internal/bitbucket"
 	"dice-sorensen-similarity-search/internal/config"
 	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/controllers"
 	"dice-sorensen-similarity-search/internal/database"
 	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/github"
 	"dice-sorensen-similarity-search/internal/logging"
 	"dice-sorensen-similarity-search/internal/markdowndoc"
+	"dice-sorensen-similarity-search/internal/metrics"
+	"dice-sorensen-similarity-search/internal/middlewares"
 	"dice-sorensen-similarity-search/internal/routes"
+	"dice-sorensen-similarity-search/internal/tracing"
 	"fmt"
 	"github.com/gin-contrib/zap"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 	"go.uber.org/zap/zapio"
 	"golang.org/x/text/collate"
 	"golang.org/x/text/language"
 	"io"
-	"net/http/httptest"
 	"os"
 	"os/signal"
 	"strings"
@@ -34,7 +40,22 @@ func main() {
 
 	logger := logging.InitLogging(c)
 
-	controllerRegistry, err := injectDependencies(c, logger)
+	shutdownTracing, err := tracing.InitTracing(c)
+	if err != nil {
+		logger.LogErrorf(logging.GetLogTypeInitialization(), "initializing tracing failed: %s", err.Error())
+		return
+	}
+
+	tokenKeys, err := middlewares.LoadTokenKeys(c.Auth.Algorithm, c.Auth.SigningKey, c.Auth.PrivateKeyFile, c.Auth.PublicKeyFile)
+	if err != nil {
+		logger.LogErrorf(logging.GetLogTypeInitialization(), "loading JWT keys failed: %s", err.Error())
+		return
+	}
+
+	// revoked tokens are kept in memory; hence, a logout does not survive a restart
+	denylist := middlewares.NewMemoryDenylist()
+
+	controllerRegistry, err := injectDependencies(c, logger, tokenKeys, denylist)
 	if err != nil {
 		logger.LogErrorf(nil, "injecting depencies failed: %s", err.Error())
 		return
@@ -49,31 +70,40 @@ func main() {
 		gin.SetMode(gin.ReleaseMode)
 	}
 	r := gin.New()
+	// without trusted proxies, a client could spoof its IP with an X-Forwarded-For header
+	err = r.SetTrustedProxies(config.Config().TrustedProxies)
+	if err != nil {
+		logger.LogErrorf(nil, "setting the trusted proxies failed: %s", err.Error())
+		return
+	}
 
 	r.Use(
 		ginzap.GinzapWithConfig(ginLogger, &ginzap.Config{
 			TimeFormat: time.RFC3339,
 			UTC:        false,
-			SkipPaths:  []string{"/status"},
+			SkipPaths:  []string{"/status", "/readiness", "/metrics"},
+			Context: func(c *gin.Context) []zapcore.Field {
+				return []zapcore.Field{zap.String(middlewares.RequestIdKey, c.GetString(middlewares.RequestIdKey))}
+			},
 		}),
 		ginzap.RecoveryWithZap(ginLogger, true),
 	)
 
 	// Routes
-	routes.InitRouter(r, controllerRegistry)
+	routes.InitRouter(r, controllerRegistry, tokenKeys, denylist, config.Config().Cors.AllowedOrigins, config.Config().BitBucket.WebhookSecret, config.Config().Compression.MinSize, config.Config().MaxRequestBodyBytes, middlewares.NewRateLimiter(config.Config().Auth.LoginAttemptsPerMinute))
 
-	SetupCloseHandler(logger)
+	SetupCloseHandler(logger, shutdownTracing, controllerRegistry)
 	go func() {
 		checkAllInitializations(logger)
-		// fetch markdowns on startup
-		ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
-		bitbucketController := controllerRegistry[constants.Bitbucket].(bitbucket.Api)
-		bitbucketController.FetchMarkdownsFromBitbucket(ctx)
+		// fetch markdowns on startup; the sync is retried until the source is reachable
+		bitbucketController := controllerRegistry[constants.Bitbucket].(*bitbucket.Controller)
+		_ = bitbucketController.SyncOnStartup(context.Background())
+	}()
+	go func() {
+		// purges the markdowns soft-deleted by the syncs once their retention window elapsed
+		housekeeper := controllerRegistry[constants.Bitbucket].(*bitbucket.Controller).MarkdownHousekeeper.(*bitbucket.DefaultMarkdownHousekeeper)
+		_ = housekeeper.RunPurgeJob(context.Background())
 	}()
-
-	if len(config.Config().ListeningAddress) == 0 && len(config.Config().ListeningPort) == 0 {
-		panic("No listening address/port provided")
-	}
 
 	logger.LogInfof(nil, "API running. Listening on %s:%s", config.Address(), config.Port())
 
@@ -84,7 +114,55 @@ func main() {
 	}
 }
 
-func injectDependencies(config *config.Configuration, logger logging.Logger) (map[int]any, error) {
+// initMarkdownReader initializes the reader of the configured Source.Provider
+// and returns it together with the project (or owner), repository, and revision to read.
+// The initialization is not fatal: if it fails (e.g., since the source is unreachable), the reader is degraded
+// and retries it on use (see bitbucket.LazyReader), so that the API still serves the Markdown files stored in the database.
+func initMarkdownReader(config *config.Configuration, env *environment.Env) (reader *bitbucket.LazyReader, projectName, repositoryName, revision string) {
+	initReader := func() (bitbucket.BitbucketReader, error) {
+		r, err := bitbucket.InitBitbucket(context.Background(), config, env)
+		if err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+	projectName, repositoryName, revision = config.BitBucket.ProjectName, config.BitBucket.Repository, config.BitBucket.Revision
+
+	if config.Source.Provider == constants.SourceProviderGitHub {
+		initReader = func() (bitbucket.BitbucketReader, error) {
+			r, err := github.InitGitHub(context.Background(), config, env)
+			if err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+		projectName, repositoryName, revision = config.GitHub.Owner, config.GitHub.Repository, config.GitHub.Revision
+	}
+
+	// serves the Markdown files stored in the database only; a sync reads no files and therefore changes nothing
+	if config.Source.Provider == constants.SourceProviderNone {
+		initReader = func() (bitbucket.BitbucketReader, error) {
+			return &bitbucket.NullBitbucketReader{}, nil
+		}
+		projectName, repositoryName, revision = "", "", ""
+	}
+
+	initialReader, err := initReader()
+	if err != nil {
+		env.LogErrorf(logging.GetLogTypeInitialization(), "Error initializing %s Api; retrying on the next sync: %v", config.Source.Provider, err)
+	}
+
+	return bitbucket.NewLazyReader(initialReader, initReader), projectName, repositoryName, revision
+}
+
+func injectDependencies(config *config.Configuration, logger logging.Logger, tokenKeys *middlewares.TokenKeys, denylist middlewares.TokenDenylist) (map[int]any, error) {
+	// the scorers are registered in code; hence, the name cannot be validated when loading the config
+	if len(config.Search.Scorer) > 0 {
+		if _, ok := markdowndoc.LookupScorer(config.Search.Scorer); !ok {
+			return nil, fmt.Errorf("unknown scorer %q; must be one of %s", config.Search.Scorer, strings.Join(markdowndoc.ScorerNames(), ", "))
+		}
+	}
+
 	db, err := database.InitDatabase(config, logger)
 	if err != nil {
 		logger.LogError(nil, "error initializing database: ", err)
@@ -92,42 +170,101 @@ func injectDependencies(config *config.Configuration, logger logging.Logger) (ma
 	}
 
 	env := environment.Environment(
-		&database.GormRepository{DB: db},
+		&database.GormRepository{DB: db, DocsRoot: config.BitBucket.DocsRoot, MinSearchCharCount: config.Search.MinCharCount, UpsertBatchSize: config.Database.UpsertBatchSize},
 		logger,
 	)
 
-	bitbucketReader, err := bitbucket.InitBitbucket(config, env)
-	if err != nil {
-		logger.LogErrorf(logging.GetLogTypeInitialization(), "Error initializing Bitbucket Api: %v", err)
-		return nil, err
+	markdownReader, projectName, repositoryName, revision := initMarkdownReader(config, env)
+
+	// the trigram cache is shared: the search reads from it, the Bitbucket sync invalidates it
+	trigramCache := markdowndoc.NewTrigramCache()
+
+	// the search cache is shared likewise; a negative size disables it
+	var searchCache *markdowndoc.SearchCache
+	if config.Search.CacheSize >= 0 {
+		var ttl time.Duration
+		if config.Search.CacheTTL != nil {
+			ttl = config.Search.CacheTTL.Duration
+		}
+		searchCache = markdowndoc.NewSearchCache(ttl, config.Search.CacheSize)
+	}
+
+	// the collectors are exposed on GET /metrics
+	m := metrics.New()
+
+	housekeeper := &bitbucket.DefaultMarkdownHousekeeper{Env: env}
+	if config.Database.SoftDeleteRetention != nil {
+		housekeeper.SoftDeleteRetention = config.Database.SoftDeleteRetention.Duration
+	}
+	if config.Database.PurgeInterval != nil {
+		housekeeper.PurgeInterval = config.Database.PurgeInterval.Duration
 	}
 
 	bitbucketController := &bitbucket.Controller{
-		Env:                 env,
-		BitbucketReader:     bitbucketReader,
-		ProjectName:         config.BitBucket.ProjectName,
-		RepositoryName:      config.BitBucket.Repository,
-		MarkdownHousekeeper: &bitbucket.DefaultMarkdownHousekeeper{Env: env},
+		Env:                    env,
+		BitbucketReader:        markdownReader,
+		ProjectName:            projectName,
+		RepositoryName:         repositoryName,
+		Revision:               revision,
+		FetchConcurrency:       config.BitBucket.FetchConcurrency,
+		StreamPageSize:         config.BitBucket.StreamPageSize,
+		MaxFileBytes:           config.BitBucket.MaxFileBytes,
+		SyncMaxDeletionPercent: config.BitBucket.SyncMaxDeletionPercent,
+		Extensions:             config.BitBucket.Extensions,
+		MarkdownHousekeeper:    housekeeper,
+		ContentCache:           bitbucket.ContentCaches{trigramCache, searchCache},
+		SyncMetrics:            m,
 	}
 
 	// the Collator is used for lexicographic order with locale-aware sorting (like filesystems do),
 	// instead of Go's default pure Unicode code point ordering
 	c := collate.New(language.English)
 	markdownDocController := &markdowndoc.Controller{
-		Env:                       env,
-		NavigationItemTreeService: markdowndoc.NavigationItemTreeService{Env: env, Collator: c},
-		MarkdownSearchMatchMapper: markdowndoc.MarkdownSearchMatchMapper{Env: env},
+		Env: env,
+		NavigationItemTreeService: markdowndoc.NavigationItemTreeService{
+			Env:                 env,
+			Collator:            c,
+			DocsRoot:            config.BitBucket.DocsRoot,
+			CollapseSingleChild: config.Navigation.CollapseSingleChild,
+			MaxDepth:            config.Navigation.MaxDepth,
+			LandingPageNames:    config.Navigation.LandingPageNames,
+		},
+		MarkdownSearchMatchMapper: markdowndoc.MarkdownSearchMatchMapper{Env: env, SnippetWindow: config.Search.SnippetWindow, MaxSnippetLength: config.Search.MaxSnippetLength},
+		TrigramCache:              trigramCache,
+		SearchEngine:              config.Search.Engine,
+		SearchBackend:             config.Search.Backend,
+		SimilarityMetric:          config.Search.Metric,
+		Scorer:                    config.Search.Scorer,
+		TitleWeight:               config.Search.TitleWeight,
+		Tokenizer:                 markdowndoc.NewTokenizer(config.Search.FoldAccents, config.Search.StopWords),
+		DefaultPageSize:           config.Search.DefaultPageSize,
+		MaxPageSize:               config.Search.MaxPageSize,
+		SearchAnalytics:           config.Search.Analytics,
+		SearchMetrics:             m,
+		SearchCache:               searchCache,
+		MaxRelatedDocuments:       config.Search.MaxRelatedDocuments,
 	}
 
 	authController := &auth.Controller{
 		Env:         env,
 		AuthService: &auth.AuthService{Env: env},
+		TokenKeys:   tokenKeys,
+		Denylist:    denylist,
+		BcryptCost:  config.Auth.BcryptCost,
+	}
+	if config.Auth.TokenTTL != nil {
+		authController.TokenTTL = config.Auth.TokenTTL.Duration
+	}
+	if config.Auth.RefreshTTL != nil {
+		authController.RefreshTTL = config.Auth.RefreshTTL.Duration
 	}
 
 	controllerRegistry := make(map[int]any)
 	controllerRegistry[constants.Bitbucket] = bitbucketController
 	controllerRegistry[constants.MarkdownDoc] = markdownDocController
 	controllerRegistry[constants.Auth] = authController
+	controllerRegistry[constants.Readiness] = &controllers.ReadinessController{Env: env}
+	controllerRegistry[constants.Metrics] = m
 
 	return controllerRegistry, nil
 }
@@ -173,14 +310,54 @@ func checkAllInitializations(logger logging.Logger) {
 	}
 }
 
-func SetupCloseHandler(logger logging.Logger) {
-	c := make(chan os.Signal)
-	signal.Notify(c, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+func SetupCloseHandler(logger logging.Logger, shutdownTracing func(context.Context) error, controllerRegistry map[int]any) {
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
+	go func() {
+		for range reload {
+			reloadConfig(logger, controllerRegistry)
+		}
+	}()
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
 	go func() {
 		<-c
 		fmt.Println()
 		logger.LogWarnf(nil, "Cleaning up...")
+
+		// flushes the spans that were not exported yet
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if err := shutdownTracing(ctx); err != nil {
+			logger.LogErrorf(nil, "shutting down tracing failed: %s", err.Error())
+		}
+		cancel()
+
 		time.Sleep(1 * time.Second)
 		os.Exit(1)
 	}()
 }
+
+// reloadConfig re-reads the config file (on SIGHUP) and applies its log levels and search thresholds;
+// changes of other fields are only logged since they require a restart
+func reloadConfig(logger logging.Logger, controllerRegistry map[int]any) {
+	c, restartRequired, err := config.Reload()
+	if err != nil {
+		logger.LogErrorf(nil, "reloading the config failed; keeping the current one: %s", err.Error())
+		return
+	}
+
+	logging.SetLevels(c)
+	logger.LogInfof(nil, "config reloaded; log level %s, console log level %s", c.Logging.Level, c.Logging.ConsoleLogLevel)
+
+	// the repository is updated first, so the controller invalidates the search pages cached until then
+	markdownDocController := controllerRegistry[constants.MarkdownDoc].(*markdowndoc.Controller)
+	if repo, ok := markdownDocController.Repository.(*database.GormRepository); ok {
+		repo.SetMinSearchCharCount(c.Search.MinCharCount)
+	}
+	markdownDocController.SetSearchThresholds(c.Search.TitleWeight, c.Search.MaxPageSize)
+
+	if len(restartRequired) > 0 {
+		logger.LogWarnf(nil, "changes of %s require a restart to take effect", strings.Join(restartRequired, ", "))
+	}
+}
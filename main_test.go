@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"dice-sorensen-similarity-search/internal/config"
+	"dice-sorensen-similarity-search/internal/constants"
+	"dice-sorensen-similarity-search/internal/environment"
+	"dice-sorensen-similarity-search/internal/logging"
+	"go.uber.org/zap"
+	"strings"
+	"testing"
+)
+
+func TestInitMarkdownReader_degradedIfInitBitbucketFails(t *testing.T) {
+	c := &config.Configuration{}
+	c.Source.Provider = constants.SourceProviderBitbucket
+	// InitBitbucket fails since the url is not set
+	c.BitBucket.ProjectName = "project"
+	c.BitBucket.Repository = "repo"
+
+	env := environment.Null()
+	env.Logger = logging.DefaultLogger{Logger: zap.NewNop().Sugar()}
+
+	reader, projectName, repositoryName, _ := initMarkdownReader(c, env)
+	if reader == nil {
+		t.Fatal("want a degraded reader, got nil")
+	}
+
+	if !reader.Degraded() {
+		t.Error("want a degraded reader since InitBitbucket failed")
+		return
+	}
+
+	if projectName != "project" || repositoryName != "repo" {
+		t.Errorf("want project and repo, got %s and %s", projectName, repositoryName)
+		return
+	}
+
+	// a sync fails (and is retried later) instead of aborting the startup
+	if _, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), projectName, repositoryName, "", 0, 10); err == nil {
+		t.Error("want the initialization error on use, got nil")
+		return
+	}
+}
+
+func TestInitMarkdownReader_noSource(t *testing.T) {
+	c := &config.Configuration{}
+	c.Source.Provider = constants.SourceProviderNone
+
+	reader, _, _, _ := initMarkdownReader(c, environment.Null())
+	if reader.Degraded() {
+		t.Fatal("want an initialized reader since no source is needed")
+	}
+
+	files, err := reader.ReadMarkdownFileStructureRecursively(context.Background(), "", "", "", 0, 10)
+	if err != nil || len(files) != 0 {
+		t.Errorf("want no files and no error, got %v and %v", files, err)
+		return
+	}
+}
+
+func TestInjectDependencies_unknownScorer(t *testing.T) {
+	c := &config.Configuration{}
+	c.Search.Scorer = "unknown"
+
+	// fails before connecting to the database
+	if _, err := injectDependencies(c, logging.NullLogger{}, nil, nil); err == nil || !strings.Contains(err.Error(), "unknown scorer") {
+		t.Errorf("want an unknown scorer error, got %v", err)
+		return
+	}
+}